@@ -0,0 +1,13 @@
+package v1alpha1
+
+const (
+	// AnnotationAdopt makes the operator adopt the milvus installed by the helm chart as the release of the same name
+	// when it's "true", it's removed once the release is adopted
+	AnnotationAdopt = "milvus.io/adopt"
+)
+
+// IsAdopting returns whether the Milvus is waiting for the operator to adopt the helm release of the same name,
+// the prefixes and the dependencies are mapped from the release then
+func (r *Milvus) IsAdopting() bool {
+	return r.Annotations[AnnotationAdopt] == "true"
+}
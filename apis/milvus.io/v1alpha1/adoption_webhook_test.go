@@ -0,0 +1,50 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMilvus_Default_Adopting(t *testing.T) {
+	m := Milvus{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "m"}}
+	m.Annotations = map[string]string{AnnotationAdopt: "true"}
+	assert.True(t, m.IsAdopting())
+	m.Default()
+	assert.Empty(t, m.Spec.Dep.Etcd.RootPath)
+	assert.Empty(t, m.Spec.Dep.Storage.BucketName)
+	assert.Empty(t, m.Spec.Dep.MsgChannelPrefix)
+
+	m.Annotations[AnnotationAdopt] = "false"
+	assert.False(t, m.IsAdopting())
+	m.Default()
+	assert.Equal(t, "ns-m", m.Spec.Dep.Etcd.RootPath)
+}
+
+func TestMilvus_ValidateUpdate_Adopting(t *testing.T) {
+	old := Milvus{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "m"}}
+	old.Annotations = map[string]string{AnnotationAdopt: "true"}
+	old.Default()
+
+	// the release is mapped
+	new := old.DeepCopy()
+	delete(new.Annotations, AnnotationAdopt)
+	new.Spec.Mode = MilvusModeCluster
+	new.Spec.Dep.Etcd.External = true
+	new.Spec.Dep.Etcd.Endpoints = []string{"m-etcd.ns:2379"}
+	new.Spec.Dep.Etcd.RootPath = "by-dev"
+	new.Spec.Dep.Storage.BucketName = "milvus-bucket"
+	new.Spec.Dep.MsgChannelPrefix = "by-dev"
+	new.Default()
+	assert.NoError(t, new.ValidateUpdate(&old))
+
+	// immutable once adopted
+	newer := new.DeepCopy()
+	newer.Spec.Dep.Etcd.RootPath = "m"
+	newer.Spec.Dep.Etcd.External = false
+	err := newer.ValidateUpdate(new)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "spec.dependencies.etcd.rootPath")
+	assert.Contains(t, err.Error(), "spec.dependencies.etcd.external")
+}
@@ -1,6 +1,7 @@
 package v1alpha1
 
 import (
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 )
 
@@ -84,6 +85,35 @@ type MilvusComponents struct {
 	QueryNode MilvusQueryNode `json:"queryNode,omitempty"`
 }
 
+// GetComponents returns the components of all types, in the order of MilvusComponentTypes
+func (c *MilvusComponents) GetComponents() []*Component {
+	return []*Component{
+		&c.RootCoord.Component,
+		&c.DataCoord.Component,
+		&c.QueryCoord.Component,
+		&c.IndexCoord.Component,
+		&c.DataNode.Component,
+		&c.QueryNode.Component,
+		&c.IndexNode.Component,
+		&c.Proxy.Component,
+	}
+}
+
+// IsActiveStandby returns whether the coordinator of the type runs in active-standby mode
+func (c *MilvusComponents) IsActiveStandby(t ComponentType) bool {
+	switch t {
+	case RootCoord:
+		return c.RootCoord.ActiveStandby
+	case DataCoord:
+		return c.DataCoord.ActiveStandby
+	case QueryCoord:
+		return c.QueryCoord.ActiveStandby
+	case IndexCoord:
+		return c.IndexCoord.ActiveStandby
+	}
+	return false
+}
+
 type Component struct {
 	ComponentSpec `json:",inline"`
 
@@ -96,18 +126,105 @@ type Component struct {
 	// +kubebuilder:validation:Minimum=0
 	// +kubebuilder:validation:Maximum=65535
 	Port int32 `json:"port,omitempty"`
+
+	// Conf is merged into the rendered config of this component only
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Conf Values `json:"config,omitempty"`
+
+	// Strategy overrides the default strategy of the deployment of the component
+	// +kubebuilder:validation:Optional
+	Strategy *appsv1.DeploymentStrategy `json:"strategy,omitempty"`
+
+	// GracefulShutdown makes the pods of the component stop gracefully
+	// +kubebuilder:validation:Optional
+	GracefulShutdown *GracefulShutdown `json:"gracefulShutdown,omitempty"`
+}
+
+// GracefulShutdown gives the node the time to hand off its work after it's signaled to stop.
+// The proxy behind the service also gets a preStop hook, which keeps it serving until it's removed
+// from the endpoints, its pods are killed after both the wait and the stop timeout
+type GracefulShutdown struct {
+	// GracefulTimeSeconds is the seconds the preStop hook of the proxy waits,
+	// it defaults to the gracefulTime in milliseconds of the component in the config, or 5
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	GracefulTimeSeconds *int64 `json:"gracefulTimeSeconds,omitempty"`
+
+	// StopTimeoutSeconds is the seconds the node is given to exit after it's signaled to stop,
+	// it defaults to the gracefulStopTimeout of the component or the common section in the config, or 30
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	StopTimeoutSeconds *int64 `json:"stopTimeoutSeconds,omitempty"`
+}
+
+// ComponentGroup is a group of the nodes of a component deployed separately,
+// the fields not set are inherited from the component
+type ComponentGroup struct {
+	// Name is the name of the group, it's also the milvus resource group the nodes are labelled for
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MaxLength=32
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Name string `json:"name"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=0
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// StatefulSetSpec is the spec of the statefulset of the nodes, each pod has a cache volume of its own
+// mounted at the local storage path of milvus, so that the cached data survives the restarts
+type StatefulSetSpec struct {
+	// MountPath is the path the cache volume mounted at, it should be the localStorage.path of the config
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default="/var/lib/milvus/data"
+	MountPath string `json:"mountPath,omitempty"`
+
+	// Ephemeral uses a generic ephemeral volume deleted with the pod, instead of a persistent volume claim kept
+	// for the pod of the same ordinal
+	// +kubebuilder:validation:Optional
+	Ephemeral bool `json:"ephemeral,omitempty"`
+
+	// VolumeClaim is the spec of the persistent volume claim of the cache volume
+	// +kubebuilder:validation:Required
+	VolumeClaim corev1.PersistentVolumeClaimSpec `json:"volumeClaim"`
 }
 
 type MilvusQueryNode struct {
 	Component `json:",inline"`
+
+	// Groups are deployed separately besides the default one of the component
+	// +kubebuilder:validation:Optional
+	Groups []ComponentGroup `json:"groups,omitempty"`
+
+	// StatefulSet runs the nodes as a statefulset instead of a deployment, it applies to the groups as well
+	// +kubebuilder:validation:Optional
+	StatefulSet *StatefulSetSpec `json:"statefulSet,omitempty"`
 }
 
 type MilvusDataNode struct {
 	Component `json:",inline"`
+
+	// StatefulSet runs the nodes as a statefulset instead of a deployment
+	// +kubebuilder:validation:Optional
+	StatefulSet *StatefulSetSpec `json:"statefulSet,omitempty"`
 }
 
 type MilvusIndexNode struct {
 	Component `json:",inline"`
+
+	// Groups are deployed separately besides the default one of the component
+	// +kubebuilder:validation:Optional
+	Groups []ComponentGroup `json:"groups,omitempty"`
 }
 
 type MilvusProxy struct {
@@ -121,16 +238,36 @@ type MilvusProxy struct {
 
 type MilvusRootCoord struct {
 	Component `json:",inline"`
+
+	// ActiveStandby enables the active-standby mode of the coordinator, so that it can run more than one replica
+	// with one of them active, and be updated by rolling update. It requires milvus v2.2.3 or later
+	// +kubebuilder:validation:Optional
+	ActiveStandby bool `json:"activeStandby,omitempty"`
 }
 
 type MilvusDataCoord struct {
 	Component `json:",inline"`
+
+	// ActiveStandby enables the active-standby mode of the coordinator, so that it can run more than one replica
+	// with one of them active, and be updated by rolling update. It requires milvus v2.2.3 or later
+	// +kubebuilder:validation:Optional
+	ActiveStandby bool `json:"activeStandby,omitempty"`
 }
 
 type MilvusQueryCoord struct {
 	Component `json:",inline"`
+
+	// ActiveStandby enables the active-standby mode of the coordinator, so that it can run more than one replica
+	// with one of them active, and be updated by rolling update. It requires milvus v2.2.3 or later
+	// +kubebuilder:validation:Optional
+	ActiveStandby bool `json:"activeStandby,omitempty"`
 }
 
 type MilvusIndexCoord struct {
 	Component `json:",inline"`
+
+	// ActiveStandby enables the active-standby mode of the coordinator, so that it can run more than one replica
+	// with one of them active, and be updated by rolling update. It requires milvus v2.2.3 or later
+	// +kubebuilder:validation:Optional
+	ActiveStandby bool `json:"activeStandby,omitempty"`
 }
@@ -0,0 +1,228 @@
+package v1alpha1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+	"sigs.k8s.io/yaml"
+
+	"github.com/milvus-io/milvus-operator/pkg/config"
+	"github.com/milvus-io/milvus-operator/pkg/util"
+)
+
+const (
+	// AnnotationStrictConfig rejects the config keys set by the other fields when it's "true",
+	// instead of dropping them with warnings
+	AnnotationStrictConfig = "milvus.io/strict-config"
+)
+
+func isStrictConfig(annotations map[string]string) bool {
+	return annotations[AnnotationStrictConfig] == "true"
+}
+
+// unsettableConf is a config key set by the other field
+type unsettableConf struct {
+	fields       []string
+	controlledBy string
+}
+
+func getUnsettableConfs(isCluster bool) []unsettableConf {
+	ret := []unsettableConf{
+		{[]string{"minio", "address"}, "spec.dependencies.storage.endpoint"},
+		{[]string{"minio", "port"}, "spec.dependencies.storage.endpoint"},
+		{[]string{"minio", "accessKeyID"}, "spec.dependencies.storage.secretRef"},
+		{[]string{"minio", "secretAccessKey"}, "spec.dependencies.storage.secretRef"},
+		{[]string{"pulsar", "address"}, "spec.dependencies.pulsar.endpoint"},
+		{[]string{"pulsar", "port"}, "spec.dependencies.pulsar.endpoint"},
+		{[]string{"etcd", "endpoints"}, "spec.dependencies.etcd.endpoints"},
+		{etcdRootPathConfFields, "spec.dependencies.etcd.rootPath"},
+		{minioBucketNameConfFields, "spec.dependencies.storage.bucketName"},
+		{msgChannelPrefixConfFields, "spec.dependencies.msgChannelPrefix"},
+	}
+
+	for _, t := range MilvusComponentTypes {
+		controlledBy := "the operator"
+		if isCluster {
+			controlledBy = fmt.Sprintf("spec.components.%s.port", t)
+		}
+		ret = append(ret, unsettableConf{[]string{t.String(), "port"}, controlledBy})
+	}
+	for _, t := range MilvusCoordTypes {
+		ret = append(ret, unsettableConf{[]string{t.String(), "address"}, "the operator"})
+	}
+
+	return ret
+}
+
+func hasConfValue(conf map[string]interface{}, fields ...string) bool {
+	if conf == nil {
+		return false
+	}
+	_, found, _ := unstructured.NestedFieldNoCopy(conf, fields...)
+	return found
+}
+
+// deleteUnsettableConf deletes the config keys set by the other fields, and returns the warnings of them
+func deleteUnsettableConf(fp *field.Path, conf map[string]interface{}, isCluster bool) []string {
+	var warnings []string
+	for _, c := range getUnsettableConfs(isCluster) {
+		if !hasConfValue(conf, c.fields...) {
+			continue
+		}
+		util.DeleteValue(conf, c.fields...)
+		warnings = append(warnings, fmt.Sprintf("%s is ignored, it's set by %s",
+			fp.Child(c.fields[0], c.fields[1:]...), c.controlledBy))
+	}
+	return warnings
+}
+
+// validateUnsettableConf rejects the config keys set by the other fields in strict mode
+func validateUnsettableConf(fp *field.Path, conf map[string]interface{}, isCluster bool) field.ErrorList {
+	var allErrs field.ErrorList
+	for _, c := range getUnsettableConfs(isCluster) {
+		if !hasConfValue(conf, c.fields...) {
+			continue
+		}
+		allErrs = append(allErrs, field.Forbidden(fp.Child(c.fields[0], c.fields[1:]...),
+			fmt.Sprintf("it's set by %s", c.controlledBy)))
+	}
+	return allErrs
+}
+
+// deprecatedConf is a config key deprecated since the milvus version
+type deprecatedConf struct {
+	fields []string
+	since  string
+	detail string
+}
+
+var deprecatedConfs = []deprecatedConf{
+	{[]string{"indexCoord"}, "2.3.0", "indexCoord is merged into dataCoord"},
+}
+
+// getConfKeys returns the dot separated paths of all the keys in conf
+func getConfKeys(conf map[string]interface{}, prefix string, keys map[string]bool) {
+	for k, v := range conf {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		keys[key] = true
+		if m, ok := v.(map[string]interface{}); ok {
+			getConfKeys(m, key, keys)
+		}
+	}
+}
+
+// getKnownConfKeys returns the config keys of the templates, which are of the default milvus version
+func getKnownConfKeys() map[string]bool {
+	keys := map[string]bool{}
+	for _, tmpl := range []string{config.GetMilvusClusterConfigTemplate(), config.GetMilvusConfigTemplate()} {
+		if tmpl == "" {
+			continue
+		}
+		confYaml, err := util.GetTemplatedValues(tmpl, &MilvusCluster{})
+		if err != nil {
+			continue
+		}
+		conf := map[string]interface{}{}
+		if err := yaml.Unmarshal(confYaml, &conf); err != nil {
+			continue
+		}
+		getConfKeys(conf, "", keys)
+	}
+	return keys
+}
+
+func isSameMinorVersion(a, b *semver.Version) bool {
+	return a.Major() == b.Major() && a.Minor() == b.Minor()
+}
+
+// getConfWarnings returns the warnings of the deprecated and unknown config keys for the milvus image
+func getConfWarnings(fp *field.Path, conf map[string]interface{}, image string) []string {
+	var warnings []string
+	version := getImageVersion(image)
+	if len(conf) == 0 || version == nil {
+		return warnings
+	}
+
+	for _, c := range deprecatedConfs {
+		since := semver.MustParse(c.since)
+		if hasConfValue(conf, c.fields...) && !version.LessThan(since) {
+			warnings = append(warnings, fmt.Sprintf("%s is deprecated since milvus v%s: %s",
+				fp.Child(c.fields[0], c.fields[1:]...), c.since, c.detail))
+		}
+	}
+
+	// the known keys are only of the default version
+	defaultVersion, err := semver.NewVersion(config.DefaultMilvusVersion)
+	if err != nil || !isSameMinorVersion(version, defaultVersion) {
+		return warnings
+	}
+	known := getKnownConfKeys()
+	if len(known) == 0 {
+		return warnings
+	}
+	keys := map[string]bool{}
+	getConfKeys(conf, "", keys)
+	unknown := []string{}
+	for key := range keys {
+		parent := key[:strings.LastIndex(key, ".")+1]
+		// only report the top unknown key
+		if !known[key] && (parent == "" || known[strings.TrimSuffix(parent, ".")]) {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		warnings = append(warnings, fmt.Sprintf("%s.%s is unknown to milvus v%d.%d",
+			fp.String(), key, version.Major(), version.Minor()))
+	}
+
+	return warnings
+}
+
+// warningDefaulter is an admission.Defaulter which returns the warnings of defaulting
+type warningDefaulter interface {
+	admission.Defaulter
+	defaultWithWarnings() []string
+}
+
+// warningDefaultingHandler works as the defaulting webhook of controller-runtime,
+// besides it returns the warnings to the client
+type warningDefaultingHandler struct {
+	defaulter warningDefaulter
+	decoder   *admission.Decoder
+}
+
+var _ admission.DecoderInjector = &warningDefaultingHandler{}
+
+// InjectDecoder injects the decoder into a warningDefaultingHandler.
+func (h *warningDefaultingHandler) InjectDecoder(d *admission.Decoder) error {
+	h.decoder = d
+	return nil
+}
+
+// Handle handles admission requests.
+func (h *warningDefaultingHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	obj := h.defaulter.DeepCopyObject().(warningDefaulter)
+	if err := h.decoder.Decode(req, obj); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	warnings := obj.defaultWithWarnings()
+	marshalled, err := json.Marshal(obj)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	return admission.PatchResponseFromRaw(req.Object.Raw, marshalled).WithWarnings(warnings...)
+}
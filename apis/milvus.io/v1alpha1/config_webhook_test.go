@@ -0,0 +1,158 @@
+package v1alpha1
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/milvus-io/milvus-operator/pkg/config"
+	"github.com/milvus-io/milvus-operator/pkg/util"
+)
+
+func TestDeleteUnsettableConf(t *testing.T) {
+	conf := map[string]interface{}{
+		"minio": map[string]interface{}{
+			"address":    "myHost",
+			"bucketName": "myBucket",
+			"useSSL":     true,
+		},
+		"queryNode": map[string]interface{}{
+			"port": 1,
+		},
+	}
+	fp := field.NewPath("spec").Child("config")
+	warnings := deleteUnsettableConf(fp, conf, true)
+	assert.Equal(t, []string{
+		"spec.config.minio.address is ignored, it's set by spec.dependencies.storage.endpoint",
+		"spec.config.minio.bucketName is ignored, it's set by spec.dependencies.storage.bucketName",
+		"spec.config.queryNode.port is ignored, it's set by spec.components.queryNode.port",
+	}, warnings)
+	assert.Equal(t, map[string]interface{}{
+		"minio": map[string]interface{}{
+			"useSSL": true,
+		},
+		"queryNode": map[string]interface{}{},
+	}, conf)
+
+	warnings = deleteUnsettableConf(fp, map[string]interface{}{
+		"queryNode": map[string]interface{}{
+			"port": 1,
+		},
+	}, false)
+	assert.Equal(t, []string{"spec.config.queryNode.port is ignored, it's set by the operator"}, warnings)
+}
+
+func TestMilvusCluster_StrictConfig(t *testing.T) {
+	mc := MilvusCluster{ObjectMeta: metav1.ObjectMeta{
+		Name:        "mc",
+		Annotations: map[string]string{AnnotationStrictConfig: "true"},
+	}}
+	mc.Spec.Com.Proxy.Conf.Data = map[string]interface{}{
+		"pulsar": map[string]interface{}{
+			"address": "myHost",
+		},
+	}
+	mc.Default()
+	assert.Equal(t, "myHost", mc.Spec.Com.Proxy.Conf.Data["pulsar"].(map[string]interface{})["address"])
+
+	err := mc.ValidateCreate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "spec.components.proxy.config.pulsar.address")
+
+	mc.Annotations = nil
+	assert.NoError(t, mc.ValidateCreate())
+}
+
+func TestMilvus_StrictConfig(t *testing.T) {
+	m := Milvus{ObjectMeta: metav1.ObjectMeta{
+		Name:        "m",
+		Annotations: map[string]string{AnnotationStrictConfig: "true"},
+	}}
+	m.Spec.Conf.Data = map[string]interface{}{
+		"etcd": map[string]interface{}{
+			"endpoints": []interface{}{"etcd:2379"},
+		},
+	}
+	m.Default()
+	err := m.ValidateCreate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "spec.config.etcd.endpoints")
+}
+
+func TestGetConfWarnings(t *testing.T) {
+	fp := field.NewPath("spec").Child("config")
+	conf := map[string]interface{}{
+		"indexCoord": map[string]interface{}{
+			"port": 1,
+		},
+	}
+
+	// deprecated
+	warnings := getConfWarnings(fp, conf, "milvusdb/milvus:v2.3.0")
+	assert.Equal(t, []string{"spec.config.indexCoord is deprecated since milvus v2.3.0: indexCoord is merged into dataCoord"}, warnings)
+	assert.Empty(t, getConfWarnings(fp, conf, "milvusdb/milvus:v2.2.0"))
+	assert.Empty(t, getConfWarnings(fp, conf, "milvusdb/milvus:latest"))
+
+	// unknown
+	assert.NoError(t, config.Init(util.GetGitRepoRootDir()))
+	conf = map[string]interface{}{
+		"queryNode": map[string]interface{}{
+			"gracefulTime": 0,
+			"unknownKey":   1,
+		},
+		"unknownSection": map[string]interface{}{
+			"key": 1,
+		},
+	}
+	warnings = getConfWarnings(fp, conf, config.DefaultMilvusImage)
+	assert.Equal(t, []string{
+		"spec.config.queryNode.unknownKey is unknown to milvus v2.0",
+		"spec.config.unknownSection is unknown to milvus v2.0",
+	}, warnings)
+
+	// other versions not checked
+	assert.Empty(t, getConfWarnings(fp, conf, "milvusdb/milvus:v2.1.0"))
+}
+
+func TestWarningDefaultingHandler(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, AddToScheme(scheme))
+	decoder, err := admission.NewDecoder(scheme)
+	assert.NoError(t, err)
+	handler := &warningDefaultingHandler{defaulter: &MilvusCluster{}}
+	assert.NoError(t, handler.InjectDecoder(decoder))
+
+	mc := MilvusCluster{
+		TypeMeta:   metav1.TypeMeta{APIVersion: GroupVersion.String(), Kind: "MilvusCluster"},
+		ObjectMeta: metav1.ObjectMeta{Name: "mc", Namespace: "ns"},
+	}
+	mc.Spec.Conf.Data = map[string]interface{}{
+		"minio": map[string]interface{}{
+			"port": 9000,
+		},
+	}
+	raw, err := json.Marshal(mc)
+	assert.NoError(t, err)
+
+	resp := handler.Handle(context.TODO(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		Operation: admissionv1.Create,
+		Object:    runtime.RawExtension{Raw: raw},
+	}})
+	assert.True(t, resp.Allowed)
+	assert.NotEmpty(t, resp.Patches)
+	assert.Equal(t, []string{"spec.config.minio.port is ignored, it's set by spec.dependencies.storage.endpoint"}, resp.Warnings)
+
+	// bad request
+	resp = handler.Handle(context.TODO(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		Operation: admissionv1.Create,
+		Object:    runtime.RawExtension{Raw: []byte("{")},
+	}})
+	assert.False(t, resp.Allowed)
+}
@@ -0,0 +1,7 @@
+package v1alpha1
+
+// Hub marks v1alpha1 as the version the other versions of Milvus are converted to and from
+func (*Milvus) Hub() {}
+
+// Hub marks v1alpha1 as the version the other versions of MilvusCluster are converted to and from
+func (*MilvusCluster) Hub() {}
@@ -0,0 +1,39 @@
+package v1alpha1
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const (
+	// AnnotationDeletionProtection protects the instance from deletion when it's "true",
+	// it works the same as spec.deletionProtection
+	AnnotationDeletionProtection = "milvus.io/deletion-protection"
+)
+
+func isDeletionProtected(meta metav1.ObjectMeta, deletionProtection bool) bool {
+	return deletionProtection || meta.Annotations[AnnotationDeletionProtection] == "true"
+}
+
+// IsDeletionProtected returns whether the MilvusCluster is protected from deletion
+func (r *MilvusCluster) IsDeletionProtected() bool {
+	return isDeletionProtected(r.ObjectMeta, r.Spec.DeletionProtection)
+}
+
+// IsDeletionProtected returns whether the Milvus is protected from deletion
+func (r *Milvus) IsDeletionProtected() bool {
+	return isDeletionProtected(r.ObjectMeta, r.Spec.DeletionProtection)
+}
+
+// validateDeletion rejects the deletion of a protected instance
+func validateDeletion(protected bool) field.ErrorList {
+	var allErrs field.ErrorList
+	if protected {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec").Child("deletionProtection"),
+			fmt.Sprintf("deletion protection is enabled, set it to false and remove annotation %s to delete the instance",
+				AnnotationDeletionProtection)))
+	}
+	return allErrs
+}
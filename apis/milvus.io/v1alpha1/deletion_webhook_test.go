@@ -0,0 +1,37 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMilvusCluster_ValidateDelete(t *testing.T) {
+	mc := MilvusCluster{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "mc"}}
+	assert.NoError(t, mc.ValidateDelete())
+
+	mc.Spec.DeletionProtection = true
+	err := mc.ValidateDelete()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "spec.deletionProtection")
+
+	mc.Spec.DeletionProtection = false
+	mc.Annotations = map[string]string{AnnotationDeletionProtection: "true"}
+	assert.Error(t, mc.ValidateDelete())
+
+	mc.Annotations[AnnotationDeletionProtection] = "false"
+	assert.NoError(t, mc.ValidateDelete())
+}
+
+func TestMilvus_ValidateDelete(t *testing.T) {
+	m := Milvus{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "m"}}
+	assert.NoError(t, m.ValidateDelete())
+
+	m.Spec.DeletionProtection = true
+	assert.Error(t, m.ValidateDelete())
+
+	m.Spec.DeletionProtection = false
+	m.Annotations = map[string]string{AnnotationDeletionProtection: "true"}
+	assert.Error(t, m.ValidateDelete())
+}
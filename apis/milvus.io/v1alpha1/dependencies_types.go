@@ -1,18 +1,67 @@
 package v1alpha1
 
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/milvus-io/milvus-operator/pkg/util"
+)
+
 type DependencyDeletionPolicy string
 
+// ChartOCIScheme is the scheme of the OCI reference of a chart
+const ChartOCIScheme = "oci://"
+
 const (
 	DeletionPolicyDelete DependencyDeletionPolicy = "Delete"
 	DeletionPolicyRetain DependencyDeletionPolicy = "Retain"
 )
 
+// the default ports of the dependencies, used if their endpoints have no port nor scheme
+const (
+	EtcdDefaultPort        int32 = 2379
+	StorageDefaultPort     int32 = 9000
+	PulsarDefaultPort      int32 = 6650
+	PulsarAdminDefaultPort int32 = 8080
+)
+
+// the schemes allowed in the endpoints of the dependencies, empty for no scheme
+var (
+	etcdSchemes        = []string{"", "http", "https"}
+	storageSchemes     = []string{"", "http", "https"}
+	pulsarSchemes      = []string{"", "pulsar", "pulsar+ssl"}
+	pulsarAdminSchemes = []string{"", "http", "https"}
+)
+
 type MilvusDependencies struct {
 	// +kubebuilder:validation:Optional
 	Etcd MilvusEtcd `json:"etcd"`
 
+	// Pulsar is the message stream in cluster mode, the standalone uses its embedded one
+	// +kubebuilder:validation:Optional
+	Pulsar MilvusPulsar `json:"pulsar"`
+
 	// +kubebuilder:validation:Optional
 	Storage MilvusStorage `json:"storage"`
+
+	// Pool refers to the MilvusDependencyPool serving the dependencies not external,
+	// instead of installing them for the instance. It's immutable
+	// +kubebuilder:validation:Optional
+	Pool *DependencyPoolRef `json:"pool,omitempty"`
+
+	// MsgChannelPrefix is the prefix of the message channel names, it's immutable once set.
+	// Defaults to <namespace>-<name> for new instances
+	// +kubebuilder:validation:Optional
+	MsgChannelPrefix string `json:"msgChannelPrefix,omitempty"`
+
+	// DataDeletionPolicy decides whether the data of the instance in the external dependencies is deleted
+	// with the instance, including the etcd rootPath and the bucket
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum:={"Delete", "Retain"}
+	// +kubebuilder:default:="Retain"
+	DataDeletionPolicy DependencyDeletionPolicy `json:"dataDeletionPolicy,omitempty"`
 }
 
 type MilvusClusterDependencies struct {
@@ -24,12 +73,38 @@ type MilvusClusterDependencies struct {
 
 	// +kubebuilder:validation:Optional
 	Storage MilvusStorage `json:"storage"`
+
+	// Pool refers to the MilvusDependencyPool serving the dependencies not external,
+	// instead of installing them for the instance. It's immutable
+	// +kubebuilder:validation:Optional
+	Pool *DependencyPoolRef `json:"pool,omitempty"`
+
+	// MsgChannelPrefix is the prefix of the message channel names, it's immutable once set.
+	// Defaults to <namespace>-<name> for new instances
+	// +kubebuilder:validation:Optional
+	MsgChannelPrefix string `json:"msgChannelPrefix,omitempty"`
+
+	// DataDeletionPolicy decides whether the data of the instance in the external dependencies is deleted
+	// with the instance, including the etcd rootPath, the bucket and the message channel topics
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum:={"Delete", "Retain"}
+	// +kubebuilder:default:="Retain"
+	DataDeletionPolicy DependencyDeletionPolicy `json:"dataDeletionPolicy,omitempty"`
 }
 
 type MilvusEtcd struct {
 	// +kubebuilder:validation:Optional
 	Endpoints []string `json:"endpoints"`
 
+	// ServiceRef refers to the service of the external etcd, the endpoints are resolved from it by the operator
+	// +kubebuilder:validation:Optional
+	ServiceRef *ServiceRef `json:"serviceRef,omitempty"`
+
+	// RootPath is the root path of the milvus meta in etcd, it's immutable once set.
+	// Defaults to <namespace>-<name> for new instances
+	// +kubebuilder:validation:Optional
+	RootPath string `json:"rootPath,omitempty"`
+
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:default:=false
 	External bool `json:"external,omitempty"`
@@ -38,6 +113,20 @@ type MilvusEtcd struct {
 	InCluster *InClusterConfig `json:"inCluster,omitempty"`
 }
 
+// ServiceRef refers to a port of a service, it's resolved to the endpoint <name>.<namespace>[.svc.<cluster domain>]:<port>
+type ServiceRef struct {
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Namespace of the service, defaults to the namespace of the instance
+	// +kubebuilder:validation:Optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Port is the name of the port of the service, the first port is used if not set
+	// +kubebuilder:validation:Optional
+	Port string `json:"port,omitempty"`
+}
+
 type InClusterConfig struct {
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:pruning:PreserveUnknownFields
@@ -50,6 +139,40 @@ type InClusterConfig struct {
 
 	// +kubebuilder:validation:Optional
 	PVCDeletion bool `json:"pvcDeletion,omitempty"`
+
+	// Timeout of installing or upgrading the release, it's rolled back if not ready in time. Defaults to 10m
+	// +kubebuilder:validation:Optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// Chart is where to get the chart of the release, the chart bundled in the operator is used if not set
+	// +kubebuilder:validation:Optional
+	Chart *ChartSource `json:"chart,omitempty"`
+}
+
+// ChartSource is a chart in a chart repository or an OCI registry
+type ChartSource struct {
+	// Repository is the URL of a chart repository like https://charts.bitnami.com/bitnami,
+	// or the OCI reference of the chart without tag like oci://registry-1.docker.io/bitnamicharts/etcd
+	// +kubebuilder:validation:Required
+	Repository string `json:"repository"`
+
+	// Name of the chart in the chart repository, defaults to the name of the bundled chart. Not used for OCI
+	// +kubebuilder:validation:Optional
+	Name string `json:"name,omitempty"`
+
+	// Version of the chart, the latest one in the chart repository is used if not set. Required for OCI
+	// +kubebuilder:validation:Optional
+	Version string `json:"version,omitempty"`
+
+	// PullSecrets are the secrets of type kubernetes.io/dockerconfigjson in the namespace of the release,
+	// with the credentials to pull the chart from the OCI registry
+	// +kubebuilder:validation:Optional
+	PullSecrets []corev1.LocalObjectReference `json:"pullSecrets,omitempty"`
+}
+
+// IsOCI returns whether the chart is in an OCI registry
+func (c ChartSource) IsOCI() bool {
+	return strings.HasPrefix(c.Repository, ChartOCIScheme)
 }
 
 type MilvusStorage struct {
@@ -64,6 +187,15 @@ type MilvusStorage struct {
 	// +kubebuilder:validation:Optional
 	Endpoint string `json:"endpoint"`
 
+	// ServiceRef refers to the service of the external storage, the endpoint is resolved from it by the operator
+	// +kubebuilder:validation:Optional
+	ServiceRef *ServiceRef `json:"serviceRef,omitempty"`
+
+	// BucketName is the bucket milvus stores its data in, it's immutable once set.
+	// Defaults to <namespace>-<name> for new instances
+	// +kubebuilder:validation:Optional
+	BucketName string `json:"bucketName,omitempty"`
+
 	// +kubebuilder:validation:Optional
 	InCluster *InClusterConfig `json:"inCluster,omitempty"`
 
@@ -82,4 +214,52 @@ type MilvusPulsar struct {
 
 	// +kubebuilder:validation:Optional
 	Endpoint string `json:"endpoint"`
+
+	// ServiceRef refers to the service of the external pulsar, the endpoint is resolved from it by the operator
+	// +kubebuilder:validation:Optional
+	ServiceRef *ServiceRef `json:"serviceRef,omitempty"`
+
+	// AdminEndpoint is the endpoint of the pulsar admin REST API, used to delete the topics of the instance.
+	// Defaults to the host of endpoint with port 8080
+	// +kubebuilder:validation:Optional
+	AdminEndpoint string `json:"adminEndpoint,omitempty"`
+}
+
+// GetEndpoints returns the parsed endpoints of etcd
+func (e MilvusEtcd) GetEndpoints() ([]util.Endpoint, error) {
+	ret := make([]util.Endpoint, 0, len(e.Endpoints))
+	for _, endpoint := range e.Endpoints {
+		parsed, err := parseEtcdEndpoint(endpoint)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, parsed)
+	}
+	return ret, nil
+}
+
+func parseEtcdEndpoint(endpoint string) (util.Endpoint, error) {
+	return util.ParseEndpoint(endpoint, EtcdDefaultPort, etcdSchemes...)
+}
+
+// GetEndpoint returns the parsed endpoint of the storage, https means useSSL
+func (s MilvusStorage) GetEndpoint() (util.Endpoint, error) {
+	return util.ParseEndpoint(s.Endpoint, StorageDefaultPort, storageSchemes...)
+}
+
+// GetEndpoint returns the parsed endpoint of pulsar, pulsar+ssl means TLS
+func (p MilvusPulsar) GetEndpoint() (util.Endpoint, error) {
+	return util.ParseEndpoint(p.Endpoint, PulsarDefaultPort, pulsarSchemes...)
+}
+
+// GetAdminEndpoint returns the parsed admin endpoint of pulsar, defaults to the host of the endpoint with the admin port
+func (p MilvusPulsar) GetAdminEndpoint() (util.Endpoint, error) {
+	if p.AdminEndpoint != "" {
+		return util.ParseEndpoint(p.AdminEndpoint, PulsarAdminDefaultPort, pulsarAdminSchemes...)
+	}
+	endpoint, err := p.GetEndpoint()
+	if err != nil {
+		return util.Endpoint{}, err
+	}
+	return util.Endpoint{Host: endpoint.Host, Port: PulsarAdminDefaultPort}, nil
 }
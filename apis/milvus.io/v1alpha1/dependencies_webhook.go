@@ -0,0 +1,315 @@
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/Masterminds/semver"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/milvus-io/milvus-operator/pkg/util"
+)
+
+// webhookClient is used by the validating webhooks to look up the other instances,
+// it's set when the webhooks registered with the manager.
+// It reads from the API server directly, because the manager's cache may be
+// filtered or not synced yet, which would let the cluster-wide validations pass
+var webhookClient client.Reader
+
+// dependencyPrefixMaxLength is limited by the max length of a bucket name
+const dependencyPrefixMaxLength = 63
+
+var (
+	etcdRootPathConfFields     = []string{"etcd", "rootPath"}
+	minioBucketNameConfFields  = []string{"minio", "bucketName"}
+	msgChannelPrefixConfFields = []string{"msgChannel", "chanNamePrefix", "cluster"}
+)
+
+// defaultDependencyPrefix returns the prefix of a new instance in the shared dependencies.
+// Instances created before the prefixes became fields keep using their names
+func defaultDependencyPrefix(meta metav1.ObjectMeta) string {
+	if !meta.CreationTimestamp.IsZero() || meta.Namespace == "" {
+		return meta.Name
+	}
+
+	prefix := meta.Namespace + "-" + meta.Name
+	if len(prefix) > dependencyPrefixMaxLength {
+		hash := util.CheckSum([]byte(prefix))
+		prefix = prefix[:dependencyPrefixMaxLength-9] + "-" + hash[:8]
+	}
+	return prefix
+}
+
+// getConfPrefix returns the prefix set in the config by the former versions
+func getConfPrefix(conf map[string]interface{}, fields ...string) string {
+	if conf == nil {
+		return ""
+	}
+	v, _, _ := unstructured.NestedString(conf, fields...)
+	return v
+}
+
+// setDefaultPrefix sets the prefix if it's not set, the value in conf is respected
+func setDefaultPrefix(prefix *string, meta metav1.ObjectMeta, conf map[string]interface{}, fields ...string) {
+	if *prefix != "" {
+		return
+	}
+	*prefix = getConfPrefix(conf, fields...)
+	if *prefix == "" {
+		*prefix = defaultDependencyPrefix(meta)
+	}
+}
+
+// getEffectivePrefix returns the prefix the instance actually uses
+func getEffectivePrefix(prefix string, name string, conf map[string]interface{}, fields ...string) string {
+	if prefix != "" {
+		return prefix
+	}
+	if v := getConfPrefix(conf, fields...); v != "" {
+		return v
+	}
+	return name
+}
+
+func validatePrefixUpdate(fp *field.Path, new, old string) *field.Error {
+	if new == old {
+		return nil
+	}
+	return invalid(fp, new, fmt.Sprintf("field is immutable, was %s", old))
+}
+
+// dependencyUsage describes how an instance uses the shared external dependencies
+type dependencyUsage struct {
+	kind      string
+	namespace string
+	name      string
+
+	etcdEndpoints []string
+	rootPath      string
+
+	storageEndpoint string
+	bucketName      string
+
+	pulsarEndpoint   string
+	msgChannelPrefix string
+}
+
+func (r *MilvusCluster) dependencyUsage() dependencyUsage {
+	ret := dependencyUsage{
+		kind:             "MilvusCluster",
+		namespace:        r.Namespace,
+		name:             r.Name,
+		rootPath:         getEffectivePrefix(r.Spec.Dep.Etcd.RootPath, r.Name, r.Spec.Conf.Data, etcdRootPathConfFields...),
+		bucketName:       getEffectivePrefix(r.Spec.Dep.Storage.BucketName, r.Name, r.Spec.Conf.Data, minioBucketNameConfFields...),
+		msgChannelPrefix: getEffectivePrefix(r.Spec.Dep.MsgChannelPrefix, r.Name, r.Spec.Conf.Data, msgChannelPrefixConfFields...),
+	}
+	// in cluster dependencies are owned by the instance, unless they're served by a pool
+	pool := poolUsageEndpoint(r.Namespace, r.Spec.Dep.Pool)
+	if r.Spec.Dep.Etcd.External {
+		ret.etcdEndpoints = usageEtcdEndpoints(r.Spec.Dep.Etcd)
+	} else if pool != "" {
+		ret.etcdEndpoints = []string{pool}
+	}
+	if r.Spec.Dep.Storage.External {
+		ret.storageEndpoint = usageEndpoint(r.Spec.Dep.Storage.Endpoint, r.Spec.Dep.Storage.GetEndpoint)
+	} else {
+		ret.storageEndpoint = pool
+	}
+	if r.Spec.Dep.Pulsar.External {
+		ret.pulsarEndpoint = usageEndpoint(r.Spec.Dep.Pulsar.Endpoint, r.Spec.Dep.Pulsar.GetEndpoint)
+	} else {
+		ret.pulsarEndpoint = pool
+	}
+	return ret
+}
+
+func (r *Milvus) dependencyUsage() dependencyUsage {
+	ret := dependencyUsage{
+		kind:             "Milvus",
+		namespace:        r.Namespace,
+		name:             r.Name,
+		rootPath:         getEffectivePrefix(r.Spec.Dep.Etcd.RootPath, r.Name, r.Spec.Conf.Data, etcdRootPathConfFields...),
+		bucketName:       getEffectivePrefix(r.Spec.Dep.Storage.BucketName, r.Name, r.Spec.Conf.Data, minioBucketNameConfFields...),
+		msgChannelPrefix: getEffectivePrefix(r.Spec.Dep.MsgChannelPrefix, r.Name, r.Spec.Conf.Data, msgChannelPrefixConfFields...),
+	}
+	pool := poolUsageEndpoint(r.Namespace, r.Spec.Dep.Pool)
+	if r.Spec.Dep.Etcd.External {
+		ret.etcdEndpoints = usageEtcdEndpoints(r.Spec.Dep.Etcd)
+	} else if pool != "" {
+		ret.etcdEndpoints = []string{pool}
+	}
+	if r.Spec.Dep.Storage.External {
+		ret.storageEndpoint = usageEndpoint(r.Spec.Dep.Storage.Endpoint, r.Spec.Dep.Storage.GetEndpoint)
+	} else {
+		ret.storageEndpoint = pool
+	}
+	if !r.Spec.IsCluster() {
+		return ret
+	}
+	if r.Spec.Dep.Pulsar.External {
+		ret.pulsarEndpoint = usageEndpoint(r.Spec.Dep.Pulsar.Endpoint, r.Spec.Dep.Pulsar.GetEndpoint)
+	} else {
+		ret.pulsarEndpoint = pool
+	}
+	return ret
+}
+
+// poolUsageEndpoint stands for the dependencies served by the pool, empty if the instance doesn't refer to a pool
+func poolUsageEndpoint(namespace string, pool *DependencyPoolRef) string {
+	if pool == nil {
+		return ""
+	}
+	return fmt.Sprintf("MilvusDependencyPool %s/%s", pool.GetNamespace(namespace), pool.Name)
+}
+
+// usageEndpoint returns the host:port of the endpoint, so that a dependency is matched however its endpoint is written.
+// The endpoint is returned as is if it's empty or invalid
+func usageEndpoint(endpoint string, parse func() (util.Endpoint, error)) string {
+	if endpoint == "" {
+		return endpoint
+	}
+	parsed, err := parse()
+	if err != nil {
+		return endpoint
+	}
+	return parsed.HostPort()
+}
+
+func usageEtcdEndpoints(etcd MilvusEtcd) []string {
+	ret := make([]string, 0, len(etcd.Endpoints))
+	for _, endpoint := range etcd.Endpoints {
+		ret = append(ret, usageEndpoint(endpoint, func() (util.Endpoint, error) {
+			return parseEtcdEndpoint(endpoint)
+		}))
+	}
+	return ret
+}
+
+func (u dependencyUsage) String() string {
+	return fmt.Sprintf("%s %s/%s", u.kind, u.namespace, u.name)
+}
+
+// isSelf returns true if they're the same instance, a MilvusCluster is served by the Milvus of the same name
+func (u dependencyUsage) isSelf(other dependencyUsage) bool {
+	return u.namespace == other.namespace && u.name == other.name
+}
+
+func hasCommonEndpoint(a, b []string) bool {
+	for _, i := range a {
+		for _, j := range b {
+			if i == j {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// collisions returns the errors of the prefixes used by the other instance as well
+func (u dependencyUsage) collisions(fp *field.Path, other dependencyUsage) field.ErrorList {
+	var allErrs field.ErrorList
+	if u.isSelf(other) {
+		return allErrs
+	}
+
+	if hasCommonEndpoint(u.etcdEndpoints, other.etcdEndpoints) && u.rootPath == other.rootPath {
+		allErrs = append(allErrs, invalid(fp.Child("etcd").Child("rootPath"), u.rootPath,
+			fmt.Sprintf("collides with %s in the same etcd", other)))
+	}
+
+	if u.storageEndpoint != "" && u.storageEndpoint == other.storageEndpoint && u.bucketName == other.bucketName {
+		allErrs = append(allErrs, invalid(fp.Child("storage").Child("bucketName"), u.bucketName,
+			fmt.Sprintf("collides with %s in the same storage", other)))
+	}
+
+	if u.pulsarEndpoint != "" && u.pulsarEndpoint == other.pulsarEndpoint && u.msgChannelPrefix == other.msgChannelPrefix {
+		allErrs = append(allErrs, invalid(fp.Child("msgChannelPrefix"), u.msgChannelPrefix,
+			fmt.Sprintf("collides with %s in the same pulsar", other)))
+	}
+
+	return allErrs
+}
+
+// validateDependencyCollisionUpdate validates the collision only if the endpoints or the prefixes are changed,
+// so that the instances colliding already, e.g. created before the validation, can still be updated and deleted
+func validateDependencyCollisionUpdate(u, old dependencyUsage, deletionTimestamp *metav1.Time) field.ErrorList {
+	if !deletionTimestamp.IsZero() || reflect.DeepEqual(u, old) {
+		return nil
+	}
+	return validateDependencyCollision(u)
+}
+
+// validateDependencyCollision rejects the instance sharing an external dependency
+// with another instance under the same prefix
+func validateDependencyCollision(u dependencyUsage) field.ErrorList {
+	var allErrs field.ErrorList
+	if webhookClient == nil {
+		return allErrs
+	}
+	if len(u.etcdEndpoints) == 0 && u.storageEndpoint == "" && u.pulsarEndpoint == "" {
+		return allErrs
+	}
+
+	fp := field.NewPath("spec").Child("dependencies")
+	ctx := context.TODO()
+
+	clusters := &MilvusClusterList{}
+	if err := webhookClient.List(ctx, clusters); err != nil {
+		return append(allErrs, field.InternalError(fp, err))
+	}
+	for i := range clusters.Items {
+		allErrs = append(allErrs, u.collisions(fp, clusters.Items[i].dependencyUsage())...)
+	}
+
+	milvuses := &MilvusList{}
+	if err := webhookClient.List(ctx, milvuses); err != nil {
+		return append(allErrs, field.InternalError(fp, err))
+	}
+	for i := range milvuses.Items {
+		allErrs = append(allErrs, u.collisions(fp, milvuses.Items[i].dependencyUsage())...)
+	}
+
+	return allErrs
+}
+
+// validateChartSource rejects the chart source of the in-cluster dependency that can't be located,
+// fp is the path of the in-cluster config
+func validateChartSource(fp *field.Path, inCluster *InClusterConfig) field.ErrorList {
+	var allErrs field.ErrorList
+	if inCluster == nil || inCluster.Chart == nil {
+		return allErrs
+	}
+
+	source := inCluster.Chart
+	fp = fp.Child("chart")
+	switch {
+	case source.Repository == "":
+		allErrs = append(allErrs, required(fp.Child("repository")))
+	case source.IsOCI():
+		if source.Version == "" {
+			allErrs = append(allErrs, field.Required(fp.Child("version"), "version is required for OCI reference"))
+		} else if _, err := semver.NewVersion(source.Version); err != nil {
+			allErrs = append(allErrs, invalid(fp.Child("version"), source.Version, "should be an exact version for OCI reference"))
+		}
+		if strings.Contains(source.Repository[strings.LastIndex(source.Repository, "/")+1:], ":") {
+			allErrs = append(allErrs, invalid(fp.Child("repository"), source.Repository, "OCI reference should not contain tag, set version instead"))
+		}
+	case !strings.HasPrefix(source.Repository, "http://") && !strings.HasPrefix(source.Repository, "https://"):
+		allErrs = append(allErrs, invalid(fp.Child("repository"), source.Repository, "should be a http(s) URL or an OCI reference starting with "+ChartOCIScheme))
+	case source.Version != "":
+		if _, err := semver.NewConstraint(source.Version); err != nil {
+			allErrs = append(allErrs, invalid(fp.Child("version"), source.Version, err.Error()))
+		}
+	}
+	if len(source.PullSecrets) > 0 && !source.IsOCI() {
+		allErrs = append(allErrs, field.Forbidden(fp.Child("pullSecrets"), "pull secrets are only used for OCI reference"))
+	}
+
+	return allErrs
+}
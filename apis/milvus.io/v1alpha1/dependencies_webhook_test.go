@@ -0,0 +1,286 @@
+package v1alpha1
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/milvus-io/milvus-operator/pkg/util"
+)
+
+func TestDefaultDependencyPrefix(t *testing.T) {
+	// new instance
+	meta := metav1.ObjectMeta{Namespace: "ns", Name: "mc"}
+	assert.Equal(t, "ns-mc", defaultDependencyPrefix(meta))
+
+	// instance created before
+	meta.CreationTimestamp = metav1.Now()
+	assert.Equal(t, "mc", defaultDependencyPrefix(meta))
+
+	// too long
+	meta = metav1.ObjectMeta{Namespace: "ns", Name: strings.Repeat("a", 100)}
+	prefix := defaultDependencyPrefix(meta)
+	assert.Len(t, prefix, dependencyPrefixMaxLength)
+	assert.True(t, strings.HasPrefix(prefix, "ns-aaa"))
+	meta.Name = strings.Repeat("a", 99) + "b"
+	assert.NotEqual(t, prefix, defaultDependencyPrefix(meta))
+}
+
+func TestMilvusCluster_Default_Prefixes(t *testing.T) {
+	mc := MilvusCluster{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "mc"}}
+	mc.Default()
+	assert.Equal(t, "ns-mc", mc.Spec.Dep.Etcd.RootPath)
+	assert.Equal(t, "ns-mc", mc.Spec.Dep.Storage.BucketName)
+	assert.Equal(t, "ns-mc", mc.Spec.Dep.MsgChannelPrefix)
+
+	// keep the value set in config
+	mc = MilvusCluster{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "mc", CreationTimestamp: metav1.Now()}}
+	mc.Spec.Conf.Data = map[string]interface{}{
+		"etcd": map[string]interface{}{
+			"rootPath": "myRoot",
+		},
+	}
+	mc.Default()
+	assert.Equal(t, "myRoot", mc.Spec.Dep.Etcd.RootPath)
+	assert.Equal(t, "mc", mc.Spec.Dep.Storage.BucketName)
+	assert.Equal(t, "mc", mc.Spec.Dep.MsgChannelPrefix)
+	assert.Equal(t, map[string]interface{}{}, mc.Spec.Conf.Data["etcd"])
+}
+
+func TestMilvusCluster_ValidateUpdate_PrefixesImmutable(t *testing.T) {
+	old := MilvusCluster{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "mc"}}
+	old.Default()
+
+	new := old.DeepCopy()
+	assert.NoError(t, new.ValidateUpdate(&old))
+
+	new.Spec.Dep.Etcd.RootPath = "other"
+	assert.Error(t, new.ValidateUpdate(&old))
+
+	new = old.DeepCopy()
+	new.Spec.Dep.Storage.BucketName = "other"
+	assert.Error(t, new.ValidateUpdate(&old))
+
+	new = old.DeepCopy()
+	new.Spec.Dep.MsgChannelPrefix = "other"
+	assert.Error(t, new.ValidateUpdate(&old))
+
+	// instance created before the prefixes fields
+	old = MilvusCluster{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "mc", CreationTimestamp: metav1.Now()}}
+	new = old.DeepCopy()
+	new.Default()
+	assert.NoError(t, new.ValidateUpdate(&old))
+}
+
+func TestMilvus_ValidateUpdate_PrefixesImmutable(t *testing.T) {
+	old := Milvus{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "m"}}
+	old.Default()
+
+	new := old.DeepCopy()
+	assert.NoError(t, new.ValidateUpdate(&old))
+
+	new.Spec.Dep.Storage.BucketName = "other"
+	assert.Error(t, new.ValidateUpdate(&old))
+}
+
+func newWebhookTestClient(t *testing.T, objs ...client.Object) client.Client {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, AddToScheme(scheme))
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+}
+
+func TestValidateDependencyCollision(t *testing.T) {
+	defer func() { webhookClient = nil }()
+
+	existed := &MilvusCluster{ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "mc"}}
+	existed.Spec.Dep.Etcd.External = true
+	existed.Spec.Dep.Etcd.Endpoints = []string{"etcd:2379"}
+	existed.Spec.Dep.Etcd.RootPath = "mc"
+	existed.Spec.Dep.Storage.External = true
+	existed.Spec.Dep.Storage.Endpoint = "minio:9000"
+	existed.Spec.Dep.Storage.BucketName = "mc"
+	existed.Spec.Dep.Pulsar.External = true
+	existed.Spec.Dep.Pulsar.Endpoint = "pulsar:6650"
+	existed.Spec.Dep.MsgChannelPrefix = "mc"
+	webhookClient = newWebhookTestClient(t, existed)
+
+	// update of itself
+	assert.NoError(t, existed.ValidateCreate())
+
+	// same name in another namespace
+	mc := existed.DeepCopy()
+	mc.Namespace = "ns2"
+	err := mc.ValidateCreate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "spec.dependencies.etcd.rootPath")
+	assert.Contains(t, err.Error(), "spec.dependencies.storage.bucketName")
+	assert.Contains(t, err.Error(), "spec.dependencies.msgChannelPrefix")
+
+	// namespace qualified prefixes
+	mc.Spec.Dep.Etcd.RootPath = "ns2-mc"
+	mc.Spec.Dep.Storage.BucketName = "ns2-mc"
+	mc.Spec.Dep.MsgChannelPrefix = "ns2-mc"
+	assert.NoError(t, mc.ValidateCreate())
+
+	// standalone sharing the storage
+	m := &Milvus{ObjectMeta: metav1.ObjectMeta{Namespace: "ns2", Name: "m"}}
+	m.Spec.Dep.Storage.External = true
+	m.Spec.Dep.Storage.Endpoint = "minio:9000"
+	m.Spec.Dep.Storage.BucketName = "mc"
+	err = m.ValidateCreate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "spec.dependencies.storage.bucketName")
+
+	// the same storage written differently
+	m.Spec.Dep.Storage.Endpoint = "http://minio:9000"
+	err = m.ValidateCreate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "spec.dependencies.storage.bucketName")
+
+	// in cluster dependencies are not shared
+	m.Spec.Dep.Storage.External = false
+	assert.NoError(t, m.ValidateCreate())
+
+	// the milvus serving the milvuscluster
+	mil := existed.ToMilvus()
+	assert.NoError(t, mil.ValidateCreate())
+}
+
+func TestValidateDependencyCollision_Update(t *testing.T) {
+	defer func() { webhookClient = nil }()
+
+	// colliding instances created before the validation
+	existed := &Milvus{ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "m"}}
+	existed.Spec.Dep.Storage.External = true
+	existed.Spec.Dep.Storage.Endpoint = "minio:9000"
+	existed.Spec.Dep.Storage.BucketName = "m"
+	existed.Default()
+	colliding := existed.DeepCopy()
+	colliding.Namespace = "ns2"
+	webhookClient = newWebhookTestClient(t, existed, colliding)
+
+	// the dependencies not changed
+	updated := colliding.DeepCopy()
+	updated.Spec.Com.Image = "milvusdb/milvus:v2.1.0"
+	assert.NoError(t, updated.ValidateUpdate(colliding))
+
+	// the finalizer removed while deleting
+	deleting := colliding.DeepCopy()
+	deleting.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	updated = deleting.DeepCopy()
+	updated.Finalizers = nil
+	assert.NoError(t, updated.ValidateUpdate(deleting))
+
+	// changed to collide with another one
+	mc := &MilvusCluster{ObjectMeta: metav1.ObjectMeta{Namespace: "ns2", Name: "mc"}}
+	mc.Spec.Dep.Storage.External = true
+	mc.Spec.Dep.Storage.Endpoint = "minio:9000"
+	mc.Spec.Dep.Storage.BucketName = "mc"
+	mc.Default()
+	updatedMC := mc.DeepCopy()
+	updatedMC.Spec.Dep.Storage.Endpoint = "minio:9001"
+	assert.NoError(t, updatedMC.ValidateUpdate(mc))
+	updatedMC.Spec.Dep.Storage.Endpoint = "http://minio:9000"
+	updatedMC.Spec.Dep.Storage.BucketName = "m"
+	updatedMC.Annotations = map[string]string{AnnotationAllowTransitions: "spec.dependencies.storage.bucketName"}
+	err := updatedMC.ValidateUpdate(mc)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "collides with")
+}
+
+func TestMilvusDependencies_GetEndpoints(t *testing.T) {
+	etcd := MilvusEtcd{Endpoints: []string{"etcd", "[fd00::1]:2389", "https://etcd-0"}}
+	endpoints, err := etcd.GetEndpoints()
+	assert.NoError(t, err)
+	assert.Equal(t, []util.Endpoint{
+		{Host: "etcd", Port: EtcdDefaultPort},
+		{Host: "fd00::1", Port: 2389},
+		{Scheme: "https", Host: "etcd-0", Port: 443},
+	}, endpoints)
+	_, err = MilvusEtcd{Endpoints: []string{"etcd", "pulsar://etcd"}}.GetEndpoints()
+	assert.Error(t, err)
+
+	storage, err := MilvusStorage{Endpoint: "minio"}.GetEndpoint()
+	assert.NoError(t, err)
+	assert.Equal(t, util.Endpoint{Host: "minio", Port: StorageDefaultPort}, storage)
+	_, err = MilvusStorage{Endpoint: "s3://bucket"}.GetEndpoint()
+	assert.Error(t, err)
+
+	pulsar := MilvusPulsar{Endpoint: "pulsar+ssl://[fd00::1]"}
+	endpoint, err := pulsar.GetEndpoint()
+	assert.NoError(t, err)
+	assert.Equal(t, util.Endpoint{Scheme: "pulsar+ssl", Host: "fd00::1", Port: 6651}, endpoint)
+	endpoint, err = pulsar.GetAdminEndpoint()
+	assert.NoError(t, err)
+	assert.Equal(t, util.Endpoint{Host: "fd00::1", Port: PulsarAdminDefaultPort}, endpoint)
+	pulsar.AdminEndpoint = "https://pulsar-admin"
+	endpoint, err = pulsar.GetAdminEndpoint()
+	assert.NoError(t, err)
+	assert.Equal(t, util.Endpoint{Scheme: "https", Host: "pulsar-admin", Port: 443}, endpoint)
+	_, err = MilvusPulsar{Endpoint: "http://pulsar"}.GetAdminEndpoint()
+	assert.Error(t, err)
+}
+
+func TestValidateEndpoints(t *testing.T) {
+	fp := field.NewPath("spec").Child("dependencies")
+	etcd := MilvusEtcd{Endpoints: []string{"[fd00::1]:2379", "etcd:port"}}
+	storage := MilvusStorage{Endpoint: "https://s3.amazonaws.com"}
+	pulsar := MilvusPulsar{Endpoint: "pulsar+ssl://pulsar", AdminEndpoint: "pulsar://pulsar"}
+	errs := validateEndpoints(fp, etcd, storage, &pulsar)
+	assert.Len(t, errs, 2)
+	assert.Equal(t, "spec.dependencies.etcd.endpoints[1]", errs[0].Field)
+	assert.Equal(t, "spec.dependencies.pulsar.adminEndpoint", errs[1].Field)
+
+	// pulsar skipped, empty ones skipped
+	assert.Empty(t, validateEndpoints(fp, MilvusEtcd{}, MilvusStorage{}, nil))
+}
+
+func TestValidateChartSource(t *testing.T) {
+	fp := field.NewPath("spec").Child("dependencies").Child("etcd").Child("inCluster")
+
+	// bundled chart
+	assert.Empty(t, validateChartSource(fp, nil))
+	assert.Empty(t, validateChartSource(fp, &InClusterConfig{}))
+
+	cases := []struct {
+		source ChartSource
+		valid  bool
+	}{
+		{ChartSource{}, false},
+		{ChartSource{Repository: "charts.bitnami.com/bitnami"}, false},
+		{ChartSource{Repository: "https://charts.bitnami.com/bitnami"}, true},
+		{ChartSource{Repository: "https://charts.bitnami.com/bitnami", Name: "etcd", Version: "~6.3.0"}, true},
+		{ChartSource{Repository: "https://charts.bitnami.com/bitnami", Version: "bad version"}, false},
+		{ChartSource{Repository: "oci://registry-1.docker.io/bitnamicharts/etcd"}, false},
+		{ChartSource{Repository: "oci://registry-1.docker.io/bitnamicharts/etcd", Version: "~6.3.0"}, false},
+		{ChartSource{Repository: "oci://registry-1.docker.io/bitnamicharts/etcd:6.3.3", Version: "6.3.3"}, false},
+		{ChartSource{Repository: "oci://localhost:5000/bitnamicharts/etcd", Version: "6.3.3"}, true},
+		{ChartSource{Repository: "oci://localhost:5000/bitnamicharts/etcd", Version: "6.3.3", PullSecrets: []corev1.LocalObjectReference{{Name: "registry"}}}, true},
+		{ChartSource{Repository: "https://charts.bitnami.com/bitnami", PullSecrets: []corev1.LocalObjectReference{{Name: "registry"}}}, false},
+	}
+	for _, c := range cases {
+		source := c.source
+		errs := validateChartSource(fp, &InClusterConfig{Chart: &source})
+		assert.Equal(t, c.valid, len(errs) == 0, "%+v: %v", c.source, errs)
+	}
+}
+
+func TestMilvusCluster_ValidateCreate_Charts(t *testing.T) {
+	mc := MilvusCluster{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "mc"}}
+	mc.Default()
+	mc.Spec.Dep.Pulsar.InCluster.Chart = &ChartSource{Repository: "oci://registry-1.docker.io/charts/pulsar"}
+	assert.Error(t, mc.ValidateCreate())
+
+	// not used by external dependency
+	mc.Spec.Dep.Pulsar.External = true
+	mc.Spec.Dep.Pulsar.Endpoint = "pulsar:6650"
+	assert.NoError(t, mc.ValidateCreate())
+}
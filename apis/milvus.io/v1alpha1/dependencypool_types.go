@@ -0,0 +1,102 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DependencyPoolRef refers to the MilvusDependencyPool serving the dependencies of an instance
+type DependencyPoolRef struct {
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Namespace of the pool, defaults to the namespace of the instance
+	// +kubebuilder:validation:Optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// GetNamespace returns the namespace of the pool referred by an instance in the namespace
+func (p DependencyPoolRef) GetNamespace(namespace string) string {
+	if p.Namespace != "" {
+		return p.Namespace
+	}
+	return namespace
+}
+
+// MilvusDependencyPoolSpec defines the in-cluster dependencies shared by the instances referring to the pool
+type MilvusDependencyPoolSpec struct {
+	// +kubebuilder:validation:Optional
+	Etcd InClusterConfig `json:"etcd,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Storage InClusterConfig `json:"storage,omitempty"`
+
+	// Pulsar is only used by the instances in cluster mode
+	// +kubebuilder:validation:Optional
+	Pulsar InClusterConfig `json:"pulsar,omitempty"`
+
+	// ImageRegistry replaces the registry of the images of the dependencies,
+	// it overrides the registry set for the operator
+	// +kubebuilder:validation:Optional
+	ImageRegistry string `json:"imageRegistry,omitempty"`
+
+	// AllowedNamespaces are the namespaces of the instances allowed to refer to the pool,
+	// the namespace of the pool is always allowed, "*" allows all namespaces
+	// +kubebuilder:validation:Optional
+	AllowedNamespaces []string `json:"allowedNamespaces,omitempty"`
+}
+
+// AllowsNamespace returns whether the instances in the namespace are allowed to refer to the pool
+func (p MilvusDependencyPool) AllowsNamespace(namespace string) bool {
+	if namespace == p.Namespace {
+		return true
+	}
+	for _, allowed := range p.Spec.AllowedNamespaces {
+		if allowed == "*" || allowed == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// MilvusDependencyPoolStatus defines the observed state of MilvusDependencyPool
+type MilvusDependencyPoolStatus struct {
+	// ObservedGeneration is the generation of the spec the status is updated for
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions of the releases of the dependencies
+	Conditions []MilvusCondition `json:"conditions,omitempty"`
+
+	// Instances are the <namespace>/<name> of the Milvus referring to the pool,
+	// the pool is not uninstalled until none refers to it
+	Instances []string `json:"instances,omitempty"`
+}
+
+// +genclient
+// +genclient:noStatus
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:shortName=mdp
+//+kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status",description="Whether the releases of the latest spec are deployed"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+// MilvusDependencyPool is the Schema for a set of in-cluster etcd, storage and pulsar shared by the instances,
+// each instance keeps its data under its own etcd rootPath, bucket and message channel prefix
+type MilvusDependencyPool struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MilvusDependencyPoolSpec   `json:"spec,omitempty"`
+	Status MilvusDependencyPoolStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+// MilvusDependencyPoolList contains a list of MilvusDependencyPool
+type MilvusDependencyPoolList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MilvusDependencyPool `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MilvusDependencyPool{}, &MilvusDependencyPoolList{})
+}
@@ -0,0 +1,178 @@
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// log is for logging in this package.
+var dependencypoollog = logf.Log.WithName("milvusdependencypool-resource")
+
+func (r *MilvusDependencyPool) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	webhookClient = mgr.GetAPIReader()
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-milvus-io-v1alpha1-milvusdependencypool,mutating=false,failurePolicy=fail,sideEffects=None,groups=milvus.io,resources=milvusdependencypools,verbs=create;update;delete,versions=v1alpha1,name=vmilvusdependencypool.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &MilvusDependencyPool{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *MilvusDependencyPool) ValidateCreate() error {
+	dependencypoollog.Info("validate create", "name", r.Name)
+	return r.validate()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *MilvusDependencyPool) ValidateUpdate(old runtime.Object) error {
+	dependencypoollog.Info("validate update", "name", r.Name)
+	if _, ok := old.(*MilvusDependencyPool); !ok {
+		return errors.Errorf("failed type assertion on kind: %s", old.GetObjectKind().GroupVersionKind().String())
+	}
+	if err := r.validate(); err != nil {
+		return err
+	}
+	return r.validateReferringNamespaces()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type,
+// the pool is not deleted while it's referred
+func (r *MilvusDependencyPool) ValidateDelete() error {
+	dependencypoollog.Info("validate delete", "name", r.Name)
+	if webhookClient == nil {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+	fp := field.NewPath("metadata").Child("name")
+	instances, err := GetDependencyPoolInstances(context.TODO(), webhookClient, r.Namespace, r.Name)
+	switch {
+	case err != nil:
+		allErrs = append(allErrs, field.InternalError(fp, err))
+	case len(instances) > 0:
+		allErrs = append(allErrs, field.Forbidden(fp, fmt.Sprintf("the pool is referred by %s, delete them first",
+			strings.Join(instances, ", "))))
+	}
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(schema.GroupKind{Group: GroupVersion.Group, Kind: "MilvusDependencyPool"}, r.Name, allErrs)
+}
+
+func (r *MilvusDependencyPool) validate() error {
+	var allErrs field.ErrorList
+	fp := field.NewPath("spec")
+	allErrs = append(allErrs, validateChartSource(fp.Child("etcd"), &r.Spec.Etcd)...)
+	allErrs = append(allErrs, validateChartSource(fp.Child("storage"), &r.Spec.Storage)...)
+	allErrs = append(allErrs, validateChartSource(fp.Child("pulsar"), &r.Spec.Pulsar)...)
+	for i, namespace := range r.Spec.AllowedNamespaces {
+		if namespace == "*" {
+			continue
+		}
+		for _, msg := range validation.IsDNS1123Label(namespace) {
+			allErrs = append(allErrs, invalid(fp.Child("allowedNamespaces").Index(i), namespace, msg))
+		}
+	}
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(schema.GroupKind{Group: GroupVersion.Group, Kind: "MilvusDependencyPool"}, r.Name, allErrs)
+}
+
+// validateReferringNamespaces rejects removing the allowed namespaces of the instances referring to the pool
+func (r *MilvusDependencyPool) validateReferringNamespaces() error {
+	if webhookClient == nil {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+	fp := field.NewPath("spec").Child("allowedNamespaces")
+	instances, err := GetDependencyPoolInstances(context.TODO(), webhookClient, r.Namespace, r.Name)
+	if err != nil {
+		allErrs = append(allErrs, field.InternalError(fp, err))
+	}
+	for _, instance := range instances {
+		namespace := strings.SplitN(instance, "/", 2)[0]
+		if !r.AllowsNamespace(namespace) {
+			allErrs = append(allErrs, field.Forbidden(fp, fmt.Sprintf("the pool is referred by %s, delete it first", instance)))
+		}
+	}
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(schema.GroupKind{Group: GroupVersion.Group, Kind: "MilvusDependencyPool"}, r.Name, allErrs)
+}
+
+// GetDependencyPoolInstances returns the sorted <namespace>/<name> of the Milvus and the MilvusCluster referring to the pool,
+// a MilvusCluster and the Milvus serving it are counted once
+func GetDependencyPoolInstances(ctx context.Context, reader client.Reader, namespace, name string) ([]string, error) {
+	instances := map[string]bool{}
+	addIfReferred := func(instanceNamespace, instanceName string, pool *DependencyPoolRef) {
+		if pool != nil && pool.Name == name && pool.GetNamespace(instanceNamespace) == namespace {
+			instances[instanceNamespace+"/"+instanceName] = true
+		}
+	}
+
+	milvuses := &MilvusList{}
+	if err := reader.List(ctx, milvuses); err != nil {
+		return nil, err
+	}
+	for _, mil := range milvuses.Items {
+		addIfReferred(mil.Namespace, mil.Name, mil.Spec.Dep.Pool)
+	}
+
+	clusters := &MilvusClusterList{}
+	if err := reader.List(ctx, clusters); err != nil {
+		return nil, err
+	}
+	for _, mc := range clusters.Items {
+		addIfReferred(mc.Namespace, mc.Name, mc.Spec.Dep.Pool)
+	}
+
+	ret := make([]string, 0, len(instances))
+	for instance := range instances {
+		ret = append(ret, instance)
+	}
+	sort.Strings(ret)
+	return ret, nil
+}
+
+// validateDependencyPoolRef rejects referring to a pool not found, being deleted
+// or not allowing the namespace of the instance
+func validateDependencyPoolRef(fp *field.Path, namespace string, pool *DependencyPoolRef) field.ErrorList {
+	var allErrs field.ErrorList
+	if webhookClient == nil || pool == nil {
+		return allErrs
+	}
+
+	fp = fp.Child("pool")
+	existing := &MilvusDependencyPool{}
+	err := webhookClient.Get(context.TODO(), client.ObjectKey{Namespace: pool.GetNamespace(namespace), Name: pool.Name}, existing)
+	switch {
+	case apierrors.IsNotFound(err):
+		allErrs = append(allErrs, field.NotFound(fp, pool.GetNamespace(namespace)+"/"+pool.Name))
+	case err != nil:
+		allErrs = append(allErrs, field.InternalError(fp, err))
+	case !existing.DeletionTimestamp.IsZero():
+		allErrs = append(allErrs, invalid(fp, pool.GetNamespace(namespace)+"/"+pool.Name, "the pool is being deleted"))
+	case !existing.AllowsNamespace(namespace):
+		allErrs = append(allErrs, field.Forbidden(fp, fmt.Sprintf("the pool %s/%s doesn't allow the namespace %s in spec.allowedNamespaces",
+			existing.Namespace, existing.Name, namespace)))
+	}
+	return allErrs
+}
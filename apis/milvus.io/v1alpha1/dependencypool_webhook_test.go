@@ -0,0 +1,180 @@
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMilvusDependencyPool_Validate(t *testing.T) {
+	pool := &MilvusDependencyPool{ObjectMeta: metav1.ObjectMeta{Namespace: "deps", Name: "shared"}}
+	assert.NoError(t, pool.ValidateCreate())
+
+	pool.Spec.Pulsar.Chart = &ChartSource{Repository: "oci://registry-1.docker.io/charts/pulsar"}
+	err := pool.ValidateCreate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "spec.pulsar.chart.version")
+	assert.Error(t, pool.ValidateUpdate(pool.DeepCopy()))
+
+	assert.Error(t, pool.ValidateUpdate(&Milvus{}))
+
+	pool.Spec.Pulsar.Chart = nil
+	pool.Spec.AllowedNamespaces = []string{"ns", "*"}
+	assert.NoError(t, pool.ValidateCreate())
+	pool.Spec.AllowedNamespaces = []string{"ns", "Invalid_NS"}
+	err = pool.ValidateCreate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "spec.allowedNamespaces[1]")
+}
+
+func TestMilvusDependencyPool_AllowsNamespace(t *testing.T) {
+	pool := MilvusDependencyPool{ObjectMeta: metav1.ObjectMeta{Namespace: "deps", Name: "shared"}}
+	assert.True(t, pool.AllowsNamespace("deps"))
+	assert.False(t, pool.AllowsNamespace("ns"))
+
+	pool.Spec.AllowedNamespaces = []string{"ns"}
+	assert.True(t, pool.AllowsNamespace("ns"))
+	assert.False(t, pool.AllowsNamespace("other"))
+
+	pool.Spec.AllowedNamespaces = []string{"*"}
+	assert.True(t, pool.AllowsNamespace("other"))
+}
+
+func TestMilvusDependencyPool_ValidateUpdate_AllowedNamespaces(t *testing.T) {
+	defer func() { webhookClient = nil }()
+
+	pool := &MilvusDependencyPool{ObjectMeta: metav1.ObjectMeta{Namespace: "deps", Name: "shared"}}
+	pool.Spec.AllowedNamespaces = []string{"ns"}
+	mc := &MilvusCluster{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "mc"}}
+	mc.Spec.Dep.Pool = &DependencyPoolRef{Name: "shared", Namespace: "deps"}
+	webhookClient = newWebhookTestClient(t, pool, mc)
+
+	assert.NoError(t, pool.ValidateUpdate(pool.DeepCopy()))
+
+	// the namespace of a referring instance removed
+	updated := pool.DeepCopy()
+	updated.Spec.AllowedNamespaces = nil
+	err := updated.ValidateUpdate(pool)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "ns/mc")
+
+	updated.Spec.AllowedNamespaces = []string{"*"}
+	assert.NoError(t, updated.ValidateUpdate(pool))
+}
+
+func TestMilvusDependencyPool_ValidateDelete(t *testing.T) {
+	defer func() { webhookClient = nil }()
+
+	pool := &MilvusDependencyPool{ObjectMeta: metav1.ObjectMeta{Namespace: "deps", Name: "shared"}}
+	mc := &MilvusCluster{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "mc"}}
+	mc.Spec.Dep.Pool = &DependencyPoolRef{Name: "shared", Namespace: "deps"}
+	other := &Milvus{ObjectMeta: metav1.ObjectMeta{Namespace: "deps", Name: "m"}}
+	other.Spec.Dep.Pool = &DependencyPoolRef{Name: "other"}
+	webhookClient = newWebhookTestClient(t, pool, mc, mc.ToMilvus(), other)
+
+	err := pool.ValidateDelete()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "ns/mc")
+	assert.NotContains(t, err.Error(), "deps/m")
+
+	webhookClient = newWebhookTestClient(t, pool, other)
+	assert.NoError(t, pool.ValidateDelete())
+}
+
+func TestGetDependencyPoolInstances(t *testing.T) {
+	mc := &MilvusCluster{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "mc"}}
+	mc.Spec.Dep.Pool = &DependencyPoolRef{Name: "shared", Namespace: "deps"}
+	m := &Milvus{ObjectMeta: metav1.ObjectMeta{Namespace: "deps", Name: "m"}}
+	m.Spec.Dep.Pool = &DependencyPoolRef{Name: "shared"}
+	// a pool of the same name in another namespace
+	other := &Milvus{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "other"}}
+	other.Spec.Dep.Pool = &DependencyPoolRef{Name: "shared"}
+	cli := newWebhookTestClient(t, mc, mc.ToMilvus(), m, other)
+
+	instances, err := GetDependencyPoolInstances(context.TODO(), cli, "deps", "shared")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"deps/m", "ns/mc"}, instances)
+}
+
+func TestMilvus_ValidateCreate_DependencyPool(t *testing.T) {
+	defer func() { webhookClient = nil }()
+
+	pool := &MilvusDependencyPool{ObjectMeta: metav1.ObjectMeta{Namespace: "deps", Name: "shared"}}
+	webhookClient = newWebhookTestClient(t, pool)
+
+	m := &Milvus{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "m"}}
+	m.Spec.Dep.Pool = &DependencyPoolRef{Name: "shared"}
+	m.Default()
+	err := m.ValidateCreate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "spec.dependencies.pool")
+
+	// the namespace of the instance not allowed by the pool
+	m.Spec.Dep.Pool.Namespace = "deps"
+	err = m.ValidateCreate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "allowedNamespaces")
+
+	pool.Spec.AllowedNamespaces = []string{"ns"}
+	webhookClient = newWebhookTestClient(t, pool)
+	assert.NoError(t, m.ValidateCreate())
+
+	// the charts are not used by the instance
+	mc := &MilvusCluster{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "mc"}}
+	mc.Spec.Dep.Pool = &DependencyPoolRef{Name: "shared", Namespace: "deps"}
+	mc.Default()
+	mc.Spec.Dep.Pulsar.InCluster.Chart = &ChartSource{Repository: "oci://registry-1.docker.io/charts/pulsar"}
+	assert.NoError(t, mc.ValidateCreate())
+}
+
+func TestValidateDependencyCollision_DependencyPool(t *testing.T) {
+	defer func() { webhookClient = nil }()
+
+	pool := &MilvusDependencyPool{ObjectMeta: metav1.ObjectMeta{Namespace: "deps", Name: "shared"}}
+	pool.Spec.AllowedNamespaces = []string{"*"}
+	existed := &MilvusCluster{ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "mc"}}
+	existed.Spec.Dep.Pool = &DependencyPoolRef{Name: "shared", Namespace: "deps"}
+	existed.Spec.Dep.Etcd.RootPath = "mc"
+	existed.Spec.Dep.Storage.BucketName = "mc"
+	existed.Spec.Dep.MsgChannelPrefix = "mc"
+	webhookClient = newWebhookTestClient(t, pool, existed)
+
+	// same prefixes in the same pool
+	mc := existed.DeepCopy()
+	mc.Namespace = "ns2"
+	err := mc.ValidateCreate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "spec.dependencies.etcd.rootPath")
+	assert.Contains(t, err.Error(), "spec.dependencies.storage.bucketName")
+	assert.Contains(t, err.Error(), "spec.dependencies.msgChannelPrefix")
+
+	// in cluster dependencies of its own
+	mc.Spec.Dep.Pool = nil
+	assert.NoError(t, mc.ValidateCreate())
+}
+
+func TestMilvus_ValidateUpdate_DependencyPoolImmutable(t *testing.T) {
+	old := Milvus{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "m"}}
+	old.Default()
+
+	new := old.DeepCopy()
+	new.Spec.Dep.Pool = &DependencyPoolRef{Name: "shared"}
+	err := new.ValidateUpdate(&old)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "spec.dependencies.pool")
+
+	// the same pool written differently
+	old.Spec.Dep.Pool = &DependencyPoolRef{Name: "shared"}
+	new.Spec.Dep.Pool = &DependencyPoolRef{Name: "shared", Namespace: "ns"}
+	assert.NoError(t, new.ValidateUpdate(&old))
+
+	mc := MilvusCluster{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "mc"}}
+	mc.Default()
+	newMC := mc.DeepCopy()
+	newMC.Spec.Dep.Pool = &DependencyPoolRef{Name: "shared"}
+	err = newMC.ValidateUpdate(&mc)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "spec.dependencies.pool")
+}
@@ -24,14 +24,36 @@ import (
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
+// MilvusMode is the deploy mode of Milvus
+type MilvusMode string
+
+const (
+	// MilvusModeStandalone runs all the components in a single pod
+	MilvusModeStandalone MilvusMode = "standalone"
+	// MilvusModeCluster runs each component in its own deployment with pulsar as the message stream
+	MilvusModeCluster MilvusMode = "cluster"
+)
+
 // MilvusSpec defines the desired state of Milvus
 type MilvusSpec struct {
 	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 
+	// Mode is the deploy mode, a standalone instance can be migrated to the cluster mode but not the reverse
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum={"standalone", "cluster"}
+	// +kubebuilder:default="standalone"
+	Mode MilvusMode `json:"mode,omitempty"`
+
+	// ComponentSpec is the global spec of the milvus components in both modes
 	// +kubebuilder:validation:Optional
 	ComponentSpec `json:",inline"`
 
+	// Com is the spec of each component in cluster mode, the global fields are set in spec instead
+	// +kubebuilder:validation:Optional
+	Com MilvusComponents `json:"components,omitempty"`
+
+	// ServiceType is the type of the service milvus serves at, it's the proxy service in cluster mode
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:validation:Enum={"ClusterIP", "NodePort", "LoadBalancer"}
 	// +kubebuilder:default="ClusterIP"
@@ -43,6 +65,21 @@ type MilvusSpec struct {
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:pruning:PreserveUnknownFields
 	Conf Values `json:"config,omitempty"`
+
+	// DeletionProtection rejects the deletion of the instance, and keeps its in cluster dependencies
+	// from being uninstalled regardless of their deletionPolicy
+	// +kubebuilder:validation:Optional
+	DeletionProtection bool `json:"deletionProtection,omitempty"`
+
+	// ImageRegistry replaces the registry of the milvus images and the images of the in-cluster dependencies,
+	// it overrides the registry set for the operator
+	// +kubebuilder:validation:Optional
+	ImageRegistry string `json:"imageRegistry,omitempty"`
+}
+
+// IsCluster returns true if it's deployed in cluster mode
+func (s MilvusSpec) IsCluster() bool {
+	return s.Mode == MilvusModeCluster
 }
 
 // MilvusStatus defines the observed state of Milvus
@@ -51,21 +88,33 @@ type MilvusStatus struct {
 	// Important: Run "make" to regenerate code after modifying this file
 
 	// Status indicates the overall status of the Milvus
-	// Status can be "Creating", "Healthy" and "Unhealthy"
+	// Status can be "Creating", "Healthy", "Unhealthy" and "Deleting"
 	// +kubebuilder:default:="Creating"
 	Status MilvusHealthStatus `json:"status"`
 
+	// ObservedGeneration is the generation of the spec the status is updated for
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
 	// Conditions of each components
 	Conditions []MilvusCondition `json:"conditions,omitempty"`
 
 	// Endpoint of milvus cluster
 	Endpoint string `json:"endpoint,omitempty"`
+
+	// ActiveCoordinators are the pods of the active coordinators in active-standby mode, keyed by the component
+	// +optional
+	ActiveCoordinators map[string]string `json:"activeCoordinators,omitempty"`
 }
 
 // +genclient
 // +genclient:noStatus
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:storageversion
+//+kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.status",description="Health status"
+//+kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status",description="Whether the latest spec is reconciled and ready"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 // Milvus is the Schema for the milvus API
 type Milvus struct {
 	metav1.TypeMeta   `json:",inline"`
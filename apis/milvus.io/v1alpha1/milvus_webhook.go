@@ -18,6 +18,7 @@ package v1alpha1
 
 import (
 	"github.com/milvus-io/milvus-operator/pkg/config"
+	"github.com/milvus-io/milvus-operator/pkg/util"
 	"github.com/pkg/errors"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -32,6 +33,11 @@ import (
 var milvuslog = logf.Log.WithName("milvus-resource")
 
 func (r *Milvus) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	webhookClient = mgr.GetAPIReader()
+	// registered before the builder so that the defaulting returns warnings
+	mgr.GetWebhookServer().Register("/mutate-milvus-io-v1alpha1-milvus", &webhook.Admission{
+		Handler: &warningDefaultingHandler{defaulter: r},
+	})
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(r).
 		Complete()
@@ -45,22 +51,48 @@ var _ webhook.Defaulter = &Milvus{}
 
 // Default implements webhook.Defaulter so a webhook will be registered for the type
 func (r *Milvus) Default() {
+	r.defaultWithWarnings()
+}
+
+// defaultWithWarnings sets the defaults, returns the warnings of the ignored config
+func (r *Milvus) defaultWithWarnings() []string {
 	milvuslog.Info("default", "name", r.Name)
+	var warnings []string
+	strict := isStrictConfig(r.Annotations)
+	isCluster := r.Spec.IsCluster()
+
+	if r.Spec.Mode == "" {
+		r.Spec.Mode = MilvusModeStandalone
+	}
 
 	if r.Spec.Dep.Storage.Type == "" {
 		r.Spec.Dep.Storage.Type = "MinIO"
 	}
 
-	if r.Spec.Conf.Data == nil {
-		r.Spec.Conf.Data = map[string]interface{}{}
-	} else {
-		deleteUnsettableConf(r.Spec.Conf.Data)
+	// the prefixes of an adopting instance are the ones the helm release uses, they're mapped by the operator
+	if !r.IsAdopting() {
+		setDefaultPrefix(&r.Spec.Dep.Etcd.RootPath, r.ObjectMeta, r.Spec.Conf.Data, etcdRootPathConfFields...)
+		setDefaultPrefix(&r.Spec.Dep.Storage.BucketName, r.ObjectMeta, r.Spec.Conf.Data, minioBucketNameConfFields...)
+		setDefaultPrefix(&r.Spec.Dep.MsgChannelPrefix, r.ObjectMeta, r.Spec.Conf.Data, msgChannelPrefixConfFields...)
 	}
 
+	r.SetOperatorDefaults(webhookOperatorDefaults(r.Namespace))
 	if r.Spec.Image == "" {
 		r.Spec.Image = config.DefaultMilvusImage
 	}
 
+	fp := field.NewPath("spec").Child("config")
+	if r.Spec.Conf.Data == nil {
+		r.Spec.Conf.Data = map[string]interface{}{}
+	} else if !strict {
+		warnings = append(warnings, deleteUnsettableConf(fp, r.Spec.Conf.Data, isCluster)...)
+	}
+	warnings = append(warnings, getConfWarnings(fp, r.Spec.Conf.Data, r.Spec.Image)...)
+	if isCluster {
+		warnings = append(warnings, defaultComponentsConf(&r.Spec.Com, r.Spec.Image, strict)...)
+		defaultPulsar(&r.Spec.Dep.Pulsar)
+	}
+
 	// set in cluster etcd endpoints
 	if !r.Spec.Dep.Etcd.External {
 		if r.Spec.Dep.Etcd.InCluster == nil {
@@ -69,7 +101,10 @@ func (r *Milvus) Default() {
 		if r.Spec.Dep.Etcd.InCluster.Values.Data == nil {
 			r.Spec.Dep.Etcd.InCluster.Values.Data = map[string]interface{}{}
 		}
-		r.Spec.Dep.Etcd.InCluster.Values.Data["replicaCount"] = 1
+		// a single node etcd for standalone unless set, it's kept after migrated to cluster mode
+		if !isCluster {
+			util.MergeDefaultValues(r.Spec.Dep.Etcd.InCluster.Values.Data, map[string]interface{}{"replicaCount": 1})
+		}
 
 		if r.Spec.Dep.Etcd.InCluster.DeletionPolicy == "" {
 			r.Spec.Dep.Etcd.InCluster.DeletionPolicy = DeletionPolicyRetain
@@ -87,17 +122,20 @@ func (r *Milvus) Default() {
 		if r.Spec.Dep.Storage.InCluster.Values.Data == nil {
 			r.Spec.Dep.Storage.InCluster.Values.Data = map[string]interface{}{}
 		}
-		r.Spec.Dep.Storage.InCluster.Values.Data["mode"] = "standalone"
+		if !isCluster {
+			util.MergeDefaultValues(r.Spec.Dep.Storage.InCluster.Values.Data, map[string]interface{}{"mode": "standalone"})
+		}
 
 		if r.Spec.Dep.Storage.InCluster.DeletionPolicy == "" {
 			r.Spec.Dep.Storage.InCluster.DeletionPolicy = DeletionPolicyRetain
 		}
 		r.Spec.Dep.Storage.SecretRef = r.Name + "-minio"
 	}
+
+	return warnings
 }
 
-// TODO(user): change verbs to "verbs=create;update;delete" if you want to enable deletion validation.
-//+kubebuilder:webhook:path=/validate-milvus-io-v1alpha1-milvus,mutating=false,failurePolicy=fail,sideEffects=None,groups=milvus.io,resources=milvus,verbs=create;update,versions=v1alpha1,name=vmilvus.kb.io,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/validate-milvus-io-v1alpha1-milvus,mutating=false,failurePolicy=fail,sideEffects=None,groups=milvus.io,resources=milvus,verbs=create;update;delete,versions=v1alpha1,name=vmilvus.kb.io,admissionReviewVersions=v1
 
 var _ webhook.Validator = &Milvus{}
 
@@ -111,6 +149,26 @@ func (r *Milvus) ValidateCreate() error {
 		allErrs = append(allErrs, errs...)
 	}
 
+	if errs := r.validateComponents(); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
+	if errs := r.validateConf(); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
+	if errs := r.validateCharts(); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
+	if errs := validateDependencyPoolRef(field.NewPath("spec").Child("dependencies"), r.Namespace, r.Spec.Dep.Pool); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
+	if errs := validateDependencyCollision(r.dependencyUsage()); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
 	if len(allErrs) == 0 {
 		return nil
 	}
@@ -122,7 +180,7 @@ func (r *Milvus) ValidateCreate() error {
 func (r *Milvus) ValidateUpdate(old runtime.Object) error {
 	milvuslog.Info("validate update", "name", r.Name)
 
-	_, ok := old.(*Milvus)
+	oldMilvus, ok := old.(*Milvus)
 	if !ok {
 		return errors.Errorf("failed type assertion on kind: %s", old.GetObjectKind().GroupVersionKind().String())
 	}
@@ -132,6 +190,39 @@ func (r *Milvus) ValidateUpdate(old runtime.Object) error {
 		allErrs = append(allErrs, errs...)
 	}
 
+	if errs := r.validateComponents(); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
+	if errs := r.validateConf(); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
+	if errs := r.validateCharts(); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
+	if poolUsageEndpoint(r.Namespace, r.Spec.Dep.Pool) != poolUsageEndpoint(oldMilvus.Namespace, oldMilvus.Spec.Dep.Pool) {
+		if errs := validateDependencyPoolRef(field.NewPath("spec").Child("dependencies"), r.Namespace, r.Spec.Dep.Pool); len(errs) > 0 {
+			allErrs = append(allErrs, errs...)
+		}
+	}
+
+	// the operator maps the prefixes and the dependencies of the helm release while adopting it
+	if !oldMilvus.IsAdopting() {
+		if errs := r.validatePrefixes(oldMilvus); len(errs) > 0 {
+			allErrs = append(allErrs, errs...)
+		}
+
+		if errs := r.validateTransitions(oldMilvus); len(errs) > 0 {
+			allErrs = append(allErrs, errs...)
+		}
+	}
+
+	if errs := validateDependencyCollisionUpdate(r.dependencyUsage(), oldMilvus.dependencyUsage(), r.DeletionTimestamp); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
 	if len(allErrs) == 0 {
 		return nil
 	}
@@ -143,7 +234,10 @@ func (r *Milvus) ValidateUpdate(old runtime.Object) error {
 func (r *Milvus) ValidateDelete() error {
 	milvuslog.Info("validate delete", "name", r.Name)
 
-	// TODO(user): fill in your validation logic upon object deletion.
+	if errs := validateDeletion(r.IsDeletionProtected()); len(errs) > 0 {
+		return apierrors.NewInvalid(schema.GroupKind{Group: GroupVersion.Group, Kind: "Milvus"}, r.Name, errs)
+	}
+
 	return nil
 }
 
@@ -156,13 +250,78 @@ func (r *Milvus) validateExternal() field.ErrorList {
 	var allErrs field.ErrorList
 	fp := field.NewPath("spec").Child("dependencies")
 
-	if r.Spec.Dep.Etcd.External && len(r.Spec.Dep.Etcd.Endpoints) == 0 {
+	if r.Spec.Dep.Etcd.External && len(r.Spec.Dep.Etcd.Endpoints) == 0 && r.Spec.Dep.Etcd.ServiceRef == nil {
 		allErrs = append(allErrs, required(fp.Child("etcd").Child("endpoints")))
 	}
 
-	if r.Spec.Dep.Storage.External && len(r.Spec.Dep.Storage.Endpoint) == 0 {
+	if r.Spec.Dep.Storage.External && len(r.Spec.Dep.Storage.Endpoint) == 0 && r.Spec.Dep.Storage.ServiceRef == nil {
 		allErrs = append(allErrs, required(fp.Child("storage").Child("endpoint")))
 	}
 
+	if r.Spec.IsCluster() && r.Spec.Dep.Pulsar.External && len(r.Spec.Dep.Pulsar.Endpoint) == 0 && r.Spec.Dep.Pulsar.ServiceRef == nil {
+		allErrs = append(allErrs, required(fp.Child("pulsar").Child("endpoint")))
+	}
+
+	allErrs = append(allErrs, validateServiceRefs(fp, r.Spec.Dep.Etcd, r.Spec.Dep.Storage, r.Spec.Dep.Pulsar)...)
+
+	// the standalone uses its embedded message stream
+	var pulsar *MilvusPulsar
+	if r.Spec.IsCluster() {
+		pulsar = &r.Spec.Dep.Pulsar
+	}
+	allErrs = append(allErrs, validateEndpoints(fp, r.Spec.Dep.Etcd, r.Spec.Dep.Storage, pulsar)...)
+
+	return allErrs
+}
+
+// validateCharts validates the chart sources of the in-cluster dependencies,
+// the external ones and the ones served by a pool don't use them
+func (r *Milvus) validateCharts() field.ErrorList {
+	var allErrs field.ErrorList
+	if r.Spec.Dep.Pool != nil {
+		return allErrs
+	}
+	fp := field.NewPath("spec").Child("dependencies")
+	if !r.Spec.Dep.Etcd.External {
+		allErrs = append(allErrs, validateChartSource(fp.Child("etcd").Child("inCluster"), r.Spec.Dep.Etcd.InCluster)...)
+	}
+	if !r.Spec.Dep.Storage.External {
+		allErrs = append(allErrs, validateChartSource(fp.Child("storage").Child("inCluster"), r.Spec.Dep.Storage.InCluster)...)
+	}
+	if r.Spec.IsCluster() && !r.Spec.Dep.Pulsar.External {
+		allErrs = append(allErrs, validateChartSource(fp.Child("pulsar").Child("inCluster"), r.Spec.Dep.Pulsar.InCluster)...)
+	}
+	return allErrs
+}
+
+// validateConf rejects the config keys set by the other fields in strict mode
+func (r *Milvus) validateConf() field.ErrorList {
+	var allErrs field.ErrorList
+	if !isStrictConfig(r.Annotations) {
+		return allErrs
+	}
+
+	allErrs = append(allErrs, validateUnsettableConf(field.NewPath("spec").Child("config"), r.Spec.Conf.Data, r.Spec.IsCluster())...)
+	if r.Spec.IsCluster() {
+		allErrs = append(allErrs, validateComponentsConf(&r.Spec.Com)...)
+	}
+	return allErrs
+}
+
+func (r *Milvus) validatePrefixes(old *Milvus) field.ErrorList {
+	var allErrs field.ErrorList
+	fp := field.NewPath("spec").Child("dependencies")
+	cur, prev := r.dependencyUsage(), old.dependencyUsage()
+
+	if err := validatePrefixUpdate(fp.Child("etcd").Child("rootPath"), cur.rootPath, prev.rootPath); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	if err := validatePrefixUpdate(fp.Child("storage").Child("bucketName"), cur.bucketName, prev.bucketName); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	if err := validatePrefixUpdate(fp.Child("msgChannelPrefix"), cur.msgChannelPrefix, prev.msgChannelPrefix); err != nil {
+		allErrs = append(allErrs, err)
+	}
+
 	return allErrs
 }
@@ -21,6 +21,26 @@ func TestMilvus_Default(t *testing.T) {
 	assert.Equal(t, DeletionPolicyRetain, r.Spec.Dep.Storage.InCluster.DeletionPolicy)
 }
 
+func TestMilvus_Default_ClusterMode(t *testing.T) {
+	r := Milvus{}
+	r.Default()
+	assert.Equal(t, MilvusModeStandalone, r.Spec.Mode)
+	assert.Nil(t, r.Spec.Dep.Pulsar.InCluster)
+	assert.Nil(t, r.Spec.Com.Proxy.Replicas)
+
+	r = Milvus{}
+	r.Spec.Mode = MilvusModeCluster
+	r.Default()
+	assert.Equal(t, MilvusModeCluster, r.Spec.Mode)
+	assert.Equal(t, DeletionPolicyRetain, r.Spec.Dep.Pulsar.InCluster.DeletionPolicy)
+	assert.NotContains(t, r.Spec.Dep.Etcd.InCluster.Values.Data, "replicaCount")
+	assert.NotContains(t, r.Spec.Dep.Storage.InCluster.Values.Data, "mode")
+	// left to the workloads or the HPAs
+	for _, component := range r.Spec.Com.GetComponents() {
+		assert.Nil(t, component.Replicas)
+	}
+}
+
 func TestMilvus_Default_NotExternalOK(t *testing.T) {
 	defaultInClusterConfig := InClusterConfig{
 		DeletionPolicy: DeletionPolicyRetain,
@@ -40,16 +60,20 @@ func TestMilvus_Default_NotExternalOK(t *testing.T) {
 	}
 
 	var defaultSpec = MilvusSpec{
+		Mode: MilvusModeStandalone,
 		Dep: MilvusDependencies{
 			Etcd: MilvusEtcd{
 				Endpoints: []string{},
+				RootPath:  crName,
 				InCluster: &etcdIC,
 			},
 			Storage: MilvusStorage{
-				Type:      "MinIO",
-				SecretRef: crName + "-minio",
-				InCluster: &storageIC,
+				Type:       "MinIO",
+				SecretRef:  crName + "-minio",
+				BucketName: crName,
+				InCluster:  &storageIC,
 			},
+			MsgChannelPrefix: crName,
 		},
 		ComponentSpec: ComponentSpec{
 			Image: config.DefaultMilvusImage,
@@ -79,7 +103,9 @@ func TestMilvus_Default_DeleteUnSetableOK(t *testing.T) {
 			Conf: Values{
 				Data: map[string]interface{}{
 					"minio": map[string]interface{}{
-						"address": "myHost",
+						"address":         "myHost",
+						"accessKeyID":     "key",
+						"secretAccessKey": "secret",
 					},
 				},
 			},
@@ -126,6 +152,38 @@ func TestMilvus_ValidateCreate_Invalid3(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestMilvus_ValidateCreate_ServiceRef(t *testing.T) {
+	mc := Milvus{}
+	mc.Spec.Dep.Etcd.External = true
+	mc.Spec.Dep.Etcd.ServiceRef = &ServiceRef{Name: "etcd", Namespace: "deps", Port: "client"}
+	mc.Spec.Dep.Storage.External = true
+	mc.Spec.Dep.Storage.ServiceRef = &ServiceRef{Name: "minio"}
+	err := mc.ValidateCreate()
+	assert.NoError(t, err)
+
+	// only for the external
+	mc.Spec.Dep.Storage.External = false
+	err = mc.ValidateCreate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "spec.dependencies.storage.serviceRef")
+}
+
+func TestMilvus_ValidateCreate_Endpoints(t *testing.T) {
+	mc := Milvus{}
+	mc.Spec.Dep.Etcd.External = true
+	mc.Spec.Dep.Etcd.Endpoints = []string{"[fd00::1]:2379"}
+	mc.Spec.Dep.Storage.External = true
+	mc.Spec.Dep.Storage.Endpoint = "https://[fd00::2]"
+	// the standalone doesn't use pulsar
+	mc.Spec.Dep.Pulsar.Endpoint = "bad endpoint"
+	assert.NoError(t, mc.ValidateCreate())
+
+	mc.Spec.Mode = MilvusModeCluster
+	err := mc.ValidateCreate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "spec.dependencies.pulsar.endpoint")
+}
+
 func TestMilvus_ValidateUpdate_NoError(t *testing.T) {
 	mc := Milvus{}
 	err := mc.ValidateUpdate(&mc)
@@ -17,6 +17,8 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"strings"
+
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -40,6 +42,16 @@ type MilvusClusterSpec struct {
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:pruning:PreserveUnknownFields
 	Conf Values `json:"config,omitempty"`
+
+	// DeletionProtection rejects the deletion of the instance, and keeps its in cluster dependencies
+	// from being uninstalled regardless of their deletionPolicy
+	// +kubebuilder:validation:Optional
+	DeletionProtection bool `json:"deletionProtection,omitempty"`
+
+	// ImageRegistry replaces the registry of the milvus images and the images of the in-cluster dependencies,
+	// it overrides the registry set for the operator
+	// +kubebuilder:validation:Optional
+	ImageRegistry string `json:"imageRegistry,omitempty"`
 }
 
 // MiluvsConditionType is a valid value for MiluvsConditionType.Type.
@@ -55,6 +67,8 @@ const (
 	StatusHealthy MilvusHealthStatus = "Healthy"
 	// StatusUnHealthy is the status of unhealthy.
 	StatusUnHealthy MilvusHealthStatus = "Unhealthy"
+	// StatusDeleting is the status of deleting.
+	StatusDeleting MilvusHealthStatus = "Deleting"
 
 	// EtcdReady means the Etcd is ready.
 	EtcdReady MiluvsConditionType = "EtcdReady"
@@ -64,6 +78,22 @@ const (
 	PulsarReady MiluvsConditionType = "PulsarReady"
 	// MilvusReady means all components of Milvus are ready.
 	MilvusReady MiluvsConditionType = "MilvusReady"
+	// EtcdReleaseReady means the release of the in cluster etcd is deployed.
+	EtcdReleaseReady MiluvsConditionType = "EtcdReleaseReady"
+	// StorageReleaseReady means the release of the in cluster storage is deployed.
+	StorageReleaseReady MiluvsConditionType = "StorageReleaseReady"
+	// PulsarReleaseReady means the release of the in cluster pulsar is deployed.
+	PulsarReleaseReady MiluvsConditionType = "PulsarReleaseReady"
+	// DataDeleted means the data in the external dependencies is deleted.
+	DataDeleted MiluvsConditionType = "DataDeleted"
+	// Adopted means the resources of the milvus helm release are adopted.
+	Adopted MiluvsConditionType = "Adopted"
+	// Ready means the latest spec is reconciled and all components of Milvus are ready, following the kstatus conventions.
+	Ready MiluvsConditionType = "Ready"
+	// Reconciling means the operator is bringing the instance to the latest spec.
+	Reconciling MiluvsConditionType = "Reconciling"
+	// Stalled means the last reconcile of the instance failed.
+	Stalled MiluvsConditionType = "Stalled"
 
 	// ReasonEndpointsHealthy means the endpoint is healthy
 	ReasonEndpointsHealthy string = "EndpointsHealthy"
@@ -85,6 +115,23 @@ const (
 	ReasonSecretDecodeErr    = "SecretDecodeError"
 	ReasonClientErr          = "ClientError"
 	ReasonDependencyNotReady = "DependencyNotReady"
+	ReasonReleaseDeployed    = "ReleaseDeployed"
+	ReasonReleasePending     = "ReleasePending"
+	ReasonReleaseFailed      = "ReleaseFailed"
+	ReasonReleaseNotFound    = "ReleaseNotFound"
+	ReasonDataDeleting       = "DataDeleting"
+	ReasonDataDeleted        = "DataDeleted"
+	ReasonAdopted            = "Adopted"
+	ReasonAdoptionFailed     = "AdoptionFailed"
+
+	// ReasonGenerationNotObserved means the latest spec is not reconciled yet
+	ReasonGenerationNotObserved = "GenerationNotObserved"
+	// ReasonRolloutNotChecked means the workloads of the latest spec are applied but not checked yet
+	ReasonRolloutNotChecked = "RolloutNotChecked"
+	// ReasonReconcileFailed means the last reconcile failed
+	ReasonReconcileFailed = "ReconcileFailed"
+	// ReasonReconcileSucceeded means the last reconcile succeeded
+	ReasonReconcileSucceeded = "ReconcileSucceeded"
 )
 
 // MilvusClusterStatus defines the observed state of MilvusCluster
@@ -93,16 +140,24 @@ type MilvusClusterStatus struct {
 	// Important: Run "make" to regenerate code after modifying this file
 
 	// Status indicates the overall status of the Milvus
-	// Status can be "Creating", "Healthy" and "Unhealthy"
+	// Status can be "Creating", "Healthy", "Unhealthy" and "Deleting"
 	// +kubebuilder:default:="Creating"
 	Status MilvusHealthStatus `json:"status"`
 
+	// ObservedGeneration is the generation of the spec the status is updated for
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
 	// Conditions of each components
 	Conditions []MilvusCondition `json:"conditions,omitempty"`
 
 	// Endpoint of milvus cluster
 	Endpoint string `json:"endpoint,omitempty"`
 
+	// ActiveCoordinators are the pods of the active coordinators in active-standby mode, keyed by the component
+	// +optional
+	ActiveCoordinators map[string]string `json:"activeCoordinators,omitempty"`
+
 	// Status of each etcd endpoint
 	//EtcdStatus []MilvusEtcdStatus `json:"etcdStatus,omitempty"`
 
@@ -148,8 +203,12 @@ type MilvusCondition struct {
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:storageversion
 //+kubebuilder:resource:shortName=mc;mic
-// MilvusCluster is the Schema for the milvusclusters API
+//+kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.status",description="Health status"
+//+kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status",description="Whether the latest spec is reconciled and ready"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+// MilvusCluster is the Schema for the milvusclusters API, it's deprecated in favor of Milvus in cluster mode which serves it
 type MilvusCluster struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -169,3 +228,45 @@ type MilvusClusterList struct {
 func init() {
 	SchemeBuilder.Register(&MilvusCluster{}, &MilvusClusterList{})
 }
+
+// ToMilvus returns the Milvus in cluster mode serving the MilvusCluster. The global component spec is moved to spec,
+// and the proxy service type to spec.serviceType. The labels and the milvus.io annotations are kept.
+// The prefixes of the dependencies are set to the ones the MilvusCluster uses, so that a MilvusCluster created
+// before they're defaulted keeps its data instead of the Milvus defaulting them to new ones
+func (r *MilvusCluster) ToMilvus() *Milvus {
+	mc := r.DeepCopy()
+	mil := &Milvus{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   mc.Namespace,
+			Name:        mc.Name,
+			Labels:      mc.Labels,
+			Annotations: map[string]string{},
+		},
+	}
+	for k, v := range mc.Annotations {
+		if strings.HasPrefix(k, GroupVersion.Group+"/") {
+			mil.Annotations[k] = v
+		}
+	}
+
+	mil.Spec.Mode = MilvusModeCluster
+	mil.Spec.ComponentSpec = mc.Spec.Com.ComponentSpec
+	mil.Spec.Com = mc.Spec.Com
+	mil.Spec.Com.ComponentSpec = ComponentSpec{}
+	mil.Spec.ServiceType = mc.Spec.Com.Proxy.ServiceType
+	mil.Spec.Dep = MilvusDependencies{
+		Etcd:               mc.Spec.Dep.Etcd,
+		Pulsar:             mc.Spec.Dep.Pulsar,
+		Storage:            mc.Spec.Dep.Storage,
+		Pool:               mc.Spec.Dep.Pool,
+		MsgChannelPrefix:   mc.Spec.Dep.MsgChannelPrefix,
+		DataDeletionPolicy: mc.Spec.Dep.DataDeletionPolicy,
+	}
+	mil.Spec.Dep.Etcd.RootPath = getEffectivePrefix(mc.Spec.Dep.Etcd.RootPath, mc.Name, mc.Spec.Conf.Data, etcdRootPathConfFields...)
+	mil.Spec.Dep.Storage.BucketName = getEffectivePrefix(mc.Spec.Dep.Storage.BucketName, mc.Name, mc.Spec.Conf.Data, minioBucketNameConfFields...)
+	mil.Spec.Dep.MsgChannelPrefix = getEffectivePrefix(mc.Spec.Dep.MsgChannelPrefix, mc.Name, mc.Spec.Conf.Data, msgChannelPrefixConfFields...)
+	mil.Spec.Conf = mc.Spec.Conf
+	mil.Spec.DeletionProtection = mc.Spec.DeletionProtection
+	mil.Spec.ImageRegistry = mc.Spec.ImageRegistry
+	return mil
+}
@@ -29,13 +29,17 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	"github.com/milvus-io/milvus-operator/pkg/config"
-	"github.com/milvus-io/milvus-operator/pkg/util"
 )
 
 // log is for logging in this package.
 var milvusclusterlog = logf.Log.WithName("milvuscluster-resource")
 
 func (r *MilvusCluster) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	webhookClient = mgr.GetAPIReader()
+	// registered before the builder so that the defaulting returns warnings
+	mgr.GetWebhookServer().Register("/mutate-milvus-io-v1alpha1-milvuscluster", &webhook.Admission{
+		Handler: &warningDefaultingHandler{defaulter: r},
+	})
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(r).
 		Complete()
@@ -49,47 +53,36 @@ var _ webhook.Defaulter = &MilvusCluster{}
 
 // Default implements webhook.Defaulter so a webhook will be registered for the type
 func (r *MilvusCluster) Default() {
+	r.defaultWithWarnings()
+}
+
+// defaultWithWarnings sets the defaults, returns the warnings of the ignored config
+func (r *MilvusCluster) defaultWithWarnings() []string {
 	//milvusclusterlog.Info("default", "name", r.Name)
+	var warnings []string
+	strict := isStrictConfig(r.Annotations)
 
 	if r.Spec.Dep.Storage.Type == "" {
 		r.Spec.Dep.Storage.Type = "MinIO"
 	}
 
-	if r.Spec.Conf.Data == nil {
-		r.Spec.Conf.Data = map[string]interface{}{}
-	} else {
-		deleteUnsettableConf(r.Spec.Conf.Data)
-	}
+	setDefaultPrefix(&r.Spec.Dep.Etcd.RootPath, r.ObjectMeta, r.Spec.Conf.Data, etcdRootPathConfFields...)
+	setDefaultPrefix(&r.Spec.Dep.Storage.BucketName, r.ObjectMeta, r.Spec.Conf.Data, minioBucketNameConfFields...)
+	setDefaultPrefix(&r.Spec.Dep.MsgChannelPrefix, r.ObjectMeta, r.Spec.Conf.Data, msgChannelPrefixConfFields...)
 
+	r.SetOperatorDefaults(webhookOperatorDefaults(r.Namespace))
 	if r.Spec.Com.Image == "" {
 		r.Spec.Com.Image = config.DefaultMilvusImage
 	}
 
-	replicas := int32(1)
-	if r.Spec.Com.Proxy.Replicas == nil {
-		r.Spec.Com.Proxy.Replicas = &replicas
-	}
-	if r.Spec.Com.RootCoord.Replicas == nil {
-		r.Spec.Com.RootCoord.Replicas = &replicas
-	}
-	if r.Spec.Com.DataCoord.Replicas == nil {
-		r.Spec.Com.DataCoord.Replicas = &replicas
-	}
-	if r.Spec.Com.IndexCoord.Replicas == nil {
-		r.Spec.Com.IndexCoord.Replicas = &replicas
-	}
-	if r.Spec.Com.QueryCoord.Replicas == nil {
-		r.Spec.Com.QueryCoord.Replicas = &replicas
-	}
-	if r.Spec.Com.DataNode.Replicas == nil {
-		r.Spec.Com.DataNode.Replicas = &replicas
-	}
-	if r.Spec.Com.IndexNode.Replicas == nil {
-		r.Spec.Com.IndexNode.Replicas = &replicas
-	}
-	if r.Spec.Com.QueryNode.Replicas == nil {
-		r.Spec.Com.QueryNode.Replicas = &replicas
+	fp := field.NewPath("spec").Child("config")
+	if r.Spec.Conf.Data == nil {
+		r.Spec.Conf.Data = map[string]interface{}{}
+	} else if !strict {
+		warnings = append(warnings, deleteUnsettableConf(fp, r.Spec.Conf.Data, true)...)
 	}
+	warnings = append(warnings, getConfWarnings(fp, r.Spec.Conf.Data, r.Spec.Com.Image)...)
+	warnings = append(warnings, defaultComponentsConf(&r.Spec.Com, r.Spec.Com.Image, strict)...)
 
 	// set in cluster etcd endpoints
 	if !r.Spec.Dep.Etcd.External {
@@ -108,17 +101,7 @@ func (r *MilvusCluster) Default() {
 	}
 
 	// set in cluster pulsar endpoint
-	if !r.Spec.Dep.Pulsar.External {
-		if r.Spec.Dep.Pulsar.InCluster == nil {
-			r.Spec.Dep.Pulsar.InCluster = &InClusterConfig{}
-		}
-		if r.Spec.Dep.Pulsar.InCluster.Values.Data == nil {
-			r.Spec.Dep.Pulsar.InCluster.Values.Data = map[string]interface{}{}
-		}
-		if r.Spec.Dep.Pulsar.InCluster.DeletionPolicy == "" {
-			r.Spec.Dep.Pulsar.InCluster.DeletionPolicy = DeletionPolicyRetain
-		}
-	}
+	defaultPulsar(&r.Spec.Dep.Pulsar)
 
 	// set in cluster storage
 	if !r.Spec.Dep.Storage.External {
@@ -133,10 +116,11 @@ func (r *MilvusCluster) Default() {
 		}
 		r.Spec.Dep.Storage.SecretRef = r.Name + "-minio"
 	}
+
+	return warnings
 }
 
-// TODO(user): change verbs to "verbs=create;update;delete" if you want to enable deletion validation.
-//+kubebuilder:webhook:path=/validate-milvus-io-v1alpha1-milvuscluster,mutating=false,failurePolicy=fail,sideEffects=None,groups=milvus.io,resources=milvusclusters,verbs=create;update,versions=v1alpha1,name=vmilvuscluster.kb.io,admissionReviewVersions={v1,v1beta1}
+//+kubebuilder:webhook:path=/validate-milvus-io-v1alpha1-milvuscluster,mutating=false,failurePolicy=fail,sideEffects=None,groups=milvus.io,resources=milvusclusters,verbs=create;update;delete,versions=v1alpha1,name=vmilvuscluster.kb.io,admissionReviewVersions={v1,v1beta1}
 
 var _ webhook.Validator = &MilvusCluster{}
 
@@ -151,6 +135,26 @@ func (r *MilvusCluster) ValidateCreate() error {
 		allErrs = append(allErrs, errs...)
 	}
 
+	if errs := r.validateComponents(); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
+	if errs := r.validateConf(); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
+	if errs := r.validateCharts(); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
+	if errs := validateDependencyPoolRef(field.NewPath("spec").Child("dependencies"), r.Namespace, r.Spec.Dep.Pool); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
+	if errs := validateDependencyCollision(r.dependencyUsage()); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
 	if len(allErrs) == 0 {
 		return nil
 	}
@@ -162,7 +166,7 @@ func (r *MilvusCluster) ValidateCreate() error {
 func (r *MilvusCluster) ValidateUpdate(old runtime.Object) error {
 	milvusclusterlog.Info("validate update", "name", r.Name)
 
-	_, ok := old.(*MilvusCluster)
+	oldMC, ok := old.(*MilvusCluster)
 	if !ok {
 		return errors.Errorf("failed type assertion on kind: %s", old.GetObjectKind().GroupVersionKind().String())
 	}
@@ -172,6 +176,36 @@ func (r *MilvusCluster) ValidateUpdate(old runtime.Object) error {
 		allErrs = append(allErrs, errs...)
 	}
 
+	if errs := r.validateComponents(); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
+	if errs := r.validateConf(); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
+	if errs := r.validateCharts(); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
+	if poolUsageEndpoint(r.Namespace, r.Spec.Dep.Pool) != poolUsageEndpoint(oldMC.Namespace, oldMC.Spec.Dep.Pool) {
+		if errs := validateDependencyPoolRef(field.NewPath("spec").Child("dependencies"), r.Namespace, r.Spec.Dep.Pool); len(errs) > 0 {
+			allErrs = append(allErrs, errs...)
+		}
+	}
+
+	if errs := r.validatePrefixes(oldMC); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
+	if errs := r.validateTransitions(oldMC); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
+	if errs := validateDependencyCollisionUpdate(r.dependencyUsage(), oldMC.dependencyUsage(), r.DeletionTimestamp); len(errs) > 0 {
+		allErrs = append(allErrs, errs...)
+	}
+
 	if len(allErrs) == 0 {
 		return nil
 	}
@@ -183,7 +217,10 @@ func (r *MilvusCluster) ValidateUpdate(old runtime.Object) error {
 func (r *MilvusCluster) ValidateDelete() error {
 	milvusclusterlog.Info("validate delete", "name", r.Name)
 
-	// TODO(user): fill in your validation logic upon object deletion.
+	if errs := validateDeletion(r.IsDeletionProtected()); len(errs) > 0 {
+		return apierrors.NewInvalid(schema.GroupKind{Group: GroupVersion.Group, Kind: "MilvusCluster"}, r.Name, errs)
+	}
+
 	return nil
 }
 
@@ -191,18 +228,162 @@ func (r *MilvusCluster) validateExternal() field.ErrorList {
 	var allErrs field.ErrorList
 	fp := field.NewPath("spec").Child("dependencies")
 
-	if r.Spec.Dep.Etcd.External && len(r.Spec.Dep.Etcd.Endpoints) == 0 {
+	if r.Spec.Dep.Etcd.External && len(r.Spec.Dep.Etcd.Endpoints) == 0 && r.Spec.Dep.Etcd.ServiceRef == nil {
 		allErrs = append(allErrs, required(fp.Child("etcd").Child("endpoints")))
 	}
 
-	if r.Spec.Dep.Storage.External && len(r.Spec.Dep.Storage.Endpoint) == 0 {
+	if r.Spec.Dep.Storage.External && len(r.Spec.Dep.Storage.Endpoint) == 0 && r.Spec.Dep.Storage.ServiceRef == nil {
 		allErrs = append(allErrs, required(fp.Child("storage").Child("endpoint")))
 	}
 
-	if r.Spec.Dep.Pulsar.External && len(r.Spec.Dep.Pulsar.Endpoint) == 0 {
+	if r.Spec.Dep.Pulsar.External && len(r.Spec.Dep.Pulsar.Endpoint) == 0 && r.Spec.Dep.Pulsar.ServiceRef == nil {
 		allErrs = append(allErrs, required(fp.Child("pulsar").Child("endpoint")))
 	}
 
+	allErrs = append(allErrs, validateServiceRefs(fp, r.Spec.Dep.Etcd, r.Spec.Dep.Storage, r.Spec.Dep.Pulsar)...)
+	allErrs = append(allErrs, validateEndpoints(fp, r.Spec.Dep.Etcd, r.Spec.Dep.Storage, &r.Spec.Dep.Pulsar)...)
+
+	return allErrs
+}
+
+// validateCharts validates the chart sources of the in-cluster dependencies,
+// the external ones and the ones served by a pool don't use them
+func (r *MilvusCluster) validateCharts() field.ErrorList {
+	var allErrs field.ErrorList
+	if r.Spec.Dep.Pool != nil {
+		return allErrs
+	}
+	fp := field.NewPath("spec").Child("dependencies")
+	if !r.Spec.Dep.Etcd.External {
+		allErrs = append(allErrs, validateChartSource(fp.Child("etcd").Child("inCluster"), r.Spec.Dep.Etcd.InCluster)...)
+	}
+	if !r.Spec.Dep.Storage.External {
+		allErrs = append(allErrs, validateChartSource(fp.Child("storage").Child("inCluster"), r.Spec.Dep.Storage.InCluster)...)
+	}
+	if !r.Spec.Dep.Pulsar.External {
+		allErrs = append(allErrs, validateChartSource(fp.Child("pulsar").Child("inCluster"), r.Spec.Dep.Pulsar.InCluster)...)
+	}
+	return allErrs
+}
+
+// validateConf rejects the config keys set by the other fields in strict mode
+func (r *MilvusCluster) validateConf() field.ErrorList {
+	var allErrs field.ErrorList
+	if !isStrictConfig(r.Annotations) {
+		return allErrs
+	}
+
+	allErrs = append(allErrs, validateUnsettableConf(field.NewPath("spec").Child("config"), r.Spec.Conf.Data, true)...)
+	allErrs = append(allErrs, validateComponentsConf(&r.Spec.Com)...)
+	return allErrs
+}
+
+func (r *MilvusCluster) validatePrefixes(old *MilvusCluster) field.ErrorList {
+	var allErrs field.ErrorList
+	fp := field.NewPath("spec").Child("dependencies")
+	cur, prev := r.dependencyUsage(), old.dependencyUsage()
+
+	if err := validatePrefixUpdate(fp.Child("etcd").Child("rootPath"), cur.rootPath, prev.rootPath); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	if err := validatePrefixUpdate(fp.Child("storage").Child("bucketName"), cur.bucketName, prev.bucketName); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	if err := validatePrefixUpdate(fp.Child("msgChannelPrefix"), cur.msgChannelPrefix, prev.msgChannelPrefix); err != nil {
+		allErrs = append(allErrs, err)
+	}
+
+	return allErrs
+}
+
+// defaultComponentsConf deletes the unsettable config of each component unless in strict mode,
+// returns the warnings of the deleted and unknown config
+func defaultComponentsConf(com *MilvusComponents, image string, strict bool) []string {
+	var warnings []string
+	for i, component := range com.GetComponents() {
+		if component.Conf.Data == nil {
+			continue
+		}
+		cp := field.NewPath("spec").Child("components", MilvusComponentTypes[i].String(), "config")
+		if !strict {
+			warnings = append(warnings, deleteUnsettableConf(cp, component.Conf.Data, true)...)
+		}
+		componentImage := component.Image
+		if componentImage == "" {
+			componentImage = image
+		}
+		warnings = append(warnings, getConfWarnings(cp, component.Conf.Data, componentImage)...)
+	}
+	return warnings
+}
+
+// defaultPulsar sets the defaults of the in cluster pulsar
+func defaultPulsar(pulsar *MilvusPulsar) {
+	if pulsar.External {
+		return
+	}
+	if pulsar.InCluster == nil {
+		pulsar.InCluster = &InClusterConfig{}
+	}
+	if pulsar.InCluster.Values.Data == nil {
+		pulsar.InCluster.Values.Data = map[string]interface{}{}
+	}
+	if pulsar.InCluster.DeletionPolicy == "" {
+		pulsar.InCluster.DeletionPolicy = DeletionPolicyRetain
+	}
+}
+
+// validateComponentsConf rejects the config keys of each component set by the other fields
+func validateComponentsConf(com *MilvusComponents) field.ErrorList {
+	var allErrs field.ErrorList
+	for i, component := range com.GetComponents() {
+		cp := field.NewPath("spec").Child("components", MilvusComponentTypes[i].String(), "config")
+		allErrs = append(allErrs, validateUnsettableConf(cp, component.Conf.Data, true)...)
+	}
+	return allErrs
+}
+
+// validateServiceRefs validates the serviceRefs are only set for the external dependencies, the in-cluster ones have their own services
+func validateServiceRefs(fp *field.Path, etcd MilvusEtcd, storage MilvusStorage, pulsar MilvusPulsar) field.ErrorList {
+	var allErrs field.ErrorList
+	if !etcd.External && etcd.ServiceRef != nil {
+		allErrs = append(allErrs, invalid(fp.Child("etcd").Child("serviceRef"), etcd.ServiceRef.Name, "serviceRef is only used by the external etcd"))
+	}
+	if !storage.External && storage.ServiceRef != nil {
+		allErrs = append(allErrs, invalid(fp.Child("storage").Child("serviceRef"), storage.ServiceRef.Name, "serviceRef is only used by the external storage"))
+	}
+	if !pulsar.External && pulsar.ServiceRef != nil {
+		allErrs = append(allErrs, invalid(fp.Child("pulsar").Child("serviceRef"), pulsar.ServiceRef.Name, "serviceRef is only used by the external pulsar"))
+	}
+	return allErrs
+}
+
+// validateEndpoints validates the endpoints of the dependencies can be parsed, the ones not set are skipped
+func validateEndpoints(fp *field.Path, etcd MilvusEtcd, storage MilvusStorage, pulsar *MilvusPulsar) field.ErrorList {
+	var allErrs field.ErrorList
+	for i, endpoint := range etcd.Endpoints {
+		if _, err := parseEtcdEndpoint(endpoint); err != nil {
+			allErrs = append(allErrs, invalid(fp.Child("etcd").Child("endpoints").Index(i), endpoint, err.Error()))
+		}
+	}
+	if storage.Endpoint != "" {
+		if _, err := storage.GetEndpoint(); err != nil {
+			allErrs = append(allErrs, invalid(fp.Child("storage").Child("endpoint"), storage.Endpoint, err.Error()))
+		}
+	}
+	if pulsar == nil {
+		return allErrs
+	}
+	if pulsar.Endpoint != "" {
+		if _, err := pulsar.GetEndpoint(); err != nil {
+			allErrs = append(allErrs, invalid(fp.Child("pulsar").Child("endpoint"), pulsar.Endpoint, err.Error()))
+		}
+	}
+	if pulsar.AdminEndpoint != "" {
+		if _, err := pulsar.GetAdminEndpoint(); err != nil {
+			allErrs = append(allErrs, invalid(fp.Child("pulsar").Child("adminEndpoint"), pulsar.AdminEndpoint, err.Error()))
+		}
+	}
 	return allErrs
 }
 
@@ -217,18 +398,3 @@ func invalid(mainPath *field.Path, value interface{}, details string) *field.Err
 func forbidden(mainPath fmt.Stringer, conflictPath *field.Path) *field.Error {
 	return field.Forbidden(conflictPath, fmt.Sprintf("conflicts: %s should not be configured as %s has been configured already", conflictPath.String(), mainPath.String()))
 }
-
-func deleteUnsettableConf(conf map[string]interface{}) {
-	util.DeleteValue(conf, "minio", "address")
-	util.DeleteValue(conf, "minio", "port")
-	util.DeleteValue(conf, "pulsar", "address")
-	util.DeleteValue(conf, "pulsar", "port")
-	util.DeleteValue(conf, "etcd", "endpoints")
-
-	for _, t := range MilvusComponentTypes {
-		util.DeleteValue(conf, t.String(), "port")
-	}
-	for _, t := range MilvusCoordTypes {
-		util.DeleteValue(conf, t.String(), "address")
-	}
-}
@@ -10,10 +10,7 @@ import (
 )
 
 func TestMilvusCluster_Default_NotExternalOK(t *testing.T) {
-	replica1 := int32(1)
-	defaultComponent := Component{
-		Replicas: &replica1,
-	}
+	defaultComponent := Component{}
 	defaultInClusterConfig := &InClusterConfig{
 		DeletionPolicy: DeletionPolicyRetain,
 		Values: Values{
@@ -27,16 +24,19 @@ func TestMilvusCluster_Default_NotExternalOK(t *testing.T) {
 		Dep: MilvusClusterDependencies{
 			Etcd: MilvusEtcd{
 				Endpoints: []string{},
+				RootPath:  crName,
 				InCluster: defaultInClusterConfig,
 			},
 			Pulsar: MilvusPulsar{
 				InCluster: defaultInClusterConfig,
 			},
 			Storage: MilvusStorage{
-				Type:      "MinIO",
-				SecretRef: crName + "-minio",
-				InCluster: defaultInClusterConfig,
+				Type:       "MinIO",
+				SecretRef:  crName + "-minio",
+				BucketName: crName,
+				InCluster:  defaultInClusterConfig,
 			},
+			MsgChannelPrefix: crName,
 		},
 		Com: MilvusComponents{
 			ComponentSpec: ComponentSpec{
@@ -84,14 +84,17 @@ func TestMilvusCluster_Default_ExternalDepOK(t *testing.T) {
 		Dep: MilvusClusterDependencies{
 			Etcd: MilvusEtcd{
 				External: true,
+				RootPath: crName,
 			},
 			Pulsar: MilvusPulsar{
 				External: true,
 			},
 			Storage: MilvusStorage{
-				External: true,
-				Type:     "MinIO",
+				External:   true,
+				Type:       "MinIO",
+				BucketName: crName,
 			},
+			MsgChannelPrefix: crName,
 		},
 	}
 
@@ -138,6 +141,15 @@ func TestMilvusCluster_Default_DeleteUnSetableOK(t *testing.T) {
 	}
 	mc.Default()
 	assert.Equal(t, conf, mc.Spec.Conf)
+
+	// component scoped conf
+	mc.Spec.Com.QueryNode.Conf.Data = map[string]interface{}{
+		"etcd": map[string]interface{}{
+			"endpoints": []interface{}{"etcd:2379"},
+		},
+	}
+	mc.Default()
+	assert.Equal(t, map[string]interface{}{"etcd": map[string]interface{}{}}, mc.Spec.Com.QueryNode.Conf.Data)
 }
 
 func TestMilvusCluster_ValidateCreate_NoError(t *testing.T) {
@@ -207,3 +219,57 @@ func TestMilvusCluster_ValidateUpdate_KindAssertionFailed(t *testing.T) {
 	err := new.ValidateUpdate(&old)
 	assert.Error(t, err)
 }
+
+func TestMilvusCluster_ToMilvus(t *testing.T) {
+	mc := MilvusCluster{ObjectMeta: metav1.ObjectMeta{
+		Namespace:   "ns",
+		Name:        "mc",
+		Labels:      map[string]string{"app": "test"},
+		Annotations: map[string]string{AnnotationAllowTransitions: "*", "other": "x"},
+	}}
+	mc.Default()
+	mc.Spec.Com.Image = "milvusdb/milvus:v2.0.0"
+	mc.Spec.Com.Proxy.ServiceType = "NodePort"
+
+	mil := mc.ToMilvus()
+	assert.Equal(t, "ns", mil.Namespace)
+	assert.Equal(t, "mc", mil.Name)
+	assert.Equal(t, mc.Labels, mil.Labels)
+	assert.Equal(t, map[string]string{AnnotationAllowTransitions: "*"}, mil.Annotations)
+	assert.True(t, mil.Spec.IsCluster())
+	assert.Equal(t, "milvusdb/milvus:v2.0.0", mil.Spec.Image)
+	assert.Equal(t, ComponentSpec{}, mil.Spec.Com.ComponentSpec)
+	assert.Equal(t, mc.Spec.Com.Proxy, mil.Spec.Com.Proxy)
+	assert.Equal(t, "NodePort", string(mil.Spec.ServiceType))
+	assert.Equal(t, mc.Spec.Dep.Pulsar, mil.Spec.Dep.Pulsar)
+	assert.Equal(t, mc.Spec.Dep.Etcd, mil.Spec.Dep.Etcd)
+
+	mil.Default()
+	assert.NoError(t, mil.ValidateCreate())
+}
+
+func TestMilvusCluster_ToMilvus_LegacyPrefixes(t *testing.T) {
+	// created before the prefixes are defaulted, it uses its name, or the ones in config
+	mc := MilvusCluster{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "mc"}}
+	mc.Spec.Conf.Data = map[string]interface{}{
+		"minio": map[string]interface{}{"bucketName": "legacy-bucket"},
+	}
+
+	mil := mc.ToMilvus()
+	assert.Equal(t, "mc", mil.Spec.Dep.Etcd.RootPath)
+	assert.Equal(t, "legacy-bucket", mil.Spec.Dep.Storage.BucketName)
+	assert.Equal(t, "mc", mil.Spec.Dep.MsgChannelPrefix)
+
+	// not changed by the defaulting of the new Milvus
+	mil.Default()
+	assert.Equal(t, "mc", mil.Spec.Dep.Etcd.RootPath)
+	assert.Equal(t, "legacy-bucket", mil.Spec.Dep.Storage.BucketName)
+	assert.Equal(t, "mc", mil.Spec.Dep.MsgChannelPrefix)
+
+	// the defaulted ones are kept
+	mc = MilvusCluster{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "mc"}}
+	mc.Default()
+	mil = mc.ToMilvus()
+	assert.Equal(t, mc.Spec.Dep.Etcd.RootPath, mil.Spec.Dep.Etcd.RootPath)
+	assert.Equal(t, "ns-mc", mil.Spec.Dep.Etcd.RootPath)
+}
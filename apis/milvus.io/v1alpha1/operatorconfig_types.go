@@ -0,0 +1,141 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/milvus-io/milvus-operator/pkg/util"
+)
+
+// MilvusOperatorConfigName is the name of the MilvusOperatorConfig read by the operator, the others are ignored
+const MilvusOperatorConfigName = "default"
+
+// OperatorDefaults are the defaults of the milvus instances, they're only set when not set in the instances
+type OperatorDefaults struct {
+	// ComponentSpec is the default of the spec of all the components,
+	// e.g. the image, the resources and the tolerations
+	// +kubebuilder:validation:Optional
+	ComponentSpec `json:",inline"`
+
+	// DeletionPolicy is the default deletionPolicy of the in-cluster dependencies
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum:={"Delete", "Retain"}
+	DeletionPolicy DependencyDeletionPolicy `json:"deletionPolicy,omitempty"`
+
+	// Milvus is the default helm values of the in-cluster dependencies of the Milvus instances
+	// +kubebuilder:validation:Optional
+	Milvus DependencyValuesDefaults `json:"milvus,omitempty"`
+
+	// MilvusCluster is the default helm values of the in-cluster dependencies of the MilvusCluster instances
+	// +kubebuilder:validation:Optional
+	MilvusCluster DependencyValuesDefaults `json:"milvusCluster,omitempty"`
+}
+
+// DependencyValuesDefaults are the default helm values of the in-cluster dependencies,
+// they're merged into the values of the instances with the values set in the instances kept
+type DependencyValuesDefaults struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Etcd Values `json:"etcd,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Storage Values `json:"storage,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Pulsar Values `json:"pulsar,omitempty"`
+}
+
+// MilvusOperatorConfigSpec defines the operator-wide defaults
+type MilvusOperatorConfigSpec struct {
+	// Defaults of the instances in all the namespaces
+	// +kubebuilder:validation:Optional
+	Defaults OperatorDefaults `json:"defaults,omitempty"`
+
+	// Namespaces overrides the defaults of the instances in the namespaces, by the namespace names
+	// +kubebuilder:validation:Optional
+	Namespaces map[string]OperatorDefaults `json:"namespaces,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// MilvusOperatorConfig is the Schema for the operator-wide defaults, only the one named default is read
+type MilvusOperatorConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec MilvusOperatorConfigSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// MilvusOperatorConfigList contains a list of MilvusOperatorConfig
+type MilvusOperatorConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MilvusOperatorConfig `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MilvusOperatorConfig{}, &MilvusOperatorConfigList{})
+}
+
+// GetDefaults returns the defaults of the instances in the namespace, the fields set in the override
+// of the namespace take precedence over the global ones
+func (s MilvusOperatorConfigSpec) GetDefaults(namespace string) OperatorDefaults {
+	ret := *s.Defaults.DeepCopy()
+	override, ok := s.Namespaces[namespace]
+	if !ok {
+		return ret
+	}
+	override = *override.DeepCopy()
+
+	spec := &ret.ComponentSpec
+	if override.Image != "" {
+		spec.Image = override.Image
+	}
+	if override.ImagePullPolicy != nil {
+		spec.ImagePullPolicy = override.ImagePullPolicy
+	}
+	if override.ImagePullSecrets != nil {
+		spec.ImagePullSecrets = override.ImagePullSecrets
+	}
+	if override.Env != nil {
+		spec.Env = override.Env
+	}
+	if override.NodeSelector != nil {
+		spec.NodeSelector = override.NodeSelector
+	}
+	if override.Tolerations != nil {
+		spec.Tolerations = override.Tolerations
+	}
+	if override.Resources != nil {
+		spec.Resources = override.Resources
+	}
+	if override.DeletionPolicy != "" {
+		ret.DeletionPolicy = override.DeletionPolicy
+	}
+	ret.Milvus = override.Milvus.mergedWith(ret.Milvus)
+	ret.MilvusCluster = override.MilvusCluster.mergedWith(ret.MilvusCluster)
+	return ret
+}
+
+// mergedWith returns the values with the defaults merged, the ones already set are kept
+func (d DependencyValuesDefaults) mergedWith(defaults DependencyValuesDefaults) DependencyValuesDefaults {
+	return DependencyValuesDefaults{
+		Etcd:    Values{Data: mergeDefaultValues(d.Etcd.Data, defaults.Etcd.Data)},
+		Storage: Values{Data: mergeDefaultValues(d.Storage.Data, defaults.Storage.Data)},
+		Pulsar:  Values{Data: mergeDefaultValues(d.Pulsar.Data, defaults.Pulsar.Data)},
+	}
+}
+
+// mergeDefaultValues merges the defaults into the values, the values is created if nil
+func mergeDefaultValues(values, defaults map[string]interface{}) map[string]interface{} {
+	if len(defaults) == 0 {
+		return values
+	}
+	if values == nil {
+		values = map[string]interface{}{}
+	}
+	util.MergeDefaultValues(values, defaults)
+	return values
+}
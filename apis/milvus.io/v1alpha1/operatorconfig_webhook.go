@@ -0,0 +1,126 @@
+package v1alpha1
+
+import (
+	"context"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// operatorDefaultsTimeout is the timeout of getting the MilvusOperatorConfig in the webhooks
+const operatorDefaultsTimeout = 5 * time.Second
+
+// GetOperatorDefaults returns the defaults of the instances in the namespace from the MilvusOperatorConfig,
+// it's empty if the MilvusOperatorConfig or its CRD doesn't exist, or it's not allowed to read
+func GetOperatorDefaults(ctx context.Context, reader client.Reader, namespace string) (OperatorDefaults, error) {
+	operatorConfig := &MilvusOperatorConfig{}
+	err := reader.Get(ctx, client.ObjectKey{Name: MilvusOperatorConfigName}, operatorConfig)
+	if err != nil {
+		// the CRD may not be installed, or the operator watching some namespaces isn't allowed to read it
+		if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) || apierrors.IsForbidden(err) {
+			return OperatorDefaults{}, nil
+		}
+		return OperatorDefaults{}, err
+	}
+	return operatorConfig.Spec.GetDefaults(namespace), nil
+}
+
+// webhookOperatorDefaults returns the defaults of the instances in the namespace for the defaulting webhooks,
+// the builtin defaults are used if it fails to get the MilvusOperatorConfig
+func webhookOperatorDefaults(namespace string) OperatorDefaults {
+	if webhookClient == nil {
+		return OperatorDefaults{}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), operatorDefaultsTimeout)
+	defer cancel()
+	defaults, err := GetOperatorDefaults(ctx, webhookClient, namespace)
+	if err != nil {
+		milvuslog.Error(err, "get operator defaults failed, use the builtin ones", "namespace", namespace)
+	}
+	return defaults
+}
+
+// SetOperatorDefaults sets the operator defaults not set in the instance,
+// the dependency defaults of MilvusCluster are used in cluster mode
+func (r *Milvus) SetOperatorDefaults(defaults OperatorDefaults) {
+	defaults.setComponentSpecDefaults(&r.Spec.ComponentSpec)
+	depDefaults := defaults.Milvus
+	if r.Spec.IsCluster() {
+		depDefaults = defaults.MilvusCluster
+	}
+	if !r.Spec.Dep.Etcd.External {
+		if r.Spec.Dep.Etcd.InCluster == nil {
+			r.Spec.Dep.Etcd.InCluster = &InClusterConfig{}
+		}
+		defaults.setInClusterDefaults(r.Spec.Dep.Etcd.InCluster, depDefaults.Etcd)
+	}
+	if r.Spec.IsCluster() && !r.Spec.Dep.Pulsar.External {
+		if r.Spec.Dep.Pulsar.InCluster == nil {
+			r.Spec.Dep.Pulsar.InCluster = &InClusterConfig{}
+		}
+		defaults.setInClusterDefaults(r.Spec.Dep.Pulsar.InCluster, depDefaults.Pulsar)
+	}
+	if !r.Spec.Dep.Storage.External {
+		if r.Spec.Dep.Storage.InCluster == nil {
+			r.Spec.Dep.Storage.InCluster = &InClusterConfig{}
+		}
+		defaults.setInClusterDefaults(r.Spec.Dep.Storage.InCluster, depDefaults.Storage)
+	}
+}
+
+// SetOperatorDefaults sets the operator defaults not set in the instance
+func (r *MilvusCluster) SetOperatorDefaults(defaults OperatorDefaults) {
+	defaults.setComponentSpecDefaults(&r.Spec.Com.ComponentSpec)
+	if !r.Spec.Dep.Etcd.External {
+		if r.Spec.Dep.Etcd.InCluster == nil {
+			r.Spec.Dep.Etcd.InCluster = &InClusterConfig{}
+		}
+		defaults.setInClusterDefaults(r.Spec.Dep.Etcd.InCluster, defaults.MilvusCluster.Etcd)
+	}
+	if !r.Spec.Dep.Pulsar.External {
+		if r.Spec.Dep.Pulsar.InCluster == nil {
+			r.Spec.Dep.Pulsar.InCluster = &InClusterConfig{}
+		}
+		defaults.setInClusterDefaults(r.Spec.Dep.Pulsar.InCluster, defaults.MilvusCluster.Pulsar)
+	}
+	if !r.Spec.Dep.Storage.External {
+		if r.Spec.Dep.Storage.InCluster == nil {
+			r.Spec.Dep.Storage.InCluster = &InClusterConfig{}
+		}
+		defaults.setInClusterDefaults(r.Spec.Dep.Storage.InCluster, defaults.MilvusCluster.Storage)
+	}
+}
+
+func (d OperatorDefaults) setComponentSpecDefaults(spec *ComponentSpec) {
+	defaults := d.ComponentSpec.DeepCopy()
+	if spec.Image == "" {
+		spec.Image = defaults.Image
+	}
+	if spec.ImagePullPolicy == nil {
+		spec.ImagePullPolicy = defaults.ImagePullPolicy
+	}
+	if len(spec.ImagePullSecrets) == 0 && len(defaults.ImagePullSecrets) > 0 {
+		spec.ImagePullSecrets = defaults.ImagePullSecrets
+	}
+	if len(spec.Env) == 0 && len(defaults.Env) > 0 {
+		spec.Env = defaults.Env
+	}
+	if spec.NodeSelector == nil {
+		spec.NodeSelector = defaults.NodeSelector
+	}
+	if len(spec.Tolerations) == 0 && len(defaults.Tolerations) > 0 {
+		spec.Tolerations = defaults.Tolerations
+	}
+	if spec.Resources == nil {
+		spec.Resources = defaults.Resources
+	}
+}
+
+func (d OperatorDefaults) setInClusterDefaults(inCluster *InClusterConfig, values Values) {
+	if inCluster.DeletionPolicy == "" {
+		inCluster.DeletionPolicy = d.DeletionPolicy
+	}
+	inCluster.Values.Data = mergeDefaultValues(inCluster.Values.Data, values.Data)
+}
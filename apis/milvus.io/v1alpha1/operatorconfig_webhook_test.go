@@ -0,0 +1,143 @@
+package v1alpha1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/milvus-io/milvus-operator/pkg/config"
+)
+
+func newTestOperatorConfig() *MilvusOperatorConfig {
+	always := corev1.PullAlways
+	ifNotPresent := corev1.PullIfNotPresent
+	return &MilvusOperatorConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: MilvusOperatorConfigName},
+		Spec: MilvusOperatorConfigSpec{
+			Defaults: OperatorDefaults{
+				ComponentSpec: ComponentSpec{
+					Image:           "my/milvus:v2.0.0",
+					ImagePullPolicy: &always,
+					Tolerations:     []corev1.Toleration{{Key: "dedicated", Value: "milvus"}},
+				},
+				DeletionPolicy: DeletionPolicyRetain,
+				Milvus: DependencyValuesDefaults{
+					Storage: Values{Data: map[string]interface{}{"persistence": map[string]interface{}{"size": "100Gi"}}},
+				},
+				MilvusCluster: DependencyValuesDefaults{
+					Etcd:   Values{Data: map[string]interface{}{"replicaCount": float64(3), "auth": map[string]interface{}{"enabled": false}}},
+					Pulsar: Values{Data: map[string]interface{}{"enabled": true}},
+				},
+			},
+			Namespaces: map[string]OperatorDefaults{
+				"dev": {
+					ComponentSpec:  ComponentSpec{ImagePullPolicy: &ifNotPresent},
+					DeletionPolicy: DeletionPolicyDelete,
+					MilvusCluster: DependencyValuesDefaults{
+						Etcd: Values{Data: map[string]interface{}{"replicaCount": float64(1)}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestMilvusOperatorConfigSpec_GetDefaults(t *testing.T) {
+	spec := newTestOperatorConfig().Spec
+
+	defaults := spec.GetDefaults("other")
+	assert.Equal(t, spec.Defaults, defaults)
+
+	defaults = spec.GetDefaults("dev")
+	assert.Equal(t, "my/milvus:v2.0.0", defaults.Image)
+	assert.Equal(t, corev1.PullIfNotPresent, *defaults.ImagePullPolicy)
+	assert.Equal(t, spec.Defaults.Tolerations, defaults.Tolerations)
+	assert.Equal(t, DeletionPolicyDelete, defaults.DeletionPolicy)
+	assert.Equal(t, map[string]interface{}{
+		"replicaCount": float64(1),
+		"auth":         map[string]interface{}{"enabled": false},
+	}, defaults.MilvusCluster.Etcd.Data)
+	assert.Equal(t, spec.Defaults.Milvus, defaults.Milvus)
+	// not modified
+	assert.Equal(t, float64(3), spec.Defaults.MilvusCluster.Etcd.Data["replicaCount"])
+	assert.Equal(t, corev1.PullAlways, *spec.Defaults.ImagePullPolicy)
+}
+
+func TestGetOperatorDefaults(t *testing.T) {
+	ctx := context.Background()
+
+	// not found
+	defaults, err := GetOperatorDefaults(ctx, newWebhookTestClient(t), "ns")
+	assert.NoError(t, err)
+	assert.Equal(t, OperatorDefaults{}, defaults)
+
+	defaults, err = GetOperatorDefaults(ctx, newWebhookTestClient(t, newTestOperatorConfig()), "dev")
+	assert.NoError(t, err)
+	assert.Equal(t, DeletionPolicyDelete, defaults.DeletionPolicy)
+	assert.Equal(t, "my/milvus:v2.0.0", defaults.Image)
+
+	// other names ignored
+	operatorConfig := newTestOperatorConfig()
+	operatorConfig.Name = "other"
+	defaults, err = GetOperatorDefaults(ctx, newWebhookTestClient(t, operatorConfig), "dev")
+	assert.NoError(t, err)
+	assert.Equal(t, OperatorDefaults{}, defaults)
+}
+
+func TestMilvusCluster_Default_OperatorDefaults(t *testing.T) {
+	defer func() { webhookClient = nil }()
+	webhookClient = newWebhookTestClient(t, newTestOperatorConfig())
+
+	mc := &MilvusCluster{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "mc"}}
+	never := corev1.PullNever
+	mc.Spec.Com.ImagePullPolicy = &never
+	mc.Spec.Dep.Etcd.InCluster = &InClusterConfig{
+		DeletionPolicy: DeletionPolicyDelete,
+		Values:         Values{Data: map[string]interface{}{"replicaCount": 5}},
+	}
+	mc.Spec.Dep.Storage.External = true
+	mc.Default()
+
+	assert.Equal(t, "my/milvus:v2.0.0", mc.Spec.Com.Image)
+	assert.Equal(t, corev1.PullNever, *mc.Spec.Com.ImagePullPolicy)
+	assert.Equal(t, []corev1.Toleration{{Key: "dedicated", Value: "milvus"}}, mc.Spec.Com.Tolerations)
+	assert.Equal(t, DeletionPolicyDelete, mc.Spec.Dep.Etcd.InCluster.DeletionPolicy)
+	assert.Equal(t, map[string]interface{}{
+		"replicaCount": 5,
+		"auth":         map[string]interface{}{"enabled": false},
+	}, mc.Spec.Dep.Etcd.InCluster.Values.Data)
+	assert.Equal(t, DeletionPolicyRetain, mc.Spec.Dep.Pulsar.InCluster.DeletionPolicy)
+	assert.Equal(t, true, mc.Spec.Dep.Pulsar.InCluster.Values.Data["enabled"])
+	assert.Nil(t, mc.Spec.Dep.Storage.InCluster)
+}
+
+func TestMilvus_Default_OperatorDefaults(t *testing.T) {
+	defer func() { webhookClient = nil }()
+	webhookClient = newWebhookTestClient(t, newTestOperatorConfig())
+
+	m := &Milvus{ObjectMeta: metav1.ObjectMeta{Namespace: "dev", Name: "m"}}
+	m.Default()
+
+	assert.Equal(t, "my/milvus:v2.0.0", m.Spec.Image)
+	assert.Equal(t, corev1.PullIfNotPresent, *m.Spec.ImagePullPolicy)
+	assert.Equal(t, DeletionPolicyDelete, m.Spec.Dep.Etcd.InCluster.DeletionPolicy)
+	assert.Equal(t, DeletionPolicyDelete, m.Spec.Dep.Storage.InCluster.DeletionPolicy)
+	assert.Equal(t, 1, m.Spec.Dep.Etcd.InCluster.Values.Data["replicaCount"])
+	assert.Equal(t, map[string]interface{}{
+		"mode":        "standalone",
+		"persistence": map[string]interface{}{"size": "100Gi"},
+	}, m.Spec.Dep.Storage.InCluster.Values.Data)
+
+	// builtin defaults without the MilvusOperatorConfig
+	webhookClient = newWebhookTestClient(t)
+	m = &Milvus{ObjectMeta: metav1.ObjectMeta{Namespace: "dev", Name: "m"}}
+	m.Spec.Dep.Etcd.InCluster = &InClusterConfig{Values: Values{Data: map[string]interface{}{"replicaCount": 3}}}
+	m.Default()
+	assert.Equal(t, config.DefaultMilvusImage, m.Spec.Image)
+	assert.Nil(t, m.Spec.ImagePullPolicy)
+	assert.Equal(t, DeletionPolicyRetain, m.Spec.Dep.Etcd.InCluster.DeletionPolicy)
+	assert.Equal(t, 3, m.Spec.Dep.Etcd.InCluster.Values.Data["replicaCount"])
+}
@@ -0,0 +1,370 @@
+package v1alpha1
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const (
+	// AnnotationAllowTransitions lists the fields allowed to make the transitions forbidden by default,
+	// separated by comma, e.g. "spec.dependencies.etcd.external,spec.components.image". "*" allows all
+	AnnotationAllowTransitions = "milvus.io/allow-transitions"
+
+	allowAllTransitions = "*"
+)
+
+// allowedTransitions returns the fields set in the AnnotationAllowTransitions
+func allowedTransitions(annotations map[string]string) map[string]bool {
+	ret := map[string]bool{}
+	for _, path := range strings.Split(annotations[AnnotationAllowTransitions], ",") {
+		path = strings.TrimSpace(path)
+		if path != "" {
+			ret[path] = true
+		}
+	}
+	return ret
+}
+
+func isTransitionAllowed(allowed map[string]bool, fp *field.Path) bool {
+	return allowed[allowAllTransitions] || allowed[fp.String()]
+}
+
+func transitionForbidden(fp *field.Path, detail string) *field.Error {
+	return field.Forbidden(fp, fmt.Sprintf("%s, set annotation %s: %s to force the change",
+		detail, AnnotationAllowTransitions, fp.String()))
+}
+
+// validateImmutable forbids the change which orphans the data
+func validateImmutable(fp *field.Path, allowed map[string]bool, new, old interface{}) *field.Error {
+	if new == old || isTransitionAllowed(allowed, fp) {
+		return nil
+	}
+	return transitionForbidden(fp, fmt.Sprintf("field is immutable, was %v", old))
+}
+
+// getStorageType returns the storage type, the objects created before defaulting have it empty
+func getStorageType(storage MilvusStorage) string {
+	if storage.Type == "" {
+		return "MinIO"
+	}
+	return storage.Type
+}
+
+// getImageVersion returns the semantic version of the image tag, nil if it's not
+func getImageVersion(image string) *semver.Version {
+	if strings.Contains(image, "@") {
+		return nil
+	}
+	idx := strings.LastIndex(image, ":")
+	if idx < 0 || strings.Contains(image[idx:], "/") {
+		return nil
+	}
+	v, err := semver.NewVersion(image[idx+1:])
+	if err != nil {
+		return nil
+	}
+	return v
+}
+
+// validateImageUpgrade forbids downgrading the image which may break the data
+func validateImageUpgrade(fp *field.Path, allowed map[string]bool, new, old string) *field.Error {
+	if new == old || isTransitionAllowed(allowed, fp) {
+		return nil
+	}
+	newVersion, oldVersion := getImageVersion(new), getImageVersion(old)
+	if newVersion == nil || oldVersion == nil {
+		return nil
+	}
+	if newVersion.LessThan(oldVersion) {
+		return transitionForbidden(fp, fmt.Sprintf("image %s downgrades from %s", new, old))
+	}
+	return nil
+}
+
+// validateResources checks the requests don't exceed the limits
+func validateResources(fp *field.Path, resources *corev1.ResourceRequirements) field.ErrorList {
+	var allErrs field.ErrorList
+	if resources == nil {
+		return allErrs
+	}
+	for name, request := range resources.Requests {
+		limit, ok := resources.Limits[name]
+		if ok && request.Cmp(limit) > 0 {
+			allErrs = append(allErrs, invalid(fp.Child("requests").Key(string(name)), request.String(),
+				fmt.Sprintf("must be less than or equal to %s limit %s", name, limit.String())))
+		}
+	}
+	return allErrs
+}
+
+// validateReplicas checks the replicas of the component
+func validateReplicas(fp *field.Path, componentType ComponentType, activeStandby bool, replicas *int32) *field.Error {
+	if replicas == nil {
+		return nil
+	}
+	if *replicas < 0 {
+		return invalid(fp, *replicas, "must be greater than or equal to 0")
+	}
+	if activeStandby {
+		return nil
+	}
+	for _, t := range MilvusCoordTypes {
+		if t == componentType && *replicas > 1 {
+			return invalid(fp, *replicas, "coordinators support at most 1 replica unless activeStandby is enabled")
+		}
+	}
+	return nil
+}
+
+// validateStrategy checks the deployment strategy of the component,
+// the coordinators are recreated so that no two of the same type run at the same time,
+// unless they run in active-standby mode
+func validateStrategy(fp *field.Path, componentType ComponentType, activeStandby bool, strategy *appsv1.DeploymentStrategy) *field.Error {
+	if strategy == nil || activeStandby {
+		return nil
+	}
+	for _, t := range MilvusCoordTypes {
+		if t == componentType && strategy.Type != appsv1.RecreateDeploymentStrategyType {
+			return field.NotSupported(fp.Child("type"), strategy.Type, []string{string(appsv1.RecreateDeploymentStrategyType)})
+		}
+	}
+	return nil
+}
+
+// activeStandbySince is the first milvus version which supports the active-standby coordinators
+var activeStandbySince = semver.MustParse("2.2.3")
+
+// validateActiveStandby checks the image of the coordinator supports the active-standby mode,
+// the image of which the tag is not a semantic version is allowed
+func validateActiveStandby(fp *field.Path, image string) *field.Error {
+	version := getImageVersion(image)
+	if version == nil || !version.LessThan(activeStandbySince) {
+		return nil
+	}
+	return field.Forbidden(fp, fmt.Sprintf("requires milvus v%s or later, the image is %s", activeStandbySince, image))
+}
+
+// validateEachComponent checks the replicas, the resources and the strategy of each component,
+// the component image defaults to the global @image
+func validateEachComponent(com *MilvusComponents, image string) field.ErrorList {
+	var allErrs field.ErrorList
+	fp := field.NewPath("spec").Child("components")
+	for i, component := range com.GetComponents() {
+		componentType := MilvusComponentTypes[i]
+		cp := fp.Child(componentType.String())
+		activeStandby := com.IsActiveStandby(componentType)
+		if activeStandby {
+			componentImage := component.Image
+			if componentImage == "" {
+				componentImage = image
+			}
+			if err := validateActiveStandby(cp.Child("activeStandby"), componentImage); err != nil {
+				allErrs = append(allErrs, err)
+			}
+		}
+		if err := validateReplicas(cp.Child("replicas"), componentType, activeStandby, component.Replicas); err != nil {
+			allErrs = append(allErrs, err)
+		}
+		allErrs = append(allErrs, validateResources(cp.Child("resources"), component.Resources)...)
+		if err := validateStrategy(cp.Child("strategy"), componentType, activeStandby, component.Strategy); err != nil {
+			allErrs = append(allErrs, err)
+		}
+	}
+	allErrs = append(allErrs, validateComponentGroups(fp.Child(QueryNode.String()).Child("groups"), com.QueryNode.Groups)...)
+	allErrs = append(allErrs, validateComponentGroups(fp.Child(IndexNode.String()).Child("groups"), com.IndexNode.Groups)...)
+	return allErrs
+}
+
+// validateComponentGroups checks the names of the groups are unique, and the resources of each group
+func validateComponentGroups(fp *field.Path, groups []ComponentGroup) field.ErrorList {
+	var allErrs field.ErrorList
+	names := map[string]bool{}
+	for i, group := range groups {
+		gp := fp.Index(i)
+		if names[group.Name] {
+			allErrs = append(allErrs, field.Duplicate(gp.Child("name"), group.Name))
+		}
+		names[group.Name] = true
+		allErrs = append(allErrs, validateResources(gp.Child("resources"), group.Resources)...)
+	}
+	return allErrs
+}
+
+// validateComponentImageUpgrades checks the upgrade of each component image, which defaults to the global one
+func validateComponentImageUpgrades(allowed map[string]bool, com, oldCom *MilvusComponents, image, oldImage string) field.ErrorList {
+	var allErrs field.ErrorList
+	fp := field.NewPath("spec").Child("components")
+	oldComponents := oldCom.GetComponents()
+	for i, component := range com.GetComponents() {
+		oldComponent := oldComponents[i]
+		if component.Image == "" && oldComponent.Image == "" {
+			continue
+		}
+		componentImage, oldComponentImage := component.Image, oldComponent.Image
+		if componentImage == "" {
+			componentImage = image
+		}
+		if oldComponentImage == "" {
+			oldComponentImage = oldImage
+		}
+		cp := fp.Child(MilvusComponentTypes[i].String()).Child("image")
+		if err := validateImageUpgrade(cp, allowed, componentImage, oldComponentImage); err != nil {
+			allErrs = append(allErrs, err)
+		}
+	}
+	return allErrs
+}
+
+// validateStatefulSetUpdate forbids changing the volume claim of a statefulset, whose volumeClaimTemplates is immutable,
+// and switching it between ephemeral and persistent, which adds or removes its volumeClaimTemplates.
+// It's changed by removing the statefulSet first, which runs the nodes as a deployment and deletes the claims
+func validateStatefulSetUpdate(fp *field.Path, new, old *StatefulSetSpec) *field.Error {
+	if new == nil || old == nil {
+		return nil
+	}
+	if new.Ephemeral != old.Ephemeral {
+		return field.Forbidden(fp.Child("ephemeral"),
+			"the volume claim templates of a statefulset are immutable, remove the statefulSet before changing it")
+	}
+	// the claim of an ephemeral volume is in the pod template
+	if new.Ephemeral {
+		return nil
+	}
+	if reflect.DeepEqual(new.VolumeClaim, old.VolumeClaim) {
+		return nil
+	}
+	return field.Forbidden(fp.Child("volumeClaim"),
+		"the volume claim of a statefulset is immutable, remove the statefulSet before changing it")
+}
+
+// validateStatefulSetUpdates checks the updates of the statefulsets of the nodes
+func validateStatefulSetUpdates(com, oldCom *MilvusComponents) field.ErrorList {
+	var allErrs field.ErrorList
+	fp := field.NewPath("spec").Child("components")
+	if err := validateStatefulSetUpdate(fp.Child(QueryNode.String()).Child("statefulSet"),
+		com.QueryNode.StatefulSet, oldCom.QueryNode.StatefulSet); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	if err := validateStatefulSetUpdate(fp.Child(DataNode.String()).Child("statefulSet"),
+		com.DataNode.StatefulSet, oldCom.DataNode.StatefulSet); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	return allErrs
+}
+
+func (r *MilvusCluster) validateComponents() field.ErrorList {
+	var allErrs field.ErrorList
+	fp := field.NewPath("spec").Child("components")
+
+	allErrs = append(allErrs, validateResources(fp.Child("resources"), r.Spec.Com.Resources)...)
+	allErrs = append(allErrs, validateEachComponent(&r.Spec.Com, r.Spec.Com.Image)...)
+
+	return allErrs
+}
+
+func (r *MilvusCluster) validateTransitions(old *MilvusCluster) field.ErrorList {
+	var allErrs field.ErrorList
+	allowed := allowedTransitions(r.Annotations)
+
+	fp := field.NewPath("spec").Child("dependencies")
+	if err := validateImmutable(fp.Child("etcd").Child("external"), allowed,
+		r.Spec.Dep.Etcd.External, old.Spec.Dep.Etcd.External); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	if err := validateImmutable(fp.Child("pulsar").Child("external"), allowed,
+		r.Spec.Dep.Pulsar.External, old.Spec.Dep.Pulsar.External); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	if err := validateImmutable(fp.Child("storage").Child("external"), allowed,
+		r.Spec.Dep.Storage.External, old.Spec.Dep.Storage.External); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	if err := validateImmutable(fp.Child("storage").Child("type"), allowed,
+		getStorageType(r.Spec.Dep.Storage), getStorageType(old.Spec.Dep.Storage)); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	if err := validateImmutable(fp.Child("pool"), allowed,
+		poolUsageEndpoint(r.Namespace, r.Spec.Dep.Pool), poolUsageEndpoint(old.Namespace, old.Spec.Dep.Pool)); err != nil {
+		allErrs = append(allErrs, err)
+	}
+
+	fp = field.NewPath("spec").Child("components")
+	if err := validateImageUpgrade(fp.Child("image"), allowed, r.Spec.Com.Image, old.Spec.Com.Image); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	allErrs = append(allErrs, validateComponentImageUpgrades(allowed, &r.Spec.Com, &old.Spec.Com, r.Spec.Com.Image, old.Spec.Com.Image)...)
+	allErrs = append(allErrs, validateStatefulSetUpdates(&r.Spec.Com, &old.Spec.Com)...)
+
+	return allErrs
+}
+
+func (r *Milvus) validateComponents() field.ErrorList {
+	var allErrs field.ErrorList
+	allErrs = append(allErrs, validateResources(field.NewPath("spec").Child("resources"), r.Spec.Resources)...)
+	if !r.Spec.IsCluster() {
+		return allErrs
+	}
+
+	// the global fields are shared by both modes
+	if !reflect.DeepEqual(r.Spec.Com.ComponentSpec, ComponentSpec{}) {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec").Child("components"),
+			"the global fields of the components should be set in spec"))
+	}
+	allErrs = append(allErrs, validateEachComponent(&r.Spec.Com, r.Spec.Image)...)
+	return allErrs
+}
+
+func (r *Milvus) validateTransitions(old *Milvus) field.ErrorList {
+	var allErrs field.ErrorList
+	allowed := allowedTransitions(r.Annotations)
+
+	fp := field.NewPath("spec").Child("dependencies")
+	if err := validateImmutable(fp.Child("etcd").Child("external"), allowed,
+		r.Spec.Dep.Etcd.External, old.Spec.Dep.Etcd.External); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	if err := validateImmutable(fp.Child("storage").Child("external"), allowed,
+		r.Spec.Dep.Storage.External, old.Spec.Dep.Storage.External); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	if err := validateImmutable(fp.Child("storage").Child("type"), allowed,
+		getStorageType(r.Spec.Dep.Storage), getStorageType(old.Spec.Dep.Storage)); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	if err := validateImmutable(fp.Child("pool"), allowed,
+		poolUsageEndpoint(r.Namespace, r.Spec.Dep.Pool), poolUsageEndpoint(old.Namespace, old.Spec.Dep.Pool)); err != nil {
+		allErrs = append(allErrs, err)
+	}
+
+	if err := validateImageUpgrade(field.NewPath("spec").Child("image"), allowed, r.Spec.Image, old.Spec.Image); err != nil {
+		allErrs = append(allErrs, err)
+	}
+
+	// a standalone can be migrated to cluster, the messages not flushed in its embedded message stream are not kept
+	modePath := field.NewPath("spec").Child("mode")
+	if old.Spec.IsCluster() && !r.Spec.IsCluster() {
+		allErrs = append(allErrs, field.Forbidden(modePath, "cluster mode can't be changed back to standalone"))
+	}
+	if !old.Spec.IsCluster() && r.Spec.IsCluster() && !isTransitionAllowed(allowed, modePath) {
+		allErrs = append(allErrs, transitionForbidden(modePath,
+			"the messages not flushed in the embedded message stream of the standalone are lost, flush all the collections first"))
+	}
+	if !old.Spec.IsCluster() || !r.Spec.IsCluster() {
+		return allErrs
+	}
+
+	if err := validateImmutable(fp.Child("pulsar").Child("external"), allowed,
+		r.Spec.Dep.Pulsar.External, old.Spec.Dep.Pulsar.External); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	allErrs = append(allErrs, validateComponentImageUpgrades(allowed, &r.Spec.Com, &old.Spec.Com, r.Spec.Image, old.Spec.Image)...)
+	allErrs = append(allErrs, validateStatefulSetUpdates(&r.Spec.Com, &old.Spec.Com)...)
+
+	return allErrs
+}
@@ -0,0 +1,305 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAllowedTransitions(t *testing.T) {
+	assert.Empty(t, allowedTransitions(nil))
+
+	allowed := allowedTransitions(map[string]string{
+		AnnotationAllowTransitions: "spec.image, spec.dependencies.etcd.external",
+	})
+	assert.Equal(t, map[string]bool{
+		"spec.image":                      true,
+		"spec.dependencies.etcd.external": true,
+	}, allowed)
+}
+
+func TestGetImageVersion(t *testing.T) {
+	assert.Equal(t, "2.0.0-rc8", getImageVersion("milvusdb/milvus:v2.0.0-rc8").String())
+	assert.Equal(t, "2.0.0", getImageVersion("localhost:5000/milvus:2.0.0").String())
+	assert.Nil(t, getImageVersion("localhost:5000/milvus"))
+	assert.Nil(t, getImageVersion("milvusdb/milvus:latest"))
+	assert.Nil(t, getImageVersion("milvusdb/milvus@sha256:abcd"))
+}
+
+func TestMilvusCluster_ValidateUpdate_Transitions(t *testing.T) {
+	old := MilvusCluster{ObjectMeta: metav1.ObjectMeta{Name: "mc"}}
+	old.Default()
+
+	// flip external
+	new := old.DeepCopy()
+	new.Spec.Dep.Pulsar.External = true
+	new.Spec.Dep.Pulsar.Endpoint = "pulsar:6650"
+	err := new.ValidateUpdate(&old)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "spec.dependencies.pulsar.external")
+
+	// with override annotation
+	new.Annotations = map[string]string{AnnotationAllowTransitions: "spec.dependencies.pulsar.external"}
+	assert.NoError(t, new.ValidateUpdate(&old))
+
+	// change storage type
+	new = old.DeepCopy()
+	new.Spec.Dep.Storage.Type = "S3"
+	assert.Error(t, new.ValidateUpdate(&old))
+	new.Annotations = map[string]string{AnnotationAllowTransitions: "*"}
+	assert.NoError(t, new.ValidateUpdate(&old))
+
+	// legacy object with empty storage type
+	legacy := old.DeepCopy()
+	legacy.Spec.Dep.Storage.Type = ""
+	assert.NoError(t, old.ValidateUpdate(legacy))
+}
+
+func TestMilvusCluster_ValidateUpdate_ImageDowngrade(t *testing.T) {
+	old := MilvusCluster{ObjectMeta: metav1.ObjectMeta{Name: "mc"}}
+	old.Spec.Com.Image = "milvusdb/milvus:v2.0.1"
+	old.Default()
+
+	new := old.DeepCopy()
+	new.Spec.Com.Image = "milvusdb/milvus:v2.1.0"
+	assert.NoError(t, new.ValidateUpdate(&old))
+
+	new.Spec.Com.Image = "milvusdb/milvus:v2.0.0"
+	err := new.ValidateUpdate(&old)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "spec.components.image")
+
+	// component image
+	new = old.DeepCopy()
+	new.Spec.Com.QueryNode.Image = "milvusdb/milvus:v2.0.0"
+	err = new.ValidateUpdate(&old)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "spec.components.queryNode.image")
+	new.Annotations = map[string]string{AnnotationAllowTransitions: "spec.components.queryNode.image"}
+	assert.NoError(t, new.ValidateUpdate(&old))
+
+	// not semantic versions
+	new = old.DeepCopy()
+	new.Spec.Com.Image = "milvusdb/milvus:latest"
+	assert.NoError(t, new.ValidateUpdate(&old))
+}
+
+func TestMilvusCluster_ValidateCreate_Components(t *testing.T) {
+	mc := MilvusCluster{ObjectMeta: metav1.ObjectMeta{Name: "mc"}}
+	mc.Default()
+	assert.NoError(t, mc.ValidateCreate())
+
+	replicas := int32(2)
+	mc.Spec.Com.RootCoord.Replicas = &replicas
+	mc.Spec.Com.QueryNode.Replicas = &replicas
+	err := mc.ValidateCreate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "spec.components.rootCoord.replicas")
+	assert.NotContains(t, err.Error(), "spec.components.queryNode.replicas")
+
+	mc.Default()
+	mc.Spec.Com.RootCoord.Replicas = nil
+	negative := int32(-1)
+	mc.Spec.Com.QueryNode.Replicas = &negative
+	err = mc.ValidateCreate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "spec.components.queryNode.replicas")
+
+	mc.Spec.Com.QueryNode.Replicas = nil
+	mc.Spec.Com.DataNode.Resources = &corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")},
+		Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+	}
+	err = mc.ValidateCreate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "spec.components.dataNode.resources.requests[cpu]")
+}
+
+func TestMilvusCluster_ValidateCreate_Strategy(t *testing.T) {
+	mc := MilvusCluster{ObjectMeta: metav1.ObjectMeta{Name: "mc"}}
+	mc.Default()
+	mc.Spec.Com.QueryNode.Strategy = &appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType}
+	mc.Spec.Com.RootCoord.Strategy = &appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType}
+	assert.NoError(t, mc.ValidateCreate())
+
+	mc.Spec.Com.RootCoord.Strategy.Type = appsv1.RollingUpdateDeploymentStrategyType
+	err := mc.ValidateCreate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "spec.components.rootCoord.strategy.type")
+}
+
+func TestMilvusCluster_ValidateCreate_ActiveStandby(t *testing.T) {
+	mc := MilvusCluster{ObjectMeta: metav1.ObjectMeta{Name: "mc"}}
+	mc.Spec.Com.Image = "milvusdb/milvus:v2.2.3"
+	mc.Default()
+	replicas := int32(2)
+	mc.Spec.Com.RootCoord.ActiveStandby = true
+	mc.Spec.Com.RootCoord.Replicas = &replicas
+	mc.Spec.Com.RootCoord.Strategy = &appsv1.DeploymentStrategy{Type: appsv1.RollingUpdateDeploymentStrategyType}
+	assert.NoError(t, mc.ValidateCreate())
+
+	mc.Spec.Com.DataCoord.Replicas = &replicas
+	err := mc.ValidateCreate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "spec.components.dataCoord.replicas")
+	assert.NotContains(t, err.Error(), "spec.components.rootCoord")
+
+	mc.Spec.Com.DataCoord.Replicas = nil
+	mc.Spec.Com.RootCoord.Image = "milvusdb/milvus:v2.2.2"
+	err = mc.ValidateCreate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "spec.components.rootCoord.activeStandby")
+
+	mc.Spec.Com.RootCoord.Image = ""
+	mc.Spec.Com.Image = "milvusdb/milvus:v2.0.0-rc8-20211104-d1f4106"
+	err = mc.ValidateCreate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "spec.components.rootCoord.activeStandby")
+
+	mc.Spec.Com.Image = "milvusdb/milvus:master-latest"
+	assert.NoError(t, mc.ValidateCreate())
+}
+
+func TestMilvusCluster_ValidateCreate_ComponentGroups(t *testing.T) {
+	mc := MilvusCluster{ObjectMeta: metav1.ObjectMeta{Name: "mc"}}
+	mc.Default()
+	mc.Spec.Com.QueryNode.Groups = []ComponentGroup{{Name: "hot"}, {Name: "batch"}}
+	mc.Spec.Com.IndexNode.Groups = []ComponentGroup{{Name: "hot"}}
+	assert.NoError(t, mc.ValidateCreate())
+
+	mc.Spec.Com.QueryNode.Groups = append(mc.Spec.Com.QueryNode.Groups, ComponentGroup{
+		Name: "hot",
+		Resources: &corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("2Gi")},
+			Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+		},
+	})
+	err := mc.ValidateCreate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "spec.components.queryNode.groups[2].name")
+	assert.Contains(t, err.Error(), "spec.components.queryNode.groups[2].resources.requests[memory]")
+	assert.NotContains(t, err.Error(), "spec.components.indexNode.groups")
+}
+
+func TestMilvus_ValidateUpdate_Transitions(t *testing.T) {
+	old := Milvus{ObjectMeta: metav1.ObjectMeta{Name: "m"}}
+	old.Spec.Image = "milvusdb/milvus:v2.0.1"
+	old.Default()
+
+	new := old.DeepCopy()
+	new.Spec.Dep.Etcd.External = true
+	new.Spec.Dep.Etcd.Endpoints = []string{"etcd:2379"}
+	new.Spec.Image = "milvusdb/milvus:v2.0.0"
+	err := new.ValidateUpdate(&old)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "spec.dependencies.etcd.external")
+	assert.Contains(t, err.Error(), "spec.image")
+
+	new.Annotations = map[string]string{AnnotationAllowTransitions: "spec.dependencies.etcd.external,spec.image"}
+	assert.NoError(t, new.ValidateUpdate(&old))
+
+	new = old.DeepCopy()
+	new.Spec.Resources = &corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("2Gi")},
+		Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+	}
+	err = new.ValidateUpdate(&old)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "spec.resources.requests[memory]")
+}
+
+func TestMilvus_ValidateUpdate_Mode(t *testing.T) {
+	old := Milvus{ObjectMeta: metav1.ObjectMeta{Name: "m"}}
+	old.Default()
+
+	new := old.DeepCopy()
+	new.Spec.Mode = MilvusModeCluster
+	new.Default()
+	err := new.ValidateUpdate(&old)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), AnnotationAllowTransitions)
+
+	new.Annotations = map[string]string{AnnotationAllowTransitions: "spec.mode"}
+	assert.NoError(t, new.ValidateUpdate(&old))
+
+	err = old.ValidateUpdate(new)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "spec.mode")
+
+	newer := new.DeepCopy()
+	newer.Spec.Dep.Pulsar.External = true
+	newer.Spec.Dep.Pulsar.Endpoint = "pulsar:6650"
+	err = newer.ValidateUpdate(new)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "spec.dependencies.pulsar.external")
+}
+
+func TestMilvus_ValidateCreate_ClusterModeComponents(t *testing.T) {
+	m := Milvus{ObjectMeta: metav1.ObjectMeta{Name: "m"}}
+	m.Spec.Mode = MilvusModeCluster
+	m.Default()
+	assert.NoError(t, m.ValidateCreate())
+
+	m.Spec.Com.Image = "milvusdb/milvus:latest"
+	err := m.ValidateCreate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "spec.components")
+
+	m.Spec.Com.Image = ""
+	replicas := int32(2)
+	m.Spec.Com.RootCoord.Replicas = &replicas
+	err = m.ValidateCreate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "spec.components.rootCoord.replicas")
+
+	// the global fields are ignored in standalone mode
+	m.Spec.Mode = MilvusModeStandalone
+	m.Spec.Com.Image = "milvusdb/milvus:latest"
+	assert.NoError(t, m.ValidateCreate())
+}
+
+func TestMilvus_ValidateUpdate_StatefulSet(t *testing.T) {
+	old := Milvus{ObjectMeta: metav1.ObjectMeta{Name: "m"}}
+	old.Spec.Mode = MilvusModeCluster
+	old.Default()
+
+	// switched to statefulset
+	new := old.DeepCopy()
+	new.Spec.Com.QueryNode.StatefulSet = &StatefulSetSpec{}
+	new.Spec.Com.QueryNode.StatefulSet.VolumeClaim.StorageClassName = &[]string{"ssd"}[0]
+	assert.NoError(t, new.ValidateUpdate(&old))
+
+	newer := new.DeepCopy()
+	newer.Spec.Com.QueryNode.StatefulSet.VolumeClaim.StorageClassName = &[]string{"hdd"}[0]
+	err := newer.ValidateUpdate(new)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "spec.components.queryNode.statefulSet.volumeClaim")
+
+	// switched to ephemeral
+	ephemeral := new.DeepCopy()
+	ephemeral.Spec.Com.QueryNode.StatefulSet.Ephemeral = true
+	err = ephemeral.ValidateUpdate(new)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "spec.components.queryNode.statefulSet.ephemeral")
+	err = new.ValidateUpdate(ephemeral)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "spec.components.queryNode.statefulSet.ephemeral")
+
+	// the claim of an ephemeral volume is created with the pod
+	new.Spec.Com.QueryNode.StatefulSet.Ephemeral = true
+	newer.Spec.Com.QueryNode.StatefulSet.Ephemeral = true
+	assert.NoError(t, newer.ValidateUpdate(new))
+
+	mc := MilvusCluster{ObjectMeta: metav1.ObjectMeta{Name: "mc"}}
+	mc.Default()
+	mc.Spec.Com.DataNode.StatefulSet = &StatefulSetSpec{}
+	newMC := mc.DeepCopy()
+	newMC.Spec.Com.DataNode.StatefulSet.VolumeClaim.VolumeName = "pv"
+	err = newMC.ValidateUpdate(&mc)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "spec.components.dataNode.statefulSet.volumeClaim")
+}
@@ -21,10 +21,32 @@ limitations under the License.
 package v1alpha1
 
 import (
+	appsv1 "k8s.io/api/apps/v1"
 	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChartSource) DeepCopyInto(out *ChartSource) {
+	*out = *in
+	if in.PullSecrets != nil {
+		in, out := &in.PullSecrets, &out.PullSecrets
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChartSource.
+func (in *ChartSource) DeepCopy() *ChartSource {
+	if in == nil {
+		return nil
+	}
+	out := new(ChartSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Component) DeepCopyInto(out *Component) {
 	*out = *in
@@ -34,6 +56,17 @@ func (in *Component) DeepCopyInto(out *Component) {
 		*out = new(int32)
 		**out = **in
 	}
+	in.Conf.DeepCopyInto(&out.Conf)
+	if in.Strategy != nil {
+		in, out := &in.Strategy, &out.Strategy
+		*out = new(appsv1.DeploymentStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GracefulShutdown != nil {
+		in, out := &in.GracefulShutdown, &out.GracefulShutdown
+		*out = new(GracefulShutdown)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Component.
@@ -46,6 +79,45 @@ func (in *Component) DeepCopy() *Component {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComponentGroup) DeepCopyInto(out *ComponentGroup) {
+	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentGroup.
+func (in *ComponentGroup) DeepCopy() *ComponentGroup {
+	if in == nil {
+		return nil
+	}
+	out := new(ComponentGroup)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ComponentSpec) DeepCopyInto(out *ComponentSpec) {
 	*out = *in
@@ -97,10 +169,78 @@ func (in *ComponentSpec) DeepCopy() *ComponentSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DependencyPoolRef) DeepCopyInto(out *DependencyPoolRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DependencyPoolRef.
+func (in *DependencyPoolRef) DeepCopy() *DependencyPoolRef {
+	if in == nil {
+		return nil
+	}
+	out := new(DependencyPoolRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DependencyValuesDefaults) DeepCopyInto(out *DependencyValuesDefaults) {
+	*out = *in
+	in.Etcd.DeepCopyInto(&out.Etcd)
+	in.Storage.DeepCopyInto(&out.Storage)
+	in.Pulsar.DeepCopyInto(&out.Pulsar)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DependencyValuesDefaults.
+func (in *DependencyValuesDefaults) DeepCopy() *DependencyValuesDefaults {
+	if in == nil {
+		return nil
+	}
+	out := new(DependencyValuesDefaults)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GracefulShutdown) DeepCopyInto(out *GracefulShutdown) {
+	*out = *in
+	if in.GracefulTimeSeconds != nil {
+		in, out := &in.GracefulTimeSeconds, &out.GracefulTimeSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.StopTimeoutSeconds != nil {
+		in, out := &in.StopTimeoutSeconds, &out.StopTimeoutSeconds
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GracefulShutdown.
+func (in *GracefulShutdown) DeepCopy() *GracefulShutdown {
+	if in == nil {
+		return nil
+	}
+	out := new(GracefulShutdown)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InClusterConfig) DeepCopyInto(out *InClusterConfig) {
 	*out = *in
 	in.Values.DeepCopyInto(&out.Values)
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Chart != nil {
+		in, out := &in.Chart, &out.Chart
+		*out = new(ChartSource)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InClusterConfig.
@@ -173,6 +313,11 @@ func (in *MilvusClusterDependencies) DeepCopyInto(out *MilvusClusterDependencies
 	in.Etcd.DeepCopyInto(&out.Etcd)
 	in.Pulsar.DeepCopyInto(&out.Pulsar)
 	in.Storage.DeepCopyInto(&out.Storage)
+	if in.Pool != nil {
+		in, out := &in.Pool, &out.Pool
+		*out = new(DependencyPoolRef)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MilvusClusterDependencies.
@@ -245,6 +390,13 @@ func (in *MilvusClusterStatus) DeepCopyInto(out *MilvusClusterStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ActiveCoordinators != nil {
+		in, out := &in.ActiveCoordinators, &out.ActiveCoordinators
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MilvusClusterStatus.
@@ -320,6 +472,11 @@ func (in *MilvusDataCoord) DeepCopy() *MilvusDataCoord {
 func (in *MilvusDataNode) DeepCopyInto(out *MilvusDataNode) {
 	*out = *in
 	in.Component.DeepCopyInto(&out.Component)
+	if in.StatefulSet != nil {
+		in, out := &in.StatefulSet, &out.StatefulSet
+		*out = new(StatefulSetSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MilvusDataNode.
@@ -336,7 +493,13 @@ func (in *MilvusDataNode) DeepCopy() *MilvusDataNode {
 func (in *MilvusDependencies) DeepCopyInto(out *MilvusDependencies) {
 	*out = *in
 	in.Etcd.DeepCopyInto(&out.Etcd)
+	in.Pulsar.DeepCopyInto(&out.Pulsar)
 	in.Storage.DeepCopyInto(&out.Storage)
+	if in.Pool != nil {
+		in, out := &in.Pool, &out.Pool
+		*out = new(DependencyPoolRef)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MilvusDependencies.
@@ -349,6 +512,115 @@ func (in *MilvusDependencies) DeepCopy() *MilvusDependencies {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MilvusDependencyPool) DeepCopyInto(out *MilvusDependencyPool) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MilvusDependencyPool.
+func (in *MilvusDependencyPool) DeepCopy() *MilvusDependencyPool {
+	if in == nil {
+		return nil
+	}
+	out := new(MilvusDependencyPool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MilvusDependencyPool) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MilvusDependencyPoolList) DeepCopyInto(out *MilvusDependencyPoolList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MilvusDependencyPool, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MilvusDependencyPoolList.
+func (in *MilvusDependencyPoolList) DeepCopy() *MilvusDependencyPoolList {
+	if in == nil {
+		return nil
+	}
+	out := new(MilvusDependencyPoolList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MilvusDependencyPoolList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MilvusDependencyPoolSpec) DeepCopyInto(out *MilvusDependencyPoolSpec) {
+	*out = *in
+	in.Etcd.DeepCopyInto(&out.Etcd)
+	in.Storage.DeepCopyInto(&out.Storage)
+	in.Pulsar.DeepCopyInto(&out.Pulsar)
+	if in.AllowedNamespaces != nil {
+		in, out := &in.AllowedNamespaces, &out.AllowedNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MilvusDependencyPoolSpec.
+func (in *MilvusDependencyPoolSpec) DeepCopy() *MilvusDependencyPoolSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MilvusDependencyPoolSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MilvusDependencyPoolStatus) DeepCopyInto(out *MilvusDependencyPoolStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]MilvusCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Instances != nil {
+		in, out := &in.Instances, &out.Instances
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MilvusDependencyPoolStatus.
+func (in *MilvusDependencyPoolStatus) DeepCopy() *MilvusDependencyPoolStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MilvusDependencyPoolStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MilvusEtcd) DeepCopyInto(out *MilvusEtcd) {
 	*out = *in
@@ -357,6 +629,11 @@ func (in *MilvusEtcd) DeepCopyInto(out *MilvusEtcd) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.ServiceRef != nil {
+		in, out := &in.ServiceRef, &out.ServiceRef
+		*out = new(ServiceRef)
+		**out = **in
+	}
 	if in.InCluster != nil {
 		in, out := &in.InCluster, &out.InCluster
 		*out = new(InClusterConfig)
@@ -409,6 +686,13 @@ func (in *MilvusIndexCoord) DeepCopy() *MilvusIndexCoord {
 func (in *MilvusIndexNode) DeepCopyInto(out *MilvusIndexNode) {
 	*out = *in
 	in.Component.DeepCopyInto(&out.Component)
+	if in.Groups != nil {
+		in, out := &in.Groups, &out.Groups
+		*out = make([]ComponentGroup, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MilvusIndexNode.
@@ -453,6 +737,87 @@ func (in *MilvusList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MilvusOperatorConfig) DeepCopyInto(out *MilvusOperatorConfig) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MilvusOperatorConfig.
+func (in *MilvusOperatorConfig) DeepCopy() *MilvusOperatorConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(MilvusOperatorConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MilvusOperatorConfig) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MilvusOperatorConfigList) DeepCopyInto(out *MilvusOperatorConfigList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]MilvusOperatorConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MilvusOperatorConfigList.
+func (in *MilvusOperatorConfigList) DeepCopy() *MilvusOperatorConfigList {
+	if in == nil {
+		return nil
+	}
+	out := new(MilvusOperatorConfigList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *MilvusOperatorConfigList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MilvusOperatorConfigSpec) DeepCopyInto(out *MilvusOperatorConfigSpec) {
+	*out = *in
+	in.Defaults.DeepCopyInto(&out.Defaults)
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make(map[string]OperatorDefaults, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MilvusOperatorConfigSpec.
+func (in *MilvusOperatorConfigSpec) DeepCopy() *MilvusOperatorConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MilvusOperatorConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MilvusProxy) DeepCopyInto(out *MilvusProxy) {
 	*out = *in
@@ -477,6 +842,11 @@ func (in *MilvusPulsar) DeepCopyInto(out *MilvusPulsar) {
 		*out = new(InClusterConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ServiceRef != nil {
+		in, out := &in.ServiceRef, &out.ServiceRef
+		*out = new(ServiceRef)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MilvusPulsar.
@@ -509,6 +879,18 @@ func (in *MilvusQueryCoord) DeepCopy() *MilvusQueryCoord {
 func (in *MilvusQueryNode) DeepCopyInto(out *MilvusQueryNode) {
 	*out = *in
 	in.Component.DeepCopyInto(&out.Component)
+	if in.Groups != nil {
+		in, out := &in.Groups, &out.Groups
+		*out = make([]ComponentGroup, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.StatefulSet != nil {
+		in, out := &in.StatefulSet, &out.StatefulSet
+		*out = new(StatefulSetSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MilvusQueryNode.
@@ -541,6 +923,7 @@ func (in *MilvusRootCoord) DeepCopy() *MilvusRootCoord {
 func (in *MilvusSpec) DeepCopyInto(out *MilvusSpec) {
 	*out = *in
 	in.ComponentSpec.DeepCopyInto(&out.ComponentSpec)
+	in.Com.DeepCopyInto(&out.Com)
 	in.Dep.DeepCopyInto(&out.Dep)
 	in.Conf.DeepCopyInto(&out.Conf)
 }
@@ -565,6 +948,13 @@ func (in *MilvusStatus) DeepCopyInto(out *MilvusStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ActiveCoordinators != nil {
+		in, out := &in.ActiveCoordinators, &out.ActiveCoordinators
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MilvusStatus.
@@ -580,6 +970,11 @@ func (in *MilvusStatus) DeepCopy() *MilvusStatus {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MilvusStorage) DeepCopyInto(out *MilvusStorage) {
 	*out = *in
+	if in.ServiceRef != nil {
+		in, out := &in.ServiceRef, &out.ServiceRef
+		*out = new(ServiceRef)
+		**out = **in
+	}
 	if in.InCluster != nil {
 		in, out := &in.InCluster, &out.InCluster
 		*out = new(InClusterConfig)
@@ -612,6 +1007,55 @@ func (in *MilvusStorageStatus) DeepCopy() *MilvusStorageStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperatorDefaults) DeepCopyInto(out *OperatorDefaults) {
+	*out = *in
+	in.ComponentSpec.DeepCopyInto(&out.ComponentSpec)
+	in.Milvus.DeepCopyInto(&out.Milvus)
+	in.MilvusCluster.DeepCopyInto(&out.MilvusCluster)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperatorDefaults.
+func (in *OperatorDefaults) DeepCopy() *OperatorDefaults {
+	if in == nil {
+		return nil
+	}
+	out := new(OperatorDefaults)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceRef) DeepCopyInto(out *ServiceRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceRef.
+func (in *ServiceRef) DeepCopy() *ServiceRef {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatefulSetSpec) DeepCopyInto(out *StatefulSetSpec) {
+	*out = *in
+	in.VolumeClaim.DeepCopyInto(&out.VolumeClaim)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatefulSetSpec.
+func (in *StatefulSetSpec) DeepCopy() *StatefulSetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(StatefulSetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Values.
 func (in *Values) DeepCopy() *Values {
 	if in == nil {
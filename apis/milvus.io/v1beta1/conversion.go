@@ -0,0 +1,145 @@
+package v1beta1
+
+import (
+	"encoding/json"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/milvus-io/milvus-operator/apis/milvus.io/v1alpha1"
+)
+
+// ConvertTo converts the Milvus to the hub version v1alpha1
+func (r *Milvus) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1alpha1.Milvus)
+	dst.ObjectMeta = r.ObjectMeta
+
+	dst.Spec = v1alpha1.MilvusSpec{}
+	if err := convertSpec(r.Spec, &dst.Spec); err != nil {
+		return err
+	}
+
+	dst.Status = v1alpha1.MilvusStatus{
+		Status:             v1alpha1.MilvusHealthStatus(r.Status.Status),
+		ObservedGeneration: r.Status.ObservedGeneration,
+		Conditions:         convertConditionsToHub(r.Status.Conditions),
+		Endpoint:           r.Status.Endpoint,
+		ActiveCoordinators: r.Status.ActiveCoordinators,
+	}
+	return nil
+}
+
+// ConvertFrom converts the hub version v1alpha1 to the Milvus
+func (r *Milvus) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1alpha1.Milvus)
+	r.ObjectMeta = src.ObjectMeta
+
+	r.Spec = MilvusSpec{}
+	if err := convertSpec(src.Spec, &r.Spec); err != nil {
+		return err
+	}
+
+	r.Status = MilvusStatus{
+		Status:             MilvusHealthStatus(src.Status.Status),
+		ObservedGeneration: src.Status.ObservedGeneration,
+		Conditions:         convertConditionsFromHub(src.Status.Conditions, src.Status.ObservedGeneration),
+		Endpoint:           src.Status.Endpoint,
+		ActiveCoordinators: src.Status.ActiveCoordinators,
+	}
+	return nil
+}
+
+// ConvertTo converts the MilvusCluster to the hub version v1alpha1
+func (r *MilvusCluster) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1alpha1.MilvusCluster)
+	dst.ObjectMeta = r.ObjectMeta
+
+	dst.Spec = v1alpha1.MilvusClusterSpec{}
+	if err := convertSpec(r.Spec, &dst.Spec); err != nil {
+		return err
+	}
+
+	dst.Status = v1alpha1.MilvusClusterStatus{
+		Status:             v1alpha1.MilvusHealthStatus(r.Status.Status),
+		ObservedGeneration: r.Status.ObservedGeneration,
+		Conditions:         convertConditionsToHub(r.Status.Conditions),
+		Endpoint:           r.Status.Endpoint,
+		ActiveCoordinators: r.Status.ActiveCoordinators,
+	}
+	return nil
+}
+
+// ConvertFrom converts the hub version v1alpha1 to the MilvusCluster
+func (r *MilvusCluster) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1alpha1.MilvusCluster)
+	r.ObjectMeta = src.ObjectMeta
+
+	r.Spec = MilvusClusterSpec{}
+	if err := convertSpec(src.Spec, &r.Spec); err != nil {
+		return err
+	}
+
+	r.Status = MilvusStatus{
+		Status:             MilvusHealthStatus(src.Status.Status),
+		ObservedGeneration: src.Status.ObservedGeneration,
+		Conditions:         convertConditionsFromHub(src.Status.Conditions, src.Status.ObservedGeneration),
+		Endpoint:           src.Status.Endpoint,
+		ActiveCoordinators: src.Status.ActiveCoordinators,
+	}
+	return nil
+}
+
+// convertSpec converts the spec between the versions, only the go field names differ between them,
+// so they're converted by the json to keep the fields added later in sync
+func convertSpec(src, dst interface{}) error {
+	data, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dst)
+}
+
+func convertConditionsToHub(conditions []metav1.Condition) []v1alpha1.MilvusCondition {
+	if conditions == nil {
+		return nil
+	}
+	ret := make([]v1alpha1.MilvusCondition, 0, len(conditions))
+	for _, c := range conditions {
+		condition := v1alpha1.MilvusCondition{
+			Type:    v1alpha1.MiluvsConditionType(c.Type),
+			Status:  corev1.ConditionStatus(c.Status),
+			Reason:  c.Reason,
+			Message: c.Message,
+		}
+		if !c.LastTransitionTime.IsZero() {
+			lastTransitionTime := c.LastTransitionTime
+			condition.LastTransitionTime = &lastTransitionTime
+		}
+		ret = append(ret, condition)
+	}
+	return ret
+}
+
+// convertConditionsFromHub converts the conditions of v1alpha1, they're updated together with the status,
+// so their observedGeneration is the one of the status
+func convertConditionsFromHub(conditions []v1alpha1.MilvusCondition, observedGeneration int64) []metav1.Condition {
+	if conditions == nil {
+		return nil
+	}
+	ret := make([]metav1.Condition, 0, len(conditions))
+	for _, c := range conditions {
+		condition := metav1.Condition{
+			Type:               string(c.Type),
+			Status:             metav1.ConditionStatus(c.Status),
+			ObservedGeneration: observedGeneration,
+			Reason:             c.Reason,
+			Message:            c.Message,
+		}
+		if c.LastTransitionTime != nil {
+			condition.LastTransitionTime = *c.LastTransitionTime
+		}
+		ret = append(ret, condition)
+	}
+	return ret
+}
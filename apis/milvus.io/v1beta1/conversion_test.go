@@ -0,0 +1,165 @@
+package v1beta1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/conversion"
+
+	"github.com/milvus-io/milvus-operator/apis/milvus.io/v1alpha1"
+)
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
+
+func newTestHubMilvusCluster() *v1alpha1.MilvusCluster {
+	now := metav1.NewTime(time.Now().Truncate(time.Second))
+	always := corev1.PullAlways
+	mc := &v1alpha1.MilvusCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "ns",
+			Name:        "mc",
+			Generation:  2,
+			Annotations: map[string]string{"a": "b"},
+		},
+	}
+	mc.Spec.Com.Image = "milvusdb/milvus:v2.0.0"
+	mc.Spec.Com.ImagePullPolicy = &always
+	mc.Spec.Com.Proxy.Replicas = int32Ptr(2)
+	mc.Spec.Com.Proxy.ServiceType = corev1.ServiceTypeNodePort
+	mc.Spec.Com.QueryNode.Conf = v1alpha1.Values{Data: map[string]interface{}{"queryNode": map[string]interface{}{"cacheSize": float64(16)}}}
+	mc.Spec.Dep.Etcd.InCluster = &v1alpha1.InClusterConfig{
+		Values:         v1alpha1.Values{Data: map[string]interface{}{"replicaCount": float64(3)}},
+		DeletionPolicy: v1alpha1.DeletionPolicyDelete,
+		Timeout:        &metav1.Duration{Duration: time.Minute},
+		Chart:          &v1alpha1.ChartSource{Repository: "https://charts.bitnami.com/bitnami", Version: "6.3.3"},
+	}
+	mc.Spec.Dep.Pulsar.External = true
+	mc.Spec.Dep.Pulsar.Endpoint = "pulsar:6650"
+	mc.Spec.Dep.Storage.SecretRef = "secret"
+	mc.Spec.Dep.MsgChannelPrefix = "ns-mc"
+	mc.Spec.Conf = v1alpha1.Values{Data: map[string]interface{}{"log": map[string]interface{}{"level": "debug"}}}
+	mc.Spec.DeletionProtection = true
+	mc.Spec.ImageRegistry = "registry.example.com"
+	mc.Status = v1alpha1.MilvusClusterStatus{
+		Status:             v1alpha1.StatusHealthy,
+		ObservedGeneration: 2,
+		Conditions: []v1alpha1.MilvusCondition{
+			{
+				Type:               v1alpha1.EtcdReady,
+				Status:             corev1.ConditionTrue,
+				LastTransitionTime: &now,
+				Reason:             v1alpha1.ReasonEtcdReady,
+				Message:            "ready",
+			},
+		},
+		Endpoint: "mc-milvus.ns:19530",
+	}
+	return mc
+}
+
+func TestMilvusCluster_Conversion(t *testing.T) {
+	hub := newTestHubMilvusCluster()
+
+	mc := &MilvusCluster{}
+	assert.NoError(t, mc.ConvertFrom(hub))
+	assert.Equal(t, hub.ObjectMeta, mc.ObjectMeta)
+	assert.Equal(t, "milvusdb/milvus:v2.0.0", mc.Spec.Components.Image)
+	assert.Equal(t, int32(2), *mc.Spec.Components.Proxy.Replicas)
+	assert.Equal(t, float64(3), mc.Spec.Dependencies.Etcd.InCluster.Values.Data["replicaCount"])
+	assert.Equal(t, "debug", mc.Spec.Config.Data["log"].(map[string]interface{})["level"])
+	assert.Equal(t, StatusHealthy, mc.Status.Status)
+	assert.Equal(t, int64(2), mc.Status.ObservedGeneration)
+	assert.Equal(t, []metav1.Condition{
+		{
+			Type:               EtcdReady,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: 2,
+			LastTransitionTime: *hub.Status.Conditions[0].LastTransitionTime,
+			Reason:             v1alpha1.ReasonEtcdReady,
+			Message:            "ready",
+		},
+	}, mc.Status.Conditions)
+
+	// round trip
+	ret := &v1alpha1.MilvusCluster{}
+	assert.NoError(t, mc.ConvertTo(ret))
+	assert.Equal(t, hub, ret)
+
+	// no transition time
+	hub.Status.Conditions[0].LastTransitionTime = nil
+	assert.NoError(t, mc.ConvertFrom(hub))
+	assert.True(t, mc.Status.Conditions[0].LastTransitionTime.IsZero())
+	assert.NoError(t, mc.ConvertTo(ret))
+	assert.Equal(t, hub, ret)
+}
+
+func TestMilvus_Conversion(t *testing.T) {
+	mc := newTestHubMilvusCluster()
+	hub := &v1alpha1.Milvus{ObjectMeta: mc.ObjectMeta}
+	hub.Spec.Mode = v1alpha1.MilvusModeCluster
+	hub.Spec.ComponentSpec = mc.Spec.Com.ComponentSpec
+	hub.Spec.Com.Proxy.Replicas = int32Ptr(2)
+	hub.Spec.ServiceType = corev1.ServiceTypeLoadBalancer
+	hub.Spec.Dep.Etcd = mc.Spec.Dep.Etcd
+	hub.Spec.Dep.Pulsar = mc.Spec.Dep.Pulsar
+	hub.Spec.Dep.Storage.External = true
+	hub.Spec.Dep.Storage.Endpoint = "minio:9000"
+	hub.Spec.Dep.DataDeletionPolicy = v1alpha1.DeletionPolicyDelete
+	hub.Spec.Conf = mc.Spec.Conf
+	hub.Status = v1alpha1.MilvusStatus{
+		Status:             mc.Status.Status,
+		ObservedGeneration: mc.Status.ObservedGeneration,
+		Conditions:         mc.Status.Conditions,
+		Endpoint:           "m-milvus.ns:19530",
+		ActiveCoordinators: map[string]string{"rootcoord": "m-milvus-rootcoord-0"},
+	}
+
+	m := &Milvus{}
+	assert.NoError(t, m.ConvertFrom(hub))
+	assert.Equal(t, MilvusModeCluster, m.Spec.Mode)
+	assert.Equal(t, int32(2), *m.Spec.Components.Proxy.Replicas)
+	assert.Equal(t, "pulsar:6650", m.Spec.Dependencies.Pulsar.Endpoint)
+	assert.Equal(t, corev1.ServiceTypeLoadBalancer, m.Spec.ServiceType)
+	assert.Equal(t, "minio:9000", m.Spec.Dependencies.Storage.Endpoint)
+	assert.Equal(t, DeletionPolicyDelete, m.Spec.Dependencies.DataDeletionPolicy)
+	assert.Equal(t, EtcdReady, m.Status.Conditions[0].Type)
+	assert.Equal(t, "m-milvus.ns:19530", m.Status.Endpoint)
+	assert.Equal(t, "m-milvus-rootcoord-0", m.Status.ActiveCoordinators["rootcoord"])
+
+	ret := &v1alpha1.Milvus{}
+	assert.NoError(t, m.ConvertTo(ret))
+	assert.Equal(t, hub, ret)
+
+	// from v1beta1
+	m.Status.Conditions = append(m.Status.Conditions, metav1.Condition{
+		Type:               MilvusReady,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: 2,
+		Reason:             v1alpha1.ReasonMilvusComponentNotHealthy,
+	})
+	assert.NoError(t, m.ConvertTo(ret))
+	assert.Len(t, ret.Status.Conditions, 2)
+	assert.Equal(t, v1alpha1.MilvusReady, ret.Status.Conditions[1].Type)
+	assert.Nil(t, ret.Status.Conditions[1].LastTransitionTime)
+	m2 := &Milvus{}
+	assert.NoError(t, m2.ConvertFrom(ret))
+	assert.Equal(t, m, m2)
+}
+
+func TestIsConvertible(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, v1alpha1.AddToScheme(scheme))
+	assert.NoError(t, AddToScheme(scheme))
+
+	for _, obj := range []runtime.Object{&Milvus{}, &MilvusCluster{}, &v1alpha1.Milvus{}, &v1alpha1.MilvusCluster{}} {
+		ok, err := conversion.IsConvertible(scheme, obj)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	}
+}
@@ -0,0 +1,208 @@
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type DependencyDeletionPolicy string
+
+const (
+	DeletionPolicyDelete DependencyDeletionPolicy = "Delete"
+	DeletionPolicyRetain DependencyDeletionPolicy = "Retain"
+)
+
+type MilvusDependencies struct {
+	// +kubebuilder:validation:Optional
+	Etcd MilvusEtcd `json:"etcd"`
+
+	// Pulsar is the message stream in cluster mode, the standalone uses its embedded one
+	// +kubebuilder:validation:Optional
+	Pulsar MilvusPulsar `json:"pulsar"`
+
+	// +kubebuilder:validation:Optional
+	Storage MilvusStorage `json:"storage"`
+
+	// Pool refers to the MilvusDependencyPool serving the dependencies not external,
+	// instead of installing them for the instance. It's immutable
+	// +kubebuilder:validation:Optional
+	Pool *DependencyPoolRef `json:"pool,omitempty"`
+
+	// MsgChannelPrefix is the prefix of the message channel names, it's immutable once set.
+	// Defaults to <namespace>-<name> for new instances
+	// +kubebuilder:validation:Optional
+	MsgChannelPrefix string `json:"msgChannelPrefix,omitempty"`
+
+	// DataDeletionPolicy decides whether the data of the instance in the external dependencies is deleted
+	// with the instance, including the etcd rootPath and the bucket
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum:={"Delete", "Retain"}
+	// +kubebuilder:default:="Retain"
+	DataDeletionPolicy DependencyDeletionPolicy `json:"dataDeletionPolicy,omitempty"`
+}
+
+type MilvusClusterDependencies struct {
+	// +kubebuilder:validation:Optional
+	Etcd MilvusEtcd `json:"etcd"`
+
+	// +kubebuilder:validation:Optional
+	Pulsar MilvusPulsar `json:"pulsar"`
+
+	// +kubebuilder:validation:Optional
+	Storage MilvusStorage `json:"storage"`
+
+	// Pool refers to the MilvusDependencyPool serving the dependencies not external,
+	// instead of installing them for the instance. It's immutable
+	// +kubebuilder:validation:Optional
+	Pool *DependencyPoolRef `json:"pool,omitempty"`
+
+	// MsgChannelPrefix is the prefix of the message channel names, it's immutable once set.
+	// Defaults to <namespace>-<name> for new instances
+	// +kubebuilder:validation:Optional
+	MsgChannelPrefix string `json:"msgChannelPrefix,omitempty"`
+
+	// DataDeletionPolicy decides whether the data of the instance in the external dependencies is deleted
+	// with the instance, including the etcd rootPath, the bucket and the message channel topics
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum:={"Delete", "Retain"}
+	// +kubebuilder:default:="Retain"
+	DataDeletionPolicy DependencyDeletionPolicy `json:"dataDeletionPolicy,omitempty"`
+}
+
+type MilvusEtcd struct {
+	// +kubebuilder:validation:Optional
+	Endpoints []string `json:"endpoints"`
+
+	// ServiceRef refers to the service of the external etcd, the endpoints are resolved from it by the operator
+	// +kubebuilder:validation:Optional
+	ServiceRef *ServiceRef `json:"serviceRef,omitempty"`
+
+	// RootPath is the root path of the milvus meta in etcd, it's immutable once set.
+	// Defaults to <namespace>-<name> for new instances
+	// +kubebuilder:validation:Optional
+	RootPath string `json:"rootPath,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:=false
+	External bool `json:"external,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	InCluster *InClusterConfig `json:"inCluster,omitempty"`
+}
+
+// ServiceRef refers to a port of a service, it's resolved to the endpoint <name>.<namespace>[.svc.<cluster domain>]:<port>
+type ServiceRef struct {
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Namespace of the service, defaults to the namespace of the instance
+	// +kubebuilder:validation:Optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Port is the name of the port of the service, the first port is used if not set
+	// +kubebuilder:validation:Optional
+	Port string `json:"port,omitempty"`
+}
+
+// DependencyPoolRef refers to the MilvusDependencyPool serving the dependencies of an instance
+type DependencyPoolRef struct {
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Namespace of the pool, defaults to the namespace of the instance
+	// +kubebuilder:validation:Optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+type InClusterConfig struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Values Values `json:"values,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum:={"Delete", "Retain"}
+	// +kubebuilder:default:="Retain"
+	DeletionPolicy DependencyDeletionPolicy `json:"deletionPolicy"`
+
+	// +kubebuilder:validation:Optional
+	PVCDeletion bool `json:"pvcDeletion,omitempty"`
+
+	// Timeout of installing or upgrading the release, it's rolled back if not ready in time. Defaults to 10m
+	// +kubebuilder:validation:Optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// Chart is where to get the chart of the release, the chart bundled in the operator is used if not set
+	// +kubebuilder:validation:Optional
+	Chart *ChartSource `json:"chart,omitempty"`
+}
+
+// ChartSource is a chart in a chart repository or an OCI registry
+type ChartSource struct {
+	// Repository is the URL of a chart repository like https://charts.bitnami.com/bitnami,
+	// or the OCI reference of the chart without tag like oci://registry-1.docker.io/bitnamicharts/etcd
+	// +kubebuilder:validation:Required
+	Repository string `json:"repository"`
+
+	// Name of the chart in the chart repository, defaults to the name of the bundled chart. Not used for OCI
+	// +kubebuilder:validation:Optional
+	Name string `json:"name,omitempty"`
+
+	// Version of the chart, the latest one in the chart repository is used if not set. Required for OCI
+	// +kubebuilder:validation:Optional
+	Version string `json:"version,omitempty"`
+
+	// PullSecrets are the secrets of type kubernetes.io/dockerconfigjson in the namespace of the release,
+	// with the credentials to pull the chart from the OCI registry
+	// +kubebuilder:validation:Optional
+	PullSecrets []corev1.LocalObjectReference `json:"pullSecrets,omitempty"`
+}
+
+type MilvusStorage struct {
+	// +kubebuilder:default:="MinIO"
+	// +kubebuilder:validation:Enum:={"MinIO", "S3"}
+	// +kubebuilder:validation:Optional
+	Type string `json:"type"`
+
+	// +kubebuilder:validation:Optional
+	SecretRef string `json:"secretRef"`
+
+	// +kubebuilder:validation:Optional
+	Endpoint string `json:"endpoint"`
+
+	// ServiceRef refers to the service of the external storage, the endpoint is resolved from it by the operator
+	// +kubebuilder:validation:Optional
+	ServiceRef *ServiceRef `json:"serviceRef,omitempty"`
+
+	// BucketName is the bucket milvus stores its data in, it's immutable once set.
+	// Defaults to <namespace>-<name> for new instances
+	// +kubebuilder:validation:Optional
+	BucketName string `json:"bucketName,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	InCluster *InClusterConfig `json:"inCluster,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:=false
+	External bool `json:"external,omitempty"`
+}
+
+type MilvusPulsar struct {
+	// +kubebuilder:validation:Optional
+	InCluster *InClusterConfig `json:"inCluster,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:=false
+	External bool `json:"external,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Endpoint string `json:"endpoint"`
+
+	// ServiceRef refers to the service of the external pulsar, the endpoint is resolved from it by the operator
+	// +kubebuilder:validation:Optional
+	ServiceRef *ServiceRef `json:"serviceRef,omitempty"`
+
+	// AdminEndpoint is the endpoint of the pulsar admin REST API, used to delete the topics of the instance.
+	// Defaults to the host of endpoint with port 8080
+	// +kubebuilder:validation:Optional
+	AdminEndpoint string `json:"adminEndpoint,omitempty"`
+}
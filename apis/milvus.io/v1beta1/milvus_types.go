@@ -0,0 +1,100 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MilvusMode is the deploy mode of Milvus
+type MilvusMode string
+
+const (
+	// MilvusModeStandalone runs all the components in a single pod
+	MilvusModeStandalone MilvusMode = "standalone"
+	// MilvusModeCluster runs each component in its own deployment with pulsar as the message stream
+	MilvusModeCluster MilvusMode = "cluster"
+)
+
+// MilvusSpec defines the desired state of Milvus
+type MilvusSpec struct {
+	// Mode is the deploy mode, a standalone instance can be migrated to the cluster mode but not the reverse
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum={"standalone", "cluster"}
+	// +kubebuilder:default="standalone"
+	Mode MilvusMode `json:"mode,omitempty"`
+
+	// ComponentSpec is the global spec of the milvus components in both modes
+	// +kubebuilder:validation:Optional
+	ComponentSpec `json:",inline"`
+
+	// Components is the spec of each component in cluster mode, the global fields are set in spec instead
+	// +kubebuilder:validation:Optional
+	Components MilvusComponents `json:"components,omitempty"`
+
+	// ServiceType is the type of the service milvus serves at, it's the proxy service in cluster mode
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Enum={"ClusterIP", "NodePort", "LoadBalancer"}
+	// +kubebuilder:default="ClusterIP"
+	ServiceType corev1.ServiceType `json:"serviceType,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Dependencies MilvusDependencies `json:"dependencies,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Config Values `json:"config,omitempty"`
+
+	// DeletionProtection rejects the deletion of the instance, and keeps its in cluster dependencies
+	// from being uninstalled regardless of their deletionPolicy
+	// +kubebuilder:validation:Optional
+	DeletionProtection bool `json:"deletionProtection,omitempty"`
+
+	// ImageRegistry replaces the registry of the milvus images and the images of the in-cluster dependencies,
+	// it overrides the registry set for the operator
+	// +kubebuilder:validation:Optional
+	ImageRegistry string `json:"imageRegistry,omitempty"`
+}
+
+// +genclient
+// +genclient:noStatus
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.status",description="Health status"
+//+kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status",description="Whether the latest spec is reconciled and ready"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+// Milvus is the Schema for the milvus API
+type Milvus struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MilvusSpec   `json:"spec,omitempty"`
+	Status MilvusStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+// MilvusList contains a list of Milvus
+type MilvusList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Milvus `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&Milvus{}, &MilvusList{})
+}
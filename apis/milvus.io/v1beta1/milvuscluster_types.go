@@ -0,0 +1,73 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MilvusClusterSpec defines the desired state of MilvusCluster
+type MilvusClusterSpec struct {
+	// +kubebuilder:validation:Optional
+	Components MilvusComponents `json:"components,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	Dependencies MilvusClusterDependencies `json:"dependencies,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Config Values `json:"config,omitempty"`
+
+	// DeletionProtection rejects the deletion of the instance, and keeps its in cluster dependencies
+	// from being uninstalled regardless of their deletionPolicy
+	// +kubebuilder:validation:Optional
+	DeletionProtection bool `json:"deletionProtection,omitempty"`
+
+	// ImageRegistry replaces the registry of the milvus images and the images of the in-cluster dependencies,
+	// it overrides the registry set for the operator
+	// +kubebuilder:validation:Optional
+	ImageRegistry string `json:"imageRegistry,omitempty"`
+}
+
+// +genclient
+// +genclient:noStatus
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:shortName=mc;mic
+//+kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.status",description="Health status"
+//+kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status",description="Whether the latest spec is reconciled and ready"
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+// MilvusCluster is the Schema for the milvusclusters API
+type MilvusCluster struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MilvusClusterSpec `json:"spec,omitempty"`
+	Status MilvusStatus      `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+// MilvusClusterList contains a list of MilvusCluster
+type MilvusClusterList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MilvusCluster `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MilvusCluster{}, &MilvusClusterList{})
+}
@@ -0,0 +1,74 @@
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MilvusHealthStatus is a type for milvus status.
+type MilvusHealthStatus string
+
+const (
+	// StatusCreating is the status of creating.
+	StatusCreating MilvusHealthStatus = "Creating"
+	// StatusHealthy is the status of healthy.
+	StatusHealthy MilvusHealthStatus = "Healthy"
+	// StatusUnHealthy is the status of unhealthy.
+	StatusUnHealthy MilvusHealthStatus = "Unhealthy"
+	// StatusDeleting is the status of deleting.
+	StatusDeleting MilvusHealthStatus = "Deleting"
+)
+
+// The types of the conditions of a milvus instance
+const (
+	// EtcdReady means the Etcd is ready.
+	EtcdReady = "EtcdReady"
+	// StorageReady means the Storage is ready.
+	StorageReady = "StorageReady"
+	// PulsarReady means the Pulsar is ready.
+	PulsarReady = "PulsarReady"
+	// MilvusReady means all components of Milvus are ready.
+	MilvusReady = "MilvusReady"
+	// EtcdReleaseReady means the release of the in cluster etcd is deployed.
+	EtcdReleaseReady = "EtcdReleaseReady"
+	// StorageReleaseReady means the release of the in cluster storage is deployed.
+	StorageReleaseReady = "StorageReleaseReady"
+	// PulsarReleaseReady means the release of the in cluster pulsar is deployed.
+	PulsarReleaseReady = "PulsarReleaseReady"
+	// DataDeleted means the data in the external dependencies is deleted.
+	DataDeleted = "DataDeleted"
+	// Adopted means the resources of the milvus helm release are adopted.
+	Adopted = "Adopted"
+	// Ready means the latest spec is reconciled and all components of Milvus are ready, following the kstatus conventions.
+	Ready = "Ready"
+	// Reconciling means the operator is bringing the instance to the latest spec.
+	Reconciling = "Reconciling"
+	// Stalled means the last reconcile of the instance failed.
+	Stalled = "Stalled"
+)
+
+// MilvusStatus defines the observed state of Milvus and MilvusCluster
+type MilvusStatus struct {
+	// Status indicates the overall status of the Milvus
+	// Status can be "Creating", "Healthy", "Unhealthy" and "Deleting"
+	// +kubebuilder:default:="Creating"
+	Status MilvusHealthStatus `json:"status"`
+
+	// ObservedGeneration is the generation of the spec the status is updated for
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions of the dependencies and the components
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+
+	// Endpoint of milvus
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// ActiveCoordinators are the pods of the active coordinators in active-standby mode, keyed by the component
+	// +optional
+	ActiveCoordinators map[string]string `json:"activeCoordinators,omitempty"`
+}
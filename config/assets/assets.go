@@ -0,0 +1,12 @@
+// Package assets embeds the config templates and the dependency charts into the operator binary
+package assets
+
+import "embed"
+
+// FS contains the templates and the charts. The files beginning with '_' or '.' are
+// excluded from a directory by go:embed, so they're listed explicitly
+//
+//go:embed templates charts
+//go:embed charts/*/.helmignore charts/*/templates/_*.tpl
+//go:embed charts/*/charts/*/.helmignore charts/*/charts/*/templates/_*.tpl charts/*/charts/*/templates/validations/_*.tpl
+var FS embed.FS
@@ -19,10 +19,14 @@ package main
 import (
 	"flag"
 	"os"
+	"strings"
+	"time"
 
+	"k8s.io/apimachinery/pkg/labels"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	"github.com/milvus-io/milvus-operator/pkg/config"
@@ -39,12 +43,50 @@ func main() {
 	var enableLeaderElection bool
 	var probeAddr string
 	var workDir string
+	var imageRegistry string
+	var imagePullSecrets string
+	var clusterDomain string
+	var leaderElectionID string
+	var namespaces string
+	var instanceSelector string
+	var maxConcurrentReconciles int
+	var reconcileBaseDelay time.Duration
+	var reconcileMaxDelay time.Duration
+	var reconcileQPS float64
+	var reconcileBurst int
+	var enableWebhooks bool
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
-	flag.StringVar(&workDir, "work-dir", "", "The work directory where the config assets locate")
+	flag.StringVar(&workDir, "work-dir", "",
+		"The optional work directory whose config/assets overrides the embedded templates and charts, reloaded when changed")
+	flag.StringVar(&imageRegistry, "image-registry", "",
+		"The registry replacing the one of all the milvus and dependency images, e.g. a mirror in air-gapped environment")
+	flag.StringVar(&imagePullSecrets, "image-pull-secrets", "",
+		"The comma separated secrets to pull all the milvus and dependency images, in the namespace of each instance")
+	flag.StringVar(&clusterDomain, "cluster-domain", "",
+		"The cluster DNS domain like cluster.local, the service endpoints of the dependencies are <name>.<namespace>.svc.<domain> if set, otherwise <name>.<namespace>")
+	flag.StringVar(&leaderElectionID, "leader-election-id", manager.DefaultLeaderElectionID,
+		"The leader election ID, it must be different for the operators sharding the instances by --instance-selector in the same namespace")
+	flag.StringVar(&namespaces, "namespaces", "",
+		"The comma separated namespaces watched by the operator, all the namespaces are watched if empty")
+	flag.StringVar(&instanceSelector, "instance-selector", "",
+		"The label selector of the Milvus and MilvusCluster instances handled by the operator, e.g. milvus.io/operator-shard=a. All are handled if empty")
+	flag.IntVar(&maxConcurrentReconciles, "max-concurrent-reconciles", 1,
+		"The max number of the instances of each kind reconciled concurrently")
+	flag.DurationVar(&reconcileBaseDelay, "reconcile-base-delay", 5*time.Millisecond,
+		"The base delay of retrying a failed reconcile, doubled on each failure")
+	flag.DurationVar(&reconcileMaxDelay, "reconcile-max-delay", 1000*time.Second,
+		"The max delay of retrying a failed reconcile")
+	flag.Float64Var(&reconcileQPS, "reconcile-qps", 10,
+		"The overall rate of the reconcile requests of each kind")
+	flag.IntVar(&reconcileBurst, "reconcile-burst", 100,
+		"The overall burst of the reconcile requests of each kind")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", true,
+		"Enable the webhooks including the conversion webhook, they should be enabled in only one of the operators sharing a cluster, "+
+			"and its pods must back the webhook service the CRDs refer to")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -56,16 +98,37 @@ func main() {
 		setupLog.Error(err, "unable to init config")
 		os.Exit(1)
 	}
+	config.SetImageSettings(imageRegistry, splitList(imagePullSecrets))
+	config.SetClusterDomain(clusterDomain)
 
-	mgr, err := manager.NewManager(metricsAddr, probeAddr, enableLeaderElection)
+	selector, err := labels.Parse(instanceSelector)
+	if err != nil {
+		setupLog.Error(err, "invalid instance selector")
+		os.Exit(1)
+	}
+	mgrOptions := manager.Options{
+		LeaderElectionID: leaderElectionID,
+		Namespaces:       splitList(namespaces),
+	}
+	if !selector.Empty() {
+		mgrOptions.InstanceSelector = selector
+	}
+	mgr, err := manager.NewManager(metricsAddr, probeAddr, enableLeaderElection, mgrOptions)
 	if err != nil {
 		setupLog.Error(err, "new manager")
 		os.Exit(1)
 	}
 
 	ctx := ctrl.SetupSignalHandler()
+	if workDir != "" {
+		go config.Watch(ctx, config.AssetsWatchInterval)
+	}
 
-	if err := controllers.SetupControllers(ctx, mgr, true); err != nil {
+	controllerOptions := controller.Options{
+		MaxConcurrentReconciles: maxConcurrentReconciles,
+		RateLimiter:             controllers.NewRateLimiter(reconcileBaseDelay, reconcileMaxDelay, reconcileQPS, reconcileBurst),
+	}
+	if err := controllers.SetupControllers(ctx, mgr, enableWebhooks, controllerOptions); err != nil {
 		setupLog.Error(err, "unable to setup controller with manager")
 		os.Exit(1)
 	}
@@ -76,3 +139,14 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// splitList splits the comma separated list, the empty items are dropped
+func splitList(list string) []string {
+	ret := []string{}
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			ret = append(ret, item)
+		}
+	}
+	return ret
+}
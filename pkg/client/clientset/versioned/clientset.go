@@ -21,6 +21,7 @@ import (
 	"fmt"
 
 	milvusv1alpha1 "github.com/milvus-io/milvus-operator/pkg/client/clientset/versioned/typed/milvus.io/v1alpha1"
+	milvusv1beta1 "github.com/milvus-io/milvus-operator/pkg/client/clientset/versioned/typed/milvus.io/v1beta1"
 	discovery "k8s.io/client-go/discovery"
 	rest "k8s.io/client-go/rest"
 	flowcontrol "k8s.io/client-go/util/flowcontrol"
@@ -29,6 +30,7 @@ import (
 type Interface interface {
 	Discovery() discovery.DiscoveryInterface
 	MilvusV1alpha1() milvusv1alpha1.MilvusV1alpha1Interface
+	MilvusV1beta1() milvusv1beta1.MilvusV1beta1Interface
 }
 
 // Clientset contains the clients for groups. Each group has exactly one
@@ -36,6 +38,7 @@ type Interface interface {
 type Clientset struct {
 	*discovery.DiscoveryClient
 	milvusV1alpha1 *milvusv1alpha1.MilvusV1alpha1Client
+	milvusV1beta1  *milvusv1beta1.MilvusV1beta1Client
 }
 
 // MilvusV1alpha1 retrieves the MilvusV1alpha1Client
@@ -43,6 +46,11 @@ func (c *Clientset) MilvusV1alpha1() milvusv1alpha1.MilvusV1alpha1Interface {
 	return c.milvusV1alpha1
 }
 
+// MilvusV1beta1 retrieves the MilvusV1beta1Client
+func (c *Clientset) MilvusV1beta1() milvusv1beta1.MilvusV1beta1Interface {
+	return c.milvusV1beta1
+}
+
 // Discovery retrieves the DiscoveryClient
 func (c *Clientset) Discovery() discovery.DiscoveryInterface {
 	if c == nil {
@@ -68,6 +76,10 @@ func NewForConfig(c *rest.Config) (*Clientset, error) {
 	if err != nil {
 		return nil, err
 	}
+	cs.milvusV1beta1, err = milvusv1beta1.NewForConfig(&configShallowCopy)
+	if err != nil {
+		return nil, err
+	}
 
 	cs.DiscoveryClient, err = discovery.NewDiscoveryClientForConfig(&configShallowCopy)
 	if err != nil {
@@ -81,6 +93,7 @@ func NewForConfig(c *rest.Config) (*Clientset, error) {
 func NewForConfigOrDie(c *rest.Config) *Clientset {
 	var cs Clientset
 	cs.milvusV1alpha1 = milvusv1alpha1.NewForConfigOrDie(c)
+	cs.milvusV1beta1 = milvusv1beta1.NewForConfigOrDie(c)
 
 	cs.DiscoveryClient = discovery.NewDiscoveryClientForConfigOrDie(c)
 	return &cs
@@ -90,6 +103,7 @@ func NewForConfigOrDie(c *rest.Config) *Clientset {
 func New(c rest.Interface) *Clientset {
 	var cs Clientset
 	cs.milvusV1alpha1 = milvusv1alpha1.New(c)
+	cs.milvusV1beta1 = milvusv1beta1.New(c)
 
 	cs.DiscoveryClient = discovery.NewDiscoveryClient(c)
 	return &cs
@@ -35,6 +35,10 @@ func (c *FakeMilvusV1alpha1) MilvusClusters(namespace string) v1alpha1.MilvusClu
 	return &FakeMilvusClusters{c, namespace}
 }
 
+func (c *FakeMilvusV1alpha1) MilvusDependencyPools(namespace string) v1alpha1.MilvusDependencyPoolInterface {
+	return &FakeMilvusDependencyPools{c, namespace}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeMilvusV1alpha1) RESTClient() rest.Interface {
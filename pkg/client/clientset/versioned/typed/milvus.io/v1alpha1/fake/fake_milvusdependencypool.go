@@ -0,0 +1,129 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	v1alpha1 "github.com/milvus-io/milvus-operator/apis/milvus.io/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeMilvusDependencyPools implements MilvusDependencyPoolInterface
+type FakeMilvusDependencyPools struct {
+	Fake *FakeMilvusV1alpha1
+	ns   string
+}
+
+var milvusdependencypoolsResource = schema.GroupVersionResource{Group: "milvus.io", Version: "v1alpha1", Resource: "milvusdependencypools"}
+
+var milvusdependencypoolsKind = schema.GroupVersionKind{Group: "milvus.io", Version: "v1alpha1", Kind: "MilvusDependencyPool"}
+
+// Get takes name of the milvusDependencyPool, and returns the corresponding milvusDependencyPool object, and an error if there is any.
+func (c *FakeMilvusDependencyPools) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.MilvusDependencyPool, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(milvusdependencypoolsResource, c.ns, name), &v1alpha1.MilvusDependencyPool{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.MilvusDependencyPool), err
+}
+
+// List takes label and field selectors, and returns the list of MilvusDependencyPools that match those selectors.
+func (c *FakeMilvusDependencyPools) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.MilvusDependencyPoolList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(milvusdependencypoolsResource, milvusdependencypoolsKind, c.ns, opts), &v1alpha1.MilvusDependencyPoolList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.MilvusDependencyPoolList{ListMeta: obj.(*v1alpha1.MilvusDependencyPoolList).ListMeta}
+	for _, item := range obj.(*v1alpha1.MilvusDependencyPoolList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested milvusDependencyPools.
+func (c *FakeMilvusDependencyPools) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(milvusdependencypoolsResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a milvusDependencyPool and creates it.  Returns the server's representation of the milvusDependencyPool, and an error, if there is any.
+func (c *FakeMilvusDependencyPools) Create(ctx context.Context, milvusDependencyPool *v1alpha1.MilvusDependencyPool, opts v1.CreateOptions) (result *v1alpha1.MilvusDependencyPool, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(milvusdependencypoolsResource, c.ns, milvusDependencyPool), &v1alpha1.MilvusDependencyPool{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.MilvusDependencyPool), err
+}
+
+// Update takes the representation of a milvusDependencyPool and updates it. Returns the server's representation of the milvusDependencyPool, and an error, if there is any.
+func (c *FakeMilvusDependencyPools) Update(ctx context.Context, milvusDependencyPool *v1alpha1.MilvusDependencyPool, opts v1.UpdateOptions) (result *v1alpha1.MilvusDependencyPool, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(milvusdependencypoolsResource, c.ns, milvusDependencyPool), &v1alpha1.MilvusDependencyPool{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.MilvusDependencyPool), err
+}
+
+// Delete takes name of the milvusDependencyPool and deletes it. Returns an error if one occurs.
+func (c *FakeMilvusDependencyPools) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(milvusdependencypoolsResource, c.ns, name), &v1alpha1.MilvusDependencyPool{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeMilvusDependencyPools) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(milvusdependencypoolsResource, c.ns, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.MilvusDependencyPoolList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched milvusDependencyPool.
+func (c *FakeMilvusDependencyPools) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.MilvusDependencyPool, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(milvusdependencypoolsResource, c.ns, name, pt, data, subresources...), &v1alpha1.MilvusDependencyPool{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.MilvusDependencyPool), err
+}
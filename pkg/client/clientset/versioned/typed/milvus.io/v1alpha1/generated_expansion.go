@@ -20,3 +20,5 @@ package v1alpha1
 type MilvusExpansion interface{}
 
 type MilvusClusterExpansion interface{}
+
+type MilvusDependencyPoolExpansion interface{}
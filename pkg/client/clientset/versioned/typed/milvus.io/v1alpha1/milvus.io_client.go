@@ -27,6 +27,7 @@ type MilvusV1alpha1Interface interface {
 	RESTClient() rest.Interface
 	MilvusesGetter
 	MilvusClustersGetter
+	MilvusDependencyPoolsGetter
 }
 
 // MilvusV1alpha1Client is used to interact with features provided by the milvus.io group.
@@ -42,6 +43,10 @@ func (c *MilvusV1alpha1Client) MilvusClusters(namespace string) MilvusClusterInt
 	return newMilvusClusters(c, namespace)
 }
 
+func (c *MilvusV1alpha1Client) MilvusDependencyPools(namespace string) MilvusDependencyPoolInterface {
+	return newMilvusDependencyPools(c, namespace)
+}
+
 // NewForConfig creates a new MilvusV1alpha1Client for the given config.
 func NewForConfig(c *rest.Config) (*MilvusV1alpha1Client, error) {
 	config := *c
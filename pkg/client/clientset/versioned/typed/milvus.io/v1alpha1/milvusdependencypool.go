@@ -0,0 +1,177 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	"context"
+	"time"
+
+	v1alpha1 "github.com/milvus-io/milvus-operator/apis/milvus.io/v1alpha1"
+	scheme "github.com/milvus-io/milvus-operator/pkg/client/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// MilvusDependencyPoolsGetter has a method to return a MilvusDependencyPoolInterface.
+// A group's client should implement this interface.
+type MilvusDependencyPoolsGetter interface {
+	MilvusDependencyPools(namespace string) MilvusDependencyPoolInterface
+}
+
+// MilvusDependencyPoolInterface has methods to work with MilvusDependencyPool resources.
+type MilvusDependencyPoolInterface interface {
+	Create(ctx context.Context, milvusDependencyPool *v1alpha1.MilvusDependencyPool, opts v1.CreateOptions) (*v1alpha1.MilvusDependencyPool, error)
+	Update(ctx context.Context, milvusDependencyPool *v1alpha1.MilvusDependencyPool, opts v1.UpdateOptions) (*v1alpha1.MilvusDependencyPool, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1alpha1.MilvusDependencyPool, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1alpha1.MilvusDependencyPoolList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.MilvusDependencyPool, err error)
+	MilvusDependencyPoolExpansion
+}
+
+// milvusDependencyPools implements MilvusDependencyPoolInterface
+type milvusDependencyPools struct {
+	client rest.Interface
+	ns     string
+}
+
+// newMilvusDependencyPools returns a MilvusDependencyPools
+func newMilvusDependencyPools(c *MilvusV1alpha1Client, namespace string) *milvusDependencyPools {
+	return &milvusDependencyPools{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the milvusDependencyPool, and returns the corresponding milvusDependencyPool object, and an error if there is any.
+func (c *milvusDependencyPools) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha1.MilvusDependencyPool, err error) {
+	result = &v1alpha1.MilvusDependencyPool{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("milvusdependencypools").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of MilvusDependencyPools that match those selectors.
+func (c *milvusDependencyPools) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha1.MilvusDependencyPoolList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1alpha1.MilvusDependencyPoolList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("milvusdependencypools").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested milvusDependencyPools.
+func (c *milvusDependencyPools) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("milvusdependencypools").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch(ctx)
+}
+
+// Create takes the representation of a milvusDependencyPool and creates it.  Returns the server's representation of the milvusDependencyPool, and an error, if there is any.
+func (c *milvusDependencyPools) Create(ctx context.Context, milvusDependencyPool *v1alpha1.MilvusDependencyPool, opts v1.CreateOptions) (result *v1alpha1.MilvusDependencyPool, err error) {
+	result = &v1alpha1.MilvusDependencyPool{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("milvusdependencypools").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(milvusDependencyPool).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Update takes the representation of a milvusDependencyPool and updates it. Returns the server's representation of the milvusDependencyPool, and an error, if there is any.
+func (c *milvusDependencyPools) Update(ctx context.Context, milvusDependencyPool *v1alpha1.MilvusDependencyPool, opts v1.UpdateOptions) (result *v1alpha1.MilvusDependencyPool, err error) {
+	result = &v1alpha1.MilvusDependencyPool{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("milvusdependencypools").
+		Name(milvusDependencyPool.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(milvusDependencyPool).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Delete takes name of the milvusDependencyPool and deletes it. Returns an error if one occurs.
+func (c *milvusDependencyPools) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("milvusdependencypools").
+		Name(name).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *milvusDependencyPools) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	var timeout time.Duration
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("milvusdependencypools").
+		VersionedParams(&listOpts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// Patch applies the patch and returns the patched milvusDependencyPool.
+func (c *milvusDependencyPools) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha1.MilvusDependencyPool, err error) {
+	result = &v1alpha1.MilvusDependencyPool{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("milvusdependencypools").
+		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
@@ -27,6 +27,8 @@ type Interface interface {
 	Milvuses() MilvusInformer
 	// MilvusClusters returns a MilvusClusterInformer.
 	MilvusClusters() MilvusClusterInformer
+	// MilvusDependencyPools returns a MilvusDependencyPoolInformer.
+	MilvusDependencyPools() MilvusDependencyPoolInformer
 }
 
 type version struct {
@@ -49,3 +51,8 @@ func (v *version) Milvuses() MilvusInformer {
 func (v *version) MilvusClusters() MilvusClusterInformer {
 	return &milvusClusterInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
+
+// MilvusDependencyPools returns a MilvusDependencyPoolInformer.
+func (v *version) MilvusDependencyPools() MilvusDependencyPoolInformer {
+	return &milvusDependencyPoolInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
@@ -32,3 +32,11 @@ type MilvusClusterListerExpansion interface{}
 // MilvusClusterNamespaceListerExpansion allows custom methods to be added to
 // MilvusClusterNamespaceLister.
 type MilvusClusterNamespaceListerExpansion interface{}
+
+// MilvusDependencyPoolListerExpansion allows custom methods to be added to
+// MilvusDependencyPoolLister.
+type MilvusDependencyPoolListerExpansion interface{}
+
+// MilvusDependencyPoolNamespaceListerExpansion allows custom methods to be added to
+// MilvusDependencyPoolNamespaceLister.
+type MilvusDependencyPoolNamespaceListerExpansion interface{}
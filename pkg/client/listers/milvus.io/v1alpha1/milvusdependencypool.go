@@ -0,0 +1,98 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/milvus-io/milvus-operator/apis/milvus.io/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// MilvusDependencyPoolLister helps list MilvusDependencyPools.
+// All objects returned here must be treated as read-only.
+type MilvusDependencyPoolLister interface {
+	// List lists all MilvusDependencyPools in the indexer.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1alpha1.MilvusDependencyPool, err error)
+	// MilvusDependencyPools returns an object that can list and get MilvusDependencyPools.
+	MilvusDependencyPools(namespace string) MilvusDependencyPoolNamespaceLister
+	MilvusDependencyPoolListerExpansion
+}
+
+// milvusDependencyPoolLister implements the MilvusDependencyPoolLister interface.
+type milvusDependencyPoolLister struct {
+	indexer cache.Indexer
+}
+
+// NewMilvusDependencyPoolLister returns a new MilvusDependencyPoolLister.
+func NewMilvusDependencyPoolLister(indexer cache.Indexer) MilvusDependencyPoolLister {
+	return &milvusDependencyPoolLister{indexer: indexer}
+}
+
+// List lists all MilvusDependencyPools in the indexer.
+func (s *milvusDependencyPoolLister) List(selector labels.Selector) (ret []*v1alpha1.MilvusDependencyPool, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.MilvusDependencyPool))
+	})
+	return ret, err
+}
+
+// MilvusDependencyPools returns an object that can list and get MilvusDependencyPools.
+func (s *milvusDependencyPoolLister) MilvusDependencyPools(namespace string) MilvusDependencyPoolNamespaceLister {
+	return milvusDependencyPoolNamespaceLister{indexer: s.indexer, namespace: namespace}
+}
+
+// MilvusDependencyPoolNamespaceLister helps list and get MilvusDependencyPools.
+// All objects returned here must be treated as read-only.
+type MilvusDependencyPoolNamespaceLister interface {
+	// List lists all MilvusDependencyPools in the indexer for a given namespace.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1alpha1.MilvusDependencyPool, err error)
+	// Get retrieves the MilvusDependencyPool from the indexer for a given namespace and name.
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*v1alpha1.MilvusDependencyPool, error)
+	MilvusDependencyPoolNamespaceListerExpansion
+}
+
+// milvusDependencyPoolNamespaceLister implements the MilvusDependencyPoolNamespaceLister
+// interface.
+type milvusDependencyPoolNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+// List lists all MilvusDependencyPools in the indexer for a given namespace.
+func (s milvusDependencyPoolNamespaceLister) List(selector labels.Selector) (ret []*v1alpha1.MilvusDependencyPool, err error) {
+	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.MilvusDependencyPool))
+	})
+	return ret, err
+}
+
+// Get retrieves the MilvusDependencyPool from the indexer for a given namespace and name.
+func (s milvusDependencyPoolNamespaceLister) Get(name string) (*v1alpha1.MilvusDependencyPool, error) {
+	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1alpha1.Resource("milvusdependencypool"), name)
+	}
+	return obj.(*v1alpha1.MilvusDependencyPool), nil
+}
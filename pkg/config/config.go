@@ -1,12 +1,29 @@
 package config
 
 import (
-	"io/ioutil"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
 	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/pkg/errors"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
 	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/milvus-io/milvus-operator/config/assets"
 )
 
+var logger = ctrl.Log.WithName("config")
+
 const (
 	DefaultMilvusVersion   = "v2.0.0-rc8-20211104-d1f4106"
 	DefaultMilvusBaseImage = "milvusdb/milvus"
@@ -17,15 +34,30 @@ const (
 )
 
 const (
-	TemplateRelativeDir = "config/assets/templates"
-	ChartDir            = "config/assets/charts"
-	ProviderName        = "milvus-operator"
+	// AssetsRelativeDir is the dir of the assets overriding the embedded ones, relative to the work dir
+	AssetsRelativeDir = "config/assets"
+	TemplateDir       = "templates"
+	ChartDir          = "charts"
+	ProviderName      = "milvus-operator"
+
+	// AssetsWatchInterval is the interval to check the changes of the assets in the work dir
+	AssetsWatchInterval = 10 * time.Second
 )
 
 var (
-	defaultConfig *Config
+	// defaultConfig uses the embedded assets until Init
+	defaultConfig = mustNewConfig("")
+
+	// imageRegistry replaces the registry of all the images, e.g. with a mirror in air-gapped environment
+	imageRegistry string
+	// imagePullSecrets are the secrets to pull all the images
+	imagePullSecrets []string
+	// clusterDomain qualifies the DNS names of the services like <name>.<namespace>.svc.<clusterDomain>,
+	// the names are <name>.<namespace> resolved by the search domains if it's empty
+	clusterDomain string
 )
 
+// Init inits the config with the assets in the work dir overriding the embedded ones, no override if workDir is empty
 func Init(workDir string) error {
 	c, err := NewConfig(workDir)
 	if err != nil {
@@ -39,6 +71,61 @@ func Init(workDir string) error {
 	return nil
 }
 
+// Watch reloads the assets in the work dir when they're changed, until the ctx done
+func Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := defaultConfig.Reload()
+			if err != nil {
+				logger.Error(err, "reload assets failed")
+			} else if reloaded {
+				logger.Info("assets reloaded", "dir", defaultConfig.assetsDir)
+			}
+		}
+	}
+}
+
+// LoadChart loads the bundled chart by its name
+func LoadChart(name string) (*chart.Chart, error) {
+	return defaultConfig.LoadChart(name)
+}
+
+// SetImageSettings sets the registry and the pull secrets of all the images
+func SetImageSettings(registry string, pullSecrets []string) {
+	imageRegistry = strings.TrimSuffix(registry, "/")
+	imagePullSecrets = []string{}
+	for _, secret := range pullSecrets {
+		if secret = strings.TrimSpace(secret); secret != "" {
+			imagePullSecrets = append(imagePullSecrets, secret)
+		}
+	}
+}
+
+// GetImageRegistry returns the registry of all the images, empty if not replaced
+func GetImageRegistry() string {
+	return imageRegistry
+}
+
+// GetImagePullSecrets returns the secrets to pull all the images
+func GetImagePullSecrets() []string {
+	return imagePullSecrets
+}
+
+// SetClusterDomain sets the cluster DNS domain of the service names, e.g. cluster.local
+func SetClusterDomain(domain string) {
+	clusterDomain = strings.Trim(strings.TrimSpace(domain), ".")
+}
+
+// GetClusterDomain returns the cluster DNS domain of the service names, empty if not set
+func GetClusterDomain() string {
+	return clusterDomain
+}
+
 func IsDebug() bool {
 	return defaultConfig.debugMode
 }
@@ -53,32 +140,148 @@ func GetMilvusClusterConfigTemplate() string {
 
 type Config struct {
 	debugMode bool
+	// assetsDir contains the assets overriding the embedded ones, no override if empty
+	assetsDir string
+
+	mu        sync.RWMutex
 	templates map[string]string
+	// signature of the files in assetsDir when loaded, to find out the changes
+	signature string
 }
 
 func NewConfig(workDir string) (*Config, error) {
-	config := &Config{
-		templates: make(map[string]string),
+	config := &Config{}
+
+	if workDir != "" {
+		config.assetsDir = filepath.Join(workDir, AssetsRelativeDir)
+		if _, err := os.Stat(config.assetsDir); err != nil {
+			return nil, err
+		}
 	}
 
-	templateDir := workDir + TemplateRelativeDir
+	if _, err := config.Reload(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func mustNewConfig(workDir string) *Config {
+	config, err := NewConfig(workDir)
+	if err != nil {
+		panic(err)
+	}
+	return config
+}
 
-	tmpls, err := ioutil.ReadDir(templateDir)
+// Reload loads the templates again if the assets dir changed, returns whether they're reloaded
+func (c *Config) Reload() (bool, error) {
+	signature, err := c.assetsSignature()
+	if err != nil {
+		return false, err
+	}
+	c.mu.RLock()
+	loaded := c.templates != nil && signature == c.signature
+	c.mu.RUnlock()
+	if loaded {
+		return false, nil
+	}
+
+	templates, err := readTemplates(assets.FS, TemplateDir)
+	if err != nil {
+		return false, err
+	}
+	if c.assetsDir != "" {
+		overrides, err := readTemplates(os.DirFS(c.assetsDir), TemplateDir)
+		if err != nil && !os.IsNotExist(err) {
+			return false, err
+		}
+		for name, tmpl := range overrides {
+			templates[name] = tmpl
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.templates = templates
+	c.signature = signature
+	return true, nil
+}
+
+// assetsSignature returns the signature of the files in assets dir by their paths, sizes and modification times
+func (c *Config) assetsSignature() (string, error) {
+	if c.assetsDir == "" {
+		return "", nil
+	}
+	h := sha256.New()
+	err := filepath.Walk(c.assetsDir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(h, "%s:%d:%d\n", filePath, info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func readTemplates(fsys fs.FS, dir string) (map[string]string, error) {
+	templates := make(map[string]string)
+	entries, err := fs.ReadDir(fsys, dir)
 	if err != nil {
 		return nil, err
 	}
-	for _, tmpl := range tmpls {
-		data, err := ioutil.ReadFile(templateDir + "/" + tmpl.Name())
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
 		if err != nil {
 			return nil, err
 		}
-
-		config.templates[tmpl.Name()] = string(data)
+		templates[entry.Name()] = string(data)
 	}
-
-	return config, nil
+	return templates, nil
 }
 
-func (c Config) GetTemplate(name string) string {
+func (c *Config) GetTemplate(name string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.templates[name]
 }
+
+// LoadChart loads the bundled chart by its name, the one in the assets dir overrides the embedded one.
+// It's loaded every time because helm may modify the chart loaded
+func (c *Config) LoadChart(name string) (*chart.Chart, error) {
+	if c.assetsDir != "" {
+		dir := filepath.Join(c.assetsDir, ChartDir, name)
+		if _, err := os.Stat(dir); err == nil {
+			return loader.Load(dir)
+		}
+	}
+
+	root := path.Join(ChartDir, name)
+	files := []*loader.BufferedFile{}
+	err := fs.WalkDir(assets.FS, root, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		data, err := fs.ReadFile(assets.FS, filePath)
+		if err != nil {
+			return err
+		}
+		files = append(files, &loader.BufferedFile{
+			Name: strings.TrimPrefix(filePath, root+"/"),
+			Data: data,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "load chart %s", name)
+	}
+	return loader.LoadFiles(files)
+}
@@ -1,7 +1,10 @@
 package config
 
 import (
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/milvus-io/milvus-operator/pkg/util"
@@ -49,3 +52,95 @@ func TestGetMilvusConfigTemplate(t *testing.T) {
 	assert.Equal(t, "value2", GetMilvusClusterConfigTemplate())
 	assert.Equal(t, "value", GetMilvusConfigTemplate())
 }
+
+func TestSetImageSettings(t *testing.T) {
+	defer SetImageSettings("", nil)
+
+	SetImageSettings("registry.local:5000/", []string{"a", " b", ""})
+	assert.Equal(t, "registry.local:5000", GetImageRegistry())
+	assert.Equal(t, []string{"a", "b"}, GetImagePullSecrets())
+
+	SetImageSettings("", strings.Split("", ","))
+	assert.Equal(t, "", GetImageRegistry())
+	assert.Empty(t, GetImagePullSecrets())
+}
+
+func TestSetClusterDomain(t *testing.T) {
+	defer SetClusterDomain("")
+
+	SetClusterDomain(" cluster.local. ")
+	assert.Equal(t, "cluster.local", GetClusterDomain())
+
+	SetClusterDomain("")
+	assert.Equal(t, "", GetClusterDomain())
+}
+
+func TestInit_Embedded(t *testing.T) {
+	err := Init("")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, GetMilvusConfigTemplate())
+	assert.NotEmpty(t, GetMilvusClusterConfigTemplate())
+}
+
+func TestConfig_Reload(t *testing.T) {
+	workDir := t.TempDir()
+	templateDir := filepath.Join(workDir, AssetsRelativeDir, TemplateDir)
+	assert.NoError(t, os.MkdirAll(templateDir, 0755))
+
+	c, err := NewConfig(workDir)
+	assert.NoError(t, err)
+	embedded := c.GetTemplate(MilvusConfigTpl)
+	assert.NotEmpty(t, embedded)
+
+	// not changed
+	reloaded, err := c.Reload()
+	assert.NoError(t, err)
+	assert.False(t, reloaded)
+
+	// overridden
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(templateDir, MilvusConfigTpl), []byte("override"), 0644))
+	reloaded, err = c.Reload()
+	assert.NoError(t, err)
+	assert.True(t, reloaded)
+	assert.Equal(t, "override", c.GetTemplate(MilvusConfigTpl))
+	assert.NotEmpty(t, c.GetTemplate(MilvusClusterConfigTpl))
+
+	// override removed
+	assert.NoError(t, os.Remove(filepath.Join(templateDir, MilvusConfigTpl)))
+	reloaded, err = c.Reload()
+	assert.NoError(t, err)
+	assert.True(t, reloaded)
+	assert.Equal(t, embedded, c.GetTemplate(MilvusConfigTpl))
+}
+
+func TestConfig_LoadChart(t *testing.T) {
+	c, err := NewConfig("")
+	assert.NoError(t, err)
+
+	ch, err := c.LoadChart("etcd")
+	assert.NoError(t, err)
+	assert.Equal(t, "etcd", ch.Name())
+	assert.Len(t, ch.Dependencies(), 1)
+	found := false
+	for _, tmpl := range ch.Templates {
+		if tmpl.Name == "templates/_helpers.tpl" {
+			found = true
+		}
+	}
+	assert.True(t, found)
+
+	_, err = c.LoadChart("not-exist")
+	assert.Error(t, err)
+
+	// overridden
+	workDir := t.TempDir()
+	chartDir := filepath.Join(workDir, AssetsRelativeDir, ChartDir, "etcd")
+	assert.NoError(t, os.MkdirAll(chartDir, 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(chartDir, "Chart.yaml"),
+		[]byte("apiVersion: v2\nname: etcd\nversion: 0.0.1\n"), 0644))
+	c, err = NewConfig(workDir)
+	assert.NoError(t, err)
+	ch, err = c.LoadChart("etcd")
+	assert.NoError(t, err)
+	assert.Equal(t, "0.0.1", ch.Metadata.Version)
+}
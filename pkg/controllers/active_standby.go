@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"path"
+	"time"
+
+	"github.com/pkg/errors"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/milvus-io/milvus-operator/apis/milvus.io/v1alpha1"
+)
+
+// coordSession is the part of the session a coordinator registers in etcd the operator reads
+type coordSession struct {
+	Address string `json:"Address"`
+}
+
+// GetCoordSessionKey returns the etcd key of the session of the active coordinator,
+// the standbys watch it and take over once it expires
+func GetCoordSessionKey(mil v1alpha1.Milvus, coord MilvusComponent) string {
+	return path.Join(stringDefault(mil.Spec.Dep.Etcd.RootPath, mil.Name), "meta", "session", coord.Name)
+}
+
+// GetActiveCoordinators returns the pods of the active coordinators running in active-standby mode,
+// keyed by the component name. The coordinators having no active session are omitted
+func GetActiveCoordinators(ctx context.Context, cli client.Client, mil v1alpha1.Milvus) (map[string]string, error) {
+	coords := []MilvusComponent{}
+	for _, coord := range MilvusCoords {
+		if coord.IsActiveStandby(mil.Spec) {
+			coords = append(coords, coord)
+		}
+	}
+	if len(coords) == 0 {
+		return nil, nil
+	}
+
+	endpoints, err := GetEtcdEndpoints(mil.Spec.Dep.Etcd)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse etcd endpoints")
+	}
+	etcd, err := etcdNewClient(clientv3.Config{
+		Endpoints:   endpoints,
+		DialTimeout: 5 * time.Second,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "new etcd client")
+	}
+	defer etcd.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	ret := map[string]string{}
+	for _, coord := range coords {
+		resp, err := etcd.Get(ctx, GetCoordSessionKey(mil, coord))
+		if err != nil {
+			return nil, errors.Wrapf(err, "get %s session", coord.Name)
+		}
+		if len(resp.Kvs) == 0 {
+			continue
+		}
+		session := coordSession{}
+		if err := json.Unmarshal(resp.Kvs[0].Value, &session); err != nil {
+			return nil, errors.Wrapf(err, "parse %s session", coord.Name)
+		}
+		pod, err := getPodByAddress(ctx, cli, mil, coord, session.Address)
+		if err != nil {
+			return nil, err
+		}
+		if pod != "" {
+			ret[coord.Name] = pod
+		}
+	}
+	return ret, nil
+}
+
+// getPodByAddress returns the name of the pod of the component serving at @address, empty if not found
+func getPodByAddress(ctx context.Context, cli client.Client, mil v1alpha1.Milvus, component MilvusComponent, address string) (string, error) {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		host = address
+	}
+	pods := &corev1.PodList{}
+	if err := cli.List(ctx, pods, client.InNamespace(mil.Namespace),
+		client.MatchingLabels(GetComponentAppLabels(mil.Name, component))); err != nil {
+		return "", fmt.Errorf("list %s pods: %w", component.Name, err)
+	}
+	for _, pod := range pods.Items {
+		if pod.Status.PodIP == host {
+			return pod.Name, nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/milvus-io/milvus-operator/apis/milvus.io/v1alpha1"
+)
+
+func TestGetCoordSessionKey(t *testing.T) {
+	mil := v1alpha1.Milvus{ObjectMeta: metav1.ObjectMeta{Name: "mc"}}
+	assert.Equal(t, "mc/meta/session/rootcoord", GetCoordSessionKey(mil, RootCoord))
+
+	mil.Spec.Dep.Etcd.RootPath = "by-dev"
+	assert.Equal(t, "by-dev/meta/session/querycoord", GetCoordSessionKey(mil, QueryCoord))
+}
+
+func TestGetActiveCoordinators(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	defer func(f NewEtcdClientFunc) { etcdNewClient = f }(etcdNewClient)
+
+	ctx := context.Background()
+	errTest := errors.New("test")
+	mil := v1alpha1.Milvus{ObjectMeta: metav1.ObjectMeta{Name: "mc", Namespace: "ns"}}
+	mil.Spec.Mode = v1alpha1.MilvusModeCluster
+	mil.Spec.Dep.Etcd.Endpoints = []string{"etcd:2379"}
+
+	newPod := func(name, ip string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "ns",
+				Labels:    GetComponentAppLabels("mc", RootCoord),
+			},
+			Status: corev1.PodStatus{PodIP: ip},
+		}
+	}
+	_, cli := newAdoptionTestEnv(t, newPod("mc-milvus-rootcoord-a", "10.0.0.1"), newPod("mc-milvus-rootcoord-b", "10.0.0.2"))
+
+	// no active-standby coordinators, etcd not accessed
+	etcdNewClient = getMockNewEtcdClient(nil, errTest)
+	ret, err := GetActiveCoordinators(ctx, cli, mil)
+	assert.NoError(t, err)
+	assert.Nil(t, ret)
+
+	mil.Spec.Com.RootCoord.ActiveStandby = true
+	mil.Spec.Com.DataCoord.ActiveStandby = true
+
+	// new client failed
+	_, err = GetActiveCoordinators(ctx, cli, mil)
+	assert.Error(t, err)
+
+	// the pod of the session address is active, the coordinator without a session is omitted
+	mockEtcdCli := NewMockEtcdClient(ctrl)
+	etcdNewClient = getMockNewEtcdClient(mockEtcdCli, nil)
+	gomock.InOrder(
+		mockEtcdCli.EXPECT().Get(gomock.Any(), "mc/meta/session/rootcoord").Return(&clientv3.GetResponse{
+			Kvs: []*mvccpb.KeyValue{{Value: []byte(`{"ServerID":1,"ServerName":"rootcoord","Address":"10.0.0.2:53100"}`)}},
+		}, nil),
+		mockEtcdCli.EXPECT().Get(gomock.Any(), "mc/meta/session/datacoord").Return(&clientv3.GetResponse{}, nil),
+		mockEtcdCli.EXPECT().Close(),
+	)
+	ret, err = GetActiveCoordinators(ctx, cli, mil)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{RootCoordName: "mc-milvus-rootcoord-b"}, ret)
+
+	// get failed
+	gomock.InOrder(
+		mockEtcdCli.EXPECT().Get(gomock.Any(), gomock.Any()).Return(nil, errTest),
+		mockEtcdCli.EXPECT().Close(),
+	)
+	_, err = GetActiveCoordinators(ctx, cli, mil)
+	assert.Error(t, err)
+
+	// invalid session
+	gomock.InOrder(
+		mockEtcdCli.EXPECT().Get(gomock.Any(), gomock.Any()).Return(&clientv3.GetResponse{
+			Kvs: []*mvccpb.KeyValue{{Value: []byte(`invalid`)}},
+		}, nil),
+		mockEtcdCli.EXPECT().Close(),
+	)
+	_, err = GetActiveCoordinators(ctx, cli, mil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,781 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/milvus-io/milvus-operator/apis/milvus.io/v1alpha1"
+	"github.com/milvus-io/milvus-operator/pkg/util"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// The labels and annotations of the resources installed by the milvus helm chart
+const (
+	// HelmLabelComponent is the component label of the milvus chart and the pulsar chart
+	HelmLabelComponent = "component"
+	// HelmLabelRelease is the release label of the minio chart and the pulsar chart
+	HelmLabelRelease = "release"
+	// HelmLabelApp is the chart label of the minio chart and the pulsar chart
+	HelmLabelApp = "app"
+	// HelmAnnotationResourcePolicy keeps the resource when the release is uninstalled if it's "keep"
+	HelmAnnotationResourcePolicy = "helm.sh/resource-policy"
+
+	helmStandaloneName = "standalone"
+)
+
+// AnnotationAdoptedPodTemplate marks an adopted deployment keeping the pod template of the release, the value is the
+// checksum of the desired state of the operator when it's kept. The deployment is rolled out by the operator once the
+// checksum is changed by the spec, remove the annotation to roll it out at once
+const AnnotationAdoptedPodTemplate = "milvus.io/adopted-pod-template"
+
+// helmRelease is the resources of a release installed by the milvus helm chart
+type helmRelease struct {
+	deployments []appsv1.Deployment
+	services    []corev1.Service
+	// dependencies is the services of the etcd, minio and pulsar subcharts
+	dependencies []corev1.Service
+	// secrets is the secrets of the minio subchart by name
+	secrets map[string]corev1.Secret
+	// conf is the milvus config rendered by the chart
+	conf map[string]interface{}
+}
+
+// helmComponent returns the component of the component label of the milvus chart
+func helmComponent(name string) (MilvusComponent, bool) {
+	if name == helmStandaloneName {
+		return MilvusStandalone, true
+	}
+	for _, component := range MilvusComponents {
+		if component.Name == name {
+			return component, true
+		}
+	}
+	return MilvusComponent{}, false
+}
+
+// helmDependencyChart returns the chart of a service of the dependencies in the release, it's empty if it's not one
+func helmDependencyChart(svc corev1.Service) string {
+	if svc.Spec.ClusterIP == corev1.ClusterIPNone {
+		return ""
+	}
+	chart := svc.Labels[AppLabelName]
+	if chart == "" {
+		chart = svc.Labels[HelmLabelApp]
+	}
+	switch chart {
+	case EtcdChart, MinioChart:
+		return chart
+	case PulsarChart:
+		if svc.Labels[HelmLabelComponent] == ProxyName {
+			return chart
+		}
+	}
+	return ""
+}
+
+// getServiceEndpoint returns the endpoint of the service at the port, or at its first port if not found
+func getServiceEndpoint(svc corev1.Service, port int32) string {
+	if len(svc.Spec.Ports) > 0 {
+		found := false
+		for _, p := range svc.Spec.Ports {
+			if p.Port == port {
+				found = true
+			}
+		}
+		if !found {
+			port = svc.Spec.Ports[0].Port
+		}
+	}
+	return fmt.Sprintf("%s:%d", ServiceHost(svc.Name, svc.Namespace), port)
+}
+
+// getConfString returns the scalar value in the config as a string, it's empty if not found
+func getConfString(conf map[string]interface{}, fields ...string) string {
+	val, found, err := unstructured.NestedFieldNoCopy(conf, fields...)
+	if err != nil || !found || val == nil {
+		return ""
+	}
+	switch val.(type) {
+	case map[string]interface{}, []interface{}:
+		return ""
+	}
+	return fmt.Sprint(val)
+}
+
+// getConfEndpoint returns the endpoint of a dependency in the config, it's empty if the address isn't set
+func getConfEndpoint(conf map[string]interface{}, section string) string {
+	address := getConfString(conf, section, "address")
+	if address == "" {
+		return ""
+	}
+	address = strings.TrimPrefix(address, "pulsar://")
+	port := getConfString(conf, section, "port")
+	if _, _, err := net.SplitHostPort(address); err == nil || port == "" {
+		return address
+	}
+	return net.JoinHostPort(strings.Trim(address, "[]"), port)
+}
+
+// getConfEtcdEndpoints returns the etcd endpoints in the config
+func getConfEtcdEndpoints(conf map[string]interface{}) []string {
+	val, _, _ := unstructured.NestedFieldNoCopy(conf, "etcd", "endpoints")
+	endpoints := []string{}
+	switch v := val.(type) {
+	case []interface{}:
+		for _, endpoint := range v {
+			endpoints = append(endpoints, fmt.Sprint(endpoint))
+		}
+	case string:
+		for _, endpoint := range strings.Split(v, ",") {
+			if endpoint = strings.TrimSpace(endpoint); endpoint != "" {
+				endpoints = append(endpoints, endpoint)
+			}
+		}
+	}
+	return endpoints
+}
+
+// mergeConfigMapConf merges the yaml files in the configmap into the conf, in the order of their keys,
+// so that user.yaml overrides the default one
+func mergeConfigMapConf(conf map[string]interface{}, cm corev1.ConfigMap) {
+	keys := make([]string, 0, len(cm.Data))
+	for key := range cm.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		data := map[string]interface{}{}
+		if err := yaml.Unmarshal([]byte(cm.Data[key]), &data); err != nil {
+			continue
+		}
+		util.MergeValues(conf, data)
+	}
+}
+
+// helmDeploymentSelector selects the deployments of the milvus chart as the release of the instance name
+func helmDeploymentSelector(instance string) client.MatchingLabelsSelector {
+	selector := labels.SelectorFromSet(NewAppLabels(instance))
+	requirement, _ := labels.NewRequirement(HelmLabelComponent, selection.Exists, nil)
+	return client.MatchingLabelsSelector{Selector: selector.Add(*requirement)}
+}
+
+// GetHelmRelease discovers the resources installed by the milvus helm chart as the release of the instance name
+func (r *MilvusReconciler) GetHelmRelease(ctx context.Context, mil v1alpha1.Milvus) (*helmRelease, error) {
+	rel := &helmRelease{
+		secrets: map[string]corev1.Secret{},
+		conf:    map[string]interface{}{},
+	}
+	ns := client.InNamespace(mil.Namespace)
+	milvusLabels := client.MatchingLabels(NewAppLabels(mil.Name))
+
+	deployments := &appsv1.DeploymentList{}
+	if err := r.List(ctx, deployments, ns, helmDeploymentSelector(mil.Name)); err != nil {
+		return nil, errors.Wrap(err, "list deployments")
+	}
+	rel.deployments = deployments.Items
+
+	services := &corev1.ServiceList{}
+	if err := r.List(ctx, services, ns, milvusLabels); err != nil {
+		return nil, errors.Wrap(err, "list services")
+	}
+	for _, svc := range services.Items {
+		if svc.Spec.Selector[HelmLabelComponent] != "" {
+			rel.services = append(rel.services, svc)
+		}
+	}
+
+	// the subcharts label the release differently
+	found := map[string]bool{}
+	for _, selector := range []client.MatchingLabels{{AppLabelInstance: mil.Name}, {HelmLabelRelease: mil.Name}} {
+		services := &corev1.ServiceList{}
+		if err := r.List(ctx, services, ns, selector); err != nil {
+			return nil, errors.Wrap(err, "list services of dependencies")
+		}
+		for _, svc := range services.Items {
+			chart := helmDependencyChart(svc)
+			if chart == "" || found[svc.Name] {
+				continue
+			}
+			found[svc.Name] = true
+			rel.dependencies = append(rel.dependencies, svc)
+			if chart != MinioChart {
+				continue
+			}
+			secret := &corev1.Secret{}
+			err := r.Get(ctx, NamespacedName(mil.Namespace, svc.Name), secret)
+			if err == nil {
+				rel.secrets[svc.Name] = *secret
+			} else if !k8sErrors.IsNotFound(err) {
+				return nil, errors.Wrap(err, "get secret of minio")
+			}
+		}
+	}
+
+	configMaps := map[string]bool{}
+	for _, deploy := range rel.deployments {
+		for _, volume := range deploy.Spec.Template.Spec.Volumes {
+			if volume.ConfigMap != nil {
+				configMaps[volume.ConfigMap.Name] = true
+			}
+		}
+	}
+	names := make([]string, 0, len(configMaps))
+	for name := range configMaps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		cm := &corev1.ConfigMap{}
+		err := r.Get(ctx, NamespacedName(mil.Namespace, name), cm)
+		if k8sErrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "get configmap")
+		}
+		mergeConfigMapConf(rel.conf, *cm)
+	}
+
+	return rel, nil
+}
+
+// getComponentPtr returns the component in spec.components, it's nil for the standalone
+func getComponentPtr(spec *v1alpha1.MilvusSpec, c MilvusComponent) *v1alpha1.Component {
+	for i, component := range MilvusComponents {
+		if component == c {
+			return spec.Com.GetComponents()[i]
+		}
+	}
+	return nil
+}
+
+// isAdoptedInPlace returns whether the deployment is named as the operator names the component,
+// so that it's updated in place instead of replaced
+func isAdoptedInPlace(mil v1alpha1.Milvus, component MilvusComponent, deploy appsv1.Deployment) bool {
+	containers := deploy.Spec.Template.Spec.Containers
+	return deploy.Name == component.GetDeploymentInstanceName(mil.Name) &&
+		len(containers) > 0 && containers[0].Name == component.GetContainerName()
+}
+
+// mapHelmDeployment maps the deployment of a component into the spec, and returns the parts not mapped
+func mapHelmDeployment(mil *v1alpha1.Milvus, component MilvusComponent, deploy appsv1.Deployment) []string {
+	notMapped := []string{}
+	for _, volume := range deploy.Spec.Template.Spec.Volumes {
+		if volume.ConfigMap == nil {
+			notMapped = append(notMapped, fmt.Sprintf("volume %s of deployment %s", volume.Name, deploy.Name))
+		}
+	}
+	containers := deploy.Spec.Template.Spec.Containers
+	if len(containers) == 0 {
+		return notMapped
+	}
+	for _, container := range containers[1:] {
+		notMapped = append(notMapped, fmt.Sprintf("container %s of deployment %s", container.Name, deploy.Name))
+	}
+
+	container := containers[0]
+	hasResources := len(container.Resources.Limits) > 0 || len(container.Resources.Requests) > 0
+	spec := getComponentPtr(&mil.Spec, component)
+	if spec == nil {
+		if hasResources {
+			mil.Spec.Resources = container.Resources.DeepCopy()
+		}
+		return notMapped
+	}
+
+	if container.Image != mil.Spec.Image {
+		spec.Image = container.Image
+	}
+	if hasResources {
+		spec.Resources = container.Resources.DeepCopy()
+	}
+	if deploy.Spec.Replicas != nil {
+		if component.IsCoord() && *deploy.Spec.Replicas > 1 {
+			notMapped = append(notMapped, fmt.Sprintf("replicas %d of deployment %s", *deploy.Spec.Replicas, deploy.Name))
+		} else {
+			replicas := *deploy.Spec.Replicas
+			spec.Replicas = &replicas
+		}
+	}
+	return notMapped
+}
+
+// mapHelmDependencies maps the dependencies of the release as external ones, the ones not installed by the subcharts
+// are read from the config. The dependencies already set external in the spec are kept
+func mapHelmDependencies(mil *v1alpha1.Milvus, rel *helmRelease) ([]string, error) {
+	notMapped := []string{}
+	dep := &mil.Spec.Dep
+	etcdSet := dep.Etcd.External && (len(dep.Etcd.Endpoints) > 0 || dep.Etcd.ServiceRef != nil)
+	storageSet := dep.Storage.External && (dep.Storage.Endpoint != "" || dep.Storage.ServiceRef != nil)
+	pulsarSet := dep.Pulsar.External && (dep.Pulsar.Endpoint != "" || dep.Pulsar.ServiceRef != nil)
+	etcdEndpoints := []string{}
+	storageEndpoint, pulsarEndpoint := "", ""
+	storageSecret, hasStorageSecret := corev1.Secret{}, false
+
+	for _, svc := range rel.dependencies {
+		switch helmDependencyChart(svc) {
+		case EtcdChart:
+			etcdEndpoints = append(etcdEndpoints, getServiceEndpoint(svc, v1alpha1.EtcdDefaultPort))
+		case MinioChart:
+			storageEndpoint = getServiceEndpoint(svc, v1alpha1.StorageDefaultPort)
+			storageSecret, hasStorageSecret = rel.secrets[svc.Name]
+		case PulsarChart:
+			if !mil.Spec.IsCluster() {
+				notMapped = append(notMapped, fmt.Sprintf("service %s of pulsar unused by the standalone", svc.Name))
+				continue
+			}
+			pulsarEndpoint = getServiceEndpoint(svc, v1alpha1.PulsarDefaultPort)
+		}
+	}
+
+	if !etcdSet {
+		if len(etcdEndpoints) == 0 {
+			etcdEndpoints = getConfEtcdEndpoints(rel.conf)
+		}
+		if len(etcdEndpoints) == 0 {
+			return nil, errors.New("etcd of the release not found, set spec.dependencies.etcd")
+		}
+		dep.Etcd.External = true
+		dep.Etcd.Endpoints = etcdEndpoints
+	}
+
+	if !storageSet {
+		if storageEndpoint == "" {
+			storageEndpoint = getConfEndpoint(rel.conf, "minio")
+		}
+		if storageEndpoint == "" {
+			return nil, errors.New("storage of the release not found, set spec.dependencies.storage")
+		}
+		dep.Storage.External = true
+		dep.Storage.Endpoint = storageEndpoint
+		// the secret defaulted for the in-cluster storage of the operator
+		dep.Storage.SecretRef = ""
+		if hasStorageSecret {
+			if len(storageSecret.Data[AccessKey]) > 0 && len(storageSecret.Data[SecretKey]) > 0 {
+				dep.Storage.SecretRef = storageSecret.Name
+			} else {
+				notMapped = append(notMapped, fmt.Sprintf("secret %s without keys %s and %s", storageSecret.Name, AccessKey, SecretKey))
+			}
+		}
+	}
+
+	if mil.Spec.IsCluster() && !pulsarSet {
+		if pulsarEndpoint == "" {
+			pulsarEndpoint = getConfEndpoint(rel.conf, "pulsar")
+		}
+		if pulsarEndpoint == "" {
+			return nil, errors.New("pulsar of the release not found, set spec.dependencies.pulsar")
+		}
+		dep.Pulsar.External = true
+		dep.Pulsar.Endpoint = pulsarEndpoint
+	}
+
+	return notMapped, nil
+}
+
+// mapHelmPrefixes maps the prefixes in the config of the release, the ones set in spec are kept
+func mapHelmPrefixes(mil *v1alpha1.Milvus, conf map[string]interface{}) error {
+	prefixes := []struct {
+		value  *string
+		fields []string
+		path   string
+	}{
+		{&mil.Spec.Dep.Etcd.RootPath, []string{"etcd", "rootPath"}, "spec.dependencies.etcd.rootPath"},
+		{&mil.Spec.Dep.Storage.BucketName, []string{"minio", "bucketName"}, "spec.dependencies.storage.bucketName"},
+		{&mil.Spec.Dep.MsgChannelPrefix, []string{"msgChannel", "chanNamePrefix", "cluster"}, "spec.dependencies.msgChannelPrefix"},
+	}
+	for _, prefix := range prefixes {
+		if *prefix.value != "" {
+			continue
+		}
+		*prefix.value = getConfString(conf, prefix.fields...)
+		if *prefix.value == "" {
+			return errors.Errorf("%s not found in the config of the release, set %s", strings.Join(prefix.fields, "."), prefix.path)
+		}
+	}
+	return nil
+}
+
+// mapHelmRelease maps the helm release into the spec of the instance, and returns the parts not mapped.
+// It fails if the instance can't serve the release
+func mapHelmRelease(mil *v1alpha1.Milvus, rel *helmRelease) ([]string, error) {
+	notMapped := []string{}
+	components := make([]MilvusComponent, len(rel.deployments))
+	found := map[MilvusComponent]string{}
+	for i, deploy := range rel.deployments {
+		if owner := metav1.GetControllerOf(&deploy); owner != nil && owner.UID != mil.UID {
+			return nil, errors.Errorf("deployment %s is controlled by %s %s", deploy.Name, owner.Kind, owner.Name)
+		}
+		component, ok := helmComponent(deploy.Labels[HelmLabelComponent])
+		if !ok {
+			notMapped = append(notMapped, fmt.Sprintf("deployment %s of unknown component %s", deploy.Name, deploy.Labels[HelmLabelComponent]))
+			continue
+		}
+		if name, ok := found[component]; ok {
+			return nil, errors.Errorf("deployments %s and %s are of the same component %s", name, deploy.Name, component.Name)
+		}
+		found[component] = deploy.Name
+		components[i] = component
+	}
+
+	if len(found) == 0 {
+		return nil, errors.Errorf("no deployment of the milvus helm release %s found", mil.Name)
+	}
+	if _, ok := found[MilvusStandalone]; ok && len(found) > 1 {
+		return nil, errors.New("both the standalone and the cluster components are found")
+	}
+	mil.Spec.Mode = v1alpha1.MilvusModeStandalone
+	if _, ok := found[MilvusStandalone]; !ok {
+		mil.Spec.Mode = v1alpha1.MilvusModeCluster
+	}
+
+	serving := GetServingComponent(mil.Spec)
+	if _, ok := found[serving]; !ok {
+		return nil, errors.Errorf("no deployment of %s found", serving.Name)
+	}
+	for i, deploy := range rel.deployments {
+		if components[i] == serving && len(deploy.Spec.Template.Spec.Containers) > 0 {
+			mil.Spec.Image = deploy.Spec.Template.Spec.Containers[0].Image
+		}
+	}
+	for i, deploy := range rel.deployments {
+		if components[i] != (MilvusComponent{}) {
+			notMapped = append(notMapped, mapHelmDeployment(mil, components[i], deploy)...)
+		}
+	}
+
+	for _, svc := range rel.services {
+		component, ok := helmComponent(svc.Spec.Selector[HelmLabelComponent])
+		if ok && component == serving {
+			mil.Spec.ServiceType = svc.Spec.Type
+		}
+	}
+
+	depNotMapped, err := mapHelmDependencies(mil, rel)
+	if err != nil {
+		return nil, err
+	}
+	notMapped = append(notMapped, depNotMapped...)
+
+	if err := mapHelmPrefixes(mil, rel.conf); err != nil {
+		return nil, err
+	}
+	return notMapped, nil
+}
+
+// getAdoptionRollouts returns how the deployments of the release are rolled out by the operator after the adoption.
+// They keep the pods of the release until the pod templates of their components are changed
+func getAdoptionRollouts(mil v1alpha1.Milvus, rel *helmRelease) []string {
+	rollouts := []string{}
+	for _, deploy := range rel.deployments {
+		component, ok := helmComponent(deploy.Labels[HelmLabelComponent])
+		if !ok {
+			continue
+		}
+		if isAdoptedInPlace(mil, component, deploy) {
+			rollouts = append(rollouts, fmt.Sprintf("deployment %s keeps the pods of the release until its component is changed", deploy.Name))
+			continue
+		}
+		rollouts = append(rollouts, fmt.Sprintf("deployment %s keeps the pods of the release until its component is changed, then it's replaced by %s",
+			deploy.Name, component.GetDeploymentInstanceName(mil.Name)))
+	}
+	return rollouts
+}
+
+// ownHelmObject takes the ownership of the object of the release with the changes made by @mutate,
+// it's kept if the release is uninstalled
+func (r *MilvusReconciler) ownHelmObject(ctx context.Context, mil v1alpha1.Milvus, obj client.Object, mutate func()) error {
+	patch := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+	mutate()
+	if err := ctrl.SetControllerReference(&mil, obj, r.Scheme); err != nil {
+		return err
+	}
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[HelmAnnotationResourcePolicy] = "keep"
+	obj.SetAnnotations(annotations)
+	return r.Patch(ctx, obj, patch)
+}
+
+// OwnHelmRelease takes the ownership of the deployments and the services of the release. The deployments are marked
+// to keep the pod templates of the release, and the services keep their selectors for the pods of the release
+func (r *MilvusReconciler) OwnHelmRelease(ctx context.Context, mil v1alpha1.Milvus, rel *helmRelease) error {
+	for i := range rel.deployments {
+		deploy := &rel.deployments[i]
+		if _, ok := helmComponent(deploy.Labels[HelmLabelComponent]); !ok {
+			continue
+		}
+		err := r.ownHelmObject(ctx, mil, deploy, func() {
+			if deploy.Annotations == nil {
+				deploy.Annotations = map[string]string{}
+			}
+			deploy.Annotations[AnnotationAdoptedPodTemplate] = ""
+		})
+		if err != nil {
+			return errors.Wrapf(err, "own deployment %s", deploy.Name)
+		}
+	}
+	for i := range rel.services {
+		svc := &rel.services[i]
+		if _, ok := helmComponent(svc.Spec.Selector[HelmLabelComponent]); !ok {
+			continue
+		}
+		if err := r.ownHelmObject(ctx, mil, svc, func() {}); err != nil {
+			return errors.Wrapf(err, "own service %s", svc.Name)
+		}
+	}
+	return nil
+}
+
+// GetAdoptedCondition returns the condition of the adoption with the rollouts of the deployments,
+// the parts not mapped or the error
+func GetAdoptedCondition(release string, rollouts, notMapped []string, err error) v1alpha1.MilvusCondition {
+	if err != nil {
+		return v1alpha1.MilvusCondition{
+			Type:    v1alpha1.Adopted,
+			Status:  corev1.ConditionFalse,
+			Reason:  v1alpha1.ReasonAdoptionFailed,
+			Message: err.Error(),
+		}
+	}
+	msg := fmt.Sprintf("release %s adopted", release)
+	if len(rollouts) > 0 {
+		msg += ", pending rollouts: " + strings.Join(rollouts, "; ")
+	}
+	if len(notMapped) > 0 {
+		msg += ", not mapped: " + strings.Join(notMapped, "; ")
+	}
+	return v1alpha1.MilvusCondition{
+		Type:    v1alpha1.Adopted,
+		Status:  corev1.ConditionTrue,
+		Reason:  v1alpha1.ReasonAdopted,
+		Message: msg,
+	}
+}
+
+// IsAdopted returns whether the instance has adopted a helm release
+func IsAdopted(conditions []v1alpha1.MilvusCondition) bool {
+	cond := GetCondition(conditions, v1alpha1.Adopted)
+	return cond != nil && cond.Status == corev1.ConditionTrue
+}
+
+// Adopt adopts the milvus installed by the helm chart as the release of the instance name. The release is mapped
+// into the spec and the operator takes the ownership of its deployments and services. The pods of the release are kept
+// until the spec is changed, the pending rollouts and the parts not mapped are reported in the Adopted condition.
+// It's retried once the spec is changed if it fails
+func (r *MilvusReconciler) Adopt(ctx context.Context, mil *v1alpha1.Milvus) error {
+	rel, err := r.GetHelmRelease(ctx, *mil)
+	if err != nil {
+		return errors.Wrap(err, "get helm release")
+	}
+
+	mapped := mil.DeepCopy()
+	notMapped, mapErr := mapHelmRelease(mapped, rel)
+	var rollouts []string
+	if mapErr == nil {
+		rollouts = getAdoptionRollouts(*mapped, rel)
+		if err := r.OwnHelmRelease(ctx, *mil, rel); err != nil {
+			return err
+		}
+	}
+
+	if mil.Status.Status == "" {
+		mil.Status.Status = v1alpha1.StatusCreating
+	}
+	UpdateCondition(&mil.Status, GetAdoptedCondition(mil.Name, rollouts, notMapped, mapErr))
+	if err := r.Status().Update(ctx, mil); err != nil {
+		return errors.Wrapf(err, "update adoption status[%s/%s] failed", mil.Namespace, mil.Name)
+	}
+	if mapErr != nil {
+		r.logger.Info("adoption failed", "name", mil.Name, "namespace", mil.Namespace, "error", mapErr.Error())
+		return nil
+	}
+
+	// the spec is updated at last, so that the adoption is retried until it's done
+	mapped.ObjectMeta.ResourceVersion = mil.ResourceVersion
+	mapped.Status = mil.Status
+	delete(mapped.Annotations, v1alpha1.AnnotationAdopt)
+	if err := r.Update(ctx, mapped); err != nil {
+		return errors.Wrap(err, "update adopted spec")
+	}
+	r.logger.Info("release adopted", "name", mil.Name, "namespace", mil.Namespace, "rollouts", rollouts, "notMapped", notMapped)
+	return nil
+}
+
+// getAdoptedCheckSum returns the checksum of the desired state of the adopted deployment
+func getAdoptedCheckSum(desired interface{}) (string, error) {
+	b, err := json.Marshal(desired)
+	if err != nil {
+		return "", err
+	}
+	return util.CheckSum(b), nil
+}
+
+// isPodTemplateKept returns whether the adopted deployment keeps the pod template of the release,
+// it's kept until the checksum of the desired state is changed
+func isPodTemplateKept(deploy *appsv1.Deployment, checksum string) bool {
+	kept, ok := deploy.Annotations[AnnotationAdoptedPodTemplate]
+	return ok && (kept == "" || kept == checksum)
+}
+
+// keepAdoptedPodTemplate keeps the pod template of the adopted deployment @existing in the @desired one of the operator,
+// until the desired pod template is changed. The annotation is no longer applied afterwards, so it's removed
+func keepAdoptedPodTemplate(existing, desired *appsv1.Deployment) error {
+	if _, ok := existing.Annotations[AnnotationAdoptedPodTemplate]; !ok {
+		return nil
+	}
+	checksum, err := getAdoptedCheckSum(desired.Spec.Template)
+	if err != nil {
+		return err
+	}
+	if !isPodTemplateKept(existing, checksum) {
+		return nil
+	}
+	desired.Spec.Template = *existing.Spec.Template.DeepCopy()
+	if desired.Annotations == nil {
+		desired.Annotations = map[string]string{}
+	}
+	desired.Annotations[AnnotationAdoptedPodTemplate] = checksum
+	return nil
+}
+
+// getReplacedDeployment returns the adopted deployment of the component not named as the operator names it, nil if not found
+func (r *MilvusReconciler) getReplacedDeployment(ctx context.Context, mil v1alpha1.Milvus, component MilvusComponent) (*appsv1.Deployment, error) {
+	deployments := &appsv1.DeploymentList{}
+	if err := r.List(ctx, deployments, client.InNamespace(mil.Namespace), helmDeploymentSelector(mil.Name)); err != nil {
+		return nil, err
+	}
+	for i := range deployments.Items {
+		deploy := &deployments.Items[i]
+		c, ok := helmComponent(deploy.Labels[HelmLabelComponent])
+		if ok && c == component && metav1.IsControlledBy(deploy, &mil) && !isAdoptedInPlace(mil, component, *deploy) {
+			return deploy, nil
+		}
+	}
+	return nil, nil
+}
+
+// ReplaceAdoptedDeployment returns whether the @desired deployment of the component can be created to replace the adopted
+// one not named as the operator names it. The adopted one is kept until the desired state of the component is changed,
+// and it's scaled down before the replacement is created if the component is recreated on updates
+func (r *MilvusReconciler) ReplaceAdoptedDeployment(
+	ctx context.Context, mil v1alpha1.Milvus, component MilvusComponent, desired *appsv1.Deployment,
+) (bool, error) {
+	adopted, err := r.getReplacedDeployment(ctx, mil, component)
+	if err != nil {
+		return false, err
+	}
+	if adopted == nil {
+		return true, nil
+	}
+
+	if kept, ok := adopted.Annotations[AnnotationAdoptedPodTemplate]; ok {
+		checksum, err := getAdoptedCheckSum(desired.Spec)
+		if err != nil {
+			return false, err
+		}
+		patch := client.MergeFrom(adopted.DeepCopy())
+		if isPodTemplateKept(adopted, checksum) {
+			if kept == checksum {
+				return false, nil
+			}
+			adopted.Annotations[AnnotationAdoptedPodTemplate] = checksum
+			return false, r.Patch(ctx, adopted, patch)
+		}
+		delete(adopted.Annotations, AnnotationAdoptedPodTemplate)
+		if err := r.Patch(ctx, adopted, patch); err != nil {
+			return false, err
+		}
+		r.logger.Info("replace adopted deployment", "name", adopted.Name, "namespace", adopted.Namespace, "by", desired.Name)
+	}
+
+	if desired.Spec.Strategy.Type != appsv1.RecreateDeploymentStrategyType {
+		return true, nil
+	}
+	if adopted.Spec.Replicas == nil || *adopted.Spec.Replicas > 0 {
+		patch := client.MergeFrom(adopted.DeepCopy())
+		adopted.Spec.Replicas = int32Ptr(0)
+		r.logger.Info("scale down the adopted deployment before it's replaced", "name", adopted.Name, "namespace", adopted.Namespace)
+		return false, r.Patch(ctx, adopted, patch)
+	}
+	return adopted.Status.Replicas == 0, nil
+}
+
+// selectReplacingPods switches the services selecting the pods of the adopted deployment of the component
+// to the pods of the operator
+func (r *MilvusReconciler) selectReplacingPods(ctx context.Context, mil v1alpha1.Milvus, component MilvusComponent) error {
+	services := &corev1.ServiceList{}
+	if err := r.List(ctx, services, client.InNamespace(mil.Namespace), client.MatchingLabels(NewAppLabels(mil.Name))); err != nil {
+		return err
+	}
+	for i := range services.Items {
+		svc := &services.Items[i]
+		c, ok := helmComponent(svc.Spec.Selector[HelmLabelComponent])
+		if !ok || c != component || !metav1.IsControlledBy(svc, &mil) {
+			continue
+		}
+		patch := client.MergeFrom(svc.DeepCopy())
+		svc.Spec.Selector = NewComponentAppLabels(mil.Name, component.String())
+		if err := r.Patch(ctx, svc, patch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReplaceAdoptedDeployments deletes the adopted deployments not named as the operator names them once the ones
+// replacing them are available. The services of the release are switched to the pods of the operator before
+func (r *MilvusReconciler) ReplaceAdoptedDeployments(ctx context.Context, mil v1alpha1.Milvus) error {
+	deployments := &appsv1.DeploymentList{}
+	if err := r.List(ctx, deployments, client.InNamespace(mil.Namespace), helmDeploymentSelector(mil.Name)); err != nil {
+		return err
+	}
+
+	for i := range deployments.Items {
+		deploy := &deployments.Items[i]
+		component, ok := helmComponent(deploy.Labels[HelmLabelComponent])
+		if !ok || !metav1.IsControlledBy(deploy, &mil) || isAdoptedInPlace(mil, component, *deploy) {
+			continue
+		}
+		if _, kept := deploy.Annotations[AnnotationAdoptedPodTemplate]; kept {
+			continue
+		}
+		replacing := &appsv1.Deployment{}
+		err := r.Get(ctx, NamespacedName(mil.Namespace, component.GetDeploymentInstanceName(mil.Name)), replacing)
+		if k8sErrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if !DeploymentReady(*replacing) {
+			r.logger.Info("waiting for the deployment replacing the adopted one", "name", replacing.Name, "namespace", mil.Namespace)
+			continue
+		}
+
+		if err := r.selectReplacingPods(ctx, mil, component); err != nil {
+			return errors.Wrapf(err, "switch services to %s", replacing.Name)
+		}
+		if err := r.Delete(ctx, deploy); err != nil && !k8sErrors.IsNotFound(err) {
+			return err
+		}
+		r.logger.Info("adopted deployment replaced", "name", deploy.Name, "namespace", deploy.Namespace, "by", replacing.Name)
+	}
+	return nil
+}
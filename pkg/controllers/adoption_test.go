@@ -0,0 +1,440 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/milvus-io/milvus-operator/apis/milvus.io/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlRuntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newHelmDeployment(release, name, component string, containers ...string) *appsv1.Deployment {
+	helmLabels := MergeLabels(NewAppLabels(release), map[string]string{HelmLabelComponent: component})
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name, Labels: helmLabels},
+	}
+	replicas := int32(2)
+	deploy.Spec.Replicas = &replicas
+	deploy.Spec.Selector = &metav1.LabelSelector{MatchLabels: helmLabels}
+	deploy.Spec.Template.Labels = helmLabels
+	for _, container := range containers {
+		deploy.Spec.Template.Spec.Containers = append(deploy.Spec.Template.Spec.Containers, corev1.Container{
+			Name:  container,
+			Image: "milvusdb/milvus:v2.0.0",
+		})
+	}
+	deploy.Spec.Template.Spec.Volumes = []corev1.Volume{{
+		Name: "milvus-config",
+		VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: release + "-milvus"}},
+		},
+	}}
+	return deploy
+}
+
+func newHelmService(name string, labels map[string]string, port int32) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name, Labels: labels},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{{Port: port}},
+		},
+	}
+}
+
+func newHelmConfigMap(release string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: release + "-milvus"},
+		Data: map[string]string{
+			"milvus.yaml": `
+etcd:
+  endpoints:
+  - external-etcd:2379
+  rootPath: by-dev
+minio:
+  address: external-minio
+  port: 9000
+  bucketName: milvus-bucket
+pulsar:
+  address: pulsar://external-pulsar
+  port: 6650
+msgChannel:
+  chanNamePrefix:
+    cluster: by-dev
+`,
+			"user.yaml": "etcd:\n  rootPath: user-dev\n",
+		},
+	}
+}
+
+func TestHelmComponent(t *testing.T) {
+	c, ok := helmComponent("standalone")
+	assert.True(t, ok)
+	assert.Equal(t, MilvusStandalone, c)
+	c, ok = helmComponent("querynode")
+	assert.True(t, ok)
+	assert.Equal(t, QueryNode, c)
+	_, ok = helmComponent("attu")
+	assert.False(t, ok)
+}
+
+func TestHelmDependencyChart(t *testing.T) {
+	etcd := newHelmService("r-etcd", map[string]string{AppLabelName: "etcd"}, 2379)
+	assert.Equal(t, EtcdChart, helmDependencyChart(*etcd))
+	etcd.Spec.ClusterIP = corev1.ClusterIPNone
+	assert.Empty(t, helmDependencyChart(*etcd))
+
+	minio := newHelmService("r-minio", map[string]string{HelmLabelApp: "minio"}, 9000)
+	assert.Equal(t, MinioChart, helmDependencyChart(*minio))
+
+	pulsar := newHelmService("r-pulsar-proxy", map[string]string{HelmLabelApp: "pulsar", HelmLabelComponent: "proxy"}, 6650)
+	assert.Equal(t, PulsarChart, helmDependencyChart(*pulsar))
+	pulsar.Labels[HelmLabelComponent] = "broker"
+	assert.Empty(t, helmDependencyChart(*pulsar))
+}
+
+func TestGetServiceEndpoint(t *testing.T) {
+	svc := newHelmService("r-etcd", nil, 2379)
+	svc.Spec.Ports = append([]corev1.ServicePort{{Port: 2380}}, svc.Spec.Ports...)
+	assert.Equal(t, "r-etcd.ns:2379", getServiceEndpoint(*svc, v1alpha1.EtcdDefaultPort))
+	assert.Equal(t, "r-etcd.ns:2380", getServiceEndpoint(*svc, v1alpha1.StorageDefaultPort))
+}
+
+func TestGetConfEndpoints(t *testing.T) {
+	conf := map[string]interface{}{}
+	mergeConfigMapConf(conf, *newHelmConfigMap("r"))
+	assert.Equal(t, "user-dev", getConfString(conf, "etcd", "rootPath"))
+	assert.Equal(t, "by-dev", getConfString(conf, "msgChannel", "chanNamePrefix", "cluster"))
+	assert.Empty(t, getConfString(conf, "msgChannel", "chanNamePrefix"))
+	assert.Equal(t, []string{"external-etcd:2379"}, getConfEtcdEndpoints(conf))
+	assert.Equal(t, "external-minio:9000", getConfEndpoint(conf, "minio"))
+	assert.Equal(t, "external-pulsar:6650", getConfEndpoint(conf, "pulsar"))
+	assert.Empty(t, getConfEndpoint(conf, "rocksmq"))
+
+	conf["minio"] = map[string]interface{}{"address": "[fd00::1]", "port": 9000}
+	assert.Equal(t, "[fd00::1]:9000", getConfEndpoint(conf, "minio"))
+	conf["minio"] = map[string]interface{}{"address": "fd00::1", "port": 9000}
+	assert.Equal(t, "[fd00::1]:9000", getConfEndpoint(conf, "minio"))
+
+	conf["etcd"] = map[string]interface{}{"endpoints": "a:2379, b:2379"}
+	assert.Equal(t, []string{"a:2379", "b:2379"}, getConfEtcdEndpoints(conf))
+}
+
+func TestMapHelmRelease_Cluster(t *testing.T) {
+	mil := &v1alpha1.Milvus{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "r"}}
+	conf := map[string]interface{}{}
+	mergeConfigMapConf(conf, *newHelmConfigMap("r"))
+	rel := &helmRelease{conf: conf, secrets: map[string]corev1.Secret{}}
+	for _, component := range MilvusComponents {
+		rel.deployments = append(rel.deployments, *newHelmDeployment("r", "r-milvus-"+component.Name, component.Name, component.Name))
+	}
+	rel.deployments[0].Spec.Template.Spec.Containers[0].Image = "milvusdb/milvus:v2.0.1"
+	proxySvc := newHelmService("r-milvus", nil, 19530)
+	proxySvc.Spec.Type = corev1.ServiceTypeLoadBalancer
+	proxySvc.Spec.Selector = map[string]string{HelmLabelComponent: "proxy"}
+	rel.services = []corev1.Service{*proxySvc}
+	rel.dependencies = []corev1.Service{
+		*newHelmService("r-pulsar-proxy", map[string]string{HelmLabelApp: "pulsar", HelmLabelComponent: "proxy"}, 6650),
+	}
+
+	notMapped, err := mapHelmRelease(mil, rel)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"replicas 2 of deployment r-milvus-rootcoord", "replicas 2 of deployment r-milvus-datacoord",
+		"replicas 2 of deployment r-milvus-querycoord", "replicas 2 of deployment r-milvus-indexcoord"}, notMapped)
+	assert.True(t, mil.Spec.IsCluster())
+	assert.Equal(t, "milvusdb/milvus:v2.0.0", mil.Spec.Image)
+	assert.Equal(t, "milvusdb/milvus:v2.0.1", mil.Spec.Com.RootCoord.Image)
+	assert.Empty(t, mil.Spec.Com.Proxy.Image)
+	assert.Nil(t, mil.Spec.Com.RootCoord.Replicas)
+	assert.Equal(t, int32(2), *mil.Spec.Com.QueryNode.Replicas)
+	assert.Equal(t, corev1.ServiceTypeLoadBalancer, mil.Spec.ServiceType)
+	assert.Equal(t, []string{"external-etcd:2379"}, mil.Spec.Dep.Etcd.Endpoints)
+	assert.Equal(t, "external-minio:9000", mil.Spec.Dep.Storage.Endpoint)
+	assert.Equal(t, "r-pulsar-proxy.ns:6650", mil.Spec.Dep.Pulsar.Endpoint)
+	assert.True(t, mil.Spec.Dep.Pulsar.External)
+	assert.Equal(t, "user-dev", mil.Spec.Dep.Etcd.RootPath)
+	assert.Equal(t, "milvus-bucket", mil.Spec.Dep.Storage.BucketName)
+	assert.Equal(t, "by-dev", mil.Spec.Dep.MsgChannelPrefix)
+}
+
+func TestMapHelmRelease_Failed(t *testing.T) {
+	newRelease := func() *helmRelease {
+		conf := map[string]interface{}{}
+		mergeConfigMapConf(conf, *newHelmConfigMap("r"))
+		return &helmRelease{conf: conf, secrets: map[string]corev1.Secret{}}
+	}
+	mil := &v1alpha1.Milvus{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "r", UID: "uid"}}
+
+	// nothing found
+	rel := newRelease()
+	_, err := mapHelmRelease(mil.DeepCopy(), rel)
+	assert.Error(t, err)
+
+	// both modes
+	rel.deployments = []appsv1.Deployment{
+		*newHelmDeployment("r", "r-milvus-standalone", "standalone", "standalone"),
+		*newHelmDeployment("r", "r-milvus-proxy", "proxy", "proxy"),
+	}
+	_, err = mapHelmRelease(mil.DeepCopy(), rel)
+	assert.Error(t, err)
+
+	// no proxy
+	rel.deployments = rel.deployments[1:]
+	rel.deployments[0].Labels[HelmLabelComponent] = "querynode"
+	_, err = mapHelmRelease(mil.DeepCopy(), rel)
+	assert.Error(t, err)
+
+	// controlled by others
+	rel.deployments = []appsv1.Deployment{*newHelmDeployment("r", "r-milvus-standalone", "standalone", "standalone")}
+	isController := true
+	rel.deployments[0].OwnerReferences = []metav1.OwnerReference{{Kind: "Milvus", Name: "other", UID: "other", Controller: &isController}}
+	_, err = mapHelmRelease(mil.DeepCopy(), rel)
+	assert.Error(t, err)
+
+	// prefix not found
+	rel.deployments[0].OwnerReferences = nil
+	delete(rel.conf, "msgChannel")
+	_, err = mapHelmRelease(mil.DeepCopy(), rel)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "spec.dependencies.msgChannelPrefix")
+
+	// set in spec
+	mapped := mil.DeepCopy()
+	mapped.Spec.Dep.MsgChannelPrefix = "by-dev"
+	_, err = mapHelmRelease(mapped.DeepCopy(), rel)
+	assert.NoError(t, err)
+
+	// storage not found
+	delete(rel.conf, "minio")
+	rel.conf["minio"] = map[string]interface{}{"bucketName": "milvus-bucket"}
+	_, err = mapHelmRelease(mapped.DeepCopy(), rel)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "spec.dependencies.storage")
+}
+
+func newAdoptionTestEnv(t *testing.T, objs ...client.Object) (*MilvusReconciler, client.Client) {
+	scheme := newSchemeForTest()
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+	return &MilvusReconciler{
+		Client: cli,
+		Scheme: scheme,
+		logger: ctrlRuntime.Log.WithName("test"),
+	}, cli
+}
+
+func TestMilvusReconciler_Adopt_Standalone(t *testing.T) {
+	ctx := context.Background()
+	mil := &v1alpha1.Milvus{ObjectMeta: metav1.ObjectMeta{
+		Namespace:   "ns",
+		Name:        "r",
+		UID:         "uid",
+		Annotations: map[string]string{v1alpha1.AnnotationAdopt: "true"},
+	}}
+	mil.Default()
+
+	deploy := newHelmDeployment("r", "r-milvus-standalone", "standalone", "standalone", "sidecar")
+	deploy.Spec.Template.Spec.Containers[0].Resources.Limits = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")}
+	deploy.Spec.Template.Spec.Volumes = append(deploy.Spec.Template.Spec.Volumes, corev1.Volume{
+		Name: "persist",
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "r-milvus"},
+		},
+	})
+	svc := newHelmService("r-milvus", NewAppLabels("r"), 19530)
+	svc.Spec.Selector = deploy.Spec.Selector.MatchLabels
+	etcd := newHelmService("r-etcd", map[string]string{AppLabelInstance: "r", AppLabelName: "etcd"}, 2379)
+	etcdHeadless := newHelmService("r-etcd-headless", map[string]string{AppLabelInstance: "r", AppLabelName: "etcd"}, 2379)
+	etcdHeadless.Spec.ClusterIP = corev1.ClusterIPNone
+	minio := newHelmService("r-minio", map[string]string{HelmLabelApp: "minio", HelmLabelRelease: "r"}, 9000)
+	minioSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "r-minio"},
+		Data:       map[string][]byte{"accesskey": []byte("a"), "secretkey": []byte("s")},
+	}
+	// another release
+	other := newHelmDeployment("other", "other-milvus-standalone", "standalone", "standalone")
+
+	r, cli := newAdoptionTestEnv(t, mil, deploy, svc, etcd, etcdHeadless, minio, minioSecret, newHelmConfigMap("r"), other)
+	assert.NoError(t, r.Adopt(ctx, mil))
+
+	adopted := &v1alpha1.Milvus{}
+	assert.NoError(t, cli.Get(ctx, NamespacedName("ns", "r"), adopted))
+	assert.False(t, adopted.IsAdopting())
+	assert.Equal(t, v1alpha1.MilvusModeStandalone, adopted.Spec.Mode)
+	assert.Equal(t, "milvusdb/milvus:v2.0.0", adopted.Spec.Image)
+	assert.Equal(t, "2", adopted.Spec.Resources.Limits.Cpu().String())
+	assert.Equal(t, []string{"r-etcd.ns:2379"}, adopted.Spec.Dep.Etcd.Endpoints)
+	assert.True(t, adopted.Spec.Dep.Storage.External)
+	assert.Equal(t, "r-minio.ns:9000", adopted.Spec.Dep.Storage.Endpoint)
+	assert.Empty(t, adopted.Spec.Dep.Storage.SecretRef)
+	assert.Equal(t, "user-dev", adopted.Spec.Dep.Etcd.RootPath)
+	assert.False(t, adopted.Spec.Dep.Pulsar.External)
+
+	assert.True(t, IsAdopted(adopted.Status.Conditions))
+	cond := GetCondition(adopted.Status.Conditions, v1alpha1.Adopted)
+	assert.Equal(t, v1alpha1.ReasonAdopted, cond.Reason)
+	assert.Contains(t, cond.Message, "deployment r-milvus-standalone keeps the pods of the release until its component is changed, then it's replaced by r")
+	assert.Contains(t, cond.Message, "volume persist of deployment r-milvus-standalone")
+	assert.Contains(t, cond.Message, "container sidecar of deployment r-milvus-standalone")
+	assert.Contains(t, cond.Message, "secret r-minio without keys")
+
+	ownedDeploy := &appsv1.Deployment{}
+	assert.NoError(t, cli.Get(ctx, NamespacedName("ns", deploy.Name), ownedDeploy))
+	assert.True(t, metav1.IsControlledBy(ownedDeploy, adopted))
+	assert.Equal(t, "keep", ownedDeploy.Annotations[HelmAnnotationResourcePolicy])
+	assert.Contains(t, ownedDeploy.Annotations, AnnotationAdoptedPodTemplate)
+	ownedSvc := &corev1.Service{}
+	assert.NoError(t, cli.Get(ctx, NamespacedName("ns", svc.Name), ownedSvc))
+	assert.True(t, metav1.IsControlledBy(ownedSvc, adopted))
+	assert.Equal(t, deploy.Spec.Selector.MatchLabels, ownedSvc.Spec.Selector)
+	otherDeploy := &appsv1.Deployment{}
+	assert.NoError(t, cli.Get(ctx, NamespacedName("ns", other.Name), otherDeploy))
+	assert.Nil(t, metav1.GetControllerOf(otherDeploy))
+
+	// the standalone of the release is kept until the spec is changed
+	assert.NoError(t, r.ReplaceAdoptedDeployments(ctx, *adopted))
+	assert.NoError(t, cli.Get(ctx, NamespacedName("ns", deploy.Name), &appsv1.Deployment{}))
+	desired := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "r"}}
+	assert.NoError(t, r.updateDeployment(*adopted, desired, MilvusStandalone, ""))
+	for i := 0; i < 2; i++ {
+		replacing, err := r.ReplaceAdoptedDeployment(ctx, *adopted, MilvusStandalone, desired)
+		assert.NoError(t, err)
+		assert.False(t, replacing)
+	}
+	keptDeploy := &appsv1.Deployment{}
+	assert.NoError(t, cli.Get(ctx, NamespacedName("ns", deploy.Name), keptDeploy))
+	assert.NotEmpty(t, keptDeploy.Annotations[AnnotationAdoptedPodTemplate])
+	assert.Equal(t, int32(2), *keptDeploy.Spec.Replicas)
+
+	// it's scaled down before the replacement is created, the standalone is recreated on updates
+	adopted.Spec.Image = "milvusdb/milvus:v2.0.1"
+	desired = &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "r"}}
+	assert.NoError(t, r.updateDeployment(*adopted, desired, MilvusStandalone, ""))
+	replacing, err := r.ReplaceAdoptedDeployment(ctx, *adopted, MilvusStandalone, desired)
+	assert.NoError(t, err)
+	assert.False(t, replacing)
+	scaledDeploy := &appsv1.Deployment{}
+	assert.NoError(t, cli.Get(ctx, NamespacedName("ns", deploy.Name), scaledDeploy))
+	assert.NotContains(t, scaledDeploy.Annotations, AnnotationAdoptedPodTemplate)
+	assert.Equal(t, int32(0), *scaledDeploy.Spec.Replicas)
+	replacing, err = r.ReplaceAdoptedDeployment(ctx, *adopted, MilvusStandalone, desired)
+	assert.NoError(t, err)
+	assert.True(t, replacing)
+
+	// it's deleted once the replacement is available
+	assert.NoError(t, cli.Create(ctx, desired))
+	assert.NoError(t, r.ReplaceAdoptedDeployments(ctx, *adopted))
+	assert.NoError(t, cli.Get(ctx, NamespacedName("ns", deploy.Name), &appsv1.Deployment{}))
+	desired.Status = appsv1.DeploymentStatus{
+		Replicas:          1,
+		UpdatedReplicas:   1,
+		AvailableReplicas: 1,
+		Conditions:        []appsv1.DeploymentCondition{{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue}},
+	}
+	assert.NoError(t, cli.Update(ctx, desired))
+	assert.NoError(t, r.ReplaceAdoptedDeployments(ctx, *adopted))
+	err = cli.Get(ctx, NamespacedName("ns", deploy.Name), &appsv1.Deployment{})
+	assert.True(t, k8sErrors.IsNotFound(err))
+	switchedSvc := &corev1.Service{}
+	assert.NoError(t, cli.Get(ctx, NamespacedName("ns", svc.Name), switchedSvc))
+	assert.Equal(t, NewComponentAppLabels("r", MilvusName), switchedSvc.Spec.Selector)
+	assert.NoError(t, cli.Get(ctx, NamespacedName("ns", other.Name), &appsv1.Deployment{}))
+}
+
+func TestMilvusReconciler_Adopt_InPlace(t *testing.T) {
+	ctx := context.Background()
+	mil := &v1alpha1.Milvus{ObjectMeta: metav1.ObjectMeta{
+		Namespace:   "ns",
+		Name:        "r",
+		UID:         "uid",
+		Annotations: map[string]string{v1alpha1.AnnotationAdopt: "true"},
+	}}
+	mil.Default()
+	objs := []client.Object{mil, newHelmConfigMap("r")}
+	for _, component := range MilvusComponents {
+		objs = append(objs, newHelmDeployment("r", "r-milvus-"+component.Name, component.Name, component.Name))
+	}
+	r, cli := newAdoptionTestEnv(t, objs...)
+	assert.NoError(t, r.Adopt(ctx, mil))
+
+	adopted := &v1alpha1.Milvus{}
+	assert.NoError(t, cli.Get(ctx, NamespacedName("ns", "r"), adopted))
+	assert.True(t, adopted.Spec.IsCluster())
+	assert.Equal(t, "external-pulsar:6650", adopted.Spec.Dep.Pulsar.Endpoint)
+	cond := GetCondition(adopted.Status.Conditions, v1alpha1.Adopted)
+	assert.Equal(t, v1alpha1.ReasonAdopted, cond.Reason)
+	assert.NotContains(t, cond.Message, "replaced")
+	assert.Contains(t, cond.Message, "deployment r-milvus-proxy keeps the pods of the release until its component is changed")
+
+	// kept in place
+	assert.NoError(t, r.ReplaceAdoptedDeployments(ctx, *adopted))
+	deploy := &appsv1.Deployment{}
+	assert.NoError(t, cli.Get(ctx, NamespacedName("ns", "r-milvus-proxy"), deploy))
+
+	// with the selector kept
+	helmSelector := deploy.Spec.Selector.MatchLabels
+	updated := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "r-milvus-proxy"}}
+	updated.Spec.Selector = &metav1.LabelSelector{MatchLabels: helmSelector}
+	assert.NoError(t, r.updateDeployment(*adopted, updated, Proxy, ""))
+	assert.Equal(t, helmSelector, updated.Spec.Selector.MatchLabels)
+	assert.Equal(t, MergeLabels(helmSelector, NewComponentAppLabels("r", ProxyName)), updated.Spec.Template.Labels)
+
+	// with the pod template of the release kept until the one of the operator is changed
+	for i := 0; i < 2; i++ {
+		kept := updated.DeepCopy()
+		assert.NoError(t, keepAdoptedPodTemplate(deploy, kept))
+		assert.Equal(t, deploy.Spec.Template, kept.Spec.Template)
+		deploy.Annotations[AnnotationAdoptedPodTemplate] = kept.Annotations[AnnotationAdoptedPodTemplate]
+	}
+	assert.NotEmpty(t, deploy.Annotations[AnnotationAdoptedPodTemplate])
+	adopted.Spec.Com.Proxy.Image = "milvusdb/milvus:v2.0.1"
+	assert.NoError(t, r.updateDeployment(*adopted, updated, Proxy, ""))
+	rolled := updated.DeepCopy()
+	assert.NoError(t, keepAdoptedPodTemplate(deploy, rolled))
+	assert.Equal(t, updated.Spec.Template, rolled.Spec.Template)
+	assert.NotContains(t, rolled.Annotations, AnnotationAdoptedPodTemplate)
+}
+
+func TestMilvusReconciler_Adopt_Failed(t *testing.T) {
+	ctx := context.Background()
+	mil := &v1alpha1.Milvus{ObjectMeta: metav1.ObjectMeta{
+		Namespace:   "ns",
+		Name:        "r",
+		Annotations: map[string]string{v1alpha1.AnnotationAdopt: "true"},
+	}}
+	mil.Default()
+	r, cli := newAdoptionTestEnv(t, mil)
+	assert.NoError(t, r.Adopt(ctx, mil))
+
+	failed := &v1alpha1.Milvus{}
+	assert.NoError(t, cli.Get(ctx, NamespacedName("ns", "r"), failed))
+	assert.True(t, failed.IsAdopting())
+	assert.False(t, IsAdopted(failed.Status.Conditions))
+	cond := GetCondition(failed.Status.Conditions, v1alpha1.Adopted)
+	assert.Equal(t, v1alpha1.ReasonAdoptionFailed, cond.Reason)
+}
+
+func TestGetAdoptedCondition(t *testing.T) {
+	cond := GetAdoptedCondition("r", nil, nil, nil)
+	assert.Equal(t, corev1.ConditionTrue, cond.Status)
+	assert.Equal(t, v1alpha1.ReasonAdopted, cond.Reason)
+	assert.Equal(t, "release r adopted", cond.Message)
+
+	cond = GetAdoptedCondition("r", nil, []string{"a", "b"}, nil)
+	assert.Equal(t, "release r adopted, not mapped: a; b", cond.Message)
+
+	cond = GetAdoptedCondition("r", []string{"c"}, []string{"a"}, nil)
+	assert.Equal(t, v1alpha1.ReasonAdopted, cond.Reason)
+	assert.Equal(t, "release r adopted, pending rollouts: c, not mapped: a", cond.Message)
+
+	cond = GetAdoptedCondition("r", nil, nil, errors.New("failed"))
+	assert.Equal(t, corev1.ConditionFalse, cond.Status)
+	assert.Equal(t, "failed", cond.Message)
+}
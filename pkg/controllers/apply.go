@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/structured-merge-diff/v4/fieldpath"
+)
+
+const (
+	// FieldManager is the field manager of the child resources applied by the operator
+	FieldManager = "milvus-operator"
+	// legacyFieldManager is the field manager of the fields updated by the operator before it applied
+	// the child resources, the API server names it after the executable in the user agent
+	legacyFieldManager = "manager"
+)
+
+// ReplicasPath is the path of the replicas of a deployment or a statefulset
+var ReplicasPath = fieldpath.MakePathOrDie("spec", "replicas")
+
+// ApplyObject applies the desired state of a child resource with server-side apply,
+// only the fields set in @obj are owned by the operator, the ones added by others are kept
+func ApplyObject(ctx context.Context, cli client.Client, scheme *runtime.Scheme, obj client.Object) error {
+	gvk, err := apiutil.GVKForObject(obj, scheme)
+	if err != nil {
+		return err
+	}
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+
+	if err := cli.Patch(ctx, obj, client.Apply, client.FieldOwner(FieldManager), client.ForceOwnership); err != nil {
+		return err
+	}
+	return UpgradeManagedFields(ctx, cli, obj)
+}
+
+// UpgradeManagedFields moves the fields of the legacy field manager of the live object @obj to the apply manager
+// of the operator, like kubectl does when it switches to server-side apply. Otherwise the fields updated before the
+// operator applied the object stay owned by the legacy manager, and are never removed once they're no longer applied.
+// The @released fields are no longer owned by the operator, so they're kept when they're no longer applied,
+// e.g. the replicas scaled by an HPA. It's a no-op once the managed fields are upgraded
+func UpgradeManagedFields(ctx context.Context, cli client.Client, obj client.Object, released ...fieldpath.Path) error {
+	entries, changed, err := upgradeManagedFields(obj.GetManagedFields(), released...)
+	if err != nil || !changed {
+		return err
+	}
+
+	patch := client.MergeFromWithOptions(obj.DeepCopyObject().(client.Object), client.MergeFromWithOptimisticLock{})
+	obj.SetManagedFields(entries)
+	return cli.Patch(ctx, obj, patch)
+}
+
+// isOperatorFieldManager returns whether the fields of the entry are set by the operator
+func isOperatorFieldManager(entry metav1.ManagedFieldsEntry) bool {
+	return entry.Manager == FieldManager && entry.Operation == metav1.ManagedFieldsOperationApply ||
+		entry.Manager == legacyFieldManager && entry.Operation == metav1.ManagedFieldsOperationUpdate
+}
+
+// upgradeManagedFields returns the managed fields with the ones of the operator merged into the entry of its
+// apply manager without the @released fields, and whether they're changed
+func upgradeManagedFields(entries []metav1.ManagedFieldsEntry, released ...fieldpath.Path) ([]metav1.ManagedFieldsEntry, bool, error) {
+	releasedSet := fieldpath.NewSet(released...)
+	owned := &fieldpath.Set{}
+	upgraded := []metav1.ManagedFieldsEntry{}
+	var applied *metav1.ManagedFieldsEntry
+	changed := false
+	for i := range entries {
+		entry := entries[i]
+		if !isOperatorFieldManager(entry) {
+			upgraded = append(upgraded, entry)
+			continue
+		}
+		set := &fieldpath.Set{}
+		if entry.FieldsV1 != nil {
+			if err := set.FromJSON(bytes.NewReader(entry.FieldsV1.Raw)); err != nil {
+				return nil, false, err
+			}
+		}
+		if entry.Manager == legacyFieldManager || !set.Intersection(releasedSet).Empty() {
+			changed = true
+		}
+		owned = owned.Union(set)
+		if applied == nil || entry.Operation == metav1.ManagedFieldsOperationApply {
+			applied = &entry
+		}
+	}
+	if !changed {
+		return entries, false, nil
+	}
+
+	owned = owned.Difference(releasedSet)
+	if owned.Empty() {
+		return upgraded, true, nil
+	}
+	raw, err := owned.ToJSON()
+	if err != nil {
+		return nil, false, err
+	}
+	now := metav1.Now()
+	upgraded = append(upgraded, metav1.ManagedFieldsEntry{
+		Manager:    FieldManager,
+		Operation:  metav1.ManagedFieldsOperationApply,
+		APIVersion: applied.APIVersion,
+		Time:       &now,
+		FieldsType: "FieldsV1",
+		FieldsV1:   &metav1.FieldsV1{Raw: raw},
+	})
+	return upgraded, true, nil
+}
@@ -0,0 +1,120 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/structured-merge-diff/v4/fieldpath"
+)
+
+func TestApplyObject(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockClient := NewMockK8sClient(ctrl)
+	ctx := context.Background()
+	scheme := newSchemeForTest()
+
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "mc"},
+	}
+	mockClient.EXPECT().
+		Patch(gomock.Any(), deploy, client.Apply, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+			patchOpts := &client.PatchOptions{}
+			patchOpts.ApplyOptions(opts)
+			assert.Equal(t, FieldManager, patchOpts.FieldManager)
+			assert.True(t, *patchOpts.Force)
+			return nil
+		})
+	err := ApplyObject(ctx, mockClient, scheme, deploy)
+	assert.NoError(t, err)
+	assert.Equal(t, "apps/v1", deploy.APIVersion)
+	assert.Equal(t, "Deployment", deploy.Kind)
+
+	// apply failed
+	mockClient.EXPECT().
+		Patch(gomock.Any(), deploy, client.Apply, gomock.Any(), gomock.Any()).
+		Return(errors.New("some network issue"))
+	err = ApplyObject(ctx, mockClient, scheme, deploy)
+	assert.Error(t, err)
+
+	// kind not registered
+	err = ApplyObject(ctx, mockClient, runtime.NewScheme(), deploy)
+	assert.Error(t, err)
+}
+
+func newManagedFieldsEntry(manager string, operation metav1.ManagedFieldsOperationType, fields string) metav1.ManagedFieldsEntry {
+	return metav1.ManagedFieldsEntry{
+		Manager:    manager,
+		Operation:  operation,
+		APIVersion: "apps/v1",
+		FieldsType: "FieldsV1",
+		FieldsV1:   &metav1.FieldsV1{Raw: []byte(fields)},
+	}
+}
+
+func getManagedFieldsSet(t *testing.T, entry metav1.ManagedFieldsEntry) *fieldpath.Set {
+	set := &fieldpath.Set{}
+	assert.NoError(t, set.FromJSON(bytes.NewReader(entry.FieldsV1.Raw)))
+	return set
+}
+
+func TestUpgradeManagedFields(t *testing.T) {
+	labelPath := fieldpath.MakePathOrDie("metadata", "labels", "a")
+	templatePath := fieldpath.MakePathOrDie("spec", "template")
+	hpa := newManagedFieldsEntry("kube-controller-manager", metav1.ManagedFieldsOperationUpdate, `{"f:status":{}}`)
+	entries := []metav1.ManagedFieldsEntry{
+		newManagedFieldsEntry(legacyFieldManager, metav1.ManagedFieldsOperationUpdate, `{"f:metadata":{"f:labels":{"f:a":{}}},"f:spec":{"f:replicas":{}}}`),
+		hpa,
+		newManagedFieldsEntry(FieldManager, metav1.ManagedFieldsOperationApply, `{"f:spec":{"f:template":{}}}`),
+	}
+
+	// the legacy fields moved to the apply manager
+	upgraded, changed, err := upgradeManagedFields(entries)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.Len(t, upgraded, 2)
+	assert.Equal(t, hpa, upgraded[0])
+	assert.Equal(t, FieldManager, upgraded[1].Manager)
+	assert.Equal(t, metav1.ManagedFieldsOperationApply, upgraded[1].Operation)
+	assert.Equal(t, "apps/v1", upgraded[1].APIVersion)
+	set := getManagedFieldsSet(t, upgraded[1])
+	assert.True(t, set.Has(labelPath))
+	assert.True(t, set.Has(templatePath))
+	assert.True(t, set.Has(ReplicasPath))
+
+	// upgraded once
+	_, changed, err = upgradeManagedFields(upgraded)
+	assert.NoError(t, err)
+	assert.False(t, changed)
+
+	// the replicas released
+	released, changed, err := upgradeManagedFields(upgraded, ReplicasPath)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	set = getManagedFieldsSet(t, released[1])
+	assert.True(t, set.Has(labelPath))
+	assert.False(t, set.Has(ReplicasPath))
+	_, changed, err = upgradeManagedFields(released, ReplicasPath)
+	assert.NoError(t, err)
+	assert.False(t, changed)
+
+	// created by server-side apply
+	_, changed, err = upgradeManagedFields([]metav1.ManagedFieldsEntry{hpa})
+	assert.NoError(t, err)
+	assert.False(t, changed)
+
+	// bad fields
+	_, _, err = upgradeManagedFields([]metav1.ManagedFieldsEntry{
+		newManagedFieldsEntry(legacyFieldManager, metav1.ManagedFieldsOperationUpdate, `bad`),
+	})
+	assert.Error(t, err)
+}
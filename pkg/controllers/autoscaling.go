@@ -0,0 +1,40 @@
+package controllers
+
+import (
+	"context"
+
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// IsScaledByHPA returns whether a HorizontalPodAutoscaler targets the workload of @kind
+func IsScaledByHPA(ctx context.Context, cli client.Client, kind string, workload client.Object) (bool, error) {
+	hpas := &autoscalingv1.HorizontalPodAutoscalerList{}
+	if err := cli.List(ctx, hpas, client.InNamespace(workload.GetNamespace())); err != nil {
+		return false, err
+	}
+	for _, hpa := range hpas.Items {
+		ref := hpa.Spec.ScaleTargetRef
+		if ref.Kind == kind && ref.Name == workload.GetName() {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// omitScaledReplicas leaves the replicas of the live workload @existing to its HPA, or to the workload itself if they're
+// not set in the spec. The operator releases its ownership of them before they're no longer applied,
+// otherwise the apply resets them to the default
+func omitScaledReplicas(ctx context.Context, cli client.Client, kind string, existing client.Object, replicas **int32) error {
+	scaled, err := IsScaledByHPA(ctx, cli, kind, existing)
+	if err != nil {
+		return err
+	}
+	if scaled {
+		*replicas = nil
+	}
+	if *replicas != nil {
+		return nil
+	}
+	return UpgradeManagedFields(ctx, cli, existing, ReplicasPath)
+}
@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/structured-merge-diff/v4/fieldpath"
+)
+
+func TestOmitScaledReplicas(t *testing.T) {
+	ctx := context.Background()
+	deploy := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+		Namespace: "ns",
+		Name:      "mc-milvus-querynode",
+		ManagedFields: []metav1.ManagedFieldsEntry{
+			newManagedFieldsEntry(FieldManager, metav1.ManagedFieldsOperationApply, `{"f:spec":{"f:replicas":{},"f:template":{}}}`),
+		},
+	}}
+	hpa := &autoscalingv1.HorizontalPodAutoscaler{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "querynode"}}
+	hpa.Spec.ScaleTargetRef = autoscalingv1.CrossVersionObjectReference{Kind: "Deployment", Name: "mc-milvus-querynode"}
+	cli := fake.NewClientBuilder().WithScheme(newSchemeForTest()).WithObjects(deploy).Build()
+
+	// set in the spec
+	replicas := int32Ptr(2)
+	scaled, err := IsScaledByHPA(ctx, cli, "Deployment", deploy)
+	assert.NoError(t, err)
+	assert.False(t, scaled)
+	assert.NoError(t, omitScaledReplicas(ctx, cli, "Deployment", deploy, &replicas))
+	assert.Equal(t, int32(2), *replicas)
+	live := &appsv1.Deployment{}
+	assert.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(deploy), live))
+	assert.True(t, getManagedFieldsSet(t, live.ManagedFields[0]).Has(ReplicasPath))
+
+	// scaled by the HPA of another workload
+	other := hpa.DeepCopy()
+	other.Name = "other"
+	other.Spec.ScaleTargetRef.Kind = "StatefulSet"
+	assert.NoError(t, cli.Create(ctx, other))
+	scaled, err = IsScaledByHPA(ctx, cli, "Deployment", deploy)
+	assert.NoError(t, err)
+	assert.False(t, scaled)
+
+	// scaled by the HPA, the replicas are released
+	assert.NoError(t, cli.Create(ctx, hpa))
+	scaled, err = IsScaledByHPA(ctx, cli, "Deployment", deploy)
+	assert.NoError(t, err)
+	assert.True(t, scaled)
+	assert.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(deploy), deploy))
+	assert.NoError(t, omitScaledReplicas(ctx, cli, "Deployment", deploy, &replicas))
+	assert.Nil(t, replicas)
+	assert.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(deploy), live))
+	assert.Len(t, live.ManagedFields, 1)
+	set := getManagedFieldsSet(t, live.ManagedFields[0])
+	assert.False(t, set.Has(ReplicasPath))
+	assert.True(t, set.Has(fieldpath.MakePathOrDie("spec", "template")))
+}
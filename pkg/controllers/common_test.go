@@ -8,9 +8,11 @@ import (
 	"github.com/milvus-io/milvus-operator/apis/milvus.io/v1alpha1"
 	"github.com/milvus-io/milvus-operator/pkg/config"
 	"github.com/milvus-io/milvus-operator/pkg/util"
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	"helm.sh/helm/v3/pkg/cli"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrlRuntime "sigs.k8s.io/controller-runtime"
 )
 
@@ -50,6 +52,15 @@ func newMilvusTestEnv(t *testing.T) *milvusTestEnv {
 	}
 }
 
+// newClusterModeTestEnv returns the env of a Milvus in cluster mode
+func newClusterModeTestEnv(t *testing.T) *milvusTestEnv {
+	env := newMilvusTestEnv(t)
+	env.Inst.Name = "mc"
+	env.Inst.Spec.Mode = v1alpha1.MilvusModeCluster
+	env.Inst.Spec.Dep.Pulsar.InCluster = new(v1alpha1.InClusterConfig)
+	return env
+}
+
 type clusterTestEnv struct {
 	MockClient *MockK8sClient
 	Ctrl       *gomock.Controller
@@ -87,19 +98,23 @@ func newClusterTestEnv(t *testing.T) *clusterTestEnv {
 	}
 }
 
+func newSchemeForTest() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	clientgoscheme.AddToScheme(scheme)
+	v1alpha1.AddToScheme(scheme)
+	monitoringv1.AddToScheme(scheme)
+	return scheme
+}
+
 func newClusterReconcilerForTest(ctrl *gomock.Controller) *MilvusClusterReconciler {
 	mockClient := NewMockK8sClient(ctrl)
 
 	logger := ctrlRuntime.Log.WithName("test")
-	scheme := runtime.NewScheme()
-	v1alpha1.AddToScheme(scheme)
-	helmSetting := cli.New()
-	helm := NewLocalHelmReconciler(helmSetting, logger)
+	scheme := newSchemeForTest()
 	r := MilvusClusterReconciler{
-		Client:         mockClient,
-		logger:         logger,
-		Scheme:         scheme,
-		helmReconciler: helm,
+		Client: mockClient,
+		logger: logger,
+		Scheme: scheme,
 	}
 	return &r
 }
@@ -108,8 +123,7 @@ func newMilvusReconcilerForTest(ctrl *gomock.Controller) *MilvusReconciler {
 	mockClient := NewMockK8sClient(ctrl)
 
 	logger := ctrlRuntime.Log.WithName("test")
-	scheme := runtime.NewScheme()
-	v1alpha1.AddToScheme(scheme)
+	scheme := newSchemeForTest()
 	helmSetting := cli.New()
 	helm := NewLocalHelmReconciler(helmSetting, logger)
 	r := MilvusReconciler{
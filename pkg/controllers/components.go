@@ -3,7 +3,9 @@ package controllers
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"reflect"
+	"strconv"
 	"strings"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -49,6 +51,14 @@ const (
 	DataNodePort   = 21124
 	ProxyPort      = 19530
 	MilvusPort     = ProxyPort
+
+	// DefaultStopTimeoutSeconds is the default seconds a node is given to exit after it's signaled to stop
+	DefaultStopTimeoutSeconds = 30
+	// DefaultPreStopSeconds is the default seconds the preStop hook waits for the pod to be removed from the endpoints
+	DefaultPreStopSeconds = 5
+
+	// ResourceGroupEnv is the env of the milvus server label which assigns the node to the resource group
+	ResourceGroupEnv = "MILVUS_SERVER_LABEL_RESOURCE_GROUP"
 )
 
 // MilvusComponent contains basic info of a milvus cluster component
@@ -56,21 +66,23 @@ type MilvusComponent struct {
 	Name        string
 	FieldName   string
 	DefaultPort int32
+	// Group is the name of the group of the component deployed separately, it's empty for the component itself
+	Group string
 }
 
 // define MilvusComponents
 var (
-	RootCoord  = MilvusComponent{RootCoordName, RootCoordFieldName, RootCoordPort}
-	DataCoord  = MilvusComponent{DataCoordName, DataCoordFieldName, DataCoordPort}
-	QueryCoord = MilvusComponent{QueryCoordName, QueryCoordFieldName, QueryCoordPort}
-	IndexCoord = MilvusComponent{IndexCoordName, IndexCoordFieldName, IndexCoordPort}
-	DataNode   = MilvusComponent{DataNodeName, DataNodeFieldName, DataNodePort}
-	QueryNode  = MilvusComponent{QueryNodeName, QueryNodeFieldName, QueryNodePort}
-	IndexNode  = MilvusComponent{IndexNodeName, IndexNodeFieldName, IndexNodePort}
-	Proxy      = MilvusComponent{ProxyName, ProxyFieldName, ProxyPort}
+	RootCoord  = MilvusComponent{RootCoordName, RootCoordFieldName, RootCoordPort, ""}
+	DataCoord  = MilvusComponent{DataCoordName, DataCoordFieldName, DataCoordPort, ""}
+	QueryCoord = MilvusComponent{QueryCoordName, QueryCoordFieldName, QueryCoordPort, ""}
+	IndexCoord = MilvusComponent{IndexCoordName, IndexCoordFieldName, IndexCoordPort, ""}
+	DataNode   = MilvusComponent{DataNodeName, DataNodeFieldName, DataNodePort, ""}
+	QueryNode  = MilvusComponent{QueryNodeName, QueryNodeFieldName, QueryNodePort, ""}
+	IndexNode  = MilvusComponent{IndexNodeName, IndexNodeFieldName, IndexNodePort, ""}
+	Proxy      = MilvusComponent{ProxyName, ProxyFieldName, ProxyPort, ""}
 
 	// Milvus standalone
-	MilvusStandalone = MilvusComponent{MilvusName, "", MilvusPort}
+	MilvusStandalone = MilvusComponent{MilvusName, "", MilvusPort, ""}
 
 	MilvusComponents = []MilvusComponent{
 		RootCoord, DataCoord, QueryCoord, IndexCoord, DataNode, QueryNode, IndexNode, Proxy,
@@ -79,6 +91,16 @@ var (
 	MilvusCoords = []MilvusComponent{
 		RootCoord, DataCoord, QueryCoord, IndexCoord,
 	}
+
+	// componentConsumedSections lists the config sections of other components a coord reads besides its own
+	componentConsumedSections = map[string][]MilvusComponent{
+		DataCoordName:  {DataNode},
+		QueryCoordName: {QueryNode},
+		IndexCoordName: {IndexNode},
+	}
+
+	// ComponentConnectionConfKeys are the keys of a component config section which others need to reach it
+	ComponentConnectionConfKeys = []string{"address", "port", "grpc"}
 )
 
 // IsCoord return if it's a coord by its name
@@ -91,8 +113,81 @@ func (c MilvusComponent) IsNode() bool {
 	return strings.HasSuffix(c.Name, "node")
 }
 
+// GetComponentsBySpec returns the components deployed in the mode of the spec, including the groups of the components
+func GetComponentsBySpec(spec v1alpha1.MilvusSpec) []MilvusComponent {
+	if !spec.IsCluster() {
+		return []MilvusComponent{MilvusStandalone}
+	}
+	ret := append([]MilvusComponent{}, MilvusComponents...)
+	for _, component := range MilvusComponents {
+		ret = append(ret, component.GetGroups(spec)...)
+	}
+	return ret
+}
+
+// GetGroups returns the groups of the component in spec, each is deployed separately
+func (c MilvusComponent) GetGroups(spec v1alpha1.MilvusSpec) []MilvusComponent {
+	ret := []MilvusComponent{}
+	for _, group := range c.getGroups(spec) {
+		component := c
+		component.Group = group.Name
+		ret = append(ret, component)
+	}
+	return ret
+}
+
+// getGroups returns the groups of the component in spec.components, it's empty if the component has none
+func (c MilvusComponent) getGroups(spec v1alpha1.MilvusSpec) []v1alpha1.ComponentGroup {
+	if c.FieldName == "" {
+		return nil
+	}
+	field := reflect.ValueOf(spec.Com).FieldByName(c.FieldName).FieldByName("Groups")
+	if !field.IsValid() {
+		return nil
+	}
+	groups, _ := field.Interface().([]v1alpha1.ComponentGroup)
+	return groups
+}
+
+// GetStatefulSetSpec returns the spec of the statefulset of the component, it's nil if it runs as a deployment
+func (c MilvusComponent) GetStatefulSetSpec(spec v1alpha1.MilvusSpec) *v1alpha1.StatefulSetSpec {
+	if c.FieldName == "" {
+		return nil
+	}
+	field := reflect.ValueOf(spec.Com).FieldByName(c.FieldName).FieldByName("StatefulSet")
+	if !field.IsValid() {
+		return nil
+	}
+	statefulSet, _ := field.Interface().(*v1alpha1.StatefulSetSpec)
+	return statefulSet
+}
+
+// IsActiveStandby returns whether the component is a coordinator running in active-standby mode
+func (c MilvusComponent) IsActiveStandby(spec v1alpha1.MilvusSpec) bool {
+	if !c.IsCoord() || !spec.IsCluster() {
+		return false
+	}
+	field := reflect.ValueOf(spec.Com).FieldByName(c.FieldName).FieldByName("ActiveStandby")
+	if !field.IsValid() {
+		return false
+	}
+	return field.Bool()
+}
+
+// GetServingComponent returns the component serving the clients, the proxy in cluster mode
+func GetServingComponent(spec v1alpha1.MilvusSpec) MilvusComponent {
+	if spec.IsCluster() {
+		return Proxy
+	}
+	return MilvusStandalone
+}
+
 // GetEnv returns the environment variables for the component
-func (c MilvusComponent) GetEnv(spec v1alpha1.MilvusClusterSpec) []corev1.EnvVar {
+func (c MilvusComponent) GetEnv(spec v1alpha1.MilvusSpec) []corev1.EnvVar {
+	if c == MilvusStandalone {
+		return spec.Env
+	}
+
 	env := c.GetComponentSpec(spec).Env
 	env = append(env, corev1.EnvVar{
 		Name: "CACHE_SIZE",
@@ -103,83 +198,188 @@ func (c MilvusComponent) GetEnv(spec v1alpha1.MilvusClusterSpec) []corev1.EnvVar
 			},
 		},
 	})
+	if c.Group != "" {
+		env = append(env, corev1.EnvVar{Name: ResourceGroupEnv, Value: c.Group})
+	}
 
-	return MergeEnvVar(spec.Com.Env, env)
+	return MergeEnvVar(spec.Env, env)
 }
 
 // GetImagePullSecrets returns the image pull secrets for the component
-func (c MilvusComponent) GetImagePullSecrets(spec v1alpha1.MilvusClusterSpec) []corev1.LocalObjectReference {
+func (c MilvusComponent) GetImagePullSecrets(spec v1alpha1.MilvusSpec) []corev1.LocalObjectReference {
 	pullSecrets := c.GetComponentSpec(spec).ImagePullSecrets
 	if len(pullSecrets) > 0 {
 		return pullSecrets
 	}
-	return spec.Com.ImagePullSecrets
+	return spec.ImagePullSecrets
 }
 
 // GetImagePullPolicy returns the image pull policy for the component
-func (c MilvusComponent) GetImagePullPolicy(spec v1alpha1.MilvusClusterSpec) corev1.PullPolicy {
+func (c MilvusComponent) GetImagePullPolicy(spec v1alpha1.MilvusSpec) corev1.PullPolicy {
 	pullPolicy := c.GetComponentSpec(spec).ImagePullPolicy
 	if pullPolicy != nil {
 		return *pullPolicy
 	}
 
-	if spec.Com.ImagePullPolicy != nil {
-		return *spec.Com.ImagePullPolicy
+	if spec.ImagePullPolicy != nil {
+		return *spec.ImagePullPolicy
 	}
 	return corev1.PullIfNotPresent
 }
 
 // GetTolerations returns the tolerations for the component
-func (c MilvusComponent) GetTolerations(spec v1alpha1.MilvusClusterSpec) []corev1.Toleration {
+func (c MilvusComponent) GetTolerations(spec v1alpha1.MilvusSpec) []corev1.Toleration {
 	tolerations := c.GetComponentSpec(spec).Tolerations
 	if len(tolerations) > 0 {
 		return tolerations
 	}
 
-	return spec.Com.Tolerations
+	return spec.Tolerations
 }
 
 // GetNodeSelector returns the node selector for the component
-func (c MilvusComponent) GetNodeSelector(spec v1alpha1.MilvusClusterSpec) map[string]string {
+func (c MilvusComponent) GetNodeSelector(spec v1alpha1.MilvusSpec) map[string]string {
 	nodeSelector := c.GetComponentSpec(spec).NodeSelector
 	if nodeSelector != nil {
 		return nodeSelector
 	}
 
-	return spec.Com.NodeSelector
+	return spec.NodeSelector
 }
 
 // GetResources returns the corev1.ResourceRequirements for the component
-func (c MilvusComponent) GetResources(spec v1alpha1.MilvusClusterSpec) corev1.ResourceRequirements {
+func (c MilvusComponent) GetResources(spec v1alpha1.MilvusSpec) corev1.ResourceRequirements {
 	resources := c.GetComponentSpec(spec).Resources
 	if c.GetComponentSpec(spec).Resources != nil {
 		return *resources
 	}
 
-	if spec.Com.Resources != nil {
-		return *spec.Com.Resources
+	if spec.Resources != nil {
+		return *spec.Resources
 	}
 
 	return corev1.ResourceRequirements{}
 }
 
 // GetImage returns the image for the component
-func (c MilvusComponent) GetImage(spec v1alpha1.MilvusClusterSpec) string {
+func (c MilvusComponent) GetImage(spec v1alpha1.MilvusSpec) string {
 	componentImage := c.GetComponentSpec(spec).Image
 	if len(componentImage) > 0 {
 		return componentImage
 	}
 
-	return spec.Com.Image
+	return spec.Image
 }
 
 // GetReplicas returns the replicas for the component
-func (c MilvusComponent) GetReplicas(spec v1alpha1.MilvusClusterSpec) *int32 {
-	replicas, _ := reflect.ValueOf(spec.Com).
+func (c MilvusComponent) GetReplicas(spec v1alpha1.MilvusSpec) *int32 {
+	if c == MilvusStandalone {
+		return int32Ptr(1)
+	}
+	return c.getComponent(spec).Replicas
+}
+
+// getComponent returns the component in spec.components, it's empty for the standalone.
+// The fields set in the group override the ones of the component
+func (c MilvusComponent) getComponent(spec v1alpha1.MilvusSpec) v1alpha1.Component {
+	if c.FieldName == "" {
+		return v1alpha1.Component{}
+	}
+	component, _ := reflect.ValueOf(spec.Com).
 		FieldByName(c.FieldName).
-		FieldByName("Component").
-		FieldByName("Replicas").Interface().(*int32)
-	return replicas
+		FieldByName("Component").Interface().(v1alpha1.Component)
+	if c.Group == "" {
+		return component
+	}
+
+	for _, group := range c.getGroups(spec) {
+		if group.Name != c.Group {
+			continue
+		}
+		if group.Replicas != nil {
+			component.Replicas = group.Replicas
+		}
+		if group.NodeSelector != nil {
+			component.NodeSelector = group.NodeSelector
+		}
+		if len(group.Tolerations) > 0 {
+			component.Tolerations = group.Tolerations
+		}
+		if group.Resources != nil {
+			component.Resources = group.Resources
+		}
+	}
+	return component
+}
+
+// servesBehindService returns if the clients connect to the component through its service
+func (c MilvusComponent) servesBehindService() bool {
+	return c.Name == ProxyName
+}
+
+// getStopTimeoutSeconds returns the seconds the node is given to exit after it's signaled to stop,
+// it defaults to the gracefulStopTimeout in seconds of the component section or the common section in @conf
+func (c MilvusComponent) getStopTimeoutSeconds(conf map[string]interface{}, gracefulShutdown v1alpha1.GracefulShutdown) int64 {
+	if gracefulShutdown.StopTimeoutSeconds != nil {
+		return *gracefulShutdown.StopTimeoutSeconds
+	}
+
+	for _, section := range []string{c.GetConfSection(), "common"} {
+		stopTimeout, ok := util.GetNumberValue(conf, section, "gracefulStopTimeout")
+		if ok && stopTimeout > 0 {
+			return int64(math.Ceil(stopTimeout))
+		}
+	}
+	return DefaultStopTimeoutSeconds
+}
+
+// getPreStopSeconds returns the seconds the preStop hook waits for the pod to be removed from the endpoints,
+// it defaults to the gracefulTime in milliseconds of the component section in @conf
+func (c MilvusComponent) getPreStopSeconds(conf map[string]interface{}, gracefulShutdown v1alpha1.GracefulShutdown) int64 {
+	if gracefulShutdown.GracefulTimeSeconds != nil {
+		return *gracefulShutdown.GracefulTimeSeconds
+	}
+
+	gracefulTime, ok := util.GetNumberValue(conf, c.GetConfSection(), "gracefulTime")
+	if ok && gracefulTime > 0 {
+		return int64(math.Ceil(gracefulTime / 1000))
+	}
+	return DefaultPreStopSeconds
+}
+
+// GetLifecycle returns the lifecycle of the component container from its rendered @conf.
+// Only the proxy behind the service gets a preStop hook, which keeps it serving
+// until it's removed from the endpoints. The others hand off their work once signaled to stop
+func (c MilvusComponent) GetLifecycle(spec v1alpha1.MilvusSpec, conf map[string]interface{}) *corev1.Lifecycle {
+	gracefulShutdown := c.getComponent(spec).GracefulShutdown
+	if gracefulShutdown == nil || !c.servesBehindService() {
+		return nil
+	}
+
+	preStop := c.getPreStopSeconds(conf, *gracefulShutdown)
+	return &corev1.Lifecycle{
+		PreStop: &corev1.Handler{
+			Exec: &corev1.ExecAction{
+				Command: []string{"sleep", strconv.FormatInt(preStop, 10)},
+			},
+		},
+	}
+}
+
+// GetTerminationGracePeriodSeconds returns the seconds the pod is killed after from the rendered @conf,
+// which covers both the preStop hook and the stop timeout.
+// It's nil for the default of kubernetes if the component doesn't stop gracefully
+func (c MilvusComponent) GetTerminationGracePeriodSeconds(spec v1alpha1.MilvusSpec, conf map[string]interface{}) *int64 {
+	gracefulShutdown := c.getComponent(spec).GracefulShutdown
+	if gracefulShutdown == nil {
+		return nil
+	}
+
+	gracePeriod := c.getStopTimeoutSeconds(conf, *gracefulShutdown)
+	if c.servesBehindService() {
+		gracePeriod += c.getPreStopSeconds(conf, *gracefulShutdown)
+	}
+	return &gracePeriod
 }
 
 // String returns the name of the component
@@ -189,16 +389,34 @@ func (c MilvusComponent) String() string {
 
 // GetInstanceName returns the name of the component instance
 func (c MilvusComponent) GetInstanceName(instance string) string {
+	if c.Group != "" {
+		return fmt.Sprintf("%s-milvus-%s-%s", instance, c.Name, c.Group)
+	}
 	return fmt.Sprintf("%s-milvus-%s", instance, c.Name)
 }
 
+// GetComponentLabel returns the value of the component label, the groups have their own
+// so that the selectors of the deployments don't overlap
+func (c MilvusComponent) GetComponentLabel() string {
+	if c.Group != "" {
+		return c.Name + "-" + c.Group
+	}
+	return c.Name
+}
+
 // GetDeploymentInstanceName returns the name of the component deployment
 func (c MilvusComponent) GetDeploymentInstanceName(instance string) string {
+	if c == MilvusStandalone {
+		return instance
+	}
 	return c.GetInstanceName(instance)
 }
 
 // GetServiceInstanceName returns the name of the component service
 func (c MilvusComponent) GetServiceInstanceName(instance string) string {
+	if c == MilvusStandalone {
+		return instance
+	}
 	if c == Proxy {
 		return instance + "-milvus"
 	}
@@ -210,8 +428,16 @@ func (c MilvusComponent) GetContainerName() string {
 	return c.Name
 }
 
+// GetRunRole returns the role the component runs as by `milvus run`
+func (c MilvusComponent) GetRunRole() string {
+	if c == MilvusStandalone {
+		return "standalone"
+	}
+	return c.Name
+}
+
 // GetContainerPorts returns the ports of the component container
-func (c MilvusComponent) GetContainerPorts(spec v1alpha1.MilvusClusterSpec) []corev1.ContainerPort {
+func (c MilvusComponent) GetContainerPorts(spec v1alpha1.MilvusSpec) []corev1.ContainerPort {
 	return []corev1.ContainerPort{
 		{
 			Name:          c.String(),
@@ -227,16 +453,16 @@ func (c MilvusComponent) GetContainerPorts(spec v1alpha1.MilvusClusterSpec) []co
 }
 
 // GetServiceType returns the type of the component service
-func (c MilvusComponent) GetServiceType(spec v1alpha1.MilvusClusterSpec) corev1.ServiceType {
-	if c != Proxy {
+func (c MilvusComponent) GetServiceType(spec v1alpha1.MilvusSpec) corev1.ServiceType {
+	if c != Proxy && c != MilvusStandalone {
 		return corev1.ServiceTypeClusterIP
 	}
 
-	return spec.Com.Proxy.ServiceType
+	return spec.ServiceType
 }
 
 // GetServicePorts returns the ports of the component service
-func (c MilvusComponent) GetServicePorts(spec v1alpha1.MilvusClusterSpec) []corev1.ServicePort {
+func (c MilvusComponent) GetServicePorts(spec v1alpha1.MilvusSpec) []corev1.ServicePort {
 	servicePorts := []corev1.ServicePort{}
 	if !c.IsNode() {
 		servicePorts = append(servicePorts, corev1.ServicePort{
@@ -257,12 +483,8 @@ func (c MilvusComponent) GetServicePorts(spec v1alpha1.MilvusClusterSpec) []core
 }
 
 // GetComponentPort returns the port of the component
-func (c MilvusComponent) GetComponentPort(spec v1alpha1.MilvusClusterSpec) int32 {
-	port, _ := reflect.ValueOf(spec.Com).
-		FieldByName(c.FieldName).
-		FieldByName("Component").
-		FieldByName("Port").Interface().(int32)
-
+func (c MilvusComponent) GetComponentPort(spec v1alpha1.MilvusSpec) int32 {
+	port := c.getComponent(spec).Port
 	if port != 0 {
 		return port
 	}
@@ -271,19 +493,101 @@ func (c MilvusComponent) GetComponentPort(spec v1alpha1.MilvusClusterSpec) int32
 }
 
 // GetComponentSpec returns the component spec
-func (c MilvusComponent) GetComponentSpec(spec v1alpha1.MilvusClusterSpec) v1alpha1.ComponentSpec {
-	value := reflect.ValueOf(spec.Com).FieldByName(c.FieldName).FieldByName("ComponentSpec")
-	comSpec, _ := value.Interface().(v1alpha1.ComponentSpec)
-	return comSpec
+func (c MilvusComponent) GetComponentSpec(spec v1alpha1.MilvusSpec) v1alpha1.ComponentSpec {
+	return c.getComponent(spec).ComponentSpec
+}
+
+// GetComponentConf returns the config overrides scoped to the component
+func (c MilvusComponent) GetComponentConf(spec v1alpha1.MilvusSpec) map[string]interface{} {
+	return c.getComponent(spec).Conf.Data
+}
+
+// GetConfSection returns the name of the component's section in milvus config
+func (c MilvusComponent) GetConfSection() string {
+	return strings.ToLower(c.FieldName[:1]) + c.FieldName[1:]
 }
 
-// GetConfCheckSum returns the checksum of the component configuration
-func GetConfCheckSum(spec v1alpha1.MilvusClusterSpec) string {
+// GetConfigMapKey returns the key of the component's rendered config in the configmap
+func (c MilvusComponent) GetConfigMapKey() string {
+	if c == MilvusStandalone {
+		return MilvusConfigYaml
+	}
+	return c.Name + ".yaml"
+}
+
+// consumesSection returns if the component reads the whole config section of @other
+func (c MilvusComponent) consumesSection(other MilvusComponent) bool {
+	if c.Name == other.Name {
+		return true
+	}
+	for _, consumed := range componentConsumedSections[c.Name] {
+		if consumed.Name == other.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterComponentConf returns the part of @conf the component consumes:
+// shared sections, its own section, and the connection keys of other components.
+// The standalone consumes the whole config
+func (c MilvusComponent) FilterComponentConf(conf map[string]interface{}) map[string]interface{} {
+	if c == MilvusStandalone {
+		return conf
+	}
+
+	componentSections := map[string]MilvusComponent{}
+	for _, com := range MilvusComponents {
+		componentSections[com.GetConfSection()] = com
+	}
+
+	ret := map[string]interface{}{}
+	for k, v := range conf {
+		other, isComponentSection := componentSections[k]
+		if !isComponentSection || c.consumesSection(other) {
+			ret[k] = v
+			continue
+		}
+
+		section, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		connection := map[string]interface{}{}
+		for _, key := range ComponentConnectionConfKeys {
+			if value, exist := section[key]; exist {
+				connection[key] = value
+			}
+		}
+		if len(connection) > 0 {
+			ret[k] = connection
+		}
+	}
+	return ret
+}
+
+// GetComponentConfCheckSum returns the checksum of the configuration the component consumes
+func GetComponentConfCheckSum(spec v1alpha1.MilvusSpec, component MilvusComponent) string {
+	if component == MilvusStandalone {
+		return GetMilvusConfCheckSum(spec)
+	}
+
+	ports := map[string]int32{}
+	for _, c := range MilvusComponents {
+		ports[c.Name] = c.GetComponentPort(spec)
+	}
+
 	conf := map[string]interface{}{}
-	conf["conf"] = spec.Conf.Data
+	conf["conf"] = component.FilterComponentConf(spec.Conf.Data)
+	conf["component-conf"] = component.GetComponentConf(spec)
+	conf["ports"] = ports
 	conf["etcd-endpoints"] = spec.Dep.Etcd.Endpoints
 	conf["pulsar-endpoint"] = spec.Dep.Pulsar.Endpoint
 	conf["storage-endpoint"] = spec.Dep.Storage.Endpoint
+	// only set when enabled, so that the checksums of the existing instances keep unchanged
+	if component.IsActiveStandby(spec) {
+		conf["active-standby"] = true
+	}
 
 	b, err := json.Marshal(conf)
 	if err != nil {
@@ -342,8 +646,14 @@ func GetReadinessProbe() *corev1.Probe {
 	}
 }
 
-func (c MilvusComponent) GetDeploymentStrategy() appsv1.DeploymentStrategy {
-	if c.IsCoord() {
+// GetDeploymentStrategy returns the strategy of the component deployment, the one in spec overrides the default.
+// The coordinators are recreated by default unless they run in active-standby mode, where the standby takes over
+func (c MilvusComponent) GetDeploymentStrategy(spec v1alpha1.MilvusSpec) appsv1.DeploymentStrategy {
+	if strategy := c.getComponent(spec).Strategy; strategy != nil {
+		return *strategy
+	}
+
+	if (c.IsCoord() && !c.IsActiveStandby(spec)) || c == MilvusStandalone {
 		return appsv1.DeploymentStrategy{
 			Type: appsv1.RecreateDeploymentStrategyType,
 		}
@@ -21,9 +21,98 @@ func TestMilvusComponent_IsNode(t *testing.T) {
 	assert.True(t, QueryNode.IsNode())
 }
 
+func TestGetComponentsBySpec(t *testing.T) {
+	spec := v1alpha1.MilvusSpec{}
+	assert.Equal(t, []MilvusComponent{MilvusStandalone}, GetComponentsBySpec(spec))
+	assert.Equal(t, MilvusStandalone, GetServingComponent(spec))
+
+	spec.Mode = v1alpha1.MilvusModeCluster
+	assert.Equal(t, MilvusComponents, GetComponentsBySpec(spec))
+	assert.Equal(t, Proxy, GetServingComponent(spec))
+
+	spec.Com.QueryNode.Groups = []v1alpha1.ComponentGroup{{Name: "hot"}}
+	spec.Com.IndexNode.Groups = []v1alpha1.ComponentGroup{{Name: "batch"}}
+	components := GetComponentsBySpec(spec)
+	assert.Len(t, components, len(MilvusComponents)+2)
+	assert.Equal(t, MilvusComponents, components[:len(MilvusComponents)])
+	assert.Equal(t, "hot", components[len(MilvusComponents)].Group)
+	assert.Equal(t, QueryNodeName, components[len(MilvusComponents)].Name)
+	assert.Equal(t, "batch", components[len(MilvusComponents)+1].Group)
+	assert.Equal(t, IndexNodeName, components[len(MilvusComponents)+1].Name)
+	assert.Len(t, MilvusComponents, 8)
+}
+
+func TestMilvusComponent_Groups(t *testing.T) {
+	spec := v1alpha1.MilvusSpec{Mode: v1alpha1.MilvusModeCluster}
+	replicas := int32(2)
+	groupReplicas := int32(3)
+	spec.Com.QueryNode.Replicas = &replicas
+	spec.Com.QueryNode.Image = "image"
+	spec.Com.QueryNode.NodeSelector = map[string]string{"a": "b"}
+	spec.Com.QueryNode.Conf.Data = map[string]interface{}{"k": "v"}
+	spec.Com.QueryNode.Groups = []v1alpha1.ComponentGroup{
+		{
+			Name:         "hot",
+			Replicas:     &groupReplicas,
+			NodeSelector: map[string]string{"memory": "high"},
+			Tolerations:  []corev1.Toleration{{Key: "memory"}},
+			Resources: &corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("64Gi")},
+			},
+		},
+		{Name: "batch"},
+	}
+	assert.Empty(t, DataNode.GetGroups(spec))
+	groups := QueryNode.GetGroups(spec)
+	assert.Len(t, groups, 2)
+	hot, batch := groups[0], groups[1]
+
+	assert.Equal(t, "mc-milvus-querynode-hot", hot.GetDeploymentInstanceName("mc"))
+	assert.Equal(t, "querynode-hot", hot.GetComponentLabel())
+	assert.Equal(t, QueryNodeName, QueryNode.GetComponentLabel())
+	assert.Equal(t, QueryNodeName, hot.GetContainerName())
+	assert.Equal(t, QueryNode.GetConfigMapKey(), hot.GetConfigMapKey())
+	assert.Equal(t, GetComponentConfCheckSum(spec, QueryNode), GetComponentConfCheckSum(spec, hot))
+
+	// overridden by the group
+	assert.Equal(t, groupReplicas, *hot.GetReplicas(spec))
+	assert.Equal(t, map[string]string{"memory": "high"}, hot.GetNodeSelector(spec))
+	assert.Equal(t, []corev1.Toleration{{Key: "memory"}}, hot.GetTolerations(spec))
+	assert.Equal(t, resource.MustParse("64Gi"), hot.GetResources(spec).Limits[corev1.ResourceMemory])
+	assert.Contains(t, hot.GetEnv(spec), corev1.EnvVar{Name: ResourceGroupEnv, Value: "hot"})
+	assert.NotContains(t, QueryNode.GetEnv(spec), corev1.EnvVar{Name: ResourceGroupEnv, Value: ""})
+
+	// inherited from the component
+	assert.Equal(t, replicas, *batch.GetReplicas(spec))
+	assert.Equal(t, map[string]string{"a": "b"}, batch.GetNodeSelector(spec))
+	assert.Equal(t, "image", batch.GetImage(spec))
+	assert.Equal(t, spec.Com.QueryNode.Conf.Data, batch.GetComponentConf(spec))
+}
+
+func TestMilvusComponent_Standalone(t *testing.T) {
+	spec := v1alpha1.MilvusSpec{}
+	spec.Env = []corev1.EnvVar{{Name: "a"}}
+	spec.Image = "a"
+	com := MilvusStandalone
+	assert.Equal(t, spec.Env, com.GetEnv(spec))
+	assert.Equal(t, "a", com.GetImage(spec))
+	assert.Equal(t, int32(1), *com.GetReplicas(spec))
+	assert.Equal(t, int32(MilvusPort), com.GetComponentPort(spec))
+	assert.Nil(t, com.GetComponentConf(spec))
+	assert.Equal(t, "inst1", com.GetDeploymentInstanceName("inst1"))
+	assert.Equal(t, "inst1", com.GetServiceInstanceName("inst1"))
+	assert.Equal(t, "standalone", com.GetRunRole())
+	assert.Equal(t, MilvusConfigYaml, com.GetConfigMapKey())
+	assert.Equal(t, appsv1.RecreateDeploymentStrategyType, com.GetDeploymentStrategy(v1alpha1.MilvusSpec{}).Type)
+	assert.Equal(t, GetMilvusConfCheckSum(spec), GetComponentConfCheckSum(spec, com))
+
+	conf := map[string]interface{}{"queryNode": map[string]interface{}{"cacheSize": 32}}
+	assert.Equal(t, conf, com.FilterComponentConf(conf))
+}
+
 func TestMilvusComponent_GetEnv(t *testing.T) {
-	spec := v1alpha1.MilvusClusterSpec{}
-	spec.Com.ComponentSpec.Env = []corev1.EnvVar{
+	spec := v1alpha1.MilvusSpec{}
+	spec.ComponentSpec.Env = []corev1.EnvVar{
 		{Name: "a"},
 	}
 	spec.Com.QueryNode.Component.ComponentSpec.Env = []corev1.EnvVar{
@@ -40,8 +129,8 @@ func TestMilvusComponent_GetImagePullSecrets(t *testing.T) {
 	globalSecrets := []corev1.LocalObjectReference{
 		{Name: "a"},
 	}
-	spec := v1alpha1.MilvusClusterSpec{}
-	spec.Com.ComponentSpec.ImagePullSecrets = globalSecrets
+	spec := v1alpha1.MilvusSpec{}
+	spec.ComponentSpec.ImagePullSecrets = globalSecrets
 	com := QueryNode
 	secrets := com.GetImagePullSecrets(spec)
 	assert.Equal(t, 1, len(secrets))
@@ -61,14 +150,14 @@ func TestMilvusComponent_GetImagePullSecrets(t *testing.T) {
 func TestMilvusComponent_GetImagePullPolicy(t *testing.T) {
 	// not set in both global & specific, use default
 
-	spec := v1alpha1.MilvusClusterSpec{}
+	spec := v1alpha1.MilvusSpec{}
 	com := QueryNode
 	policy := com.GetImagePullPolicy(spec)
 	assert.Equal(t, corev1.PullIfNotPresent, policy)
 
 	// has only global, use global
 	globalPullPolicy := corev1.PullAlways
-	spec.Com.ComponentSpec.ImagePullPolicy = &globalPullPolicy
+	spec.ComponentSpec.ImagePullPolicy = &globalPullPolicy
 	com = QueryNode
 	policy = com.GetImagePullPolicy(spec)
 	assert.Equal(t, globalPullPolicy, policy)
@@ -86,11 +175,9 @@ func TestMilvusComponent_GetTolerations(t *testing.T) {
 	globalTolerations := []corev1.Toleration{
 		{Key: "a"},
 	}
-	spec := v1alpha1.MilvusClusterSpec{
-		Com: v1alpha1.MilvusComponents{
-			ComponentSpec: v1alpha1.ComponentSpec{
-				Tolerations: globalTolerations,
-			},
+	spec := v1alpha1.MilvusSpec{
+		ComponentSpec: v1alpha1.ComponentSpec{
+			Tolerations: globalTolerations,
 		},
 	}
 	com := QueryNode
@@ -109,8 +196,8 @@ func TestMilvusComponent_GetNodeSelector(t *testing.T) {
 	globalNodeSelector := map[string]string{
 		"a": "b",
 	}
-	spec := v1alpha1.MilvusClusterSpec{}
-	spec.Com.ComponentSpec.NodeSelector = globalNodeSelector
+	spec := v1alpha1.MilvusSpec{}
+	spec.ComponentSpec.NodeSelector = globalNodeSelector
 	com := QueryNode
 	assert.Equal(t, globalNodeSelector, com.GetNodeSelector(spec))
 
@@ -124,7 +211,7 @@ func TestMilvusComponent_GetNodeSelector(t *testing.T) {
 
 func TestMilvusComponent_GetResources(t *testing.T) {
 	// not set, return empty
-	spec := v1alpha1.MilvusClusterSpec{}
+	spec := v1alpha1.MilvusSpec{}
 	com := QueryNode
 	assert.Equal(t, corev1.ResourceRequirements{}, com.GetResources(spec))
 
@@ -134,7 +221,7 @@ func TestMilvusComponent_GetResources(t *testing.T) {
 			"cpu": resource.MustParse("100m"),
 		},
 	}
-	spec.Com.ComponentSpec.Resources = &globalResources
+	spec.ComponentSpec.Resources = &globalResources
 	assert.Equal(t, globalResources, com.GetResources(spec))
 
 	// has specific, use specific
@@ -149,8 +236,8 @@ func TestMilvusComponent_GetResources(t *testing.T) {
 
 func TestMilvusComponent_GetImage(t *testing.T) {
 	// has global, use global
-	spec := v1alpha1.MilvusClusterSpec{}
-	spec.Com.ComponentSpec.Image = "a"
+	spec := v1alpha1.MilvusSpec{}
+	spec.ComponentSpec.Image = "a"
 	com := QueryNode
 	assert.Equal(t, "a", com.GetImage(spec))
 
@@ -161,7 +248,7 @@ func TestMilvusComponent_GetImage(t *testing.T) {
 
 func TestMilvusComponent_GetReplicas(t *testing.T) {
 	// has global, use global
-	spec := v1alpha1.MilvusClusterSpec{}
+	spec := v1alpha1.MilvusSpec{}
 	com := QueryNode
 	replica := int32(1)
 	spec.Com.QueryNode.Component.Replicas = &replica
@@ -193,7 +280,7 @@ func TestMilvusComponent_GetContainerName(t *testing.T) {
 
 func TestMilvusComponent_GetContainerPorts(t *testing.T) {
 	com := QueryNode
-	spec := v1alpha1.MilvusClusterSpec{}
+	spec := v1alpha1.MilvusSpec{}
 	spec.Com.QueryNode.Component.Port = 8080
 	ports := com.GetContainerPorts(spec)
 	assert.Equal(t, 2, len(ports))
@@ -203,17 +290,18 @@ func TestMilvusComponent_GetContainerPorts(t *testing.T) {
 
 func TestMilvusComponent_GetServiceType(t *testing.T) {
 	com := QueryNode
-	spec := v1alpha1.MilvusClusterSpec{}
+	spec := v1alpha1.MilvusSpec{}
 	assert.Equal(t, corev1.ServiceTypeClusterIP, com.GetServiceType(spec))
 
 	com = Proxy
-	spec.Com.Proxy.ServiceType = corev1.ServiceTypeNodePort
+	spec.ServiceType = corev1.ServiceTypeNodePort
 	assert.Equal(t, corev1.ServiceTypeNodePort, com.GetServiceType(spec))
+	assert.Equal(t, corev1.ServiceTypeNodePort, MilvusStandalone.GetServiceType(spec))
 }
 
 func TestMilvusComponent_GetServicePorts(t *testing.T) {
 	com := QueryNode
-	spec := v1alpha1.MilvusClusterSpec{}
+	spec := v1alpha1.MilvusSpec{}
 	ports := com.GetServicePorts(spec)
 	assert.Equal(t, 1, len(ports))
 	assert.Equal(t, int32(MetricPort), ports[0].Port)
@@ -228,7 +316,7 @@ func TestMilvusComponent_GetServicePorts(t *testing.T) {
 
 func TestMilvusComponent_GetComponentPort(t *testing.T) {
 	com := QueryNode
-	spec := v1alpha1.MilvusClusterSpec{}
+	spec := v1alpha1.MilvusSpec{}
 	assert.Equal(t, com.DefaultPort, com.GetComponentPort(spec))
 
 	spec.Com.QueryNode.Component.Port = 8080
@@ -236,22 +324,76 @@ func TestMilvusComponent_GetComponentPort(t *testing.T) {
 }
 
 func TestMilvusComponent_GetComponentSpec(t *testing.T) {
-	spec := v1alpha1.MilvusClusterSpec{}
+	spec := v1alpha1.MilvusSpec{}
 	spec.Com.QueryNode.Component.ComponentSpec.Image = "a"
 	com := QueryNode
 	assert.Equal(t, "a", com.GetComponentSpec(spec).Image)
 }
 
-func TestMilvusComponent_GetConfCheckSum(t *testing.T) {
-	spec := v1alpha1.MilvusClusterSpec{}
-	checksum1 := GetConfCheckSum(spec)
+func TestMilvusComponent_GetComponentConf(t *testing.T) {
+	spec := v1alpha1.MilvusSpec{}
+	assert.Nil(t, QueryNode.GetComponentConf(spec))
+
+	spec.Com.QueryNode.Conf.Data = map[string]interface{}{"k": "v"}
+	assert.Equal(t, "v", QueryNode.GetComponentConf(spec)["k"])
+	assert.Nil(t, DataNode.GetComponentConf(spec))
+}
+
+func TestMilvusComponent_GetConfSection(t *testing.T) {
+	assert.Equal(t, "queryNode", QueryNode.GetConfSection())
+	assert.Equal(t, "rootCoord", RootCoord.GetConfSection())
+	assert.Equal(t, "proxy", Proxy.GetConfSection())
+}
+
+func TestMilvusComponent_GetConfigMapKey(t *testing.T) {
+	assert.Equal(t, "querynode.yaml", QueryNode.GetConfigMapKey())
+}
+
+func TestMilvusComponent_FilterComponentConf(t *testing.T) {
+	conf := map[string]interface{}{
+		"etcd": map[string]interface{}{"rootPath": "mc"},
+		"queryNode": map[string]interface{}{
+			"port":      21123,
+			"cacheSize": 32,
+		},
+		"dataNode": map[string]interface{}{
+			"port": 21124,
+		},
+		"queryCoord": map[string]interface{}{
+			"address":     "mc-milvus-querycoord",
+			"autoHandoff": true,
+		},
+	}
+
+	// own section & shared sections kept, others reduced to connection keys
+	filtered := QueryNode.FilterComponentConf(conf)
+	assert.Equal(t, conf["etcd"], filtered["etcd"])
+	assert.Equal(t, conf["queryNode"], filtered["queryNode"])
+	assert.Equal(t, map[string]interface{}{"port": 21124}, filtered["dataNode"])
+	assert.Equal(t, map[string]interface{}{"address": "mc-milvus-querycoord"}, filtered["queryCoord"])
+
+	// coord consumes its nodes' section
+	filtered = QueryCoord.FilterComponentConf(conf)
+	assert.Equal(t, conf["queryNode"], filtered["queryNode"])
+	assert.Equal(t, conf["queryCoord"], filtered["queryCoord"])
+
+	// section without connection keys dropped
+	conf["dataNode"] = map[string]interface{}{"flush": 1}
+	filtered = QueryNode.FilterComponentConf(conf)
+	_, exist := filtered["dataNode"]
+	assert.False(t, exist)
+}
+
+func TestMilvusComponent_GetComponentConfCheckSum(t *testing.T) {
+	spec := v1alpha1.MilvusSpec{}
+	checksum1 := GetComponentConfCheckSum(spec, QueryNode)
 
 	spec.Conf.Data = map[string]interface{}{
 		"k1": "v1",
 		"k2": "v2",
 		"k3": "v3",
 	}
-	checksum2 := GetConfCheckSum(spec)
+	checksum2 := GetComponentConfCheckSum(spec, QueryNode)
 	assert.NotEqual(t, checksum1, checksum2)
 
 	spec.Conf.Data = map[string]interface{}{
@@ -259,8 +401,24 @@ func TestMilvusComponent_GetConfCheckSum(t *testing.T) {
 		"k2": "v2",
 		"k1": "v1",
 	}
-	checksum3 := GetConfCheckSum(spec)
+	checksum3 := GetComponentConfCheckSum(spec, QueryNode)
 	assert.Equal(t, checksum2, checksum3)
+
+	// other component's section only changes its checksum
+	proxyChecksum := GetComponentConfCheckSum(spec, Proxy)
+	queryCoordChecksum := GetComponentConfCheckSum(spec, QueryCoord)
+	spec.Conf.Data["queryNode"] = map[string]interface{}{"cacheSize": 16}
+	assert.NotEqual(t, checksum3, GetComponentConfCheckSum(spec, QueryNode))
+	assert.NotEqual(t, queryCoordChecksum, GetComponentConfCheckSum(spec, QueryCoord))
+	assert.Equal(t, proxyChecksum, GetComponentConfCheckSum(spec, Proxy))
+
+	// component scoped conf
+	spec.Com.Proxy.Conf.Data = map[string]interface{}{"proxy": map[string]interface{}{"maxTaskNum": 10}}
+	assert.NotEqual(t, proxyChecksum, GetComponentConfCheckSum(spec, Proxy))
+
+	// port changes all
+	spec.Com.Proxy.Port = 1
+	assert.NotEqual(t, queryCoordChecksum, GetComponentConfCheckSum(spec, QueryCoord))
 }
 
 func TestMilvusComponent_GetMilvusConfCheckSumt(t *testing.T) {
@@ -297,11 +455,88 @@ func TestMilvusComponent_GetLivenessProbe_GetReadinessProbe(t *testing.T) {
 
 func TestMilvusComponent_GetDeploymentStrategy(t *testing.T) {
 	com := QueryNode
-	strategy := com.GetDeploymentStrategy()
+	strategy := com.GetDeploymentStrategy(v1alpha1.MilvusSpec{})
 	assert.Equal(t, appsv1.RollingUpdateDeploymentStrategyType, strategy.Type)
 	assert.Equal(t, intstr.FromInt(0), *strategy.RollingUpdate.MaxUnavailable)
 	assert.Equal(t, intstr.FromInt(1), *strategy.RollingUpdate.MaxSurge)
 
 	com = DataCoord
-	assert.Equal(t, appsv1.RecreateDeploymentStrategyType, com.GetDeploymentStrategy().Type)
+	assert.Equal(t, appsv1.RecreateDeploymentStrategyType, com.GetDeploymentStrategy(v1alpha1.MilvusSpec{}).Type)
+
+	// overridden
+	spec := v1alpha1.MilvusSpec{Mode: v1alpha1.MilvusModeCluster}
+	maxUnavailable := intstr.FromString("25%")
+	spec.Com.QueryNode.Strategy = &appsv1.DeploymentStrategy{
+		Type:          appsv1.RollingUpdateDeploymentStrategyType,
+		RollingUpdate: &appsv1.RollingUpdateDeployment{MaxUnavailable: &maxUnavailable},
+	}
+	assert.Equal(t, *spec.Com.QueryNode.Strategy, QueryNode.GetDeploymentStrategy(spec))
+	assert.Equal(t, appsv1.RollingUpdateDeploymentStrategyType, DataNode.GetDeploymentStrategy(spec).Type)
+	assert.Equal(t, intstr.FromInt(1), *DataNode.GetDeploymentStrategy(spec).RollingUpdate.MaxSurge)
+}
+
+func TestMilvusComponent_ActiveStandby(t *testing.T) {
+	spec := v1alpha1.MilvusSpec{Mode: v1alpha1.MilvusModeCluster}
+	rootCoordChecksum := GetComponentConfCheckSum(spec, RootCoord)
+	dataCoordChecksum := GetComponentConfCheckSum(spec, DataCoord)
+
+	spec.Com.RootCoord.ActiveStandby = true
+	assert.True(t, RootCoord.IsActiveStandby(spec))
+	assert.False(t, DataCoord.IsActiveStandby(spec))
+	assert.False(t, QueryNode.IsActiveStandby(spec))
+	assert.False(t, RootCoord.IsActiveStandby(v1alpha1.MilvusSpec{Com: spec.Com}))
+
+	strategy := RootCoord.GetDeploymentStrategy(spec)
+	assert.Equal(t, appsv1.RollingUpdateDeploymentStrategyType, strategy.Type)
+	assert.Equal(t, intstr.FromInt(0), *strategy.RollingUpdate.MaxUnavailable)
+	assert.Equal(t, intstr.FromInt(1), *strategy.RollingUpdate.MaxSurge)
+	assert.Equal(t, appsv1.RecreateDeploymentStrategyType, DataCoord.GetDeploymentStrategy(spec).Type)
+
+	// only the checksum of the coordinator in active-standby mode changes
+	assert.NotEqual(t, rootCoordChecksum, GetComponentConfCheckSum(spec, RootCoord))
+	assert.Equal(t, dataCoordChecksum, GetComponentConfCheckSum(spec, DataCoord))
+}
+
+func TestMilvusComponent_GracefulShutdown(t *testing.T) {
+	spec := v1alpha1.MilvusSpec{Mode: v1alpha1.MilvusModeCluster}
+	conf := map[string]interface{}{}
+	assert.Nil(t, QueryNode.GetLifecycle(spec, conf))
+	assert.Nil(t, QueryNode.GetTerminationGracePeriodSeconds(spec, conf))
+	assert.Nil(t, MilvusStandalone.GetLifecycle(spec, conf))
+
+	// the nodes not behind a service stop by the stop timeout without a preStop hook
+	spec.Com.QueryNode.GracefulShutdown = &v1alpha1.GracefulShutdown{}
+	assert.Nil(t, QueryNode.GetLifecycle(spec, conf))
+	assert.Equal(t, int64(DefaultStopTimeoutSeconds), *QueryNode.GetTerminationGracePeriodSeconds(spec, conf))
+
+	// the gracefulStopTimeout in the config, in seconds
+	conf = map[string]interface{}{
+		"common": map[string]interface{}{"gracefulStopTimeout": float64(120)},
+	}
+	assert.Equal(t, int64(120), *QueryNode.GetTerminationGracePeriodSeconds(spec, conf))
+	conf["queryNode"] = map[string]interface{}{"gracefulStopTimeout": float64(600)}
+	assert.Equal(t, int64(600), *QueryNode.GetTerminationGracePeriodSeconds(spec, conf))
+
+	// the seconds set
+	stopTimeout := int64(10)
+	spec.Com.QueryNode.GracefulShutdown.StopTimeoutSeconds = &stopTimeout
+	assert.Equal(t, int64(10), *QueryNode.GetTerminationGracePeriodSeconds(spec, conf))
+
+	// the proxy waits to be removed from the endpoints, by the gracefulTime in the config in milliseconds
+	spec.Com.Proxy.GracefulShutdown = &v1alpha1.GracefulShutdown{}
+	assert.Equal(t, []string{"sleep", "5"}, Proxy.GetLifecycle(spec, conf).PreStop.Exec.Command)
+	assert.Equal(t, int64(120+DefaultPreStopSeconds), *Proxy.GetTerminationGracePeriodSeconds(spec, conf))
+	conf["proxy"] = map[string]interface{}{"gracefulTime": float64(5500)}
+	assert.Equal(t, []string{"sleep", "6"}, Proxy.GetLifecycle(spec, conf).PreStop.Exec.Command)
+	assert.Equal(t, int64(126), *Proxy.GetTerminationGracePeriodSeconds(spec, conf))
+
+	gracefulTime := int64(20)
+	spec.Com.Proxy.GracefulShutdown.GracefulTimeSeconds = &gracefulTime
+	spec.Com.Proxy.GracefulShutdown.StopTimeoutSeconds = &stopTimeout
+	assert.Equal(t, []string{"sleep", "20"}, Proxy.GetLifecycle(spec, conf).PreStop.Exec.Command)
+	assert.Equal(t, int64(30), *Proxy.GetTerminationGracePeriodSeconds(spec, conf))
+
+	// inherited by the groups
+	spec.Com.QueryNode.Groups = []v1alpha1.ComponentGroup{{Name: "hot"}}
+	assert.Equal(t, int64(10), *QueryNode.GetGroups(spec)[0].GetTerminationGracePeriodSeconds(spec, conf))
 }
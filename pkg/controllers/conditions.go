@@ -26,9 +26,13 @@ import (
 var pulsarNewClient = pulsar.NewClient
 
 func GetPulsarCondition(ctx context.Context, logger logr.Logger, p v1alpha1.MilvusPulsar) (v1alpha1.MilvusCondition, error) {
+	endpoint, err := p.GetEndpoint()
+	if err != nil {
+		return newErrPulsarCondResult(v1alpha1.ReasonPulsarNotReady, err.Error()), nil
+	}
 
 	client, err := pulsarNewClient(pulsar.ClientOptions{
-		URL:               "pulsar://" + p.Endpoint,
+		URL:               endpoint.URL("pulsar"),
 		ConnectionTimeout: 2 * time.Second,
 		OperationTimeout:  3 * time.Second,
 		Logger:            newPulsarLog(logger),
@@ -60,8 +64,9 @@ func GetPulsarCondition(ctx context.Context, logger logr.Logger, p v1alpha1.Milv
 type StorageConditionInfo struct {
 	Namespace string
 	Storage   v1alpha1.MilvusStorage
-	EndPoint  string
-	UseSSL    bool
+	// EndPoint is the host:port of the storage
+	EndPoint string
+	UseSSL   bool
 }
 
 type NewMinioClientFunc func(endpoint string, accessKeyID, secretAccessKey string, secure bool) (MinioClient, error)
@@ -91,7 +96,7 @@ func GetMinioCondition(
 	}
 
 	mdmClnt, err := newMinioClientFunc(
-		info.Storage.Endpoint,
+		info.EndPoint,
 		string(accesskey), string(secretkey),
 		info.UseSSL,
 	)
@@ -240,8 +245,9 @@ func newErrPulsarCondResult(reason, message string) v1alpha1.MilvusCondition {
 
 // MilvusEndpointInfo info for calculate the endpoint
 type MilvusEndpointInfo struct {
-	Namespace   string
-	Name        string
+	Namespace string
+	// ServiceName is the name of the service of the serving component
+	ServiceName string
 	ServiceType corev1.ServiceType
 	Port        int32
 }
@@ -249,7 +255,7 @@ type MilvusEndpointInfo struct {
 func GetMilvusEndpoint(ctx context.Context, logger logr.Logger, client client.Client, info MilvusEndpointInfo) string {
 	if info.ServiceType == corev1.ServiceTypeLoadBalancer {
 		proxy := &corev1.Service{}
-		key := NamespacedName(info.Namespace, Proxy.GetServiceInstanceName(info.Name))
+		key := NamespacedName(info.Namespace, info.ServiceName)
 		if err := client.Get(ctx, key, proxy); err != nil {
 			logger.Error(err, "Get Milvus endpoint error")
 			return ""
@@ -262,7 +268,7 @@ func GetMilvusEndpoint(ctx context.Context, logger logr.Logger, client client.Cl
 	}
 
 	if info.ServiceType == corev1.ServiceTypeClusterIP {
-		return fmt.Sprintf("%s-milvus.%s:%d", info.Name, info.Namespace, info.Port)
+		return fmt.Sprintf("%s:%d", ServiceHost(info.ServiceName, info.Namespace), info.Port)
 	}
 
 	return ""
@@ -294,17 +300,32 @@ func GetMilvusInstanceCondition(ctx context.Context, cli client.Client, info Mil
 	if err := cli.List(ctx, deployments, opts); err != nil {
 		return v1alpha1.MilvusCondition{}, err
 	}
+	statefulSets := &appsv1.StatefulSetList{}
+	if err := cli.List(ctx, statefulSets, opts); err != nil {
+		return v1alpha1.MilvusCondition{}, err
+	}
 
 	ready := 0
 	notReadyComponents := []string{}
-	for _, deployment := range deployments.Items {
-		if metav1.IsControlledBy(&deployment, info.Object) {
-			if DeploymentReady(deployment) {
-				ready++
-			} else {
-				notReadyComponents = append(notReadyComponents, deployment.Labels[AppLabelComponent])
-			}
+	countReady := func(obj client.Object, isReady bool) {
+		// the standalone is being deleted after changed to cluster mode
+		if info.IsCluster && obj.GetLabels()[AppLabelComponent] == MilvusName {
+			return
 		}
+		if !metav1.IsControlledBy(obj, info.Object) {
+			return
+		}
+		if isReady {
+			ready++
+		} else {
+			notReadyComponents = append(notReadyComponents, obj.GetLabels()[AppLabelComponent])
+		}
+	}
+	for i := range deployments.Items {
+		countReady(&deployments.Items[i], DeploymentReady(deployments.Items[i]))
+	}
+	for i := range statefulSets.Items {
+		countReady(&statefulSets.Items[i], StatefulSetReady(statefulSets.Items[i]))
 	}
 
 	cond := v1alpha1.MilvusCondition{
@@ -316,7 +337,7 @@ func GetMilvusInstanceCondition(ctx context.Context, cli client.Client, info Mil
 		readyNeeded = len(MilvusComponents)
 	}
 
-	if ready >= readyNeeded {
+	if ready >= readyNeeded && len(notReadyComponents) == 0 {
 		cond.Status = corev1.ConditionTrue
 		cond.Reason = v1alpha1.ReasonMilvusClusterHealthy
 		cond.Message = MessageMilvusHealthy
@@ -329,3 +350,73 @@ func GetMilvusInstanceCondition(ctx context.Context, cli client.Client, info Mil
 
 	return cond, nil
 }
+
+// GetStalledCondition returns the Stalled condition by the error of the last reconcile
+func GetStalledCondition(reconcileErr error) v1alpha1.MilvusCondition {
+	if reconcileErr != nil {
+		return v1alpha1.MilvusCondition{
+			Type:    v1alpha1.Stalled,
+			Status:  corev1.ConditionTrue,
+			Reason:  v1alpha1.ReasonReconcileFailed,
+			Message: reconcileErr.Error(),
+		}
+	}
+	return v1alpha1.MilvusCondition{
+		Type:    v1alpha1.Stalled,
+		Status:  corev1.ConditionFalse,
+		Reason:  v1alpha1.ReasonReconcileSucceeded,
+		Message: MessageReconciled,
+	}
+}
+
+// GetRolloutNotCheckedCondition returns the MilvusReady condition once the workloads of a new generation are applied,
+// so that Ready is not reported by the MilvusReady of the previous generation. The status syncer replaces it
+// once the workloads observed the new generation and are updated and available
+func GetRolloutNotCheckedCondition(generation int64) v1alpha1.MilvusCondition {
+	return v1alpha1.MilvusCondition{
+		Type:    v1alpha1.MilvusReady,
+		Status:  corev1.ConditionFalse,
+		Reason:  v1alpha1.ReasonRolloutNotChecked,
+		Message: fmt.Sprintf("The workloads of generation %d are not checked yet", generation),
+	}
+}
+
+// GetKStatusConditions returns the Ready and Reconciling conditions following the kstatus conventions.
+// Ready is True only when the latest generation is reconciled without error and milvus is ready,
+// Reconciling is True while it's progressing towards that, and False once it's ready or stalled.
+func GetKStatusConditions(conditions []v1alpha1.MilvusCondition, generation, observedGeneration int64) []v1alpha1.MilvusCondition {
+	ready := v1alpha1.MilvusCondition{
+		Type:   v1alpha1.Ready,
+		Status: corev1.ConditionFalse,
+	}
+	reconciling := v1alpha1.MilvusCondition{
+		Type:   v1alpha1.Reconciling,
+		Status: corev1.ConditionTrue,
+	}
+
+	stalled := GetCondition(conditions, v1alpha1.Stalled)
+	milvusReady := GetCondition(conditions, v1alpha1.MilvusReady)
+	switch {
+	case stalled != nil && stalled.Status == corev1.ConditionTrue:
+		ready.Reason = stalled.Reason
+		ready.Message = stalled.Message
+		reconciling.Status = corev1.ConditionFalse
+	case observedGeneration != generation:
+		ready.Reason = v1alpha1.ReasonGenerationNotObserved
+		ready.Message = fmt.Sprintf("Generation %d is not reconciled yet", generation)
+	case milvusReady == nil:
+		ready.Reason = v1alpha1.ReasonMilvusComponentNotHealthy
+		ready.Message = MessageMilvusUnchecked
+	case milvusReady.Status != corev1.ConditionTrue:
+		ready.Reason = milvusReady.Reason
+		ready.Message = milvusReady.Message
+	default:
+		ready.Status = corev1.ConditionTrue
+		ready.Reason = milvusReady.Reason
+		ready.Message = milvusReady.Message
+		reconciling.Status = corev1.ConditionFalse
+	}
+	reconciling.Reason = ready.Reason
+	reconciling.Message = ready.Message
+	return []v1alpha1.MilvusCondition{ready, reconciling}
+}
@@ -35,13 +35,28 @@ func TestGetPulsarCondition(t *testing.T) {
 	logger := logf.Log.WithName("test")
 	mockPulsarNewClient := NewMockPulsarClient(ctrl)
 	errTest := errors.New("test")
+	p := v1alpha1.MilvusPulsar{Endpoint: "pulsar:6650"}
+
+	// invalid endpoint, no err
+	ret, err := GetPulsarCondition(ctx, logger, v1alpha1.MilvusPulsar{Endpoint: "http://pulsar:6650"})
+	assert.NoError(t, err)
+	assert.Equal(t, corev1.ConditionFalse, ret.Status)
+	assert.Equal(t, v1alpha1.ReasonPulsarNotReady, ret.Reason)
 
 	// new client failed, no err
-	pulsarNewClient = getMockPulsarNewClient(mockPulsarNewClient, errTest)
-	ret, err := GetPulsarCondition(ctx, logger, v1alpha1.MilvusPulsar{})
+	url := ""
+	pulsarNewClient = func(options pulsar.ClientOptions) (pulsar.Client, error) {
+		url = options.URL
+		return nil, errTest
+	}
+	ret, err = GetPulsarCondition(ctx, logger, p)
 	assert.NoError(t, err)
 	assert.Equal(t, corev1.ConditionFalse, ret.Status)
 	assert.Equal(t, v1alpha1.ReasonPulsarNotReady, ret.Reason)
+	assert.Equal(t, "pulsar://pulsar:6650", url)
+
+	_, _ = GetPulsarCondition(ctx, logger, v1alpha1.MilvusPulsar{Endpoint: "pulsar+ssl://[fd00::1]"})
+	assert.Equal(t, "pulsar+ssl://[fd00::1]:6651", url)
 
 	// new client ok, create read failed, no err
 	gomock.InOrder(
@@ -49,7 +64,7 @@ func TestGetPulsarCondition(t *testing.T) {
 		mockPulsarNewClient.EXPECT().Close(),
 	)
 	pulsarNewClient = getMockPulsarNewClient(mockPulsarNewClient, nil)
-	ret, err = GetPulsarCondition(ctx, logger, v1alpha1.MilvusPulsar{})
+	ret, err = GetPulsarCondition(ctx, logger, p)
 	assert.NoError(t, err)
 	assert.Equal(t, corev1.ConditionFalse, ret.Status)
 	assert.Equal(t, v1alpha1.ReasonPulsarNotReady, ret.Reason)
@@ -62,7 +77,7 @@ func TestGetPulsarCondition(t *testing.T) {
 		mockPulsarNewClient.EXPECT().Close(),
 	)
 	pulsarNewClient = getMockPulsarNewClient(mockPulsarNewClient, nil)
-	ret, err = GetPulsarCondition(ctx, logger, v1alpha1.MilvusPulsar{})
+	ret, err = GetPulsarCondition(ctx, logger, p)
 	assert.NoError(t, err)
 	assert.Equal(t, corev1.ConditionTrue, ret.Status)
 	assert.Equal(t, v1alpha1.ReasonPulsarReady, ret.Reason)
@@ -244,7 +259,7 @@ func TestGetMilvusEndpoint(t *testing.T) {
 	// nodePort empty return
 	info := MilvusEndpointInfo{
 		Namespace:   "ns",
-		Name:        "name",
+		ServiceName: "name-milvus",
 		ServiceType: corev1.ServiceTypeNodePort,
 		Port:        10086,
 	}
@@ -335,7 +350,10 @@ func TestGetMilvusInstanceCondition(t *testing.T) {
 			list.Items[0].Status.Conditions = []appsv1.DeploymentCondition{
 				{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue},
 			}
+			list.Items[0].Status.UpdatedReplicas = 1
+			list.Items[0].Status.AvailableReplicas = 1
 		})
+	mockClient.EXPECT().List(gomock.Any(), gomock.AssignableToTypeOf(&appsv1.StatefulSetList{}), gomock.Any())
 	ret, err = GetMilvusInstanceCondition(ctx, mockClient, info)
 	assert.NoError(t, err)
 	assert.Equal(t, corev1.ConditionTrue, ret.Status)
@@ -363,8 +381,11 @@ func TestGetMilvusInstanceCondition(t *testing.T) {
 				list.Items[i].Status.Conditions = []appsv1.DeploymentCondition{
 					{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue},
 				}
+				list.Items[i].Status.UpdatedReplicas = 1
+				list.Items[i].Status.AvailableReplicas = 1
 			}
 		})
+	mockClient.EXPECT().List(gomock.Any(), gomock.AssignableToTypeOf(&appsv1.StatefulSetList{}), gomock.Any())
 	ret, err = GetMilvusInstanceCondition(ctx, mockClient, info)
 	assert.NoError(t, err)
 	assert.Equal(t, corev1.ConditionTrue, ret.Status)
@@ -383,12 +404,165 @@ func TestGetMilvusInstanceCondition(t *testing.T) {
 				list.Items[i].Status.Conditions = []appsv1.DeploymentCondition{
 					{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue},
 				}
+				list.Items[i].Status.UpdatedReplicas = 1
+				list.Items[i].Status.AvailableReplicas = 1
 			}
 			list.Items[7].Status.Conditions = []appsv1.DeploymentCondition{
 				{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionFalse},
 			}
 		})
+	mockClient.EXPECT().List(gomock.Any(), gomock.AssignableToTypeOf(&appsv1.StatefulSetList{}), gomock.Any())
 	ret, err = GetMilvusInstanceCondition(ctx, mockClient, info)
 	assert.NoError(t, err)
 	assert.Equal(t, corev1.ConditionFalse, ret.Status)
+
+	// dependency ready, cluster 7 ok with the standalone being deleted, fail
+	mockClient.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(ctx interface{}, list *appsv1.DeploymentList, opts interface{}) {
+			list.Items = make([]appsv1.Deployment, 8)
+			for i := 0; i < 8; i++ {
+				list.Items[i].OwnerReferences = []metav1.OwnerReference{
+					{Controller: &trueVal, UID: "uid"},
+				}
+				list.Items[i].Status.Conditions = []appsv1.DeploymentCondition{
+					{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue},
+				}
+				list.Items[i].Status.UpdatedReplicas = 1
+				list.Items[i].Status.AvailableReplicas = 1
+			}
+			list.Items[7].Labels = map[string]string{AppLabelComponent: MilvusName}
+		})
+	mockClient.EXPECT().List(gomock.Any(), gomock.AssignableToTypeOf(&appsv1.StatefulSetList{}), gomock.Any())
+	ret, err = GetMilvusInstanceCondition(ctx, mockClient, info)
+	assert.NoError(t, err)
+	assert.Equal(t, corev1.ConditionFalse, ret.Status)
+
+	// dependency ready, cluster 8 ok with 1 group fail, fail
+	mockClient.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(ctx interface{}, list *appsv1.DeploymentList, opts interface{}) {
+			list.Items = make([]appsv1.Deployment, 9)
+			for i := 0; i < 9; i++ {
+				list.Items[i].OwnerReferences = []metav1.OwnerReference{
+					{Controller: &trueVal, UID: "uid"},
+				}
+				list.Items[i].Status.Conditions = []appsv1.DeploymentCondition{
+					{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue},
+				}
+				list.Items[i].Status.UpdatedReplicas = 1
+				list.Items[i].Status.AvailableReplicas = 1
+			}
+			list.Items[8].Labels = map[string]string{AppLabelComponent: QueryNodeName + "-hot"}
+			list.Items[8].Status.Conditions = []appsv1.DeploymentCondition{
+				{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionFalse},
+			}
+		})
+	mockClient.EXPECT().List(gomock.Any(), gomock.AssignableToTypeOf(&appsv1.StatefulSetList{}), gomock.Any())
+	ret, err = GetMilvusInstanceCondition(ctx, mockClient, info)
+	assert.NoError(t, err)
+	assert.Equal(t, corev1.ConditionFalse, ret.Status)
+	assert.Contains(t, ret.Message, "querynode-hot")
+
+	// dependency ready, cluster 7 ok with the querynode statefulset
+	listDeployments := func() {
+		mockClient.EXPECT().List(gomock.Any(), gomock.AssignableToTypeOf(&appsv1.DeploymentList{}), gomock.Any()).
+			Do(func(ctx interface{}, list *appsv1.DeploymentList, opts interface{}) {
+				list.Items = make([]appsv1.Deployment, 7)
+				for i := 0; i < 7; i++ {
+					list.Items[i].OwnerReferences = []metav1.OwnerReference{
+						{Controller: &trueVal, UID: "uid"},
+					}
+					list.Items[i].Status.Conditions = []appsv1.DeploymentCondition{
+						{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue},
+					}
+					list.Items[i].Status.UpdatedReplicas = 1
+					list.Items[i].Status.AvailableReplicas = 1
+				}
+			})
+	}
+	listStatefulSets := func(readyReplicas int32) {
+		mockClient.EXPECT().List(gomock.Any(), gomock.AssignableToTypeOf(&appsv1.StatefulSetList{}), gomock.Any()).
+			Do(func(ctx interface{}, list *appsv1.StatefulSetList, opts interface{}) {
+				list.Items = make([]appsv1.StatefulSet, 1)
+				list.Items[0].Labels = map[string]string{AppLabelComponent: QueryNodeName}
+				list.Items[0].OwnerReferences = []metav1.OwnerReference{
+					{Controller: &trueVal, UID: "uid"},
+				}
+				list.Items[0].Spec.Replicas = int32Ptr(2)
+				list.Items[0].Status.Replicas = 2
+				list.Items[0].Status.UpdatedReplicas = 2
+				list.Items[0].Status.ReadyReplicas = readyReplicas
+			})
+	}
+	listDeployments()
+	listStatefulSets(2)
+	ret, err = GetMilvusInstanceCondition(ctx, mockClient, info)
+	assert.NoError(t, err)
+	assert.Equal(t, corev1.ConditionTrue, ret.Status)
+
+	listDeployments()
+	listStatefulSets(1)
+	ret, err = GetMilvusInstanceCondition(ctx, mockClient, info)
+	assert.NoError(t, err)
+	assert.Equal(t, corev1.ConditionFalse, ret.Status)
+	assert.Contains(t, ret.Message, QueryNodeName)
+}
+
+func TestGetStalledCondition(t *testing.T) {
+	cond := GetStalledCondition(errors.New("test"))
+	assert.Equal(t, v1alpha1.Stalled, cond.Type)
+	assert.Equal(t, corev1.ConditionTrue, cond.Status)
+	assert.Equal(t, v1alpha1.ReasonReconcileFailed, cond.Reason)
+	assert.Equal(t, "test", cond.Message)
+
+	cond = GetStalledCondition(nil)
+	assert.Equal(t, corev1.ConditionFalse, cond.Status)
+	assert.Equal(t, v1alpha1.ReasonReconcileSucceeded, cond.Reason)
+}
+
+func TestGetKStatusConditions(t *testing.T) {
+	milvusReady := v1alpha1.MilvusCondition{
+		Type:    v1alpha1.MilvusReady,
+		Status:  corev1.ConditionTrue,
+		Reason:  v1alpha1.ReasonMilvusClusterHealthy,
+		Message: MessageMilvusHealthy,
+	}
+	milvusNotReady := v1alpha1.MilvusCondition{
+		Type:    v1alpha1.MilvusReady,
+		Status:  corev1.ConditionFalse,
+		Reason:  v1alpha1.ReasonMilvusComponentNotHealthy,
+		Message: "[proxy] not ready",
+	}
+
+	// ready
+	conds := GetKStatusConditions([]v1alpha1.MilvusCondition{milvusReady, GetStalledCondition(nil)}, 2, 2)
+	assert.Len(t, conds, 2)
+	assert.Equal(t, v1alpha1.Ready, conds[0].Type)
+	assert.Equal(t, corev1.ConditionTrue, conds[0].Status)
+	assert.Equal(t, v1alpha1.Reconciling, conds[1].Type)
+	assert.Equal(t, corev1.ConditionFalse, conds[1].Status)
+
+	// generation not observed
+	conds = GetKStatusConditions([]v1alpha1.MilvusCondition{milvusReady}, 3, 2)
+	assert.Equal(t, corev1.ConditionFalse, conds[0].Status)
+	assert.Equal(t, v1alpha1.ReasonGenerationNotObserved, conds[0].Reason)
+	assert.Equal(t, corev1.ConditionTrue, conds[1].Status)
+
+	// milvus not checked yet
+	conds = GetKStatusConditions(nil, 1, 1)
+	assert.Equal(t, corev1.ConditionFalse, conds[0].Status)
+	assert.Equal(t, MessageMilvusUnchecked, conds[0].Message)
+	assert.Equal(t, corev1.ConditionTrue, conds[1].Status)
+
+	// milvus not ready
+	conds = GetKStatusConditions([]v1alpha1.MilvusCondition{milvusNotReady}, 1, 1)
+	assert.Equal(t, corev1.ConditionFalse, conds[0].Status)
+	assert.Equal(t, milvusNotReady.Message, conds[0].Message)
+	assert.Equal(t, corev1.ConditionTrue, conds[1].Status)
+	assert.Equal(t, milvusNotReady.Reason, conds[1].Reason)
+
+	// stalled
+	conds = GetKStatusConditions([]v1alpha1.MilvusCondition{milvusReady, GetStalledCondition(errors.New("test"))}, 2, 1)
+	assert.Equal(t, corev1.ConditionFalse, conds[0].Status)
+	assert.Equal(t, v1alpha1.ReasonReconcileFailed, conds[0].Reason)
+	assert.Equal(t, corev1.ConditionFalse, conds[1].Status)
 }
@@ -3,10 +3,9 @@ package controllers
 import (
 	"context"
 
+	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/yaml"
 
@@ -19,159 +18,99 @@ const (
 	MilvusConfigYaml = "milvus.yaml"
 )
 
-func (r *MilvusClusterReconciler) getMinioAccessInfo(ctx context.Context, mc v1alpha1.MilvusCluster) (string, string) {
-	secret := &corev1.Secret{}
-	key := types.NamespacedName{Namespace: mc.Namespace, Name: mc.Spec.Dep.Storage.SecretRef}
-	if err := r.Get(ctx, key, secret); err != nil {
-		// TODO @shaoyue: handle error, or not get if no secret set
-		r.logger.Error(err, "get minio secret error")
-		return "", ""
-	}
-
-	return string(secret.Data[AccessKey]), string(secret.Data[SecretKey])
-
+// deleteStorageCredentials removes the storage credentials from the conf,
+// milvus reads them from the env injected from the storage secret
+func deleteStorageCredentials(conf map[string]interface{}) {
+	util.DeleteValue(conf, "minio", "accessKeyID")
+	util.DeleteValue(conf, "minio", "secretAccessKey")
 }
 
-func (r *MilvusClusterReconciler) updateConfigMap(ctx context.Context, mc v1alpha1.MilvusCluster, configmap *corev1.ConfigMap) error {
-	confYaml, err := util.GetTemplatedValues(config.GetMilvusClusterConfigTemplate(), mc)
+// RenderMilvusConfig renders the config of the instance, which is shared by its components
+func RenderMilvusConfig(mil v1alpha1.Milvus) (map[string]interface{}, error) {
+	template := config.GetMilvusConfigTemplate()
+	if mil.Spec.IsCluster() {
+		template = config.GetMilvusClusterConfigTemplate()
+	}
+	confYaml, err := util.GetTemplatedValues(template, mil)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	conf := map[string]interface{}{}
 	if err := yaml.Unmarshal(confYaml, &conf); err != nil {
-		r.logger.Error(err, "yaml Unmarshal conf error")
-		return err
+		return nil, errors.Wrap(err, "unmarshal conf")
 	}
 
-	key, secret := r.getMinioAccessInfo(ctx, mc)
-	util.SetValue(conf, key, "minio", "accessKeyID")
-	util.SetValue(conf, secret, "minio", "secretAccessKey")
-
-	util.MergeValues(conf, mc.Spec.Conf.Data)
-	util.SetStringSlice(conf, mc.Spec.Dep.Etcd.Endpoints, "etcd", "endpoints")
-
-	host, port := util.GetHostPort(mc.Spec.Dep.Storage.Endpoint)
-	util.SetValue(conf, host, "minio", "address")
-	util.SetValue(conf, int64(port), "minio", "port")
-
-	host, port = util.GetHostPort(mc.Spec.Dep.Pulsar.Endpoint)
-	util.SetValue(conf, host, "pulsar", "address")
-	util.SetValue(conf, int64(port), "pulsar", "port")
-
-	milvusYaml, err := yaml.Marshal(conf)
+	util.MergeValues(conf, mil.Spec.Conf.Data)
+	deleteStorageCredentials(conf)
+	etcdEndpoints, err := GetEtcdEndpoints(mil.Spec.Dep.Etcd)
 	if err != nil {
-		r.logger.Error(err, "yaml Marshal conf error")
-		return err
-	}
-
-	configmap.Labels = MergeLabels(configmap.Labels, NewAppLabels(mc.Name))
-
-	if err := ctrl.SetControllerReference(&mc, configmap, r.Scheme); err != nil {
-		r.logger.Error(err, "configmap SetControllerReference error")
-		return err
+		return nil, errors.Wrap(err, "parse etcd endpoints")
 	}
+	util.SetStringSlice(conf, etcdEndpoints, "etcd", "endpoints")
 
-	if configmap.Data == nil {
-		configmap.Data = make(map[string]string)
+	// the endpoints are always set by SetDefault, the template defaults are kept if not
+	if mil.Spec.Dep.Storage.Endpoint != "" {
+		storage, useSSL, err := GetStorageEndpoint(mil.Spec.Dep.Storage, conf)
+		if err != nil {
+			return nil, errors.Wrap(err, "parse storage endpoint")
+		}
+		// milvus joins the address and the port, so IPv6 is kept in brackets
+		util.SetValue(conf, storage.Address(), "minio", "address")
+		util.SetValue(conf, int64(storage.Port), "minio", "port")
+		util.SetValue(conf, useSSL, "minio", "useSSL")
 	}
-	configmap.Data[MilvusConfigYaml] = string(milvusYaml)
-
-	return nil
-}
 
-//
-func (r *MilvusClusterReconciler) ReconcileConfigMaps(ctx context.Context, mc v1alpha1.MilvusCluster) error {
-	namespacedName := NamespacedName(mc.Namespace, mc.Name)
-	old := &corev1.ConfigMap{}
-	err := r.Get(ctx, namespacedName, old)
-	if errors.IsNotFound(err) {
-		new := &corev1.ConfigMap{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      mc.Name,
-				Namespace: mc.Namespace,
-			},
+	if mil.Spec.IsCluster() && mil.Spec.Dep.Pulsar.Endpoint != "" {
+		pulsar, err := mil.Spec.Dep.Pulsar.GetEndpoint()
+		if err != nil {
+			return nil, errors.Wrap(err, "parse pulsar endpoint")
 		}
-		if err = r.updateConfigMap(ctx, mc, new); err != nil {
-			return err
+		address := pulsar.Address()
+		if pulsar.IsSecure() {
+			// milvus uses the address with a port as the service URL, so the TLS scheme is kept
+			address = pulsar.String()
+		}
+		util.SetValue(conf, address, "pulsar", "address")
+		util.SetValue(conf, int64(pulsar.Port), "pulsar", "port")
+		// the topics of the instances sharing the pulsar of a pool are in their own namespaces
+		if mil.Spec.Dep.Pool != nil && !mil.Spec.Dep.Pulsar.External {
+			util.SetValue(conf, mil.Namespace, "pulsar", "tenant")
+			util.SetValue(conf, mil.Name, "pulsar", "namespace")
 		}
-
-		r.logger.Info("Create Configmap", "name", new.Name, "namespace", new.Namespace)
-		return r.Create(ctx, new)
-	} else if err != nil {
-		return err
-	}
-
-	cur := old.DeepCopy()
-	if err := r.updateConfigMap(ctx, mc, cur); err != nil {
-		return err
-	}
-
-	if IsEqual(old, cur) {
-		return nil
 	}
 
-	r.logger.Info("Update Configmap", "name", cur.Name, "namespace", cur.Namespace)
-	return r.Update(ctx, cur)
-}
-
-func (r *MilvusReconciler) ReconcileConfigMaps(ctx context.Context, mil v1alpha1.Milvus) error {
-	namespacedName := NamespacedName(mil.Namespace, mil.Name)
-	old := &corev1.ConfigMap{}
-	err := r.Get(ctx, namespacedName, old)
-	if errors.IsNotFound(err) {
-		new := &corev1.ConfigMap{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      mil.Name,
-				Namespace: mil.Namespace,
-			},
+	for _, coord := range MilvusCoords {
+		if coord.IsActiveStandby(mil.Spec) {
+			util.SetValue(conf, true, coord.GetConfSection(), "enableActiveStandby")
 		}
-		if err = r.updateConfigMap(ctx, mil, new); err != nil {
-			return err
-		}
-
-		r.logger.Info("Create Configmap", "name", new.Name, "namespace", new.Namespace)
-		return r.Create(ctx, new)
-	} else if err != nil {
-		return err
 	}
+	return conf, nil
+}
 
-	cur := old.DeepCopy()
-	if err := r.updateConfigMap(ctx, mil, cur); err != nil {
-		return err
+// RenderComponentConfig returns the config the component reads from the rendered @conf of the instance,
+// which is the part it consumes with its overrides merged in cluster mode
+func RenderComponentConfig(conf map[string]interface{}, spec v1alpha1.MilvusSpec, component MilvusComponent) map[string]interface{} {
+	if !spec.IsCluster() {
+		return conf
 	}
-
-	if IsEqual(old, cur) {
-		return nil
+	componentConf := component.FilterComponentConf(conf)
+	if overrides := component.GetComponentConf(spec); len(overrides) > 0 {
+		// sections are shared with conf, merge into a copy
+		componentConf = (&v1alpha1.Values{Data: componentConf}).DeepCopy().Data
+		util.MergeValues(componentConf, overrides)
+		deleteStorageCredentials(componentConf)
 	}
-
-	r.logger.Info("Update Configmap", "name", cur.Name, "namespace", cur.Namespace)
-	return r.Update(ctx, cur)
+	return componentConf
 }
 
 func (r *MilvusReconciler) updateConfigMap(ctx context.Context, mil v1alpha1.Milvus, configmap *corev1.ConfigMap) error {
-	confYaml, err := util.GetTemplatedValues(config.GetMilvusConfigTemplate(), mil)
+	conf, err := RenderMilvusConfig(mil)
 	if err != nil {
+		r.logger.Error(err, "render conf error")
 		return err
 	}
 
-	conf := map[string]interface{}{}
-	if err := yaml.Unmarshal(confYaml, &conf); err != nil {
-		r.logger.Error(err, "yaml Unmarshal conf error")
-		return err
-	}
-
-	key, secret := r.getMinioAccessInfo(ctx, mil)
-	util.SetValue(conf, key, "minio", "accessKeyID")
-	util.SetValue(conf, secret, "minio", "secretAccessKey")
-
-	util.MergeValues(conf, mil.Spec.Conf.Data)
-	util.SetStringSlice(conf, mil.Spec.Dep.Etcd.Endpoints, "etcd", "endpoints")
-
-	host, port := util.GetHostPort(mil.Spec.Dep.Storage.Endpoint)
-	util.SetValue(conf, host, "minio", "address")
-	util.SetValue(conf, int64(port), "minio", "port")
-
 	milvusYaml, err := yaml.Marshal(conf)
 	if err != nil {
 		r.logger.Error(err, "yaml Marshal conf error")
@@ -190,18 +129,33 @@ func (r *MilvusReconciler) updateConfigMap(ctx context.Context, mil v1alpha1.Mil
 	}
 	configmap.Data[MilvusConfigYaml] = string(milvusYaml)
 
+	if !mil.Spec.IsCluster() {
+		return nil
+	}
+
+	for _, component := range MilvusComponents {
+		componentConf := RenderComponentConfig(conf, mil.Spec, component)
+		componentYaml, err := yaml.Marshal(componentConf)
+		if err != nil {
+			r.logger.Error(err, "yaml Marshal component conf error", "component", component.Name)
+			return err
+		}
+		configmap.Data[component.GetConfigMapKey()] = string(componentYaml)
+	}
+
 	return nil
 }
 
-func (r *MilvusReconciler) getMinioAccessInfo(ctx context.Context, mil v1alpha1.Milvus) (string, string) {
-	secret := &corev1.Secret{}
-	key := types.NamespacedName{Namespace: mil.Namespace, Name: mil.Spec.Dep.Storage.SecretRef}
-	if err := r.Get(ctx, key, secret); err != nil {
-		// TODO @shaoyue: handle error, or not get if no secret set
-		r.logger.Error(err, "get minio secret error")
-		return "", ""
+func (r *MilvusReconciler) ReconcileConfigMaps(ctx context.Context, mil v1alpha1.Milvus) error {
+	configmap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      mil.Name,
+			Namespace: mil.Namespace,
+		},
+	}
+	if err := r.updateConfigMap(ctx, mil, configmap); err != nil {
+		return err
 	}
 
-	return string(secret.Data[AccessKey]), string(secret.Data[SecretKey])
-
+	return ApplyObject(ctx, r.Client, r.Scheme, configmap)
 }
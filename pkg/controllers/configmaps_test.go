@@ -6,104 +6,115 @@ import (
 	"testing"
 
 	corev1 "k8s.io/api/core/v1"
-	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/milvus-io/milvus-operator/apis/milvus.io/v1alpha1"
 )
 
-func TestReconcileConfigMaps_CreateIfNotfound(t *testing.T) {
-	env := newClusterTestEnv(t)
+func TestMilvusReconciler_ClusterMode_updateConfigMap_ComponentConf(t *testing.T) {
+	env := newClusterModeTestEnv(t)
 	defer env.tearDown()
 	r := env.Reconciler
-	mockClient := env.MockClient
 	ctx := env.ctx
 	mc := env.Inst
+	mc.Spec.Com.QueryNode.Conf.Data = map[string]interface{}{
+		"queryNode": map[string]interface{}{
+			"cacheSize": 16,
+		},
+	}
+
+	cm := &corev1.ConfigMap{}
+	cm.Namespace = "ns"
+	err := r.updateConfigMap(ctx, mc, cm)
+	assert.NoError(t, err)
 
-	// all ok
-	gomock.InOrder(
-		mockClient.EXPECT().
-			Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&corev1.ConfigMap{})).
-			Return(k8sErrors.NewNotFound(schema.GroupResource{}, "")),
-		// get secret of minio
-		mockClient.EXPECT().
-			Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&corev1.Secret{})).
-			Return(k8sErrors.NewNotFound(schema.GroupResource{}, "mockErr")),
-		mockClient.EXPECT().
-			Create(gomock.Any(), gomock.Any()).Return(nil),
-	)
-	err := r.ReconcileConfigMaps(ctx, mc)
+	assert.Contains(t, cm.Data, MilvusConfigYaml)
+	for _, component := range MilvusComponents {
+		assert.Contains(t, cm.Data, component.GetConfigMapKey())
+	}
+	assert.Contains(t, cm.Data[QueryNode.GetConfigMapKey()], "cacheSize: 16")
+	assert.NotContains(t, cm.Data[MilvusConfigYaml], "cacheSize: 16")
+	assert.NotContains(t, cm.Data[DataNode.GetConfigMapKey()], "cacheSize")
+	assert.Contains(t, cm.Data[DataNode.GetConfigMapKey()], "insertBufSize")
+}
+
+func TestMilvusReconciler_ClusterMode_updateConfigMap_ActiveStandby(t *testing.T) {
+	env := newClusterModeTestEnv(t)
+	defer env.tearDown()
+	r := env.Reconciler
+	mc := env.Inst
+	mc.Spec.Com.RootCoord.ActiveStandby = true
+
+	cm := &corev1.ConfigMap{}
+	cm.Namespace = "ns"
+	err := r.updateConfigMap(env.ctx, mc, cm)
 	assert.NoError(t, err)
 
-	// get failed
-	mockClient.EXPECT().
-		Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&corev1.ConfigMap{})).
-		Return(errors.New("some network issue"))
-	err = r.ReconcileConfigMaps(ctx, mc)
-	assert.Error(t, err)
+	conf := map[string]interface{}{}
+	assert.NoError(t, yaml.Unmarshal([]byte(cm.Data[RootCoord.GetConfigMapKey()]), &conf))
+	assert.Equal(t, true, conf["rootCoord"].(map[string]interface{})["enableActiveStandby"])
+	assert.NotContains(t, cm.Data[DataCoord.GetConfigMapKey()], "enableActiveStandby: true")
+}
 
-	// get failed
-	mockClient.EXPECT().
-		Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&corev1.ConfigMap{})).
-		Return(errors.New("some network issue"))
-	err = r.ReconcileConfigMaps(ctx, mc)
-	assert.Error(t, err)
+func TestMilvusReconciler_ClusterMode_updateConfigMap_NoCredentials(t *testing.T) {
+	env := newClusterModeTestEnv(t)
+	defer env.tearDown()
+	r := env.Reconciler
+	ctx := env.ctx
+	mc := env.Inst
+	mc.Spec.Dep.Storage.SecretRef = "secret"
+	mc.Spec.Conf.Data = map[string]interface{}{
+		"minio": map[string]interface{}{
+			"accessKeyID":     "key",
+			"secretAccessKey": "secret",
+		},
+	}
+	mc.Spec.Com.DataNode.Conf.Data = map[string]interface{}{
+		"minio": map[string]interface{}{
+			"secretAccessKey": "secret",
+		},
+	}
+
+	cm := &corev1.ConfigMap{}
+	cm.Namespace = "ns"
+	err := r.updateConfigMap(ctx, mc, cm)
+	assert.NoError(t, err)
+	for _, data := range cm.Data {
+		assert.NotContains(t, data, "accessKeyID")
+		assert.NotContains(t, data, "secretAccessKey")
+	}
 }
 
-func TestReconcileConfigMaps_Existed(t *testing.T) {
-	env := newClusterTestEnv(t)
+func TestMilvusReconciler_ClusterMode_updateConfigMap_Prefixes(t *testing.T) {
+	env := newClusterModeTestEnv(t)
 	defer env.tearDown()
 	r := env.Reconciler
-	mockClient := env.MockClient
 	ctx := env.ctx
 	mc := env.Inst
 
-	// call client.Update if changed configmap
-	gomock.InOrder(
-		mockClient.EXPECT().
-			Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&corev1.ConfigMap{})).
-			DoAndReturn(func(ctx context.Context, key client.ObjectKey, obj client.Object) error {
-				cm := obj.(*corev1.ConfigMap)
-				cm.Namespace = "ns"
-				cm.Name = "cm1"
-				return nil
-			}),
-		// get secret of minio
-		mockClient.EXPECT().
-			Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&corev1.Secret{})).
-			Return(k8sErrors.NewNotFound(schema.GroupResource{}, "mockErr")),
-		mockClient.EXPECT().
-			Update(gomock.Any(), gomock.Any()).Return(nil),
-	)
-
-	err := r.ReconcileConfigMaps(ctx, mc)
+	cm := &corev1.ConfigMap{}
+	cm.Namespace = "ns"
+	err := r.updateConfigMap(ctx, mc, cm)
 	assert.NoError(t, err)
-
-	// not call client.Update if configmap not changed
-	gomock.InOrder(
-		mockClient.EXPECT().
-			Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&corev1.ConfigMap{})).
-			DoAndReturn(func(ctx context.Context, key client.ObjectKey, obj client.Object) error {
-				cm := obj.(*corev1.ConfigMap)
-				cm.Namespace = "ns"
-				cm.Name = "cm1"
-				r.updateConfigMap(ctx, mc, cm)
-				return nil
-			}),
-		// get secret of minio
-		mockClient.EXPECT().
-			Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&corev1.Secret{})).
-			Return(k8sErrors.NewNotFound(schema.GroupResource{}, "mockErr")).Times(2),
-	)
-	err = r.ReconcileConfigMaps(ctx, mc)
+	assert.Contains(t, cm.Data[MilvusConfigYaml], "rootPath: mc\n")
+	assert.Contains(t, cm.Data[MilvusConfigYaml], "bucketName: mc\n")
+	assert.Contains(t, cm.Data[MilvusConfigYaml], "cluster: mc\n")
+
+	mc.Spec.Dep.Etcd.RootPath = "ns-mc"
+	mc.Spec.Dep.Storage.BucketName = "ns-mc"
+	mc.Spec.Dep.MsgChannelPrefix = "ns-mc"
+	err = r.updateConfigMap(ctx, mc, cm)
 	assert.NoError(t, err)
+	assert.Contains(t, cm.Data[MilvusConfigYaml], "rootPath: ns-mc\n")
+	assert.Contains(t, cm.Data[MilvusConfigYaml], "bucketName: ns-mc\n")
+	assert.Contains(t, cm.Data[MilvusConfigYaml], "cluster: ns-mc\n")
 }
 
-// ---------------- Test Milvus Reconciler ----------------
-
-func TestMilvusReconciler_ReconcileConfigMaps_CreateIfNotFound(t *testing.T) {
+func TestMilvusReconciler_ReconcileConfigMaps(t *testing.T) {
 	env := newMilvusTestEnv(t)
 	defer env.tearDown()
 	r := env.Reconciler
@@ -111,81 +122,98 @@ func TestMilvusReconciler_ReconcileConfigMaps_CreateIfNotFound(t *testing.T) {
 	ctx := env.ctx
 	m := env.Inst
 
-	// all ok
-	gomock.InOrder(
-		mockClient.EXPECT().
-			Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&corev1.ConfigMap{})).
-			Return(k8sErrors.NewNotFound(schema.GroupResource{}, "")),
-		// get secret of minio
-		mockClient.EXPECT().
-			Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&corev1.Secret{})).
-			Return(k8sErrors.NewNotFound(schema.GroupResource{}, "mockErr")),
-		mockClient.EXPECT().
-			Create(gomock.Any(), gomock.Any()).Return(nil),
-	)
+	// all applied
+	mockClient.EXPECT().
+		Patch(gomock.Any(), gomock.AssignableToTypeOf(&corev1.ConfigMap{}), client.Apply, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+			cm := obj.(*corev1.ConfigMap)
+			assert.Equal(t, m.Name, cm.Name)
+			assert.Contains(t, cm.Data, MilvusConfigYaml)
+			return nil
+		})
 	err := r.ReconcileConfigMaps(ctx, m)
 	assert.NoError(t, err)
 
-	// get failed
-	mockClient.EXPECT().
-		Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&corev1.ConfigMap{})).
-		Return(errors.New("some network issue"))
-	err = r.ReconcileConfigMaps(ctx, m)
-	assert.Error(t, err)
+	// only the conf of the standalone
+	cm := &corev1.ConfigMap{}
+	cm.Namespace = "ns"
+	err = r.updateConfigMap(ctx, m, cm)
+	assert.NoError(t, err)
+	assert.Len(t, cm.Data, 1)
+	assert.NotContains(t, cm.Data[MilvusConfigYaml], "pulsar:")
 
-	// get failed
+	// apply failed
 	mockClient.EXPECT().
-		Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&corev1.ConfigMap{})).
+		Patch(gomock.Any(), gomock.AssignableToTypeOf(&corev1.ConfigMap{}), client.Apply, gomock.Any(), gomock.Any()).
 		Return(errors.New("some network issue"))
 	err = r.ReconcileConfigMaps(ctx, m)
 	assert.Error(t, err)
 }
 
-func TestMilvusReconciler_ReconcileConfigMaps_Existed(t *testing.T) {
+func TestMilvusReconciler_updateConfigMap_NoCredentials(t *testing.T) {
 	env := newMilvusTestEnv(t)
 	defer env.tearDown()
 	r := env.Reconciler
-	mockClient := env.MockClient
 	ctx := env.ctx
 	m := env.Inst
-
-	// call client.Update if changed configmap
-	gomock.InOrder(
-		mockClient.EXPECT().
-			Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&corev1.ConfigMap{})).
-			DoAndReturn(func(ctx context.Context, key client.ObjectKey, obj client.Object) error {
-				cm := obj.(*corev1.ConfigMap)
-				cm.Namespace = "ns"
-				cm.Name = "cm1"
-				return nil
-			}),
-		// get secret of minio
-		mockClient.EXPECT().
-			Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&corev1.Secret{})).
-			Return(k8sErrors.NewNotFound(schema.GroupResource{}, "mockErr")),
-		mockClient.EXPECT().
-			Update(gomock.Any(), gomock.Any()).Return(nil),
-	)
-
-	err := r.ReconcileConfigMaps(ctx, m)
+	m.Spec.Dep.Storage.SecretRef = "secret"
+	m.Spec.Conf.Data = map[string]interface{}{
+		"minio": map[string]interface{}{
+			"accessKeyID":     "key",
+			"secretAccessKey": "secret",
+		},
+	}
+
+	cm := &corev1.ConfigMap{}
+	cm.Namespace = "ns"
+	err := r.updateConfigMap(ctx, m, cm)
 	assert.NoError(t, err)
+	assert.NotContains(t, cm.Data[MilvusConfigYaml], "accessKeyID")
+	assert.NotContains(t, cm.Data[MilvusConfigYaml], "secretAccessKey")
+}
 
-	// not call client.Update if configmap not changed
-	gomock.InOrder(
-		mockClient.EXPECT().
-			Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&corev1.ConfigMap{})).
-			DoAndReturn(func(ctx context.Context, key client.ObjectKey, obj client.Object) error {
-				cm := obj.(*corev1.ConfigMap)
-				cm.Namespace = "ns"
-				cm.Name = "cm1"
-				r.updateConfigMap(ctx, m, cm)
-				return nil
-			}),
-		// get secret of minio
-		mockClient.EXPECT().
-			Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&corev1.Secret{})).
-			Return(k8sErrors.NewNotFound(schema.GroupResource{}, "mockErr")).Times(2),
-	)
-	err = r.ReconcileConfigMaps(ctx, m)
-	assert.NoError(t, err)
+func TestMilvusReconciler_ClusterMode_updateConfigMap_Endpoints(t *testing.T) {
+	env := newClusterModeTestEnv(t)
+	defer env.tearDown()
+	r := env.Reconciler
+	ctx := env.ctx
+	mc := env.Inst
+	mc.Spec.Dep.Etcd.Endpoints = []string{"[fd00::1]:2379", "https://etcd"}
+	mc.Spec.Dep.Storage.Endpoint = "https://[fd00::2]"
+	mc.Spec.Dep.Pulsar.Endpoint = "fd00::3"
+
+	getConf := func() map[string]interface{} {
+		cm := &corev1.ConfigMap{}
+		cm.Namespace = "ns"
+		err := r.updateConfigMap(ctx, mc, cm)
+		assert.NoError(t, err)
+		conf := map[string]interface{}{}
+		assert.NoError(t, yaml.Unmarshal([]byte(cm.Data[MilvusConfigYaml]), &conf))
+		return conf
+	}
+	conf := getConf()
+	assert.Equal(t, []interface{}{"[fd00::1]:2379", "https://etcd:443"}, conf["etcd"].(map[string]interface{})["endpoints"])
+	minio := conf["minio"].(map[string]interface{})
+	assert.Equal(t, "[fd00::2]", minio["address"])
+	assert.Equal(t, float64(443), minio["port"])
+	assert.Equal(t, true, minio["useSSL"])
+	pulsar := conf["pulsar"].(map[string]interface{})
+	assert.Equal(t, "[fd00::3]", pulsar["address"])
+	assert.Equal(t, float64(6650), pulsar["port"])
+
+	mc.Spec.Dep.Pulsar.Endpoint = "pulsar+ssl://pulsar"
+	pulsar = getConf()["pulsar"].(map[string]interface{})
+	assert.Equal(t, "pulsar+ssl://pulsar:6651", pulsar["address"])
+	assert.Equal(t, float64(6651), pulsar["port"])
+	assert.Nil(t, pulsar["tenant"])
+
+	// the pulsar namespace of the instance served by a pool
+	mc.Spec.Dep.Pool = &v1alpha1.DependencyPoolRef{Name: "shared"}
+	pulsar = getConf()["pulsar"].(map[string]interface{})
+	assert.Equal(t, mc.Namespace, pulsar["tenant"])
+	assert.Equal(t, mc.Name, pulsar["namespace"])
+
+	mc.Spec.Dep.Storage.Endpoint = "minio:badPort"
+	err := r.updateConfigMap(ctx, mc, &corev1.ConfigMap{})
+	assert.Error(t, err)
 }
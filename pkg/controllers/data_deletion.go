@@ -0,0 +1,444 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/pkg/errors"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/milvus-io/milvus-operator/apis/milvus.io/v1alpha1"
+)
+
+const (
+	// PulsarTopicNamespace is the pulsar namespace milvus creates its topics in
+	PulsarTopicNamespace = "public/default"
+)
+
+// milvusChannelSuffixes are the channel names after the prefix, see msgChannel.chanNamePrefix in the config templates
+var milvusChannelSuffixes = []string{
+	"rootcoord-timetick",
+	"rootcoord-statistics",
+	"rootcoord-dml",
+	"rootcoord-delta",
+	"search",
+	"searchResult",
+	"proxyTimeTick",
+	"queryTimeTick",
+	"query-node-stats",
+	"cmd",
+	"insert-channel-",
+	"datacoord-statistics-channel",
+	"datacoord-timetick-channel",
+	"segment-info-channel",
+}
+
+// ExternalDataInfo is info for deleting the data of an instance in the external or pooled dependencies,
+// the data of a dependency is kept if its endpoint is empty
+type ExternalDataInfo struct {
+	Namespace string
+
+	EtcdEndpoints []string
+	EtcdRootPath  string
+
+	Storage    v1alpha1.MilvusStorage
+	UseSSL     bool
+	BucketName string
+
+	PulsarAdminEndpoint string
+	// PulsarNamespaces are the <tenant>/<namespace> the topics are in
+	PulsarNamespaces []string
+	MsgChannelPrefix string
+}
+
+// IsEmpty returns true if no data to delete
+func (info ExternalDataInfo) IsEmpty() bool {
+	return len(info.EtcdEndpoints) == 0 && info.Storage.Endpoint == "" && info.PulsarAdminEndpoint == ""
+}
+
+func stringDefault(s, defaultValue string) string {
+	if s == "" {
+		return defaultValue
+	}
+	return s
+}
+
+// GetPulsarAdminEndpoint returns the admin endpoint of pulsar, defaults to the host of the endpoint with the admin port.
+// It's returned as is if it's invalid, so that the deletion fails with the error
+func GetPulsarAdminEndpoint(p v1alpha1.MilvusPulsar) string {
+	endpoint, err := p.GetAdminEndpoint()
+	if err != nil {
+		return stringDefault(p.AdminEndpoint, p.Endpoint)
+	}
+	return endpoint.String()
+}
+
+func GetExternalDataInfo(mil v1alpha1.Milvus) ExternalDataInfo {
+	info := ExternalDataInfo{Namespace: mil.Namespace}
+	if mil.Spec.Dep.DataDeletionPolicy != v1alpha1.DeletionPolicyDelete {
+		return info
+	}
+
+	// the dependencies served by a pool are shared like the external ones.
+	// The invalid endpoints are kept as is, so that the deletion fails with the error
+	pooled := mil.Spec.Dep.Pool != nil
+	if mil.Spec.Dep.Etcd.External || pooled {
+		info.EtcdEndpoints = mil.Spec.Dep.Etcd.Endpoints
+		if endpoints, err := GetEtcdEndpoints(mil.Spec.Dep.Etcd); err == nil {
+			info.EtcdEndpoints = endpoints
+		}
+		info.EtcdRootPath = stringDefault(mil.Spec.Dep.Etcd.RootPath, mil.Name)
+	}
+	if mil.Spec.Dep.Storage.External || pooled {
+		info.Storage = mil.Spec.Dep.Storage
+		info.UseSSL = GetMinioSecure(mil.Spec.Conf.Data)
+		// the storage client takes host:port
+		if endpoint, useSSL, err := GetStorageEndpoint(mil.Spec.Dep.Storage, mil.Spec.Conf.Data); err == nil {
+			info.Storage.Endpoint = endpoint.HostPort()
+			info.UseSSL = useSSL
+		}
+		info.BucketName = stringDefault(mil.Spec.Dep.Storage.BucketName, mil.Name)
+	}
+	if mil.Spec.IsCluster() && (mil.Spec.Dep.Pulsar.External || pooled) && mil.Spec.Dep.Pulsar.Endpoint != "" {
+		info.PulsarAdminEndpoint = GetPulsarAdminEndpoint(mil.Spec.Dep.Pulsar)
+		info.MsgChannelPrefix = stringDefault(mil.Spec.Dep.MsgChannelPrefix, mil.Name)
+		info.PulsarNamespaces = []string{PulsarTopicNamespace}
+		if pooled && !mil.Spec.Dep.Pulsar.External {
+			// milvus before v2.2 ignores the namespace of the pool and creates the topics in the default one
+			info.PulsarNamespaces = []string{GetPoolPulsarNamespace(mil), PulsarTopicNamespace}
+		}
+	}
+	return info
+}
+
+// DeleteExternalData deletes the data of an instance in the external dependencies,
+// it's idempotent so that it can be retried until the returned condition is true
+func DeleteExternalData(ctx context.Context, logger logr.Logger, cli client.Client, info ExternalDataInfo) v1alpha1.MilvusCondition {
+	results := []string{}
+	failed := false
+	addResult := func(target string, err error) {
+		if err != nil {
+			failed = true
+			results = append(results, fmt.Sprintf("%s: %s", target, err.Error()))
+			logger.Error(err, "delete external data failed", "target", target)
+			return
+		}
+		results = append(results, fmt.Sprintf("%s: deleted", target))
+		logger.Info("external data deleted", "target", target)
+	}
+
+	if len(info.EtcdEndpoints) > 0 {
+		addResult(fmt.Sprintf("etcd rootPath %s", info.EtcdRootPath),
+			DeleteEtcdData(ctx, info.EtcdEndpoints, info.EtcdRootPath))
+	}
+	if info.Storage.Endpoint != "" {
+		addResult(fmt.Sprintf("bucket %s", info.BucketName),
+			DeleteStorageData(ctx, cli, info))
+	}
+	if info.PulsarAdminEndpoint != "" {
+		addResult(fmt.Sprintf("topics of %s", info.MsgChannelPrefix),
+			DeletePulsarData(ctx, info.PulsarAdminEndpoint, info.PulsarNamespaces, info.MsgChannelPrefix))
+	}
+
+	cond := v1alpha1.MilvusCondition{
+		Type:    v1alpha1.DataDeleted,
+		Status:  GetConditionStatus(!failed),
+		Reason:  v1alpha1.ReasonDataDeleted,
+		Message: strings.Join(results, "; "),
+	}
+	if failed {
+		cond.Reason = v1alpha1.ReasonDataDeleting
+	}
+	return cond
+}
+
+// DeleteEtcdData deletes all the keys under the rootPath
+func DeleteEtcdData(ctx context.Context, endpoints []string, rootPath string) error {
+	if rootPath == "" {
+		return errors.New("empty rootPath")
+	}
+
+	cli, err := etcdNewClient(clientv3.Config{
+		Endpoints:   endpoints,
+		DialTimeout: 5 * time.Second,
+	})
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	_, err = cli.Delete(ctx, rootPath+"/", clientv3.WithPrefix())
+	return err
+}
+
+type NewMinioBucketClientFunc func(endpoint string, accessKeyID, secretAccessKey string, secure bool) (MinioBucketClient, error)
+
+// newMinioBucketClientFunc wraps minio.New for test mock convenience
+var newMinioBucketClientFunc NewMinioBucketClientFunc = func(endpoint string, accessKeyID, secretAccessKey string, secure bool) (MinioBucketClient, error) {
+	return minio.New(endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(accessKeyID, secretAccessKey, ""),
+		Secure: secure,
+	})
+}
+
+// DeleteStorageData removes all the objects in the bucket and the bucket itself
+func DeleteStorageData(ctx context.Context, cli client.Client, info ExternalDataInfo) error {
+	if info.BucketName == "" {
+		return errors.New("empty bucket name")
+	}
+
+	secret := &corev1.Secret{}
+	key := types.NamespacedName{Namespace: info.Namespace, Name: info.Storage.SecretRef}
+	if err := cli.Get(ctx, key, secret); err != nil {
+		return errors.Wrap(err, "get storage secret")
+	}
+	accesskey, exist1 := secret.Data[AccessKey]
+	secretkey, exist2 := secret.Data[SecretKey]
+	if !exist1 || !exist2 {
+		return errors.New(MessageKeyNotExist)
+	}
+
+	bucketCli, err := newMinioBucketClientFunc(info.Storage.Endpoint, string(accesskey), string(secretkey), info.UseSSL)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	exist, err := bucketCli.BucketExists(ctx, info.BucketName)
+	if err != nil {
+		return err
+	}
+	if !exist {
+		return nil
+	}
+
+	objectsCh := make(chan minio.ObjectInfo)
+	listErrCh := make(chan error, 1)
+	go func() {
+		defer close(objectsCh)
+		for object := range bucketCli.ListObjects(ctx, info.BucketName, minio.ListObjectsOptions{
+			Recursive:    true,
+			WithVersions: true,
+		}) {
+			if object.Err != nil {
+				listErrCh <- object.Err
+				return
+			}
+			select {
+			case objectsCh <- object:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for removeErr := range bucketCli.RemoveObjects(ctx, info.BucketName, objectsCh, minio.RemoveObjectsOptions{}) {
+		// the listing stops when ctx canceled
+		return errors.Wrapf(removeErr.Err, "remove object %s", removeErr.ObjectName)
+	}
+	select {
+	case err := <-listErrCh:
+		return errors.Wrap(err, "list objects")
+	default:
+	}
+
+	return bucketCli.RemoveBucket(ctx, info.BucketName)
+}
+
+// pulsarAdmin implements PulsarAdminClient with the pulsar admin REST API
+type pulsarAdmin struct {
+	endpoint string
+	client   *http.Client
+}
+
+type NewPulsarAdminClientFunc func(endpoint string) PulsarAdminClient
+
+// newPulsarAdminClientFunc creates a PulsarAdminClient, it's a var for test mock convenience
+var newPulsarAdminClientFunc NewPulsarAdminClientFunc = func(endpoint string) PulsarAdminClient {
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "http://" + endpoint
+	}
+	return &pulsarAdmin{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (p *pulsarAdmin) do(ctx context.Context, method, path string, query url.Values) (*http.Response, error) {
+	return p.doWithBody(ctx, method, path, query, nil)
+}
+
+// doWithBody sends the request with the body encoded in json if it's not nil
+func (p *pulsarAdmin) doWithBody(ctx context.Context, method, path string, query url.Values, body interface{}) (*http.Response, error) {
+	u := p.endpoint + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return p.client.Do(req)
+}
+
+// ListTopics lists the persistent topics in the namespace, none if the namespace is not found
+func (p *pulsarAdmin) ListTopics(ctx context.Context, namespace string) ([]string, error) {
+	resp, err := p.do(ctx, http.MethodGet, "/admin/v2/persistent/"+namespace, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("list topics of %s: %s", namespace, resp.Status)
+	}
+
+	topics := []string{}
+	if err := json.NewDecoder(resp.Body).Decode(&topics); err != nil {
+		return nil, errors.Wrap(err, "decode topics")
+	}
+	return topics, nil
+}
+
+// DeleteTopic deletes the topic forcefully, the topic not found is ignored
+func (p *pulsarAdmin) DeleteTopic(ctx context.Context, topic string) error {
+	// persistent://tenant/namespace/topic => /admin/v2/persistent/tenant/namespace/topic
+	path := "/admin/v2/" + strings.Replace(topic, "://", "/", 1)
+	resp, err := p.do(ctx, http.MethodDelete, path, url.Values{"force": []string{"true"}})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK &&
+		resp.StatusCode != http.StatusNotFound {
+		return errors.Errorf("delete topic %s: %s", topic, resp.Status)
+	}
+	return nil
+}
+
+// ListClusters lists the names of the pulsar clusters
+func (p *pulsarAdmin) ListClusters(ctx context.Context) ([]string, error) {
+	resp, err := p.do(ctx, http.MethodGet, "/admin/v2/clusters", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("list clusters: %s", resp.Status)
+	}
+
+	clusters := []string{}
+	if err := json.NewDecoder(resp.Body).Decode(&clusters); err != nil {
+		return nil, errors.Wrap(err, "decode clusters")
+	}
+	return clusters, nil
+}
+
+// CreateTenant creates the tenant allowed in the clusters, the tenant existing is ignored
+func (p *pulsarAdmin) CreateTenant(ctx context.Context, tenant string, allowedClusters []string) error {
+	body := map[string]interface{}{
+		"adminRoles":      []string{},
+		"allowedClusters": allowedClusters,
+	}
+	resp, err := p.doWithBody(ctx, http.MethodPut, "/admin/v2/tenants/"+tenant, nil, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK &&
+		resp.StatusCode != http.StatusConflict {
+		return errors.Errorf("create tenant %s: %s", tenant, resp.Status)
+	}
+	return nil
+}
+
+// CreateNamespace creates the namespace of <tenant>/<namespace>, the namespace existing is ignored
+func (p *pulsarAdmin) CreateNamespace(ctx context.Context, namespace string) error {
+	resp, err := p.do(ctx, http.MethodPut, "/admin/v2/namespaces/"+namespace, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK &&
+		resp.StatusCode != http.StatusConflict {
+		return errors.Errorf("create namespace %s: %s", namespace, resp.Status)
+	}
+	return nil
+}
+
+// isMilvusTopic returns true if the topic is a message channel of the prefix
+func isMilvusTopic(topic, prefix string) bool {
+	name := topic[strings.LastIndex(topic, "/")+1:]
+	if !strings.HasPrefix(name, prefix+"-") {
+		return false
+	}
+	name = strings.TrimPrefix(name, prefix+"-")
+	for _, suffix := range milvusChannelSuffixes {
+		if strings.HasPrefix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// DeletePulsarData deletes the message channel topics of the prefix in the pulsar namespaces
+func DeletePulsarData(ctx context.Context, adminEndpoint string, namespaces []string, prefix string) error {
+	if prefix == "" {
+		return errors.New("empty msgChannelPrefix")
+	}
+
+	admin := newPulsarAdminClientFunc(adminEndpoint)
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	topics := []string{}
+	for _, namespace := range namespaces {
+		namespaceTopics, err := admin.ListTopics(ctx, namespace)
+		if err != nil {
+			return err
+		}
+		topics = append(topics, namespaceTopics...)
+	}
+
+	errs := []string{}
+	for _, topic := range topics {
+		if !isMilvusTopic(topic, prefix) {
+			continue
+		}
+		if err := admin.DeleteTopic(ctx, topic); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,307 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/minio/minio-go/v7"
+	"github.com/stretchr/testify/assert"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	corev1 "k8s.io/api/core/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/milvus-io/milvus-operator/apis/milvus.io/v1alpha1"
+)
+
+func TestGetPulsarAdminEndpoint(t *testing.T) {
+	assert.Equal(t, "pulsar:8080", GetPulsarAdminEndpoint(v1alpha1.MilvusPulsar{Endpoint: "pulsar:6650"}))
+	assert.Equal(t, "pulsar:8080", GetPulsarAdminEndpoint(v1alpha1.MilvusPulsar{Endpoint: "pulsar"}))
+	assert.Equal(t, "admin:80", GetPulsarAdminEndpoint(v1alpha1.MilvusPulsar{Endpoint: "pulsar:6650", AdminEndpoint: "admin:80"}))
+	assert.Equal(t, "[fd00::1]:8080", GetPulsarAdminEndpoint(v1alpha1.MilvusPulsar{Endpoint: "pulsar+ssl://[fd00::1]"}))
+	assert.Equal(t, "https://admin:443", GetPulsarAdminEndpoint(v1alpha1.MilvusPulsar{Endpoint: "pulsar", AdminEndpoint: "https://admin"}))
+	// invalid ones are kept
+	assert.Equal(t, "admin:port", GetPulsarAdminEndpoint(v1alpha1.MilvusPulsar{Endpoint: "pulsar", AdminEndpoint: "admin:port"}))
+}
+
+func TestGetExternalDataInfo_Endpoints(t *testing.T) {
+	m := v1alpha1.Milvus{}
+	m.Name = "m"
+	m.Spec.Dep.DataDeletionPolicy = v1alpha1.DeletionPolicyDelete
+	m.Spec.Dep.Etcd.External = true
+	m.Spec.Dep.Etcd.Endpoints = []string{"fd00::1"}
+	m.Spec.Dep.Storage.External = true
+	m.Spec.Dep.Storage.Endpoint = "https://minio"
+	info := GetExternalDataInfo(m)
+	assert.Equal(t, []string{"[fd00::1]:2379"}, info.EtcdEndpoints)
+	assert.Equal(t, "minio:443", info.Storage.Endpoint)
+	assert.True(t, info.UseSSL)
+}
+
+func TestGetExternalDataInfo(t *testing.T) {
+	m := v1alpha1.Milvus{}
+	m.Name = "m"
+	m.Spec.Dep.DataDeletionPolicy = v1alpha1.DeletionPolicyDelete
+	m.Spec.Dep.Storage.External = true
+	m.Spec.Dep.Storage.Endpoint = "minio:9000"
+	m.Spec.Dep.Pulsar.External = true
+	m.Spec.Dep.Pulsar.Endpoint = "pulsar:6650"
+	info := GetExternalDataInfo(m)
+	assert.Empty(t, info.EtcdEndpoints)
+	assert.Equal(t, "m", info.BucketName)
+	// the standalone uses its embedded message stream
+	assert.Empty(t, info.PulsarAdminEndpoint)
+	assert.False(t, info.IsEmpty())
+}
+
+func TestGetExternalDataInfo_ClusterMode(t *testing.T) {
+	mc := v1alpha1.Milvus{}
+	mc.Namespace = "ns"
+	mc.Name = "mc"
+	mc.Spec.Mode = v1alpha1.MilvusModeCluster
+	mc.Spec.Dep.Etcd.External = true
+	mc.Spec.Dep.Etcd.Endpoints = []string{"etcd:2379"}
+	mc.Spec.Dep.Storage.External = true
+	mc.Spec.Dep.Storage.Endpoint = "minio:9000"
+	mc.Spec.Dep.Storage.BucketName = "ns-mc"
+	mc.Spec.Dep.Pulsar.External = true
+	mc.Spec.Dep.Pulsar.Endpoint = "pulsar:6650"
+
+	// retain by default
+	assert.True(t, GetExternalDataInfo(mc).IsEmpty())
+
+	mc.Spec.Dep.DataDeletionPolicy = v1alpha1.DeletionPolicyDelete
+	info := GetExternalDataInfo(mc)
+	assert.Equal(t, "ns", info.Namespace)
+	assert.Equal(t, []string{"etcd:2379"}, info.EtcdEndpoints)
+	assert.Equal(t, "mc", info.EtcdRootPath)
+	assert.Equal(t, "minio:9000", info.Storage.Endpoint)
+	assert.Equal(t, "ns-mc", info.BucketName)
+	assert.Equal(t, "pulsar:8080", info.PulsarAdminEndpoint)
+	assert.Equal(t, []string{PulsarTopicNamespace}, info.PulsarNamespaces)
+	assert.Equal(t, "mc", info.MsgChannelPrefix)
+
+	// in cluster dependencies are deleted by their deletionPolicy
+	mc.Spec.Dep.Etcd.External = false
+	mc.Spec.Dep.Storage.External = false
+	mc.Spec.Dep.Pulsar.External = false
+	assert.True(t, GetExternalDataInfo(mc).IsEmpty())
+}
+
+func TestIsMilvusTopic(t *testing.T) {
+	assert.True(t, isMilvusTopic("persistent://public/default/mc-rootcoord-dml_0", "mc"))
+	assert.True(t, isMilvusTopic("persistent://public/default/mc-insert-channel-1", "mc"))
+	assert.False(t, isMilvusTopic("persistent://public/default/mc2-rootcoord-dml_0", "mc"))
+	assert.False(t, isMilvusTopic("persistent://public/default/mc-other", "mc"))
+	assert.False(t, isMilvusTopic("persistent://public/default/mc-2-rootcoord-dml_0", "mc"))
+}
+
+func TestDeleteEtcdData(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	defer func(f NewEtcdClientFunc) { etcdNewClient = f }(etcdNewClient)
+
+	ctx := context.TODO()
+	errTest := errors.New("test")
+
+	// new client failed
+	etcdNewClient = getMockNewEtcdClient(nil, errTest)
+	assert.Error(t, DeleteEtcdData(ctx, []string{"etcd:2379"}, "mc"))
+
+	// delete the prefix
+	mockEtcdCli := NewMockEtcdClient(ctrl)
+	etcdNewClient = getMockNewEtcdClient(mockEtcdCli, nil)
+	gomock.InOrder(
+		mockEtcdCli.EXPECT().Delete(gomock.Any(), "mc/", gomock.Any()).Return(&clientv3.DeleteResponse{}, nil),
+		mockEtcdCli.EXPECT().Close(),
+	)
+	assert.NoError(t, DeleteEtcdData(ctx, []string{"etcd:2379"}, "mc"))
+
+	// empty rootPath
+	assert.Error(t, DeleteEtcdData(ctx, []string{"etcd:2379"}, ""))
+}
+
+func getMockNewMinioBucketClientFunc(cli MinioBucketClient, err error) NewMinioBucketClientFunc {
+	return func(endpoint string, accessKeyID, secretAccessKey string, secure bool) (MinioBucketClient, error) {
+		return cli, err
+	}
+}
+
+func mockStorageSecret(mockK8sCli *MockK8sClient) {
+	mockK8sCli.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(ctx interface{}, key interface{}, secret *corev1.Secret) {
+			secret.Data = map[string][]byte{
+				AccessKey: []byte("accessKeyID"),
+				SecretKey: []byte("secretAccessKey"),
+			}
+		})
+}
+
+func TestDeleteStorageData(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	defer func(f NewMinioBucketClientFunc) { newMinioBucketClientFunc = f }(newMinioBucketClientFunc)
+
+	ctx := context.TODO()
+	errTest := errors.New("test")
+	mockK8sCli := NewMockK8sClient(ctrl)
+	mockMinio := NewMockMinioBucketClient(ctrl)
+	newMinioBucketClientFunc = getMockNewMinioBucketClientFunc(mockMinio, nil)
+	info := ExternalDataInfo{
+		Namespace:  "ns",
+		Storage:    v1alpha1.MilvusStorage{Endpoint: "minio:9000", SecretRef: "secret"},
+		BucketName: "mc",
+	}
+
+	// get secret failed
+	mockK8sCli.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).Return(errTest)
+	assert.Error(t, DeleteStorageData(ctx, mockK8sCli, info))
+
+	// bucket already deleted
+	mockStorageSecret(mockK8sCli)
+	mockMinio.EXPECT().BucketExists(gomock.Any(), "mc").Return(false, nil)
+	assert.NoError(t, DeleteStorageData(ctx, mockK8sCli, info))
+
+	// remove objects and bucket
+	mockStorageSecret(mockK8sCli)
+	mockMinio.EXPECT().BucketExists(gomock.Any(), "mc").Return(true, nil)
+	mockMinio.EXPECT().ListObjects(gomock.Any(), "mc", gomock.Any()).
+		DoAndReturn(func(ctx context.Context, bucketName string, opts minio.ListObjectsOptions) <-chan minio.ObjectInfo {
+			ch := make(chan minio.ObjectInfo, 2)
+			ch <- minio.ObjectInfo{Key: "a"}
+			ch <- minio.ObjectInfo{Key: "b"}
+			close(ch)
+			return ch
+		})
+	mockMinio.EXPECT().RemoveObjects(gomock.Any(), "mc", gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, bucketName string, objectsCh <-chan minio.ObjectInfo, opts minio.RemoveObjectsOptions) <-chan minio.RemoveObjectError {
+			keys := []string{}
+			for object := range objectsCh {
+				keys = append(keys, object.Key)
+			}
+			assert.Equal(t, []string{"a", "b"}, keys)
+			ch := make(chan minio.RemoveObjectError)
+			close(ch)
+			return ch
+		})
+	mockMinio.EXPECT().RemoveBucket(gomock.Any(), "mc").Return(nil)
+	assert.NoError(t, DeleteStorageData(ctx, mockK8sCli, info))
+
+	// remove object failed
+	mockStorageSecret(mockK8sCli)
+	mockMinio.EXPECT().BucketExists(gomock.Any(), "mc").Return(true, nil)
+	mockMinio.EXPECT().ListObjects(gomock.Any(), "mc", gomock.Any()).
+		DoAndReturn(func(ctx context.Context, bucketName string, opts minio.ListObjectsOptions) <-chan minio.ObjectInfo {
+			ch := make(chan minio.ObjectInfo, 1)
+			ch <- minio.ObjectInfo{Key: "a"}
+			close(ch)
+			return ch
+		})
+	mockMinio.EXPECT().RemoveObjects(gomock.Any(), "mc", gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, bucketName string, objectsCh <-chan minio.ObjectInfo, opts minio.RemoveObjectsOptions) <-chan minio.RemoveObjectError {
+			object := <-objectsCh
+			ch := make(chan minio.RemoveObjectError, 1)
+			ch <- minio.RemoveObjectError{ObjectName: object.Key, Err: errTest}
+			close(ch)
+			return ch
+		})
+	assert.Error(t, DeleteStorageData(ctx, mockK8sCli, info))
+}
+
+func TestPulsarAdmin(t *testing.T) {
+	deleted := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			// the namespace of the pool not found
+			if r.URL.Path == "/admin/v2/persistent/ns/mc" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			assert.Equal(t, "/admin/v2/persistent/public/default", r.URL.Path)
+			w.Write([]byte(`["persistent://public/default/mc-rootcoord-dml_0","persistent://public/default/other"]`))
+		case http.MethodDelete:
+			assert.Equal(t, "true", r.URL.Query().Get("force"))
+			deleted = append(deleted, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	assert.NoError(t, DeletePulsarData(context.TODO(), server.URL, []string{"ns/mc", PulsarTopicNamespace}, "mc"))
+	assert.Equal(t, []string{"/admin/v2/persistent/public/default/mc-rootcoord-dml_0"}, deleted)
+}
+
+func TestPulsarAdmin_CreateNamespace(t *testing.T) {
+	requests := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch r.URL.Path {
+		case "/admin/v2/clusters":
+			w.Write([]byte(`["pulsar"]`))
+		case "/admin/v2/tenants/ns":
+			body := map[string]interface{}{}
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, []interface{}{"pulsar"}, body["allowedClusters"])
+			w.WriteHeader(http.StatusNoContent)
+		case "/admin/v2/namespaces/ns/mc":
+			// created already
+			w.WriteHeader(http.StatusConflict)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.TODO()
+	admin := newPulsarAdminClientFunc(server.URL)
+	clusters, err := admin.ListClusters(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"pulsar"}, clusters)
+	assert.NoError(t, admin.CreateTenant(ctx, "ns", clusters))
+	assert.NoError(t, admin.CreateNamespace(ctx, "ns/mc"))
+	assert.Equal(t, []string{"GET /admin/v2/clusters", "PUT /admin/v2/tenants/ns", "PUT /admin/v2/namespaces/ns/mc"}, requests)
+
+	assert.Error(t, admin.CreateTenant(ctx, "other", clusters))
+	assert.Error(t, admin.CreateNamespace(ctx, "other/mc"))
+}
+
+func TestDeletePulsarData_Failed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	assert.Error(t, DeletePulsarData(context.TODO(), server.URL, []string{PulsarTopicNamespace}, "mc"))
+	assert.Error(t, DeletePulsarData(context.TODO(), server.URL, []string{PulsarTopicNamespace}, ""))
+}
+
+func TestDeleteExternalData(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	defer func(f NewEtcdClientFunc) { etcdNewClient = f }(etcdNewClient)
+
+	ctx := context.TODO()
+	logger := logf.Log.WithName("test")
+	mockEtcdCli := NewMockEtcdClient(ctrl)
+	etcdNewClient = getMockNewEtcdClient(mockEtcdCli, nil)
+	info := ExternalDataInfo{EtcdEndpoints: []string{"etcd:2379"}, EtcdRootPath: "mc"}
+
+	mockEtcdCli.EXPECT().Delete(gomock.Any(), "mc/", gomock.Any()).Return(nil, errors.New("test"))
+	mockEtcdCli.EXPECT().Close()
+	cond := DeleteExternalData(ctx, logger, nil, info)
+	assert.Equal(t, v1alpha1.DataDeleted, cond.Type)
+	assert.Equal(t, corev1.ConditionFalse, cond.Status)
+	assert.Equal(t, v1alpha1.ReasonDataDeleting, cond.Reason)
+	assert.Contains(t, cond.Message, "etcd rootPath mc: test")
+
+	mockEtcdCli.EXPECT().Delete(gomock.Any(), "mc/", gomock.Any()).Return(&clientv3.DeleteResponse{}, nil)
+	mockEtcdCli.EXPECT().Close()
+	cond = DeleteExternalData(ctx, logger, nil, info)
+	assert.Equal(t, corev1.ConditionTrue, cond.Status)
+	assert.Equal(t, v1alpha1.ReasonDataDeleted, cond.Reason)
+}
@@ -5,42 +5,68 @@ import (
 	"fmt"
 	"os"
 	"reflect"
+	"sync"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/milvus-io/milvus-operator/apis/milvus.io/v1alpha1"
 	"github.com/milvus-io/milvus-operator/pkg/helm"
+	"github.com/milvus-io/milvus-operator/pkg/util"
+	"github.com/pkg/errors"
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 //go:generate mockgen -package=controllers -source=dependencies.go -destination=dependencies_mock.go HelmReconciler
 
 const (
-	EtcdChart   = "config/assets/charts/etcd"
-	MinioChart  = "config/assets/charts/minio"
-	PulsarChart = "config/assets/charts/pulsar"
+	EtcdChart   = "etcd"
+	MinioChart  = "minio"
+	PulsarChart = "pulsar"
 )
 
 // HelmReconciler reconciles Helm releases
 type HelmReconciler interface {
 	NewHelmCfg(namespace string) *action.Configuration
 	Reconcile(ctx context.Context, request helm.ChartRequest) error
+	GetReleaseCondition(namespace, releaseName string, condType v1alpha1.MiluvsConditionType) v1alpha1.MilvusCondition
+}
+
+// helmRetryInterval is the interval to retry a failed install, upgrade or rollback
+const helmRetryInterval = time.Minute
+
+// releaseOperation is the last install, upgrade or rollback of a release
+type releaseOperation struct {
+	name     string
+	running  bool
+	err      error
+	finished time.Time
 }
 
 // LocalHelmReconciler implements HelmReconciler at local
 type LocalHelmReconciler struct {
 	helmSettings *cli.EnvSettings
 	logger       logr.Logger
+
+	mu         sync.Mutex
+	operations map[string]*releaseOperation
+	wg         sync.WaitGroup
 }
 
 func NewLocalHelmReconciler(helmSettings *cli.EnvSettings, logger logr.Logger) HelmReconciler {
 	return &LocalHelmReconciler{
 		helmSettings: helmSettings,
 		logger:       logger,
+		operations:   map[string]*releaseOperation{},
 	}
 }
 
-func (l LocalHelmReconciler) NewHelmCfg(namespace string) *action.Configuration {
+func (l *LocalHelmReconciler) NewHelmCfg(namespace string) *action.Configuration {
 	cfg := new(action.Configuration)
 	helmLogger := func(format string, v ...interface{}) {
 		l.logger.Info(fmt.Sprintf(format, v...))
@@ -52,8 +78,63 @@ func (l LocalHelmReconciler) NewHelmCfg(namespace string) *action.Configuration
 	return cfg
 }
 
-// ReconcileHelm reconciles Helm releases
-func (l LocalHelmReconciler) Reconcile(ctx context.Context, request helm.ChartRequest) error {
+func releaseKey(namespace, releaseName string) string {
+	return namespace + "/" + releaseName
+}
+
+func (l *LocalHelmReconciler) getOperation(key string) (releaseOperation, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	op, ok := l.operations[key]
+	if !ok {
+		return releaseOperation{}, false
+	}
+	return *op, true
+}
+
+// runAsync runs the install, upgrade or rollback in background, so that waiting the release ready won't block the reconcile.
+// It's skipped if the last one is still running, or failed within the helmRetryInterval
+func (l *LocalHelmReconciler) runAsync(key, name string, run func() error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if op, ok := l.operations[key]; ok {
+		if op.running || op.err != nil && time.Since(op.finished) < helmRetryInterval {
+			return
+		}
+	}
+
+	op := &releaseOperation{name: name, running: true}
+	l.operations[key] = op
+	l.wg.Add(1)
+	go func() {
+		defer l.wg.Done()
+		err := run()
+		if err != nil {
+			l.logger.Error(err, "helm operation failed", "release", key, "operation", name)
+		} else {
+			l.logger.Info("helm operation done", "release", key, "operation", name)
+		}
+
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		op.running = false
+		op.err = err
+		op.finished = time.Now()
+	}()
+}
+
+// wait waits for the running operations to finish
+func (l *LocalHelmReconciler) wait() {
+	l.wg.Wait()
+}
+
+// Reconcile installs or upgrades the release in background, and rolls back the failed one
+func (l *LocalHelmReconciler) Reconcile(ctx context.Context, request helm.ChartRequest) error {
+	key := releaseKey(request.Namespace, request.ReleaseName)
+	if op, ok := l.getOperation(key); ok && op.running {
+		return nil
+	}
+
 	cfg := l.NewHelmCfg(request.Namespace)
 
 	exist, err := helm.ReleaseExist(cfg, request.ReleaseName)
@@ -65,7 +146,10 @@ func (l LocalHelmReconciler) Reconcile(ctx context.Context, request helm.ChartRe
 		if request.Chart == PulsarChart {
 			request.Values["initialize"] = true
 		}
-		return helm.Install(cfg, request)
+		l.runAsync(key, "install", func() error {
+			return helm.Install(cfg, request)
+		})
+		return nil
 	}
 
 	vals, err := helm.GetValues(cfg, request.ReleaseName)
@@ -78,64 +162,190 @@ func (l LocalHelmReconciler) Reconcile(ctx context.Context, request helm.ChartRe
 		return err
 	}
 
+	if status == release.StatusFailed {
+		l.runAsync(key, "rollback", func() error {
+			err := helm.Rollback(cfg, request)
+			if !errors.Is(err, helm.ErrNoDeployedRevision) {
+				return err
+			}
+			// never deployed, upgrade it instead
+			return helm.Update(cfg, request)
+		})
+		return nil
+	}
+
 	if request.Chart == PulsarChart {
 		delete(vals, "initialize")
 	}
 
-	if reflect.DeepEqual(vals, request.Values) && !helm.NeedUpdate(status) {
+	if reflect.DeepEqual(vals, request.Values) && !helm.NeedUpdate(status) && !l.needChartUpdate(cfg, request) {
 		return nil
 	}
 
-	return helm.Update(cfg, request)
+	l.runAsync(key, "upgrade", func() error {
+		return helm.Update(cfg, request)
+	})
+	return nil
 }
 
-func (r *MilvusClusterReconciler) ReconcileEtcd(ctx context.Context, mc v1alpha1.MilvusCluster) error {
-	if mc.Spec.Dep.Etcd.External {
-		return nil
+// needChartUpdate returns whether the chart version pinned in the request is not deployed yet
+func (l *LocalHelmReconciler) needChartUpdate(cfg *action.Configuration, request helm.ChartRequest) bool {
+	if request.Source == nil {
+		return false
+	}
+	rel, err := helm.GetRelease(cfg, request.ReleaseName)
+	if err != nil {
+		l.logger.Error(err, "get release failed", "release", releaseKey(request.Namespace, request.ReleaseName))
+		return false
 	}
+	return helm.NeedChartUpdate(rel, request.Source)
+}
 
-	request := helm.ChartRequest{
-		ReleaseName: mc.Name + "-etcd",
-		Namespace:   mc.Namespace,
-		Chart:       EtcdChart,
-		Values:      mc.Spec.Dep.Etcd.InCluster.Values.Data,
+// GetReleaseCondition returns the condition of the release with its chart version, revision and status
+func (l *LocalHelmReconciler) GetReleaseCondition(namespace, releaseName string, condType v1alpha1.MiluvsConditionType) v1alpha1.MilvusCondition {
+	cond := v1alpha1.MilvusCondition{
+		Type:   condType,
+		Status: corev1.ConditionFalse,
+	}
+
+	key := releaseKey(namespace, releaseName)
+	op, hasOp := l.getOperation(key)
+	lastErr := ""
+	if hasOp && op.err != nil {
+		lastErr = fmt.Sprintf(", last %s failed: %s", op.name, op.err.Error())
+	}
+
+	rel, err := helm.GetRelease(l.NewHelmCfg(namespace), releaseName)
+	if err != nil {
+		if errors.Is(err, driver.ErrReleaseNotFound) {
+			cond.Reason = v1alpha1.ReasonReleaseNotFound
+			cond.Message = "release not found" + lastErr
+			if hasOp && op.running {
+				cond.Reason = v1alpha1.ReasonReleasePending
+				cond.Message = "release installing"
+			}
+			return cond
+		}
+		cond.Status = corev1.ConditionUnknown
+		cond.Reason = v1alpha1.ReasonClientErr
+		cond.Message = err.Error()
+		return cond
 	}
 
-	return r.helmReconciler.Reconcile(ctx, request)
+	status := release.StatusUnknown
+	if rel.Info != nil {
+		status = rel.Info.Status
+	}
+	chartVersion := ""
+	if rel.Chart != nil && rel.Chart.Metadata != nil {
+		chartVersion = rel.Chart.Metadata.Name + "-" + rel.Chart.Metadata.Version
+	}
+	cond.Message = fmt.Sprintf("chart %s, revision %d, status %s", chartVersion, rel.Version, status) + lastErr
+
+	switch {
+	case status == release.StatusDeployed:
+		cond.Status = corev1.ConditionTrue
+		cond.Reason = v1alpha1.ReasonReleaseDeployed
+	case status.IsPending() || hasOp && op.running:
+		cond.Reason = v1alpha1.ReasonReleasePending
+	default:
+		cond.Reason = v1alpha1.ReasonReleaseFailed
+	}
+	return cond
 }
 
-func (r *MilvusClusterReconciler) ReconcilePulsar(ctx context.Context, mc v1alpha1.MilvusCluster) error {
-	if mc.Spec.Dep.Pulsar.External {
+// UninstallReleases uninstalls the releases in the namespace, the PVCs of a release are deleted as well if it's mapped to true
+func UninstallReleases(ctx context.Context, cli client.Client, logger logr.Logger, helmReconciler HelmReconciler,
+	namespace string, releases map[string]bool) error {
+	if len(releases) == 0 {
 		return nil
 	}
+	cfg := helmReconciler.NewHelmCfg(namespace)
 
-	request := helm.ChartRequest{
-		ReleaseName: mc.Name + "-pulsar",
-		Namespace:   mc.Namespace,
-		Chart:       PulsarChart,
-		Values:      mc.Spec.Dep.Pulsar.InCluster.Values.Data,
+	errs := []error{}
+	for releaseName, deletePVC := range releases {
+		if err := helm.Uninstall(cfg, releaseName); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if deletePVC {
+			pvcList := &corev1.PersistentVolumeClaimList{}
+			if err := cli.List(ctx, pvcList, &client.ListOptions{
+				Namespace: namespace,
+				LabelSelector: labels.SelectorFromSet(map[string]string{
+					AppLabelInstance: releaseName,
+				}),
+			}); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+
+			for _, pvc := range pvcList.Items {
+				if err := cli.Delete(ctx, &pvc); err != nil {
+					errs = append(errs, err)
+				} else {
+					logger.Info("pvc deleted", "name", pvc.Name, "namespace", pvc.Namespace)
+				}
+			}
+		}
 	}
 
-	return r.helmReconciler.Reconcile(ctx, request)
+	if len(errs) > 0 {
+		return errors.Errorf(util.JoinErrors(errs))
+	}
+	return nil
 }
 
-func (r *MilvusClusterReconciler) ReconcileMinio(ctx context.Context, mc v1alpha1.MilvusCluster) error {
-	if mc.Spec.Dep.Storage.External {
+// inClusterTimeout returns the timeout set in spec, zero for the default one
+func inClusterTimeout(inCluster *v1alpha1.InClusterConfig) time.Duration {
+	if inCluster == nil || inCluster.Timeout == nil {
+		return 0
+	}
+	return inCluster.Timeout.Duration
+}
+
+// inClusterChartSource returns the chart source set in spec, nil for the bundled chart
+func inClusterChartSource(inCluster *v1alpha1.InClusterConfig) *v1alpha1.ChartSource {
+	if inCluster == nil {
 		return nil
 	}
+	return inCluster.Chart.DeepCopy()
+}
 
-	request := helm.ChartRequest{
-		ReleaseName: mc.Name + "-minio",
-		Namespace:   mc.Namespace,
-		Chart:       MinioChart,
-		Values:      mc.Spec.Dep.Storage.InCluster.Values.Data,
+// GetChartRegistryConfigs returns the docker configs in the pull secrets of the OCI chart source in the namespace
+func GetChartRegistryConfigs(ctx context.Context, cli client.Client, namespace string, source *v1alpha1.ChartSource) ([][]byte, error) {
+	if source == nil || !source.IsOCI() {
+		return nil, nil
+	}
+
+	var ret [][]byte
+	for _, ref := range source.PullSecrets {
+		secret := &corev1.Secret{}
+		if err := cli.Get(ctx, NamespacedName(namespace, ref.Name), secret); err != nil {
+			return nil, errors.Wrapf(err, "get chart pull secret %s", ref.Name)
+		}
+		registryConfig, ok := secret.Data[corev1.DockerConfigJsonKey]
+		if !ok {
+			return nil, errors.Errorf("chart pull secret %s has no %s", ref.Name, corev1.DockerConfigJsonKey)
+		}
+		ret = append(ret, registryConfig)
 	}
+	return ret, nil
+}
 
-	return r.helmReconciler.Reconcile(ctx, request)
+// reconcileRelease installs or upgrades the release of the request, with the credentials of its chart source
+func reconcileRelease(ctx context.Context, cli client.Client, helmReconciler HelmReconciler, request helm.ChartRequest) error {
+	registryConfigs, err := GetChartRegistryConfigs(ctx, cli, request.Namespace, request.Source)
+	if err != nil {
+		return err
+	}
+	request.RegistryConfigs = registryConfigs
+	return helmReconciler.Reconcile(ctx, request)
 }
 
 func (r *MilvusReconciler) ReconcileEtcd(ctx context.Context, mil v1alpha1.Milvus) error {
-	if mil.Spec.Dep.Etcd.External {
+	if mil.Spec.Dep.Etcd.External || mil.Spec.Dep.Pool != nil {
 		return nil
 	}
 
@@ -143,23 +353,50 @@ func (r *MilvusReconciler) ReconcileEtcd(ctx context.Context, mil v1alpha1.Milvu
 		ReleaseName: mil.Name + "-etcd",
 		Namespace:   mil.Namespace,
 		Chart:       EtcdChart,
-		Values:      mil.Spec.Dep.Etcd.InCluster.Values.Data,
+		Values:      dependencyValues(mil.Spec.Dep.Etcd.InCluster.Values, GetImageRegistry(mil.Spec.ImageRegistry), InjectBitnamiImageValues),
+		Timeout:     inClusterTimeout(mil.Spec.Dep.Etcd.InCluster),
+		Source:      inClusterChartSource(mil.Spec.Dep.Etcd.InCluster),
 	}
 
-	return r.helmReconciler.Reconcile(ctx, request)
+	return reconcileRelease(ctx, r.Client, r.helmReconciler, request)
 }
 
 func (r *MilvusReconciler) ReconcileMinio(ctx context.Context, mil v1alpha1.Milvus) error {
 	if mil.Spec.Dep.Storage.External {
 		return nil
 	}
+	if mil.Spec.Dep.Pool != nil {
+		return r.ReconcilePoolStorageSecret(ctx, mil)
+	}
 
 	request := helm.ChartRequest{
 		ReleaseName: mil.Name + "-minio",
 		Namespace:   mil.Namespace,
 		Chart:       MinioChart,
-		Values:      mil.Spec.Dep.Storage.InCluster.Values.Data,
+		Values:      dependencyValues(mil.Spec.Dep.Storage.InCluster.Values, GetImageRegistry(mil.Spec.ImageRegistry), InjectBitnamiImageValues),
+		Timeout:     inClusterTimeout(mil.Spec.Dep.Storage.InCluster),
+		Source:      inClusterChartSource(mil.Spec.Dep.Storage.InCluster),
+	}
+
+	return reconcileRelease(ctx, r.Client, r.helmReconciler, request)
+}
+
+func (r *MilvusReconciler) ReconcilePulsar(ctx context.Context, mil v1alpha1.Milvus) error {
+	if !mil.Spec.IsCluster() || mil.Spec.Dep.Pulsar.External {
+		return nil
+	}
+	if mil.Spec.Dep.Pool != nil {
+		return r.ReconcilePoolPulsarNamespace(ctx, mil)
+	}
+
+	request := helm.ChartRequest{
+		ReleaseName: mil.Name + "-pulsar",
+		Namespace:   mil.Namespace,
+		Chart:       PulsarChart,
+		Values:      dependencyValues(mil.Spec.Dep.Pulsar.InCluster.Values, GetImageRegistry(mil.Spec.ImageRegistry), InjectPulsarImageValues),
+		Timeout:     inClusterTimeout(mil.Spec.Dep.Pulsar.InCluster),
+		Source:      inClusterChartSource(mil.Spec.Dep.Pulsar.InCluster),
 	}
 
-	return r.helmReconciler.Reconcile(ctx, request)
+	return reconcileRelease(ctx, r.Client, r.helmReconciler, request)
 }
@@ -5,15 +5,21 @@ import (
 	"errors"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/milvus-io/milvus-operator/apis/milvus.io/v1alpha1"
 	"github.com/milvus-io/milvus-operator/pkg/helm"
 	"github.com/stretchr/testify/assert"
 	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/cli"
 	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func TestLocalHelmReconciler_ReconcilePanic(t *testing.T) {
@@ -75,6 +81,7 @@ func TestLocalHelmReconciler_Reconcile(t *testing.T) {
 		})
 	err = rec.Reconcile(ctx, request)
 	assert.NoError(t, err)
+	rec.(*LocalHelmReconciler).wait()
 
 	// existed, get values failed
 	request.Values = make(map[string]interface{})
@@ -122,6 +129,197 @@ func TestLocalHelmReconciler_Reconcile(t *testing.T) {
 		})
 	err = rec.Reconcile(ctx, request)
 	assert.NoError(t, err)
+	rec.(*LocalHelmReconciler).wait()
+
+	// existed, failed, rolled back in background with the request's timeout
+	request.Timeout = time.Minute
+	mockHelm.EXPECT().
+		ReleaseExist(gomock.Any(), gomock.Any()).
+		Return(true, nil)
+	mockHelm.EXPECT().GetValues(gomock.Any(), gomock.Any()).Return(map[string]interface{}{}, nil)
+	mockHelm.EXPECT().GetStatus(gomock.Any(), gomock.Any()).Return(release.StatusFailed, nil)
+	mockHelm.EXPECT().Rollback(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(cfg *action.Configuration, request helm.ChartRequest) error {
+			assert.Equal(t, time.Minute, request.Timeout)
+			return nil
+		})
+	err = rec.Reconcile(ctx, request)
+	assert.NoError(t, err)
+	rec.(*LocalHelmReconciler).wait()
+
+	// existed, failed, rollback failed
+	mockHelm.EXPECT().
+		ReleaseExist(gomock.Any(), gomock.Any()).
+		Return(true, nil)
+	mockHelm.EXPECT().GetValues(gomock.Any(), gomock.Any()).Return(map[string]interface{}{}, nil)
+	mockHelm.EXPECT().GetStatus(gomock.Any(), gomock.Any()).Return(release.StatusFailed, nil)
+	mockHelm.EXPECT().Rollback(gomock.Any(), gomock.Any()).Return(errTest)
+	err = rec.Reconcile(ctx, request)
+	assert.NoError(t, err)
+	rec.(*LocalHelmReconciler).wait()
+	op, _ := rec.(*LocalHelmReconciler).getOperation(releaseKey(request.Namespace, request.ReleaseName))
+	assert.Equal(t, "rollback", op.name)
+	assert.Equal(t, errTest, op.err)
+
+	// existed, failed, never deployed, update
+	rec = NewLocalHelmReconciler(cli.New(), ctrl.Log.WithName("test"))
+	mockHelm.EXPECT().
+		ReleaseExist(gomock.Any(), gomock.Any()).
+		Return(true, nil)
+	mockHelm.EXPECT().GetValues(gomock.Any(), gomock.Any()).Return(map[string]interface{}{}, nil)
+	mockHelm.EXPECT().GetStatus(gomock.Any(), gomock.Any()).Return(release.StatusFailed, nil)
+	mockHelm.EXPECT().Rollback(gomock.Any(), gomock.Any()).Return(helm.ErrNoDeployedRevision)
+	mockHelm.EXPECT().Update(gomock.Any(), gomock.Any()).Return(errTest)
+	err = rec.Reconcile(ctx, request)
+	assert.NoError(t, err)
+	rec.(*LocalHelmReconciler).wait()
+
+	// last update failed just now, not retried
+	mockHelm.EXPECT().
+		ReleaseExist(gomock.Any(), gomock.Any()).
+		Return(true, nil)
+	mockHelm.EXPECT().GetValues(gomock.Any(), gomock.Any()).Return(map[string]interface{}{}, nil)
+	mockHelm.EXPECT().GetStatus(gomock.Any(), gomock.Any()).Return(release.StatusDeployed, nil)
+	err = rec.Reconcile(ctx, request)
+	assert.NoError(t, err)
+	rec.(*LocalHelmReconciler).wait()
+}
+
+func TestLocalHelmReconciler_ReconcileRunning(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockHelm := helm.NewMockClient(mockCtrl)
+	helm.SetDefaultClient(mockHelm)
+
+	ctx := context.TODO()
+	request := helm.ChartRequest{}
+	rec := NewLocalHelmReconciler(cli.New(), ctrl.Log.WithName("test"))
+
+	// install running, skipped
+	installing := make(chan struct{})
+	mockHelm.EXPECT().
+		ReleaseExist(gomock.Any(), gomock.Any()).
+		Return(false, nil)
+	mockHelm.EXPECT().
+		Install(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(cfg *action.Configuration, request helm.ChartRequest) error {
+			<-installing
+			return nil
+		})
+	err := rec.Reconcile(ctx, request)
+	assert.NoError(t, err)
+	err = rec.Reconcile(ctx, request)
+	assert.NoError(t, err)
+	close(installing)
+	rec.(*LocalHelmReconciler).wait()
+}
+
+func TestLocalHelmReconciler_ReconcileChartVersion(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockHelm := helm.NewMockClient(mockCtrl)
+	helm.SetDefaultClient(mockHelm)
+
+	ctx := context.TODO()
+	rec := NewLocalHelmReconciler(cli.New(), ctrl.Log.WithName("test"))
+	request := helm.ChartRequest{
+		ReleaseName: "mc-etcd",
+		Chart:       EtcdChart,
+		Values:      map[string]interface{}{},
+		Source:      &v1alpha1.ChartSource{Repository: "https://charts.bitnami.com/bitnami", Version: "6.3.4"},
+	}
+	deployed := &release.Release{
+		Chart: &chart.Chart{Metadata: &chart.Metadata{Name: "etcd", Version: "6.3.3"}},
+	}
+
+	// pinned version deployed
+	mockHelm.EXPECT().ReleaseExist(gomock.Any(), gomock.Any()).Return(true, nil)
+	mockHelm.EXPECT().GetValues(gomock.Any(), gomock.Any()).Return(map[string]interface{}{}, nil)
+	mockHelm.EXPECT().GetStatus(gomock.Any(), gomock.Any()).Return(release.StatusDeployed, nil)
+	mockHelm.EXPECT().GetRelease(gomock.Any(), "mc-etcd").Return(deployed, nil)
+	request.Source.Version = "6.3.3"
+	err := rec.Reconcile(ctx, request)
+	assert.NoError(t, err)
+
+	// pinned version changed, upgrade
+	mockHelm.EXPECT().ReleaseExist(gomock.Any(), gomock.Any()).Return(true, nil)
+	mockHelm.EXPECT().GetValues(gomock.Any(), gomock.Any()).Return(map[string]interface{}{}, nil)
+	mockHelm.EXPECT().GetStatus(gomock.Any(), gomock.Any()).Return(release.StatusDeployed, nil)
+	mockHelm.EXPECT().GetRelease(gomock.Any(), "mc-etcd").Return(deployed, nil)
+	mockHelm.EXPECT().Update(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(cfg *action.Configuration, request helm.ChartRequest) error {
+			assert.Equal(t, "6.3.4", request.Source.Version)
+			return nil
+		})
+	request.Source.Version = "6.3.4"
+	err = rec.Reconcile(ctx, request)
+	assert.NoError(t, err)
+	rec.(*LocalHelmReconciler).wait()
+}
+
+func TestLocalHelmReconciler_GetReleaseCondition(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockHelm := helm.NewMockClient(mockCtrl)
+	helm.SetDefaultClient(mockHelm)
+
+	rec := NewLocalHelmReconciler(cli.New(), ctrl.Log.WithName("test"))
+	newRelease := func(status release.Status) *release.Release {
+		return &release.Release{
+			Version: 2,
+			Info:    &release.Info{Status: status},
+			Chart: &chart.Chart{
+				Metadata: &chart.Metadata{Name: "etcd", Version: "6.3.3"},
+			},
+		}
+	}
+
+	// query failed
+	mockHelm.EXPECT().GetRelease(gomock.Any(), "mc-etcd").Return(nil, errors.New("test"))
+	cond := rec.GetReleaseCondition("default", "mc-etcd", v1alpha1.EtcdReleaseReady)
+	assert.Equal(t, v1alpha1.EtcdReleaseReady, cond.Type)
+	assert.Equal(t, corev1.ConditionUnknown, cond.Status)
+	assert.Equal(t, v1alpha1.ReasonClientErr, cond.Reason)
+
+	// not found
+	mockHelm.EXPECT().GetRelease(gomock.Any(), "mc-etcd").Return(nil, driver.ErrReleaseNotFound)
+	cond = rec.GetReleaseCondition("default", "mc-etcd", v1alpha1.EtcdReleaseReady)
+	assert.Equal(t, corev1.ConditionFalse, cond.Status)
+	assert.Equal(t, v1alpha1.ReasonReleaseNotFound, cond.Reason)
+
+	// deployed
+	mockHelm.EXPECT().GetRelease(gomock.Any(), "mc-etcd").Return(newRelease(release.StatusDeployed), nil)
+	cond = rec.GetReleaseCondition("default", "mc-etcd", v1alpha1.EtcdReleaseReady)
+	assert.Equal(t, corev1.ConditionTrue, cond.Status)
+	assert.Equal(t, v1alpha1.ReasonReleaseDeployed, cond.Reason)
+	assert.Equal(t, "chart etcd-6.3.3, revision 2, status deployed", cond.Message)
+
+	// pending
+	mockHelm.EXPECT().GetRelease(gomock.Any(), "mc-etcd").Return(newRelease(release.StatusPendingUpgrade), nil)
+	cond = rec.GetReleaseCondition("default", "mc-etcd", v1alpha1.EtcdReleaseReady)
+	assert.Equal(t, corev1.ConditionFalse, cond.Status)
+	assert.Equal(t, v1alpha1.ReasonReleasePending, cond.Reason)
+
+	// failed with the last upgrade error
+	mockHelm.EXPECT().ReleaseExist(gomock.Any(), gomock.Any()).Return(true, nil)
+	mockHelm.EXPECT().GetValues(gomock.Any(), gomock.Any()).Return(map[string]interface{}{}, nil)
+	mockHelm.EXPECT().GetStatus(gomock.Any(), gomock.Any()).Return(release.StatusDeployed, nil)
+	mockHelm.EXPECT().Update(gomock.Any(), gomock.Any()).Return(errors.New("timed out"))
+	err := rec.Reconcile(context.TODO(), helm.ChartRequest{
+		Namespace:   "default",
+		ReleaseName: "mc-etcd",
+		Values:      map[string]interface{}{"replicaCount": 3},
+	})
+	assert.NoError(t, err)
+	rec.(*LocalHelmReconciler).wait()
+	mockHelm.EXPECT().GetRelease(gomock.Any(), "mc-etcd").Return(newRelease(release.StatusFailed), nil)
+	cond = rec.GetReleaseCondition("default", "mc-etcd", v1alpha1.EtcdReleaseReady)
+	assert.Equal(t, corev1.ConditionFalse, cond.Status)
+	assert.Equal(t, v1alpha1.ReasonReleaseFailed, cond.Reason)
+	assert.Contains(t, cond.Message, "last upgrade failed: timed out")
 }
 
 func TestMilvusReconciler_ReconcileDeps(t *testing.T) {
@@ -162,8 +360,8 @@ func TestMilvusReconciler_ReconcileDeps(t *testing.T) {
 	assert.NoError(t, r.ReconcileMinio(ctx, m))
 }
 
-func TestClusterReconciler_ReconcileDeps(t *testing.T) {
-	env := newClusterTestEnv(t)
+func TestMilvusReconciler_ClusterMode_ReconcileDeps(t *testing.T) {
+	env := newClusterModeTestEnv(t)
 	defer env.tearDown()
 	r := env.Reconciler
 	ctx := env.ctx
@@ -208,7 +406,65 @@ func TestClusterReconciler_ReconcileDeps(t *testing.T) {
 		})
 	assert.NoError(t, r.ReconcilePulsar(ctx, m))
 
+	// chart source set
+	m.Spec.Dep.Pulsar.InCluster = &v1alpha1.InClusterConfig{
+		Chart: &v1alpha1.ChartSource{Repository: "https://pulsar.apache.org/charts", Version: "2.7.8"},
+	}
+	mockHelm.EXPECT().Reconcile(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, request helm.ChartRequest) error {
+			assert.Equal(t, &v1alpha1.ChartSource{Repository: "https://pulsar.apache.org/charts", Version: "2.7.8"}, request.Source)
+			return nil
+		})
+	assert.NoError(t, r.ReconcilePulsar(ctx, m))
+
 	// external ignored
 	m.Spec.Dep.Pulsar.External = true
 	assert.NoError(t, r.ReconcilePulsar(ctx, m))
+
+	// standalone ignored
+	m.Spec.Dep.Pulsar.External = false
+	m.Spec.Mode = v1alpha1.MilvusModeStandalone
+	assert.NoError(t, r.ReconcilePulsar(ctx, m))
+}
+
+func TestGetChartRegistryConfigs(t *testing.T) {
+	ctx := context.TODO()
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "registry"},
+		Type:       corev1.SecretTypeDockerConfigJson,
+		Data:       map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths": {}}`)},
+	}
+	opaque := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "opaque"},
+		Data:       map[string][]byte{"password": []byte("pass")},
+	}
+	cli := fake.NewClientBuilder().WithScheme(newSchemeForTest()).WithObjects(secret, opaque).Build()
+
+	configs, err := GetChartRegistryConfigs(ctx, cli, "ns", nil)
+	assert.NoError(t, err)
+	assert.Nil(t, configs)
+
+	// not OCI
+	source := &v1alpha1.ChartSource{
+		Repository:  "https://charts.bitnami.com/bitnami",
+		PullSecrets: []corev1.LocalObjectReference{{Name: "registry"}},
+	}
+	configs, err = GetChartRegistryConfigs(ctx, cli, "ns", source)
+	assert.NoError(t, err)
+	assert.Nil(t, configs)
+
+	source.Repository = "oci://registry-1.docker.io/bitnamicharts/etcd"
+	configs, err = GetChartRegistryConfigs(ctx, cli, "ns", source)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte(`{"auths": {}}`)}, configs)
+
+	// not a docker config
+	source.PullSecrets = append(source.PullSecrets, corev1.LocalObjectReference{Name: "opaque"})
+	_, err = GetChartRegistryConfigs(ctx, cli, "ns", source)
+	assert.Error(t, err)
+
+	// not found
+	source.PullSecrets = []corev1.LocalObjectReference{{Name: "not-exist"}}
+	_, err = GetChartRegistryConfigs(ctx, cli, "ns", source)
+	assert.Error(t, err)
 }
@@ -0,0 +1,344 @@
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/minio/madmin-go"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/milvus-io/milvus-operator/apis/milvus.io/v1alpha1"
+	"github.com/milvus-io/milvus-operator/pkg/helm"
+	"github.com/milvus-io/milvus-operator/pkg/util"
+)
+
+// poolPulsarAdminPort is the http port of the pulsar proxy service, which serves the admin REST API
+const poolPulsarAdminPort = 80
+
+// DependencyPoolReleaseName returns the name of the release of the chart installed by the pool,
+// it's different from the releases installed for an instance of the same name
+func DependencyPoolReleaseName(pool, chart string) string {
+	return pool + "-pool-" + chart
+}
+
+// SetDependencyPoolEndpoints sets the endpoints of the dependencies served by the pool, i.e. the ones not external.
+// The pulsar is skipped if it's nil
+func SetDependencyPoolEndpoints(namespace string, pool v1alpha1.DependencyPoolRef,
+	etcd *v1alpha1.MilvusEtcd, storage *v1alpha1.MilvusStorage, pulsar *v1alpha1.MilvusPulsar) {
+	poolNamespace := pool.GetNamespace(namespace)
+	if !etcd.External {
+		etcd.Endpoints = []string{fmt.Sprintf("%s:%d",
+			ServiceHost(DependencyPoolReleaseName(pool.Name, EtcdChart), poolNamespace), v1alpha1.EtcdDefaultPort)}
+	}
+	if !storage.External {
+		storage.Endpoint = fmt.Sprintf("%s:%d",
+			ServiceHost(DependencyPoolReleaseName(pool.Name, MinioChart), poolNamespace), v1alpha1.StorageDefaultPort)
+	}
+	if pulsar != nil && !pulsar.External {
+		proxyHost := ServiceHost(DependencyPoolReleaseName(pool.Name, PulsarChart)+"-proxy", poolNamespace)
+		pulsar.Endpoint = fmt.Sprintf("%s:%d", proxyHost, v1alpha1.PulsarDefaultPort)
+		pulsar.AdminEndpoint = fmt.Sprintf("http://%s:%d", proxyHost, poolPulsarAdminPort)
+	}
+}
+
+// GetPoolStorageUser returns the access key of the storage user of the instance served by a pool,
+// which is also the name of its policy. MinIO limits the access key to 20 characters
+func GetPoolStorageUser(mil v1alpha1.Milvus) string {
+	return "milvus" + util.CheckSum([]byte(mil.Namespace + "/" + mil.Name))[:12]
+}
+
+// getPoolStoragePolicy returns the policy which only allows the instance to access its bucket,
+// and to get the server info for its storage condition
+func getPoolStoragePolicy(bucket string) []byte {
+	policy := map[string]interface{}{
+		"Version": "2012-10-17",
+		"Statement": []interface{}{
+			map[string]interface{}{
+				"Effect":   "Allow",
+				"Action":   []string{"s3:*"},
+				"Resource": []string{"arn:aws:s3:::" + bucket, "arn:aws:s3:::" + bucket + "/*"},
+			},
+			map[string]interface{}{
+				"Effect": "Allow",
+				"Action": []string{"admin:ServerInfo"},
+			},
+		},
+	}
+	data, _ := json.Marshal(policy)
+	return data
+}
+
+type NewMinioAdminClientFunc func(endpoint string, accessKeyID, secretAccessKey string, secure bool) (MinioAdminClient, error)
+
+// newMinioAdminClientFunc wraps madmin.New for test mock convenience
+var newMinioAdminClientFunc NewMinioAdminClientFunc = func(endpoint string, accessKeyID, secretAccessKey string, secure bool) (MinioAdminClient, error) {
+	return madmin.New(endpoint, accessKeyID, secretAccessKey, secure)
+}
+
+// newPoolMinioAdminClient returns the admin client of the storage of the pool with its root credentials,
+// it returns nil if the credentials of the pool are not found
+func (r *MilvusReconciler) newPoolMinioAdminClient(ctx context.Context, mil v1alpha1.Milvus) (MinioAdminClient, error) {
+	pool := mil.Spec.Dep.Pool
+	poolSecret := &corev1.Secret{}
+	poolNamespace := pool.GetNamespace(mil.Namespace)
+	poolSecretName := DependencyPoolReleaseName(pool.Name, MinioChart)
+	if err := r.Get(ctx, NamespacedName(poolNamespace, poolSecretName), poolSecret); err != nil {
+		if k8sErrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "get storage secret[%s/%s] of the pool", poolNamespace, poolSecretName)
+	}
+
+	endpoint, useSSL, err := GetStorageEndpoint(mil.Spec.Dep.Storage, mil.Spec.Conf.Data)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse storage endpoint")
+	}
+	return newMinioAdminClientFunc(endpoint.HostPort(), string(poolSecret.Data[AccessKey]), string(poolSecret.Data[SecretKey]), useSSL)
+}
+
+// ReconcilePoolStorageSecret creates the storage user of the instance, which only accesses the bucket of the instance,
+// and writes its credentials to the secretRef of the instance. The root credentials of the pool are never copied
+func (r *MilvusReconciler) ReconcilePoolStorageSecret(ctx context.Context, mil v1alpha1.Milvus) error {
+	pool := mil.Spec.Dep.Pool
+	if mil.Spec.Dep.Storage.SecretRef == "" {
+		return errors.New("storage secretRef not set")
+	}
+
+	poolNamespace := pool.GetNamespace(mil.Namespace)
+	existingPool := &v1alpha1.MilvusDependencyPool{}
+	if err := r.Get(ctx, NamespacedName(poolNamespace, pool.Name), existingPool); err != nil {
+		return errors.Wrapf(err, "get dependency pool[%s/%s]", poolNamespace, pool.Name)
+	}
+	if !existingPool.AllowsNamespace(mil.Namespace) {
+		return errors.Errorf("dependency pool[%s/%s] doesn't allow namespace %s", poolNamespace, pool.Name, mil.Namespace)
+	}
+
+	adminCli, err := r.newPoolMinioAdminClient(ctx, mil)
+	if err != nil {
+		return err
+	}
+	if adminCli == nil {
+		return errors.Errorf("storage secret[%s/%s] of the pool not found",
+			poolNamespace, DependencyPoolReleaseName(pool.Name, MinioChart))
+	}
+
+	// the secret key is kept once generated, so that the running pods keep working
+	accessKey := GetPoolStorageUser(mil)
+	secretKey := ""
+	existing := &corev1.Secret{}
+	err = r.Get(ctx, NamespacedName(mil.Namespace, mil.Spec.Dep.Storage.SecretRef), existing)
+	switch {
+	case err == nil && string(existing.Data[AccessKey]) == accessKey:
+		secretKey = string(existing.Data[SecretKey])
+	case err != nil && !k8sErrors.IsNotFound(err):
+		return errors.Wrap(err, "get storage secret")
+	}
+	if secretKey == "" {
+		key := make([]byte, 20)
+		if _, err := rand.Read(key); err != nil {
+			return errors.Wrap(err, "generate storage secret key")
+		}
+		secretKey = hex.EncodeToString(key)
+	}
+
+	bucket := stringDefault(mil.Spec.Dep.Storage.BucketName, mil.Name)
+	if err := adminCli.AddUser(ctx, accessKey, secretKey); err != nil {
+		return errors.Wrap(err, "add storage user")
+	}
+	if err := adminCli.AddCannedPolicy(ctx, accessKey, getPoolStoragePolicy(bucket)); err != nil {
+		return errors.Wrap(err, "add storage policy")
+	}
+	if err := adminCli.SetPolicy(ctx, accessKey, accessKey, false); err != nil {
+		return errors.Wrap(err, "set storage policy")
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: mil.Namespace,
+			Name:      mil.Spec.Dep.Storage.SecretRef,
+			Labels:    NewAppLabels(mil.Name),
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			AccessKey: []byte(accessKey),
+			SecretKey: []byte(secretKey),
+		},
+	}
+	if err := ctrl.SetControllerReference(&mil, secret, r.Scheme); err != nil {
+		return err
+	}
+	return ApplyObject(ctx, r.Client, r.Scheme, secret)
+}
+
+// DeletePoolStorageUser removes the storage user and the policy of the instance served by a pool,
+// they're gone with the pool if its credentials are not found
+func (r *MilvusReconciler) DeletePoolStorageUser(ctx context.Context, mil v1alpha1.Milvus) error {
+	if mil.Spec.Dep.Storage.External {
+		return nil
+	}
+	adminCli, err := r.newPoolMinioAdminClient(ctx, mil)
+	if err != nil || adminCli == nil {
+		return err
+	}
+
+	accessKey := GetPoolStorageUser(mil)
+	if err := adminCli.RemoveUser(ctx, accessKey); err != nil && !isMinioNotFound(err) {
+		return errors.Wrap(err, "remove storage user")
+	}
+	if err := adminCli.RemoveCannedPolicy(ctx, accessKey); err != nil && !isMinioNotFound(err) {
+		return errors.Wrap(err, "remove storage policy")
+	}
+	return nil
+}
+
+// isMinioNotFound returns true if the minio admin error is of the user or the policy not found
+func isMinioNotFound(err error) bool {
+	code := madmin.ToErrorResponse(err).Code
+	return code == "XMinioAdminNoSuchUser" || code == "XMinioAdminNoSuchPolicy"
+}
+
+// GetPoolPulsarNamespace returns the <tenant>/<namespace> of the topics of the instance served by a pool,
+// the tenant is of the namespace of the instance
+func GetPoolPulsarNamespace(mil v1alpha1.Milvus) string {
+	return mil.Namespace + "/" + mil.Name
+}
+
+// ReconcilePoolPulsarNamespace creates the pulsar tenant and namespace of the instance served by a pool
+func (r *MilvusReconciler) ReconcilePoolPulsarNamespace(ctx context.Context, mil v1alpha1.Milvus) error {
+	admin := newPulsarAdminClientFunc(GetPulsarAdminEndpoint(mil.Spec.Dep.Pulsar))
+	clusters, err := admin.ListClusters(ctx)
+	if err != nil {
+		return errors.Wrap(err, "list pulsar clusters")
+	}
+	if err := admin.CreateTenant(ctx, mil.Namespace, clusters); err != nil {
+		return err
+	}
+	return admin.CreateNamespace(ctx, GetPoolPulsarNamespace(mil))
+}
+
+// poolChartRequest returns the request of the release of the chart installed by the pool
+func poolChartRequest(pool v1alpha1.MilvusDependencyPool, chart string, inCluster v1alpha1.InClusterConfig,
+	inject func(map[string]interface{}, string)) helm.ChartRequest {
+	values := dependencyValues(inCluster.Values, GetImageRegistry(pool.Spec.ImageRegistry), inject)
+	if values == nil {
+		values = map[string]interface{}{}
+	}
+	return helm.ChartRequest{
+		ReleaseName: DependencyPoolReleaseName(pool.Name, chart),
+		Namespace:   pool.Namespace,
+		Chart:       chart,
+		Values:      values,
+		Timeout:     inClusterTimeout(&inCluster),
+		Source:      inClusterChartSource(&inCluster),
+	}
+}
+
+// ReconcileReleases installs or upgrades the releases of the dependencies of the pool
+func (r *MilvusDependencyPoolReconciler) ReconcileReleases(ctx context.Context, pool v1alpha1.MilvusDependencyPool) error {
+	requests := []helm.ChartRequest{
+		poolChartRequest(pool, EtcdChart, pool.Spec.Etcd, InjectBitnamiImageValues),
+		poolChartRequest(pool, MinioChart, pool.Spec.Storage, InjectBitnamiImageValues),
+		poolChartRequest(pool, PulsarChart, pool.Spec.Pulsar, InjectPulsarImageValues),
+	}
+	for _, request := range requests {
+		if err := reconcileRelease(ctx, r.Client, r.helmReconciler, request); err != nil {
+			return errors.Wrapf(err, "reconcile release %s", request.ReleaseName)
+		}
+	}
+	return nil
+}
+
+// GetReleaseConditions returns the conditions of the releases of the pool, and the Ready condition
+// which is True once all of them are deployed for the latest generation
+func (r *MilvusDependencyPoolReconciler) GetReleaseConditions(pool v1alpha1.MilvusDependencyPool, reconcileErr error) []v1alpha1.MilvusCondition {
+	ret := []v1alpha1.MilvusCondition{
+		r.helmReconciler.GetReleaseCondition(pool.Namespace, DependencyPoolReleaseName(pool.Name, EtcdChart), v1alpha1.EtcdReleaseReady),
+		r.helmReconciler.GetReleaseCondition(pool.Namespace, DependencyPoolReleaseName(pool.Name, MinioChart), v1alpha1.StorageReleaseReady),
+		r.helmReconciler.GetReleaseCondition(pool.Namespace, DependencyPoolReleaseName(pool.Name, PulsarChart), v1alpha1.PulsarReleaseReady),
+	}
+
+	ready := v1alpha1.MilvusCondition{
+		Type:    v1alpha1.Ready,
+		Status:  corev1.ConditionTrue,
+		Reason:  v1alpha1.ReasonReleaseDeployed,
+		Message: "all the releases are deployed",
+	}
+	for _, cond := range ret {
+		if cond.Status != corev1.ConditionTrue {
+			ready.Status = corev1.ConditionFalse
+			ready.Reason = cond.Reason
+			ready.Message = fmt.Sprintf("%s: %s", cond.Type, cond.Message)
+			break
+		}
+	}
+	if reconcileErr != nil {
+		ready.Status = corev1.ConditionFalse
+		ready.Reason = v1alpha1.ReasonReconcileFailed
+		ready.Message = reconcileErr.Error()
+	}
+	return append(ret, ready)
+}
+
+// UpdateStatus updates the conditions of the releases and the instances referring to the pool,
+// the generation is observed if the releases are reconciled without error
+func (r *MilvusDependencyPoolReconciler) UpdateStatus(ctx context.Context, pool *v1alpha1.MilvusDependencyPool, reconcileErr error) error {
+	old := pool.Status.DeepCopy()
+	instances, err := v1alpha1.GetDependencyPoolInstances(ctx, r.apiReader, pool.Namespace, pool.Name)
+	if err != nil {
+		return errors.Wrap(err, "list instances")
+	}
+	pool.Status.Instances = instances
+	if reconcileErr == nil {
+		pool.Status.ObservedGeneration = pool.Generation
+	}
+	for _, cond := range r.GetReleaseConditions(*pool, reconcileErr) {
+		UpdateDependencyPoolCondition(&pool.Status, cond)
+	}
+	if IsEqual(*old, pool.Status) {
+		return nil
+	}
+	err = r.Status().Update(ctx, pool)
+	return errors.Wrapf(err, "update status[%s/%s] failed", pool.Namespace, pool.Name)
+}
+
+// Finalize uninstalls the releases whose deletionPolicy is Delete, it returns false while the pool is still referred
+func (r *MilvusDependencyPoolReconciler) Finalize(ctx context.Context, pool v1alpha1.MilvusDependencyPool) (bool, error) {
+	instances, err := v1alpha1.GetDependencyPoolInstances(ctx, r.apiReader, pool.Namespace, pool.Name)
+	if err != nil {
+		return false, errors.Wrap(err, "list instances")
+	}
+	if len(instances) > 0 {
+		r.logger.Info("pool is referred, wait for the instances deleted", "name", pool.Name, "namespace", pool.Namespace,
+			"instances", instances)
+		if !IsEqual(instances, pool.Status.Instances) {
+			pool.Status.Instances = instances
+			if err := r.Status().Update(ctx, &pool); err != nil {
+				return false, errors.Wrapf(err, "update status[%s/%s] failed", pool.Namespace, pool.Name)
+			}
+		}
+		return false, nil
+	}
+
+	deletingReleases := map[string]bool{}
+	inClusters := map[string]v1alpha1.InClusterConfig{
+		EtcdChart:   pool.Spec.Etcd,
+		MinioChart:  pool.Spec.Storage,
+		PulsarChart: pool.Spec.Pulsar,
+	}
+	for chart, inCluster := range inClusters {
+		if inCluster.DeletionPolicy == v1alpha1.DeletionPolicyDelete {
+			deletingReleases[DependencyPoolReleaseName(pool.Name, chart)] = inCluster.PVCDeletion
+		}
+	}
+	if err := UninstallReleases(ctx, r.Client, r.logger, r.helmReconciler, pool.Namespace, deletingReleases); err != nil {
+		return false, err
+	}
+	return true, nil
+}
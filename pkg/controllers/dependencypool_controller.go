@@ -0,0 +1,135 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	milvusv1alpha1 "github.com/milvus-io/milvus-operator/apis/milvus.io/v1alpha1"
+	"github.com/milvus-io/milvus-operator/pkg/config"
+)
+
+const (
+	DependencyPoolFinalizerName = "milvusdependencypool.milvus.io/finalizer"
+)
+
+// poolStatusSyncInterval is the interval to sync the status of the pool until its releases are deployed,
+// the releases are installed in background
+const poolStatusSyncInterval = 30 * time.Second
+
+// MilvusDependencyPoolReconciler reconciles a MilvusDependencyPool object
+type MilvusDependencyPoolReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+	// apiReader reads the instances referring to the pool from the API server,
+	// the cache only has the ones of the --namespaces and the --instance-selector
+	apiReader      client.Reader
+	logger         logr.Logger
+	helmReconciler HelmReconciler
+}
+
+//+kubebuilder:rbac:groups=milvus.io,resources=milvusdependencypools,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=milvus.io,resources=milvusdependencypools/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=milvus.io,resources=milvusdependencypools/finalizers,verbs=update
+
+// Reconcile installs the releases of the dependencies of the pool, they're uninstalled by their deletionPolicy
+// once the pool is deleted and no instance refers to it
+func (r *MilvusDependencyPoolReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	if !config.IsDebug() {
+		defer func() {
+			if err := recover(); err != nil {
+				r.logger.Error(err.(error), "reconcile panic")
+			}
+		}()
+	}
+
+	pool := &milvusv1alpha1.MilvusDependencyPool{}
+	if err := r.Get(ctx, req.NamespacedName, pool); err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, fmt.Errorf("error get milvus dependency pool: %w", err)
+	}
+
+	// Finalize
+	if pool.ObjectMeta.DeletionTimestamp.IsZero() {
+		if !controllerutil.ContainsFinalizer(pool, DependencyPoolFinalizerName) {
+			controllerutil.AddFinalizer(pool, DependencyPoolFinalizerName)
+			err := r.Update(ctx, pool)
+			return ctrl.Result{}, err
+		}
+	} else {
+		if controllerutil.ContainsFinalizer(pool, DependencyPoolFinalizerName) {
+			// the deletion of the last instance referring to the pool requeues it,
+			// the instances not watched by the operator are checked periodically
+			done, err := r.Finalize(ctx, *pool)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			if !done {
+				return ctrl.Result{RequeueAfter: poolStatusSyncInterval}, nil
+			}
+			controllerutil.RemoveFinalizer(pool, DependencyPoolFinalizerName)
+			return ctrl.Result{}, r.Update(ctx, pool)
+		}
+		// Stop reconciliation as the item is being deleted
+		return ctrl.Result{}, nil
+	}
+
+	reconcileErr := r.ReconcileReleases(ctx, *pool)
+	err := r.UpdateStatus(ctx, pool, reconcileErr)
+	if reconcileErr != nil {
+		return ctrl.Result{}, reconcileErr
+	}
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if cond := GetCondition(pool.Status.Conditions, milvusv1alpha1.Ready); cond == nil || cond.Status != "True" {
+		return ctrl.Result{RequeueAfter: poolStatusSyncInterval}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *MilvusDependencyPoolReconciler) SetupWithManager(mgr ctrl.Manager, options controller.Options) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&milvusv1alpha1.MilvusDependencyPool{}).
+		Watches(
+			&source.Kind{Type: &milvusv1alpha1.Milvus{}},
+			handler.EnqueueRequestsFromMapFunc(r.instanceToDependencyPool),
+		).
+		Watches(
+			&source.Kind{Type: &milvusv1alpha1.MilvusCluster{}},
+			handler.EnqueueRequestsFromMapFunc(r.instanceToDependencyPool),
+		).
+		WithOptions(options).
+		Complete(r)
+}
+
+// instanceToDependencyPool enqueues the pool referred by the Milvus or the MilvusCluster,
+// so that the instances of the pool are updated, and the deletion of the pool proceeds once none refers to it
+func (r *MilvusDependencyPoolReconciler) instanceToDependencyPool(obj client.Object) []reconcile.Request {
+	var pool *milvusv1alpha1.DependencyPoolRef
+	switch instance := obj.(type) {
+	case *milvusv1alpha1.Milvus:
+		pool = instance.Spec.Dep.Pool
+	case *milvusv1alpha1.MilvusCluster:
+		pool = instance.Spec.Dep.Pool
+	}
+	if pool == nil {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: NamespacedName(pool.GetNamespace(obj.GetNamespace()), pool.Name)}}
+}
@@ -0,0 +1,378 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/milvus-io/milvus-operator/apis/milvus.io/v1alpha1"
+	"github.com/milvus-io/milvus-operator/pkg/helm"
+	"github.com/minio/madmin-go"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrlRuntime "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newDependencyPoolTestEnv(t *testing.T, objs ...client.Object) (*MilvusDependencyPoolReconciler, *MockHelmReconciler, client.Client) {
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+	scheme := newSchemeForTest()
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+	mockHelm := NewMockHelmReconciler(ctrl)
+	return &MilvusDependencyPoolReconciler{
+		Client:         cli,
+		Scheme:         scheme,
+		apiReader:      cli,
+		logger:         ctrlRuntime.Log.WithName("test"),
+		helmReconciler: mockHelm,
+	}, mockHelm, cli
+}
+
+func newDependencyPoolForTest() *v1alpha1.MilvusDependencyPool {
+	return &v1alpha1.MilvusDependencyPool{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "deps", Name: "shared", Generation: 2},
+	}
+}
+
+func TestSetDependencyPoolEndpoints(t *testing.T) {
+	m := v1alpha1.Milvus{}
+	pool := v1alpha1.DependencyPoolRef{Name: "shared", Namespace: "deps"}
+	SetDependencyPoolEndpoints("ns", pool, &m.Spec.Dep.Etcd, &m.Spec.Dep.Storage, &m.Spec.Dep.Pulsar)
+	assert.Equal(t, []string{"shared-pool-etcd.deps:2379"}, m.Spec.Dep.Etcd.Endpoints)
+	assert.Equal(t, "shared-pool-minio.deps:9000", m.Spec.Dep.Storage.Endpoint)
+	assert.Equal(t, "shared-pool-pulsar-proxy.deps:6650", m.Spec.Dep.Pulsar.Endpoint)
+	assert.Equal(t, "http://shared-pool-pulsar-proxy.deps:80", m.Spec.Dep.Pulsar.AdminEndpoint)
+
+	// the pool in the namespace of the instance, external and skipped ones kept
+	m = v1alpha1.Milvus{}
+	m.Spec.Dep.Storage.External = true
+	m.Spec.Dep.Storage.Endpoint = "s3.amazonaws.com:443"
+	SetDependencyPoolEndpoints("ns", v1alpha1.DependencyPoolRef{Name: "shared"}, &m.Spec.Dep.Etcd, &m.Spec.Dep.Storage, nil)
+	assert.Equal(t, []string{"shared-pool-etcd.ns:2379"}, m.Spec.Dep.Etcd.Endpoints)
+	assert.Equal(t, "s3.amazonaws.com:443", m.Spec.Dep.Storage.Endpoint)
+	assert.Empty(t, m.Spec.Dep.Pulsar.Endpoint)
+}
+
+// applyingClient serves the server-side apply the fake client doesn't support by creating or updating the object
+type applyingClient struct {
+	client.Client
+}
+
+func (c applyingClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if patch != client.Apply {
+		return c.Client.Patch(ctx, obj, patch, opts...)
+	}
+	existing := obj.DeepCopyObject().(client.Object)
+	err := c.Get(ctx, client.ObjectKeyFromObject(obj), existing)
+	if k8sErrors.IsNotFound(err) {
+		return c.Create(ctx, obj)
+	}
+	if err != nil {
+		return err
+	}
+	obj.SetResourceVersion(existing.GetResourceVersion())
+	return c.Update(ctx, obj)
+}
+
+func getMockNewMinioAdminClientFunc(cli MinioAdminClient, err error) NewMinioAdminClientFunc {
+	return func(endpoint string, accessKeyID, secretAccessKey string, secure bool) (MinioAdminClient, error) {
+		return cli, err
+	}
+}
+
+func getMockNewPulsarAdminClientFunc(cli PulsarAdminClient) NewPulsarAdminClientFunc {
+	return func(endpoint string) PulsarAdminClient {
+		return cli
+	}
+}
+
+func TestGetPoolStorageUser(t *testing.T) {
+	m := v1alpha1.Milvus{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "mc"}}
+	user := GetPoolStorageUser(m)
+	assert.Len(t, user, 18)
+	assert.Equal(t, user, GetPoolStorageUser(m))
+
+	m.Namespace = "other"
+	assert.NotEqual(t, user, GetPoolStorageUser(m))
+}
+
+func TestMilvusReconciler_ReconcileDeps_Pool(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	defer func(f NewMinioAdminClientFunc) { newMinioAdminClientFunc = f }(newMinioAdminClientFunc)
+	defer func(f NewPulsarAdminClientFunc) { newPulsarAdminClientFunc = f }(newPulsarAdminClientFunc)
+
+	ctx := context.TODO()
+	pool := newDependencyPoolForTest()
+	poolSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "deps", Name: "shared-pool-minio"},
+		Data:       map[string][]byte{AccessKey: []byte("root"), SecretKey: []byte("rootpass")},
+	}
+	m := v1alpha1.Milvus{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "mc", UID: "uid"}}
+	m.Spec.Mode = v1alpha1.MilvusModeCluster
+	m.Spec.Dep.Pool = &v1alpha1.DependencyPoolRef{Name: "shared", Namespace: "deps"}
+	m.Spec.Dep.Storage.SecretRef = "mc-minio"
+	m.Spec.Dep.Storage.BucketName = "ns-mc"
+	SetDependencyPoolEndpoints(m.Namespace, *m.Spec.Dep.Pool, &m.Spec.Dep.Etcd, &m.Spec.Dep.Storage, &m.Spec.Dep.Pulsar)
+	r, cli := newAdoptionTestEnv(t, pool, poolSecret)
+	r.Client = applyingClient{cli}
+	mockMinio := NewMockMinioAdminClient(ctrl)
+	newMinioAdminClientFunc = getMockNewMinioAdminClientFunc(mockMinio, nil)
+	mockPulsar := NewMockPulsarAdminClient(ctrl)
+	newPulsarAdminClientFunc = getMockNewPulsarAdminClientFunc(mockPulsar)
+
+	// etcd served by the pool
+	assert.NoError(t, r.ReconcileEtcd(ctx, m))
+
+	// the namespace of the instance created in the pulsar of the pool
+	gomock.InOrder(
+		mockPulsar.EXPECT().ListClusters(gomock.Any()).Return([]string{"pulsar"}, nil),
+		mockPulsar.EXPECT().CreateTenant(gomock.Any(), "ns", []string{"pulsar"}).Return(nil),
+		mockPulsar.EXPECT().CreateNamespace(gomock.Any(), "ns/mc").Return(nil),
+	)
+	assert.NoError(t, r.ReconcilePulsar(ctx, m))
+
+	// the namespace of the instance not allowed by the pool
+	assert.Error(t, r.ReconcileMinio(ctx, m))
+
+	pool.Spec.AllowedNamespaces = []string{"ns"}
+	assert.NoError(t, cli.Update(ctx, pool))
+	user := GetPoolStorageUser(m)
+	var secretKey string
+	gomock.InOrder(
+		mockMinio.EXPECT().AddUser(gomock.Any(), user, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, accessKey, sk string) error {
+				secretKey = sk
+				return nil
+			}),
+		mockMinio.EXPECT().AddCannedPolicy(gomock.Any(), user, gomock.Any()).
+			DoAndReturn(func(ctx context.Context, name string, policy []byte) error {
+				assert.Contains(t, string(policy), `"arn:aws:s3:::ns-mc/*"`)
+				return nil
+			}),
+		mockMinio.EXPECT().SetPolicy(gomock.Any(), user, user, false).Return(nil),
+	)
+	assert.NoError(t, r.ReconcileMinio(ctx, m))
+
+	// the secret of the instance has its own credentials
+	secret := &corev1.Secret{}
+	assert.NoError(t, cli.Get(ctx, NamespacedName("ns", "mc-minio"), secret))
+	assert.Equal(t, map[string][]byte{AccessKey: []byte(user), SecretKey: []byte(secretKey)}, secret.Data)
+	assert.Len(t, secretKey, 40)
+
+	// the secret key kept once generated
+	secret.Data = map[string][]byte{AccessKey: []byte(user), SecretKey: []byte("kept")}
+	assert.NoError(t, cli.Update(ctx, secret))
+	mockMinio.EXPECT().AddUser(gomock.Any(), user, "kept").Return(nil)
+	mockMinio.EXPECT().AddCannedPolicy(gomock.Any(), user, gomock.Any()).Return(nil)
+	mockMinio.EXPECT().SetPolicy(gomock.Any(), user, user, false).Return(errors.New("test"))
+	assert.Error(t, r.ReconcileMinio(ctx, m))
+
+	// the root credentials copied before are replaced
+	secret.Data = map[string][]byte{AccessKey: []byte("root"), SecretKey: []byte("rootpass")}
+	assert.NoError(t, cli.Update(ctx, secret))
+	mockMinio.EXPECT().AddUser(gomock.Any(), user, gomock.Not("rootpass")).Return(errors.New("test"))
+	assert.Error(t, r.ReconcileMinio(ctx, m))
+
+	// secretRef required
+	m.Spec.Dep.Storage.SecretRef = ""
+	assert.Error(t, r.ReconcileMinio(ctx, m))
+
+	// external ignored
+	m.Spec.Dep.Storage.External = true
+	assert.NoError(t, r.ReconcileMinio(ctx, m))
+	m.Spec.Dep.Pulsar.External = true
+	assert.NoError(t, r.ReconcilePulsar(ctx, m))
+}
+
+func TestMilvus_Finalize_Pool(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	defer func(f NewMinioAdminClientFunc) { newMinioAdminClientFunc = f }(newMinioAdminClientFunc)
+
+	ctx := context.TODO()
+	m := v1alpha1.Milvus{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "mc"}}
+	m.Spec.Mode = v1alpha1.MilvusModeCluster
+	m.Spec.Dep.Pool = &v1alpha1.DependencyPoolRef{Name: "shared"}
+	m.Spec.Dep.Etcd.InCluster = &v1alpha1.InClusterConfig{DeletionPolicy: v1alpha1.DeletionPolicyDelete}
+	m.Spec.Dep.Pulsar.InCluster = &v1alpha1.InClusterConfig{DeletionPolicy: v1alpha1.DeletionPolicyDelete}
+	SetDependencyPoolEndpoints(m.Namespace, *m.Spec.Dep.Pool, &m.Spec.Dep.Etcd, &m.Spec.Dep.Storage, &m.Spec.Dep.Pulsar)
+	mockMinio := NewMockMinioAdminClient(ctrl)
+	newMinioAdminClientFunc = getMockNewMinioAdminClientFunc(mockMinio, nil)
+
+	// the releases of the pool are not uninstalled with the instance, the pool already deleted
+	r, _ := newAdoptionTestEnv(t)
+	done, err := r.Finalize(ctx, m)
+	assert.NoError(t, err)
+	assert.True(t, done)
+
+	// the storage user of the instance removed
+	poolSecret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "shared-pool-minio"}}
+	r, _ = newAdoptionTestEnv(t, poolSecret)
+	user := GetPoolStorageUser(m)
+	mockMinio.EXPECT().RemoveUser(gomock.Any(), user).Return(nil)
+	mockMinio.EXPECT().RemoveCannedPolicy(gomock.Any(), user).Return(madmin.ErrorResponse{Code: "XMinioAdminNoSuchPolicy"})
+	_, err = r.Finalize(ctx, m)
+	assert.NoError(t, err)
+
+	mockMinio.EXPECT().RemoveUser(gomock.Any(), user).Return(errors.New("test"))
+	_, err = r.Finalize(ctx, m)
+	assert.Error(t, err)
+}
+
+func TestGetExternalDataInfo_Pool(t *testing.T) {
+	mc := v1alpha1.Milvus{}
+	mc.Namespace = "ns"
+	mc.Name = "mc"
+	mc.Spec.Mode = v1alpha1.MilvusModeCluster
+	mc.Spec.Dep.Pool = &v1alpha1.DependencyPoolRef{Name: "shared"}
+	mc.Spec.Dep.DataDeletionPolicy = v1alpha1.DeletionPolicyDelete
+	mc.Spec.Dep.Etcd.RootPath = "ns-mc"
+	mc.Spec.Dep.Storage.BucketName = "ns-mc"
+	mc.Spec.Dep.MsgChannelPrefix = "ns-mc"
+	SetDependencyPoolEndpoints(mc.Namespace, *mc.Spec.Dep.Pool, &mc.Spec.Dep.Etcd, &mc.Spec.Dep.Storage, &mc.Spec.Dep.Pulsar)
+
+	// the data in the pooled dependencies is deleted like in the external ones
+	info := GetExternalDataInfo(mc)
+	assert.Equal(t, []string{"shared-pool-etcd.ns:2379"}, info.EtcdEndpoints)
+	assert.Equal(t, "ns-mc", info.EtcdRootPath)
+	assert.Equal(t, "shared-pool-minio.ns:9000", info.Storage.Endpoint)
+	assert.Equal(t, "ns-mc", info.BucketName)
+	assert.Equal(t, "http://shared-pool-pulsar-proxy.ns:80", info.PulsarAdminEndpoint)
+	assert.Equal(t, "ns-mc", info.MsgChannelPrefix)
+	assert.Equal(t, []string{"ns/mc", PulsarTopicNamespace}, info.PulsarNamespaces)
+}
+
+func TestStatusSyncer_GetReleaseConditions_Pool(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockHelm := NewMockHelmReconciler(ctrl)
+	s := &MilvusStatusSyncer{helmReconciler: mockHelm}
+
+	m := v1alpha1.Milvus{}
+	m.Namespace = "ns"
+	m.Name = "mc"
+	m.Spec.Mode = v1alpha1.MilvusModeCluster
+	m.Spec.Dep.Pool = &v1alpha1.DependencyPoolRef{Name: "shared", Namespace: "deps"}
+	m.Spec.Dep.Storage.External = true
+	mockHelm.EXPECT().GetReleaseCondition("deps", "shared-pool-etcd", v1alpha1.EtcdReleaseReady).
+		Return(v1alpha1.MilvusCondition{Type: v1alpha1.EtcdReleaseReady})
+	mockHelm.EXPECT().GetReleaseCondition("deps", "shared-pool-pulsar", v1alpha1.PulsarReleaseReady).
+		Return(v1alpha1.MilvusCondition{Type: v1alpha1.PulsarReleaseReady})
+	assert.Len(t, s.GetReleaseConditions(m), 2)
+}
+
+func TestMilvusDependencyPoolReconciler_ReconcileReleases(t *testing.T) {
+	pool := newDependencyPoolForTest()
+	pool.Spec.ImageRegistry = "registry.example.com"
+	pool.Spec.Etcd.Values.Data = map[string]interface{}{"replicaCount": 3}
+	r, mockHelm, _ := newDependencyPoolTestEnv(t)
+
+	charts := []string{}
+	mockHelm.EXPECT().Reconcile(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, request helm.ChartRequest) error {
+			assert.Equal(t, "deps", request.Namespace)
+			assert.Equal(t, DependencyPoolReleaseName("shared", request.Chart), request.ReleaseName)
+			assert.NotNil(t, request.Values)
+			if request.Chart == EtcdChart {
+				assert.EqualValues(t, 3, request.Values["replicaCount"])
+			}
+			charts = append(charts, request.Chart)
+			return nil
+		}).Times(3)
+	assert.NoError(t, r.ReconcileReleases(context.Background(), *pool))
+	assert.Equal(t, []string{EtcdChart, MinioChart, PulsarChart}, charts)
+
+	// stopped at the failed one
+	mockHelm.EXPECT().Reconcile(gomock.Any(), gomock.Any()).Return(assert.AnError)
+	assert.Error(t, r.ReconcileReleases(context.Background(), *pool))
+}
+
+func TestMilvusDependencyPoolReconciler_UpdateStatus(t *testing.T) {
+	pool := newDependencyPoolForTest()
+	mil := &v1alpha1.Milvus{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "m"}}
+	mil.Spec.Dep.Pool = &v1alpha1.DependencyPoolRef{Name: "shared", Namespace: "deps"}
+	r, mockHelm, cli := newDependencyPoolTestEnv(t, pool, mil)
+	ctx := context.Background()
+
+	mockHelm.EXPECT().GetReleaseCondition("deps", gomock.Any(), gomock.Any()).
+		DoAndReturn(func(namespace, releaseName string, condType v1alpha1.MiluvsConditionType) v1alpha1.MilvusCondition {
+			return v1alpha1.MilvusCondition{Type: condType, Status: corev1.ConditionTrue, Reason: v1alpha1.ReasonReleaseDeployed}
+		}).Times(3)
+	assert.NoError(t, r.UpdateStatus(ctx, pool, nil))
+
+	updated := &v1alpha1.MilvusDependencyPool{}
+	assert.NoError(t, cli.Get(ctx, NamespacedName("deps", "shared"), updated))
+	assert.Equal(t, []string{"ns/m"}, updated.Status.Instances)
+	assert.Equal(t, int64(2), updated.Status.ObservedGeneration)
+	assert.Equal(t, corev1.ConditionTrue, GetCondition(updated.Status.Conditions, v1alpha1.Ready).Status)
+
+	// not ready with a failed release
+	mockHelm.EXPECT().GetReleaseCondition("deps", gomock.Any(), gomock.Any()).
+		DoAndReturn(func(namespace, releaseName string, condType v1alpha1.MiluvsConditionType) v1alpha1.MilvusCondition {
+			if condType == v1alpha1.StorageReleaseReady {
+				return v1alpha1.MilvusCondition{Type: condType, Status: corev1.ConditionFalse, Reason: v1alpha1.ReasonReleaseFailed}
+			}
+			return v1alpha1.MilvusCondition{Type: condType, Status: corev1.ConditionTrue, Reason: v1alpha1.ReasonReleaseDeployed}
+		}).Times(3)
+	ready := GetCondition(r.GetReleaseConditions(*pool, nil), v1alpha1.Ready)
+	assert.Equal(t, corev1.ConditionFalse, ready.Status)
+	assert.Equal(t, v1alpha1.ReasonReleaseFailed, ready.Reason)
+}
+
+func TestMilvusDependencyPoolReconciler_Finalize(t *testing.T) {
+	pool := newDependencyPoolForTest()
+	pool.Spec.Etcd.DeletionPolicy = v1alpha1.DeletionPolicyDelete
+	pool.Spec.Storage.DeletionPolicy = v1alpha1.DeletionPolicyRetain
+	mc := &v1alpha1.MilvusCluster{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "mc"}}
+	mc.Spec.Dep.Pool = &v1alpha1.DependencyPoolRef{Name: "shared", Namespace: "deps"}
+	r, mockHelm, cli := newDependencyPoolTestEnv(t, pool, mc)
+	ctx := context.Background()
+
+	// wait while referred
+	done, err := r.Finalize(ctx, *pool)
+	assert.NoError(t, err)
+	assert.False(t, done)
+	updated := &v1alpha1.MilvusDependencyPool{}
+	assert.NoError(t, cli.Get(ctx, NamespacedName("deps", "shared"), updated))
+	assert.Equal(t, []string{"ns/mc"}, updated.Status.Instances)
+
+	// the instance not in the cache of the operator is counted
+	assert.NoError(t, cli.Delete(ctx, mc))
+	r.apiReader = fake.NewClientBuilder().WithScheme(r.Scheme).WithObjects(mc).Build()
+	done, err = r.Finalize(ctx, *updated)
+	assert.NoError(t, err)
+	assert.False(t, done)
+
+	// the releases to delete are uninstalled once free
+	r.apiReader = cli
+	mockHelmClient := helm.NewMockClient(gomock.NewController(t))
+	helm.SetDefaultClient(mockHelmClient)
+	mockHelm.EXPECT().NewHelmCfg("deps")
+	mockHelmClient.EXPECT().Uninstall(gomock.Any(), "shared-pool-etcd")
+	done, err = r.Finalize(ctx, *updated)
+	assert.NoError(t, err)
+	assert.True(t, done)
+}
+
+func TestMilvusDependencyPoolReconciler_instanceToDependencyPool(t *testing.T) {
+	r := &MilvusDependencyPoolReconciler{}
+	mil := &v1alpha1.Milvus{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "m"}}
+	assert.Empty(t, r.instanceToDependencyPool(mil))
+
+	mil.Spec.Dep.Pool = &v1alpha1.DependencyPoolRef{Name: "shared"}
+	reqs := r.instanceToDependencyPool(mil)
+	assert.Len(t, reqs, 1)
+	assert.Equal(t, NamespacedName("ns", "shared"), reqs[0].NamespacedName)
+
+	mc := &v1alpha1.MilvusCluster{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "mc"}}
+	mc.Spec.Dep.Pool = &v1alpha1.DependencyPoolRef{Name: "shared", Namespace: "deps"}
+	reqs = r.instanceToDependencyPool(mc)
+	assert.Len(t, reqs, 1)
+	assert.Equal(t, NamespacedName("deps", "shared"), reqs[0].NamespacedName)
+}
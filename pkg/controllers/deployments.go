@@ -2,15 +2,19 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"reflect"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/milvus-io/milvus-operator/apis/milvus.io/v1alpha1"
+	"github.com/milvus-io/milvus-operator/pkg/util"
 )
 
 const (
@@ -20,14 +24,20 @@ const (
 	AccessKey                = "access-key"
 	SecretKey                = "secret-key"
 	AnnotationCheckSum       = "checksum/config"
+	AnnotationSecretCheckSum = "checksum/secret"
 )
 
 var (
 	MilvusConfigMapMode int32 = 420
 )
 
+// GetStorageSecretRefEnv returns the env milvus reads the storage credentials from,
+// the credentials are never rendered into the configmap
 func GetStorageSecretRefEnv(secretRef string) []corev1.EnvVar {
 	env := []corev1.EnvVar{}
+	if secretRef == "" {
+		return env
+	}
 	env = append(env, corev1.EnvVar{
 		Name: "MINIO_ACCESS_KEY",
 		ValueFrom: &corev1.EnvVarSource{
@@ -53,136 +63,183 @@ func GetStorageSecretRefEnv(secretRef string) []corev1.EnvVar {
 	return env
 }
 
-func (r *MilvusClusterReconciler) updateDeployment(
-	mc v1alpha1.MilvusCluster, deployment *appsv1.Deployment, component MilvusComponent,
+// GetStorageSecretCheckSum returns the checksum of the storage secret data,
+// it's empty if no secret referenced or the secret not found
+func GetStorageSecretCheckSum(ctx context.Context, cli client.Client, namespace, secretRef string) (string, error) {
+	if secretRef == "" {
+		return "", nil
+	}
+
+	secret := &corev1.Secret{}
+	err := cli.Get(ctx, NamespacedName(namespace, secretRef), secret)
+	if errors.IsNotFound(err) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+
+	b, err := json.Marshal(secret.Data)
+	if err != nil {
+		return "", err
+	}
+
+	return util.CheckSum(b), nil
+}
+
+// updateSecretCheckSum makes the pods restart when the storage secret changes
+func updateSecretCheckSum(template *corev1.PodTemplateSpec, checksum string) {
+	if checksum == "" {
+		delete(template.Annotations, AnnotationSecretCheckSum)
+		return
+	}
+	template.Annotations[AnnotationSecretCheckSum] = checksum
+}
+
+// GetComponentAppLabels returns the labels of the workload and the pods of the component
+func GetComponentAppLabels(instance string, component MilvusComponent) map[string]string {
+	appLabels := NewComponentAppLabels(instance, component.GetComponentLabel())
+	if component.Group != "" {
+		appLabels[AppLabelResourceGroup] = component.Group
+	}
+	return appLabels
+}
+
+func (r *MilvusReconciler) updateDeployment(
+	mil v1alpha1.Milvus, deployment *appsv1.Deployment, component MilvusComponent, secretCheckSum string,
 ) error {
-	appLabels := NewComponentAppLabels(mc.Name, component.String())
+	appLabels := GetComponentAppLabels(mil.Name, component)
 
 	deployment.Labels = MergeLabels(deployment.Labels, appLabels)
-	if err := ctrl.SetControllerReference(&mc, deployment, r.Scheme); err != nil {
+	if err := ctrl.SetControllerReference(&mil, deployment, r.Scheme); err != nil {
 		return err
 	}
 
-	deployment.Spec.Replicas = component.GetReplicas(mc.Spec)
-	deployment.Spec.Strategy = component.GetDeploymentStrategy()
+	deployment.Spec.Replicas = component.GetReplicas(mil.Spec)
+	deployment.Spec.Strategy = component.GetDeploymentStrategy(mil.Spec)
 
+	// the selector is immutable, an adopted deployment keeps its own
 	if deployment.Spec.Selector == nil {
-		deployment.Spec.Selector = new(metav1.LabelSelector)
-		deployment.Spec.Selector.MatchLabels = appLabels
+		deployment.Spec.Selector = &metav1.LabelSelector{
+			MatchLabels: MergeLabels(appLabels),
+		}
 	}
-	deployment.Spec.Template.Labels = MergeLabels(deployment.Spec.Template.Labels, appLabels)
+	deployment.Spec.Template.Labels = MergeLabels(deployment.Spec.Selector.MatchLabels, appLabels)
+	return updatePodTemplate(mil, &deployment.Spec.Template, component, secretCheckSum)
+}
 
-	if deployment.Spec.Template.Annotations == nil {
-		deployment.Spec.Template.Annotations = map[string]string{}
+// updatePodTemplate updates the pod template of the component, it's the same for a deployment and a statefulset
+func updatePodTemplate(mil v1alpha1.Milvus, template *corev1.PodTemplateSpec, component MilvusComponent, secretCheckSum string) error {
+	conf, err := RenderMilvusConfig(mil)
+	if err != nil {
+		return err
+	}
+	componentConf := RenderComponentConfig(conf, mil.Spec, component)
+
+	template.Annotations = map[string]string{
+		AnnotationCheckSum: GetComponentConfCheckSum(mil.Spec, component),
 	}
-	deployment.Spec.Template.Annotations[AnnotationCheckSum] = GetConfCheckSum(mc.Spec)
+	updateSecretCheckSum(template, secretCheckSum)
 
-	// update configmap volume
-	volumeIdx := GetVolumeIndex(deployment.Spec.Template.Spec.Volumes, MilvusConfigVolumeName)
 	volume := corev1.Volume{
 		Name: MilvusConfigVolumeName,
 		VolumeSource: corev1.VolumeSource{
 			ConfigMap: &corev1.ConfigMapVolumeSource{
 				LocalObjectReference: corev1.LocalObjectReference{
-					Name: mc.Name,
+					Name: mil.Name,
 				},
 				DefaultMode: &MilvusConfigMapMode,
 			},
 		},
 	}
-	if volumeIdx < 0 {
-		deployment.Spec.Template.Spec.Volumes = append(deployment.Spec.Template.Spec.Volumes, volume)
-	} else {
-		deployment.Spec.Template.Spec.Volumes[volumeIdx] = volume
-	}
+	template.Spec.Volumes = []corev1.Volume{volume}
 
-	// update component container
-	containerIdx := GetContainerIndex(deployment.Spec.Template.Spec.Containers, component.GetContainerName())
-	if containerIdx < 0 {
-		deployment.Spec.Template.Spec.Containers = append(
-			deployment.Spec.Template.Spec.Containers,
-			corev1.Container{Name: component.GetContainerName()},
-		)
-		containerIdx = len(deployment.Spec.Template.Spec.Containers) - 1
-	}
-	container := &deployment.Spec.Template.Spec.Containers[containerIdx]
-	container.Args = []string{"milvus", "run", component.String()}
-	env := component.GetEnv(mc.Spec)
-	env = append(env, GetStorageSecretRefEnv(mc.Spec.Dep.Storage.SecretRef)...)
-	container.Env = MergeEnvVar(container.Env, env)
-	container.Ports = MergeContainerPort(container.Ports, component.GetContainerPorts(mc.Spec))
+	template.Spec.Containers = []corev1.Container{{Name: component.GetContainerName()}}
+	container := &template.Spec.Containers[0]
+	container.Args = []string{"milvus", "run", component.GetRunRole()}
+	env := component.GetEnv(mil.Spec)
+	env = append(env, GetStorageSecretRefEnv(mil.Spec.Dep.Storage.SecretRef)...)
+	container.Env = env
+	container.Ports = component.GetContainerPorts(mil.Spec)
 
 	milvusVolumeMount := corev1.VolumeMount{
 		Name:      MilvusConfigVolumeName,
 		ReadOnly:  true,
 		MountPath: MilvusConfigMountPath,
-		SubPath:   MilvusConfigMountSubPath,
-	}
-	mountIdx := GetVolumeMountIndex(container.VolumeMounts, milvusVolumeMount.MountPath)
-	if mountIdx < 0 {
-		container.VolumeMounts = append(container.VolumeMounts, milvusVolumeMount)
-	} else {
-		container.VolumeMounts[mountIdx] = milvusVolumeMount
+		SubPath:   component.GetConfigMapKey(),
 	}
+	container.VolumeMounts = []corev1.VolumeMount{milvusVolumeMount}
 
-	container.ImagePullPolicy = component.GetImagePullPolicy(mc.Spec)
-	container.Image = component.GetImage(mc.Spec)
-	container.Resources = component.GetResources(mc.Spec)
+	container.ImagePullPolicy = component.GetImagePullPolicy(mil.Spec)
+	container.Image = ReplaceImageRegistry(component.GetImage(mil.Spec), GetImageRegistry(mil.Spec.ImageRegistry))
+	container.Resources = component.GetResources(mil.Spec)
 	container.LivenessProbe = GetLivenessProbe()
 	container.ReadinessProbe = GetReadinessProbe()
-	deployment.Spec.Template.Spec.ImagePullSecrets = component.GetImagePullSecrets(mc.Spec)
-
+	container.Lifecycle = component.GetLifecycle(mil.Spec, componentConf)
+	template.Spec.TerminationGracePeriodSeconds = component.GetTerminationGracePeriodSeconds(mil.Spec, componentConf)
+	template.Spec.ImagePullSecrets = MergeImagePullSecrets(component.GetImagePullSecrets(mil.Spec))
+	template.Spec.NodeSelector = component.GetNodeSelector(mil.Spec)
+	template.Spec.Tolerations = component.GetTolerations(mil.Spec)
 	return nil
 }
 
-func (r *MilvusClusterReconciler) ReconcileComponentDeployment(
-	ctx context.Context, mc v1alpha1.MilvusCluster, component MilvusComponent,
+func (r *MilvusReconciler) ReconcileComponentDeployment(
+	ctx context.Context, mil v1alpha1.Milvus, component MilvusComponent,
 ) error {
-	namespacedName := NamespacedName(mc.Namespace, component.GetDeploymentInstanceName(mc.Name))
-	old := &appsv1.Deployment{}
-	err := r.Get(ctx, namespacedName, old)
-	if errors.IsNotFound(err) {
-		new := &appsv1.Deployment{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      namespacedName.Name,
-				Namespace: namespacedName.Namespace,
-			},
-		}
-		if err := r.updateDeployment(mc, new, component); err != nil {
-			return err
-		}
+	if component.GetStatefulSetSpec(mil.Spec) != nil {
+		return r.ReconcileComponentStatefulSet(ctx, mil, component)
+	}
 
-		r.logger.Info("Create Deployment", "name", new.Name, "namespace", new.Namespace)
-		return r.Create(ctx, new)
-	} else if err != nil {
+	secretCheckSum, err := GetStorageSecretCheckSum(ctx, r.Client, mil.Namespace, mil.Spec.Dep.Storage.SecretRef)
+	if err != nil {
 		return err
 	}
 
-	cur := old.DeepCopy()
-	if err := r.updateDeployment(mc, cur, component); err != nil {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      component.GetDeploymentInstanceName(mil.Name),
+			Namespace: mil.Namespace,
+		},
+	}
+	existing := &appsv1.Deployment{}
+	err = r.Get(ctx, client.ObjectKeyFromObject(deployment), existing)
+	if err != nil && !errors.IsNotFound(err) {
 		return err
 	}
-
-	if IsEqual(old, cur) {
-		//r.logger.Info("Equal", "cur", cur.Name)
-		return nil
+	found := err == nil
+	adopted := IsAdopted(mil.Status.Conditions)
+	if found && adopted {
+		deployment.Spec.Selector = existing.Spec.Selector
 	}
-
-	/* if config.IsDebug() {
-		diff, err := diffObject(old, cur)
-		if err == nil {
-			r.logger.Info("Deployment diff", "name", cur.Name, "namespace", cur.Namespace, "diff", string(diff))
+	if err := r.updateDeployment(mil, deployment, component, secretCheckSum); err != nil {
+		return err
+	}
+	if found {
+		if err := omitScaledReplicas(ctx, r.Client, "Deployment", existing, &deployment.Spec.Replicas); err != nil {
+			return fmt.Errorf("omit scaled replicas: %w", err)
+		}
+	}
+	if found && adopted {
+		if err := keepAdoptedPodTemplate(existing, deployment); err != nil {
+			return fmt.Errorf("keep adopted pod template: %w", err)
 		}
-	} */
+	}
+	if !found && adopted {
+		replacing, err := r.ReplaceAdoptedDeployment(ctx, mil, component, deployment)
+		if err != nil {
+			return fmt.Errorf("replace adopted deployment: %w", err)
+		}
+		if !replacing {
+			return nil
+		}
+	}
 
-	r.logger.Info("Update Deployment", "name", cur.Name, "namespace", cur.Namespace)
-	return r.Update(ctx, cur)
+	return ApplyObject(ctx, r.Client, r.Scheme, deployment)
 }
 
-func (r *MilvusClusterReconciler) ReconcileDeployments(ctx context.Context, mc v1alpha1.MilvusCluster) error {
+func (r *MilvusReconciler) ReconcileDeployments(ctx context.Context, mil v1alpha1.Milvus) error {
 	g, gtx := NewGroup(ctx)
-	for _, component := range MilvusComponents {
-		g.Go(WarppedReconcileComponentFunc(r.ReconcileComponentDeployment, gtx, mc, component))
+	for _, component := range GetComponentsBySpec(mil.Spec) {
+		g.Go(WarppedReconcileComponentFunc(r.ReconcileComponentDeployment, gtx, mil, component))
 	}
 
 	if err := g.Wait(); err != nil {
@@ -192,135 +249,77 @@ func (r *MilvusClusterReconciler) ReconcileDeployments(ctx context.Context, mc v
 	return nil
 }
 
-func (r *MilvusReconciler) ReconcileDeployments(ctx context.Context, mil v1alpha1.Milvus) error {
-	namespacedName := NamespacedName(mil.Namespace, mil.Name)
-	old := &appsv1.Deployment{}
-	err := r.Get(ctx, namespacedName, old)
-	if errors.IsNotFound(err) {
-		new := &appsv1.Deployment{
-			ObjectMeta: metav1.ObjectMeta{
-				Name:      namespacedName.Name,
-				Namespace: namespacedName.Namespace,
-			},
+// DeleteStaleWorkloads deletes the deployments and the statefulsets not expected by the spec: the ones of the removed groups,
+// and the ones of the other kind after a component switches between a deployment and a statefulset.
+// The cache claims of the pods no longer in the statefulsets are deleted as well
+func (r *MilvusReconciler) DeleteStaleWorkloads(ctx context.Context, mil v1alpha1.Milvus) error {
+	deployments, statefulSets := map[string]bool{}, map[string]int32{}
+	for _, component := range GetComponentsBySpec(mil.Spec) {
+		name := component.GetDeploymentInstanceName(mil.Name)
+		if component.GetStatefulSetSpec(mil.Spec) == nil {
+			deployments[name] = true
+			continue
 		}
-		if err := r.updateDeployment(mil, new); err != nil {
-			return err
+		statefulSets[name] = 1
+		if replicas := component.GetReplicas(mil.Spec); replicas != nil {
+			statefulSets[name] = *replicas
 		}
-
-		r.logger.Info("Create Deployment", "name", new.Name, "namespace", new.Namespace)
-		return r.Create(ctx, new)
-	} else if err != nil {
-		return err
-	}
-
-	cur := old.DeepCopy()
-	if err := r.updateDeployment(mil, cur); err != nil {
-		return err
-	}
-
-	if IsEqual(old, cur) {
-		return nil
-	}
-
-	r.logger.Info("Update Deployment", "name", cur.Name, "namespace", cur.Namespace)
-	return r.Update(ctx, cur)
-}
-
-func (r *MilvusReconciler) updateDeployment(
-	mc v1alpha1.Milvus, deployment *appsv1.Deployment,
-) error {
-	appLabels := NewComponentAppLabels(mc.Name, MilvusName)
-
-	deployment.Labels = MergeLabels(deployment.Labels, appLabels)
-	if err := ctrl.SetControllerReference(&mc, deployment, r.Scheme); err != nil {
-		return err
 	}
 
-	deployment.Spec.Replicas = int32Ptr(1)
-	deployment.Spec.Strategy = appsv1.DeploymentStrategy{
-		Type: appsv1.RecreateDeploymentStrategyType,
+	deploymentList := &appsv1.DeploymentList{}
+	if err := r.List(ctx, deploymentList, client.InNamespace(mil.Namespace),
+		client.MatchingLabels(NewAppLabels(mil.Name))); err != nil {
+		return fmt.Errorf("list deployments: %w", err)
 	}
-
-	if deployment.Spec.Selector == nil {
-		deployment.Spec.Selector = new(metav1.LabelSelector)
-		deployment.Spec.Selector.MatchLabels = appLabels
+	for i := range deploymentList.Items {
+		deployment := &deploymentList.Items[i]
+		_, isStatefulSet := statefulSets[deployment.Name]
+		if !isStaleWorkload(deployment, &mil, deployments[deployment.Name], isStatefulSet) {
+			continue
+		}
+		if err := r.deleteStaleWorkload(ctx, deployment); err != nil {
+			return err
+		}
 	}
-	deployment.Spec.Template.Labels = MergeLabels(deployment.Spec.Template.Labels, appLabels)
 
-	if deployment.Spec.Template.Annotations == nil {
-		deployment.Spec.Template.Annotations = map[string]string{}
+	statefulSetList := &appsv1.StatefulSetList{}
+	if err := r.List(ctx, statefulSetList, client.InNamespace(mil.Namespace),
+		client.MatchingLabels(NewAppLabels(mil.Name))); err != nil {
+		return fmt.Errorf("list statefulsets: %w", err)
 	}
-	deployment.Spec.Template.Annotations[AnnotationCheckSum] = GetMilvusConfCheckSum(mc.Spec)
-
-	// update configmap volume
-	volumeIdx := GetVolumeIndex(deployment.Spec.Template.Spec.Volumes, MilvusConfigVolumeName)
-	volume := corev1.Volume{
-		Name: MilvusConfigVolumeName,
-		VolumeSource: corev1.VolumeSource{
-			ConfigMap: &corev1.ConfigMapVolumeSource{
-				LocalObjectReference: corev1.LocalObjectReference{
-					Name: mc.Name,
-				},
-				DefaultMode: &MilvusConfigMapMode,
-			},
-		},
-	}
-	if volumeIdx < 0 {
-		deployment.Spec.Template.Spec.Volumes = append(deployment.Spec.Template.Spec.Volumes, volume)
-	} else {
-		deployment.Spec.Template.Spec.Volumes[volumeIdx] = volume
+	for i := range statefulSetList.Items {
+		statefulSet := &statefulSetList.Items[i]
+		replicas, isStatefulSet := statefulSets[statefulSet.Name]
+		// the claims of the pods scaled by others, e.g. an HPA, are kept
+		if isStatefulSet && statefulSet.Spec.Replicas != nil && *statefulSet.Spec.Replicas > replicas {
+			statefulSets[statefulSet.Name] = *statefulSet.Spec.Replicas
+		}
+		if !isStaleWorkload(statefulSet, &mil, isStatefulSet, deployments[statefulSet.Name]) {
+			continue
+		}
+		if err := r.deleteStaleWorkload(ctx, statefulSet); err != nil {
+			return err
+		}
 	}
 
-	// update component container
-	containerIdx := GetContainerIndex(deployment.Spec.Template.Spec.Containers, MilvusName)
-	if containerIdx < 0 {
-		deployment.Spec.Template.Spec.Containers = append(
-			deployment.Spec.Template.Spec.Containers,
-			corev1.Container{Name: MilvusName},
-		)
-		containerIdx = len(deployment.Spec.Template.Spec.Containers) - 1
-	}
-	container := &deployment.Spec.Template.Spec.Containers[containerIdx]
-	container.Args = []string{"milvus", "run", "standalone"}
-	env := mc.Spec.Env
-	env = append(env, GetStorageSecretRefEnv(mc.Spec.Dep.Storage.SecretRef)...)
-	container.Env = MergeEnvVar(container.Env, env)
-	container.Ports = MergeContainerPort(container.Ports, []corev1.ContainerPort{
-		{
-			Name:          MilvusName,
-			ContainerPort: MilvusPort,
-			Protocol:      corev1.ProtocolTCP,
-		},
-		{
-			Name:          MetricPortName,
-			ContainerPort: MetricPort,
-			Protocol:      corev1.ProtocolTCP,
-		},
-	})
+	return r.DeleteStaleCacheClaims(ctx, mil, statefulSets)
+}
 
-	milvusVolumeMount := corev1.VolumeMount{
-		Name:      MilvusConfigVolumeName,
-		ReadOnly:  true,
-		MountPath: MilvusConfigMountPath,
-		SubPath:   MilvusConfigMountSubPath,
-	}
-	mountIdx := GetVolumeMountIndex(container.VolumeMounts, milvusVolumeMount.MountPath)
-	if mountIdx < 0 {
-		container.VolumeMounts = append(container.VolumeMounts, milvusVolumeMount)
-	} else {
-		container.VolumeMounts[mountIdx] = milvusVolumeMount
+// isStaleWorkload returns if the workload controlled by the instance is no longer expected,
+// the workloads of the components are kept unless they're expected as the other kind
+func isStaleWorkload(obj client.Object, mil *v1alpha1.Milvus, expected, expectedAsOther bool) bool {
+	if expected || !metav1.IsControlledBy(obj, mil) {
+		return false
 	}
+	_, isGroup := obj.GetLabels()[AppLabelResourceGroup]
+	return isGroup || expectedAsOther
+}
 
-	if mc.Spec.ImagePullPolicy != nil {
-		container.ImagePullPolicy = *mc.Spec.ImagePullPolicy
-	}
-	container.Image = mc.Spec.Image
-	if mc.Spec.Resources != nil {
-		container.Resources = *mc.Spec.Resources
+func (r *MilvusReconciler) deleteStaleWorkload(ctx context.Context, obj client.Object) error {
+	kind := reflect.TypeOf(obj).Elem().Name()
+	r.logger.Info("delete stale workload", "kind", kind, "name", obj.GetName(), "namespace", obj.GetNamespace())
+	if err := r.Delete(ctx, obj); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("delete %s %s: %w", kind, obj.GetName(), err)
 	}
-	container.LivenessProbe = GetLivenessProbe()
-	container.ReadinessProbe = GetReadinessProbe()
-	deployment.Spec.Template.Spec.ImagePullSecrets = mc.Spec.ImagePullSecrets
-
 	return nil
 }
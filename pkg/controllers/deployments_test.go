@@ -2,155 +2,317 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/milvus-io/milvus-operator/apis/milvus.io/v1alpha1"
+	"github.com/milvus-io/milvus-operator/pkg/config"
 )
 
-func TestClusterReconciler_ReconcileDeployments_CreateIfNotFound(t *testing.T) {
-	env := newClusterTestEnv(t)
+func TestMilvusReconciler_ClusterMode_ReconcileDeployments(t *testing.T) {
+	env := newClusterModeTestEnv(t)
 	defer env.tearDown()
 	r := env.Reconciler
 	mockClient := env.MockClient
 	ctx := env.ctx
 	mc := env.Inst
 
-	// all ok
+	// not created yet
 	mockClient.EXPECT().
 		Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&appsv1.Deployment{})).
 		Return(k8sErrors.NewNotFound(schema.GroupResource{}, "")).
-		Times(len(MilvusComponents))
+		AnyTimes()
+	// all applied
 	mockClient.EXPECT().
-		Create(gomock.Any(), gomock.AssignableToTypeOf(&appsv1.Deployment{})).
-		Return(nil).
+		Patch(gomock.Any(), gomock.AssignableToTypeOf(&appsv1.Deployment{}), client.Apply, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+			deploy := obj.(*appsv1.Deployment)
+			assert.Equal(t, "ns", deploy.Namespace)
+			assert.NotEqual(t, mc.Name, deploy.Name)
+			assert.Len(t, deploy.Spec.Template.Spec.Containers, 1)
+			return nil
+		}).
 		Times(len(MilvusComponents))
 
 	err := r.ReconcileDeployments(ctx, mc)
 	assert.NoError(t, err)
+
+	// apply failed
+	mockClient.EXPECT().
+		Patch(gomock.Any(), gomock.AssignableToTypeOf(&appsv1.Deployment{}), client.Apply, gomock.Any(), gomock.Any()).
+		Return(errors.New("some network issue")).
+		Times(len(MilvusComponents))
+
+	err = r.ReconcileDeployments(ctx, mc)
+	assert.Error(t, err)
 }
 
-func TestClusterReconciler_ReconcileDeployments_Existed(t *testing.T) {
-	env := newClusterTestEnv(t)
+func TestMilvusReconciler_ReconcileDeployments(t *testing.T) {
+	env := newMilvusTestEnv(t)
 	defer env.tearDown()
 	r := env.Reconciler
 	mockClient := env.MockClient
 	ctx := env.ctx
 	m := env.Inst
 
-	// call client.Update if changed
+	// not created yet
 	mockClient.EXPECT().
 		Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&appsv1.Deployment{})).
-		DoAndReturn(func(ctx context.Context, key client.ObjectKey, obj client.Object) error {
-			cm := obj.(*appsv1.Deployment)
-			cm.Namespace = "ns"
-			cm.Name = "mc"
-			return nil
-		}).Times(len(MilvusComponents))
+		Return(k8sErrors.NewNotFound(schema.GroupResource{}, "")).
+		AnyTimes()
 	mockClient.EXPECT().
-		Update(gomock.Any(), gomock.Any()).Return(nil).
-		Times(len(MilvusComponents))
+		Patch(gomock.Any(), gomock.AssignableToTypeOf(&appsv1.Deployment{}), client.Apply, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+			deploy := obj.(*appsv1.Deployment)
+			assert.Equal(t, m.Name, deploy.Name)
+			assert.Equal(t, []string{"milvus", "run", "standalone"}, deploy.Spec.Template.Spec.Containers[0].Args)
+			return nil
+		})
 
 	err := r.ReconcileDeployments(ctx, m)
 	assert.NoError(t, err)
 
-	// not call client.Update if configmap not changed
 	mockClient.EXPECT().
-		Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&appsv1.Deployment{})).
-		DoAndReturn(func(ctx context.Context, key client.ObjectKey, obj client.Object) error {
-			cm := obj.(*appsv1.Deployment)
-			cm.Namespace = "ns"
-			cm.Name = "mc-xxx"
-			switch key.Name {
-			case "mc-milvus-proxy":
-				r.updateDeployment(m, cm, Proxy)
-			case "mc-milvus-rootcoord":
-				r.updateDeployment(m, cm, RootCoord)
-			case "mc-milvus-datacoord":
-				r.updateDeployment(m, cm, DataCoord)
-			case "mc-milvus-querycoord":
-				r.updateDeployment(m, cm, QueryCoord)
-			case "mc-milvus-indexcoord":
-				r.updateDeployment(m, cm, IndexCoord)
-			case "mc-milvus-datanode":
-				r.updateDeployment(m, cm, DataNode)
-			case "mc-milvus-querynode":
-				r.updateDeployment(m, cm, QueryNode)
-			case "mc-milvus-indexnode":
-				r.updateDeployment(m, cm, IndexNode)
-			}
-			return nil
-		}).Times(len(MilvusComponents))
+		Patch(gomock.Any(), gomock.AssignableToTypeOf(&appsv1.Deployment{}), client.Apply, gomock.Any(), gomock.Any()).
+		Return(errors.New("some network issue"))
 
 	err = r.ReconcileDeployments(ctx, m)
-	assert.NoError(t, err)
+	assert.Error(t, err)
 }
 
-func TestReconciler_ReconcileDeployments_CreateIfNotFound(t *testing.T) {
+func TestGetStorageSecretRefEnv(t *testing.T) {
+	assert.Len(t, GetStorageSecretRefEnv(""), 0)
+
+	env := GetStorageSecretRefEnv("secret")
+	assert.Len(t, env, 2)
+	for _, e := range env {
+		assert.Equal(t, "secret", e.ValueFrom.SecretKeyRef.Name)
+	}
+}
+
+func TestGetStorageSecretCheckSum(t *testing.T) {
 	env := newMilvusTestEnv(t)
 	defer env.tearDown()
-	r := env.Reconciler
 	mockClient := env.MockClient
 	ctx := env.ctx
-	m := env.Inst
 
-	// all ok
-	gomock.InOrder(
-		mockClient.EXPECT().
-			Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&appsv1.Deployment{})).
-			Return(k8sErrors.NewNotFound(schema.GroupResource{}, "")).
-			Times(1),
+	// no secret referenced
+	sum, err := GetStorageSecretCheckSum(ctx, mockClient, "ns", "")
+	assert.NoError(t, err)
+	assert.Empty(t, sum)
+
+	// secret not found
+	mockClient.EXPECT().
+		Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&corev1.Secret{})).
+		Return(k8sErrors.NewNotFound(schema.GroupResource{}, ""))
+	sum, err = GetStorageSecretCheckSum(ctx, mockClient, "ns", "secret")
+	assert.NoError(t, err)
+	assert.Empty(t, sum)
+
+	// get failed
+	mockClient.EXPECT().
+		Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&corev1.Secret{})).
+		Return(errors.New("some network issue"))
+	_, err = GetStorageSecretCheckSum(ctx, mockClient, "ns", "secret")
+	assert.Error(t, err)
+
+	// checksum changes with the secret data
+	getSecret := func(key string) {
 		mockClient.EXPECT().
-			Create(gomock.Any(), gomock.AssignableToTypeOf(&appsv1.Deployment{})).
-			Return(nil).
-			Times(1),
-	)
+			Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&corev1.Secret{})).
+			DoAndReturn(func(ctx context.Context, k client.ObjectKey, obj client.Object) error {
+				obj.(*corev1.Secret).Data = map[string][]byte{AccessKey: []byte(key)}
+				return nil
+			})
+	}
+	getSecret("key1")
+	sum1, err := GetStorageSecretCheckSum(ctx, mockClient, "ns", "secret")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, sum1)
+	getSecret("key2")
+	sum2, err := GetStorageSecretCheckSum(ctx, mockClient, "ns", "secret")
+	assert.NoError(t, err)
+	assert.NotEqual(t, sum1, sum2)
+}
 
-	err := r.ReconcileDeployments(ctx, m)
+func TestMilvusReconciler_ClusterMode_updateDeployment_SecretCheckSum(t *testing.T) {
+	env := newClusterModeTestEnv(t)
+	defer env.tearDown()
+	r := env.Reconciler
+	mc := env.Inst
+
+	deploy := &appsv1.Deployment{}
+	deploy.Namespace = "ns"
+	err := r.updateDeployment(mc, deploy, Proxy, "sum")
+	assert.NoError(t, err)
+	assert.Equal(t, "sum", deploy.Spec.Template.Annotations[AnnotationSecretCheckSum])
+
+	err = r.updateDeployment(mc, deploy, Proxy, "")
 	assert.NoError(t, err)
+	assert.NotContains(t, deploy.Spec.Template.Annotations, AnnotationSecretCheckSum)
 }
 
-func TestMilvusReconciler_ReconcileDeployments_Existed(t *testing.T) {
-	env := newMilvusTestEnv(t)
+func TestMilvusReconciler_ClusterMode_updateDeployment_Group(t *testing.T) {
+	env := newClusterModeTestEnv(t)
 	defer env.tearDown()
 	r := env.Reconciler
-	mockClient := env.MockClient
-	ctx := env.ctx
-	m := env.Inst
+	mc := env.Inst
+	mc.Spec.Com.QueryNode.NodeSelector = map[string]string{"a": "b"}
+	mc.Spec.Com.QueryNode.Groups = []v1alpha1.ComponentGroup{
+		{Name: "hot", NodeSelector: map[string]string{"memory": "high"}, Tolerations: []corev1.Toleration{{Key: "memory"}}},
+	}
 
-	// call client.Update if changed configmap
-	gomock.InOrder(
-		mockClient.EXPECT().
-			Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&appsv1.Deployment{})).
-			DoAndReturn(func(ctx context.Context, key client.ObjectKey, obj client.Object) error {
-				cm := obj.(*appsv1.Deployment)
-				cm.Namespace = "ns"
-				cm.Name = "mc"
-				return nil
-			}),
-		mockClient.EXPECT().
-			Update(gomock.Any(), gomock.Any()).Return(nil),
-	)
+	deploy := &appsv1.Deployment{}
+	deploy.Namespace = "ns"
+	err := r.updateDeployment(mc, deploy, QueryNode, "")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "b"}, deploy.Spec.Template.Spec.NodeSelector)
+	assert.NotContains(t, deploy.Spec.Template.Labels, AppLabelResourceGroup)
 
-	err := r.ReconcileDeployments(ctx, m)
+	deploy = &appsv1.Deployment{}
+	deploy.Namespace = "ns"
+	err = r.updateDeployment(mc, deploy, QueryNode.GetGroups(mc.Spec)[0], "")
 	assert.NoError(t, err)
+	assert.Equal(t, "querynode-hot", deploy.Spec.Selector.MatchLabels[AppLabelComponent])
+	assert.Equal(t, "hot", deploy.Spec.Selector.MatchLabels[AppLabelResourceGroup])
+	assert.Equal(t, "hot", deploy.Labels[AppLabelResourceGroup])
+	assert.Equal(t, "hot", deploy.Spec.Template.Labels[AppLabelResourceGroup])
+	assert.Equal(t, map[string]string{"memory": "high"}, deploy.Spec.Template.Spec.NodeSelector)
+	assert.Equal(t, []corev1.Toleration{{Key: "memory"}}, deploy.Spec.Template.Spec.Tolerations)
+	assert.Equal(t, []string{"milvus", "run", QueryNodeName}, deploy.Spec.Template.Spec.Containers[0].Args)
+}
 
-	// not call client.Update if configmap not changed
-	gomock.InOrder(
-		mockClient.EXPECT().
-			Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&appsv1.Deployment{})).
-			DoAndReturn(func(ctx context.Context, key client.ObjectKey, obj client.Object) error {
-				cm := obj.(*appsv1.Deployment)
-				cm.Namespace = "ns"
-				cm.Name = "mc"
-				r.updateDeployment(m, cm)
-				return nil
-			}),
-	)
-	err = r.ReconcileDeployments(ctx, m)
+func TestMilvusReconciler_ClusterMode_updateDeployment_GracefulShutdown(t *testing.T) {
+	env := newClusterModeTestEnv(t)
+	defer env.tearDown()
+	r := env.Reconciler
+	mc := env.Inst
+	gracefulTime := int64(60)
+	mc.Spec.Conf.Data = map[string]interface{}{
+		"common": map[string]interface{}{"gracefulStopTimeout": float64(600)},
+	}
+	mc.Spec.Com.QueryNode.GracefulShutdown = &v1alpha1.GracefulShutdown{}
+	mc.Spec.Com.QueryNode.Strategy = &appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType}
+	mc.Spec.Com.Proxy.GracefulShutdown = &v1alpha1.GracefulShutdown{GracefulTimeSeconds: &gracefulTime}
+
+	// sized by the gracefulStopTimeout of the rendered config
+	deploy := &appsv1.Deployment{}
+	deploy.Namespace = "ns"
+	err := r.updateDeployment(mc, deploy, QueryNode, "")
+	assert.NoError(t, err)
+	assert.Equal(t, appsv1.RecreateDeploymentStrategyType, deploy.Spec.Strategy.Type)
+	assert.Equal(t, int64(600), *deploy.Spec.Template.Spec.TerminationGracePeriodSeconds)
+	assert.Nil(t, deploy.Spec.Template.Spec.Containers[0].Lifecycle)
+
+	// the proxy waits to be removed from the endpoints
+	proxyDeploy := &appsv1.Deployment{}
+	proxyDeploy.Namespace = "ns"
+	err = r.updateDeployment(mc, proxyDeploy, Proxy, "")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(660), *proxyDeploy.Spec.Template.Spec.TerminationGracePeriodSeconds)
+	assert.Equal(t, []string{"sleep", "60"}, proxyDeploy.Spec.Template.Spec.Containers[0].Lifecycle.PreStop.Exec.Command)
+
+	// reset once disabled
+	mc.Spec.Com.Proxy.GracefulShutdown = nil
+	err = r.updateDeployment(mc, proxyDeploy, Proxy, "")
+	assert.NoError(t, err)
+	assert.Nil(t, proxyDeploy.Spec.Template.Spec.TerminationGracePeriodSeconds)
+	assert.Nil(t, proxyDeploy.Spec.Template.Spec.Containers[0].Lifecycle)
+}
+
+func TestMilvusReconciler_DeleteStaleWorkloads(t *testing.T) {
+	ctx := context.Background()
+	mc := &v1alpha1.Milvus{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "mc", UID: "uid"}}
+	mc.Spec.Mode = v1alpha1.MilvusModeCluster
+	mc.Spec.Com.QueryNode.Groups = []v1alpha1.ComponentGroup{{Name: "hot"}}
+	mc.Spec.Com.QueryNode.StatefulSet = &v1alpha1.StatefulSetSpec{}
+
+	trueVal := true
+	owner := []metav1.OwnerReference{{Controller: &trueVal, UID: mc.UID}}
+	groupLabels := MergeLabels(NewAppLabels(mc.Name), map[string]string{AppLabelResourceGroup: "g"})
+	newObjectMeta := func(name string, labels map[string]string, controlled bool) metav1.ObjectMeta {
+		objMeta := metav1.ObjectMeta{Namespace: "ns", Name: name, Labels: labels}
+		if controlled {
+			objMeta.OwnerReferences = owner
+		}
+		return objMeta
+	}
+	// scaled by an HPA
+	hotReplicas := int32(2)
+	hot := &appsv1.StatefulSet{ObjectMeta: newObjectMeta("mc-milvus-querynode-hot", groupLabels, true)}
+	hot.Spec.Replicas = &hotReplicas
+	objs := []client.Object{
+		// the group switched to statefulset
+		&appsv1.Deployment{ObjectMeta: newObjectMeta("mc-milvus-querynode-hot", groupLabels, true)},
+		hot,
+		// the group removed
+		&appsv1.StatefulSet{ObjectMeta: newObjectMeta("mc-milvus-querynode-batch", groupLabels, true)},
+		&appsv1.Deployment{ObjectMeta: newObjectMeta("mc-milvus-indexnode-batch", groupLabels, false)},
+		// the component switched to statefulset
+		&appsv1.Deployment{ObjectMeta: newObjectMeta("mc-milvus-querynode", NewComponentAppLabels(mc.Name, QueryNodeName), true)},
+		// the component switched to deployment
+		&appsv1.Deployment{ObjectMeta: newObjectMeta("mc-milvus-datanode", NewComponentAppLabels(mc.Name, DataNodeName), true)},
+		&appsv1.StatefulSet{ObjectMeta: newObjectMeta("mc-milvus-datanode", NewComponentAppLabels(mc.Name, DataNodeName), true)},
+		// the cache claims
+		&corev1.PersistentVolumeClaim{ObjectMeta: newObjectMeta("cache-mc-milvus-querynode-0", NewAppLabels(mc.Name), false)},
+		&corev1.PersistentVolumeClaim{ObjectMeta: newObjectMeta("cache-mc-milvus-querynode-1", NewAppLabels(mc.Name), false)},
+		&corev1.PersistentVolumeClaim{ObjectMeta: newObjectMeta("cache-mc-milvus-querynode-hot-0", NewAppLabels(mc.Name), false)},
+		&corev1.PersistentVolumeClaim{ObjectMeta: newObjectMeta("cache-mc-milvus-querynode-hot-1", NewAppLabels(mc.Name), false)},
+		&corev1.PersistentVolumeClaim{ObjectMeta: newObjectMeta("cache-mc-milvus-querynode-hot-2", NewAppLabels(mc.Name), false)},
+		&corev1.PersistentVolumeClaim{ObjectMeta: newObjectMeta("cache-mc-milvus-datanode-0", NewAppLabels(mc.Name), false)},
+		&corev1.PersistentVolumeClaim{ObjectMeta: newObjectMeta("mc-milvus-querynode-0-cache", NewAppLabels(mc.Name), false)},
+	}
+	r, cli := newAdoptionTestEnv(t, objs...)
+
+	err := r.DeleteStaleWorkloads(ctx, *mc)
+	assert.NoError(t, err)
+
+	getNames := func(list client.ObjectList) []string {
+		assert.NoError(t, cli.List(ctx, list))
+		items, err := meta.ExtractList(list)
+		assert.NoError(t, err)
+		names := []string{}
+		for _, item := range items {
+			names = append(names, item.(client.Object).GetName())
+		}
+		return names
+	}
+	assert.ElementsMatch(t, []string{"mc-milvus-indexnode-batch", "mc-milvus-datanode"}, getNames(&appsv1.DeploymentList{}))
+	assert.ElementsMatch(t, []string{"mc-milvus-querynode-hot"}, getNames(&appsv1.StatefulSetList{}))
+	assert.ElementsMatch(t, []string{"cache-mc-milvus-querynode-0", "cache-mc-milvus-querynode-hot-0", "cache-mc-milvus-querynode-hot-1",
+		"mc-milvus-querynode-0-cache"},
+		getNames(&corev1.PersistentVolumeClaimList{}))
+}
+
+func TestMilvusReconciler_ClusterMode_updateDeployment_ImageRegistry(t *testing.T) {
+	env := newClusterModeTestEnv(t)
+	defer env.tearDown()
+	r := env.Reconciler
+	mc := env.Inst
+	defer config.SetImageSettings("", nil)
+	config.SetImageSettings("operator.registry", []string{"global-secret"})
+
+	mc.Spec.Image = "milvusdb/milvus:v2.0.0"
+	mc.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "secret"}}
+	deploy := &appsv1.Deployment{}
+	deploy.Namespace = "ns"
+	err := r.updateDeployment(mc, deploy, Proxy, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "operator.registry/milvusdb/milvus:v2.0.0", deploy.Spec.Template.Spec.Containers[0].Image)
+	assert.Equal(t, []corev1.LocalObjectReference{{Name: "secret"}, {Name: "global-secret"}}, deploy.Spec.Template.Spec.ImagePullSecrets)
+
+	mc.Spec.ImageRegistry = "my.registry"
+	err = r.updateDeployment(mc, deploy, Proxy, "")
 	assert.NoError(t, err)
+	assert.Equal(t, "my.registry/milvusdb/milvus:v2.0.0", deploy.Spec.Template.Spec.Containers[0].Image)
 }
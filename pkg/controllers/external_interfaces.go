@@ -6,6 +6,7 @@ import (
 	"github.com/apache/pulsar-client-go/pulsar"
 	"github.com/go-logr/logr"
 	"github.com/minio/madmin-go"
+	"github.com/minio/minio-go/v7"
 	clientv3 "go.etcd.io/etcd/client/v3"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -37,9 +38,36 @@ type MinioClient interface {
 	ServerInfo(ctx context.Context) (madmin.InfoMessage, error)
 }
 
+// MinioAdminClient for mock
+type MinioAdminClient interface {
+	AddUser(ctx context.Context, accessKey, secretKey string) error
+	RemoveUser(ctx context.Context, accessKey string) error
+	AddCannedPolicy(ctx context.Context, policyName string, policy []byte) error
+	RemoveCannedPolicy(ctx context.Context, policyName string) error
+	SetPolicy(ctx context.Context, policyName, entityName string, isGroup bool) error
+}
+
+// MinioBucketClient for mock
+type MinioBucketClient interface {
+	BucketExists(ctx context.Context, bucketName string) (bool, error)
+	ListObjects(ctx context.Context, bucketName string, opts minio.ListObjectsOptions) <-chan minio.ObjectInfo
+	RemoveObjects(ctx context.Context, bucketName string, objectsCh <-chan minio.ObjectInfo, opts minio.RemoveObjectsOptions) <-chan minio.RemoveObjectError
+	RemoveBucket(ctx context.Context, bucketName string) error
+}
+
+// PulsarAdminClient for mock
+type PulsarAdminClient interface {
+	ListTopics(ctx context.Context, namespace string) ([]string, error)
+	DeleteTopic(ctx context.Context, topic string) error
+	ListClusters(ctx context.Context) ([]string, error)
+	CreateTenant(ctx context.Context, tenant string, allowedClusters []string) error
+	CreateNamespace(ctx context.Context, namespace string) error
+}
+
 // EtcdClient for mock
 type EtcdClient interface {
 	Get(ctx context.Context, key string, opts ...clientv3.OpOption) (*clientv3.GetResponse, error)
+	Delete(ctx context.Context, key string, opts ...clientv3.OpOption) (*clientv3.DeleteResponse, error)
 	AlarmList(ctx context.Context) (*clientv3.AlarmResponse, error)
 	Close() error
 }
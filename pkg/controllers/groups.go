@@ -89,9 +89,9 @@ func (g *Group) Wait() error {
 }
 
 func WarppedReconcileComponentFunc(
-	f func(context.Context, v1alpha1.MilvusCluster, MilvusComponent) error,
-	ctx context.Context, mc v1alpha1.MilvusCluster, c MilvusComponent) func() error {
+	f func(context.Context, v1alpha1.Milvus, MilvusComponent) error,
+	ctx context.Context, mil v1alpha1.Milvus, c MilvusComponent) func() error {
 	return func() error {
-		return f(ctx, mc, c)
+		return f(ctx, mil, c)
 	}
 }
@@ -0,0 +1,125 @@
+package controllers
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/milvus-io/milvus-operator/apis/milvus.io/v1alpha1"
+	"github.com/milvus-io/milvus-operator/pkg/config"
+)
+
+// pulsarImageRepositories are the images in the pulsar chart and their default repositories
+var pulsarImageRepositories = map[string]string{
+	"zookeeper":      "apachepulsar/pulsar",
+	"bookie":         "apachepulsar/pulsar",
+	"autorecovery":   "apachepulsar/pulsar",
+	"broker":         "apachepulsar/pulsar",
+	"proxy":          "apachepulsar/pulsar",
+	"functions":      "apachepulsar/pulsar",
+	"prometheus":     "prom/prometheus",
+	"grafana":        "streamnative/apache-pulsar-grafana-dashboard-k8s",
+	"pulsar_manager": "apachepulsar/pulsar-manager",
+}
+
+// GetImageRegistry returns the registry replacing the one of the images,
+// the instance's registry overrides the operator's
+func GetImageRegistry(instanceRegistry string) string {
+	if instanceRegistry != "" {
+		return strings.TrimSuffix(instanceRegistry, "/")
+	}
+	return config.GetImageRegistry()
+}
+
+// ReplaceImageRegistry replaces the registry of the image, or adds one if the image doesn't have
+func ReplaceImageRegistry(image, registry string) string {
+	if registry == "" || image == "" {
+		return image
+	}
+	if i := strings.Index(image, "/"); i > 0 {
+		first := image[:i]
+		if strings.ContainsAny(first, ".:") || first == "localhost" {
+			image = image[i+1:]
+		}
+	}
+	return registry + "/" + image
+}
+
+// MergeImagePullSecrets returns the pull secrets with the operator's appended
+func MergeImagePullSecrets(secrets []corev1.LocalObjectReference) []corev1.LocalObjectReference {
+	globalSecrets := config.GetImagePullSecrets()
+	if len(globalSecrets) == 0 {
+		return secrets
+	}
+
+	ret := append([]corev1.LocalObjectReference{}, secrets...)
+	for _, name := range globalSecrets {
+		found := false
+		for _, secret := range secrets {
+			if secret.Name == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			ret = append(ret, corev1.LocalObjectReference{Name: name})
+		}
+	}
+	return ret
+}
+
+// setDefaultValue sets the helm value if it's not set in the values
+func setDefaultValue(values map[string]interface{}, value interface{}, fields ...string) {
+	if _, found, _ := unstructured.NestedFieldNoCopy(values, fields...); found {
+		return
+	}
+	unstructured.SetNestedField(values, value, fields...)
+}
+
+func pullSecretNames() []interface{} {
+	ret := []interface{}{}
+	for _, name := range config.GetImagePullSecrets() {
+		ret = append(ret, name)
+	}
+	return ret
+}
+
+// dependencyValues returns the helm values of the in-cluster dependency with the image settings injected,
+// the values in spec are kept unchanged
+func dependencyValues(values v1alpha1.Values, registry string, inject func(map[string]interface{}, string)) map[string]interface{} {
+	if registry == "" && len(config.GetImagePullSecrets()) == 0 {
+		return values.Data
+	}
+	ret := values.DeepCopy().Data
+	if ret == nil {
+		ret = map[string]interface{}{}
+	}
+	inject(ret, registry)
+	return ret
+}
+
+// InjectBitnamiImageValues injects the registry and the pull secrets into the values of the bitnami charts,
+// i.e. etcd and minio. The ones set in the values are respected
+func InjectBitnamiImageValues(values map[string]interface{}, registry string) {
+	if registry != "" {
+		setDefaultValue(values, registry, "global", "imageRegistry")
+	}
+	if secrets := pullSecretNames(); len(secrets) > 0 {
+		setDefaultValue(values, secrets, "global", "imagePullSecrets")
+	}
+}
+
+// InjectPulsarImageValues injects the registry and the pull secrets into the values of the pulsar chart.
+// The chart has no global registry, so the registry is added to the default repository of each image.
+// The repositories set in the values are respected, like the bitnami charts do
+func InjectPulsarImageValues(values map[string]interface{}, registry string) {
+	if registry != "" {
+		for name, repository := range pulsarImageRepositories {
+			setDefaultValue(values, ReplaceImageRegistry(repository, registry), "images", name, "repository")
+		}
+	}
+	if secrets := pullSecretNames(); len(secrets) > 0 {
+		setDefaultValue(values, secrets, "images", "imagePullSecrets")
+	}
+}
@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/milvus-io/milvus-operator/apis/milvus.io/v1alpha1"
+	"github.com/milvus-io/milvus-operator/pkg/config"
+)
+
+func TestGetImageRegistry(t *testing.T) {
+	defer config.SetImageSettings("", nil)
+
+	assert.Equal(t, "", GetImageRegistry(""))
+	assert.Equal(t, "my.registry", GetImageRegistry("my.registry/"))
+
+	config.SetImageSettings("operator.registry", nil)
+	assert.Equal(t, "operator.registry", GetImageRegistry(""))
+	assert.Equal(t, "my.registry", GetImageRegistry("my.registry"))
+}
+
+func TestReplaceImageRegistry(t *testing.T) {
+	assert.Equal(t, "milvusdb/milvus:v2.0.0", ReplaceImageRegistry("milvusdb/milvus:v2.0.0", ""))
+	assert.Equal(t, "my.registry/milvusdb/milvus:v2.0.0", ReplaceImageRegistry("milvusdb/milvus:v2.0.0", "my.registry"))
+	assert.Equal(t, "my.registry/milvusdb/milvus:v2.0.0", ReplaceImageRegistry("docker.io/milvusdb/milvus:v2.0.0", "my.registry"))
+	assert.Equal(t, "my.registry/milvus", ReplaceImageRegistry("localhost:5000/milvus", "my.registry"))
+	assert.Equal(t, "my.registry:5000/busybox", ReplaceImageRegistry("busybox", "my.registry:5000"))
+	assert.Equal(t, "my.registry/path/milvus", ReplaceImageRegistry("localhost/milvus", "my.registry/path"))
+}
+
+func TestMergeImagePullSecrets(t *testing.T) {
+	defer config.SetImageSettings("", nil)
+
+	secrets := []corev1.LocalObjectReference{{Name: "a"}}
+	assert.Equal(t, secrets, MergeImagePullSecrets(secrets))
+
+	config.SetImageSettings("", []string{"a", "b"})
+	assert.Equal(t, []corev1.LocalObjectReference{{Name: "a"}, {Name: "b"}}, MergeImagePullSecrets(secrets))
+	assert.Equal(t, []corev1.LocalObjectReference{{Name: "a"}, {Name: "b"}}, MergeImagePullSecrets(nil))
+	// not modified
+	assert.Len(t, secrets, 1)
+}
+
+func TestDependencyValues(t *testing.T) {
+	defer config.SetImageSettings("", nil)
+	values := v1alpha1.Values{Data: map[string]interface{}{"replicaCount": 1}}
+
+	// not injected
+	assert.Equal(t, values.Data, dependencyValues(values, "", InjectBitnamiImageValues))
+
+	// bitnami
+	config.SetImageSettings("", []string{"secret"})
+	ret := dependencyValues(values, "my.registry", InjectBitnamiImageValues)
+	assert.Equal(t, map[string]interface{}{
+		"replicaCount": float64(1),
+		"global": map[string]interface{}{
+			"imageRegistry":    "my.registry",
+			"imagePullSecrets": []interface{}{"secret"},
+		},
+	}, ret)
+	_, injected := values.Data["global"]
+	assert.False(t, injected)
+
+	// bitnami, the one in values respected
+	values.Data["global"] = map[string]interface{}{"imageRegistry": "other.registry"}
+	ret = dependencyValues(values, "my.registry", InjectBitnamiImageValues)
+	assert.Equal(t, "other.registry", ret["global"].(map[string]interface{})["imageRegistry"])
+
+	// pulsar, the repository in values respected
+	values = v1alpha1.Values{Data: map[string]interface{}{
+		"images": map[string]interface{}{
+			"broker": map[string]interface{}{"repository": "docker.io/my/pulsar"},
+		},
+	}}
+	ret = dependencyValues(values, "my.registry", InjectPulsarImageValues)
+	images := ret["images"].(map[string]interface{})
+	assert.Equal(t, "docker.io/my/pulsar", images["broker"].(map[string]interface{})["repository"])
+	assert.Equal(t, "my.registry/apachepulsar/pulsar", images["zookeeper"].(map[string]interface{})["repository"])
+	assert.Equal(t, "my.registry/prom/prometheus", images["prometheus"].(map[string]interface{})["repository"])
+	assert.Equal(t, []interface{}{"secret"}, images["imagePullSecrets"])
+
+	// nil values
+	ret = dependencyValues(v1alpha1.Values{}, "my.registry", InjectBitnamiImageValues)
+	assert.Equal(t, "my.registry", ret["global"].(map[string]interface{})["imageRegistry"])
+}
@@ -3,13 +3,17 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"reflect"
 
 	"github.com/milvus-io/milvus-operator/apis/milvus.io/v1alpha1"
-	"github.com/milvus-io/milvus-operator/pkg/helm"
-	"github.com/milvus-io/milvus-operator/pkg/util"
 	"github.com/pkg/errors"
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/labels"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -17,64 +21,109 @@ func IsSetDefaultDone(mc *v1alpha1.Milvus) bool {
 	return mc.Status.Status != ""
 }
 
-func (r *MilvusReconciler) Finalize(ctx context.Context, mil v1alpha1.Milvus) error {
+// Finalize deletes the data and the dependencies of the instance by their deletion policies,
+// it returns true once they're deleted
+func (r *MilvusReconciler) Finalize(ctx context.Context, mil v1alpha1.Milvus) (bool, error) {
+	// a protected instance gets here only by bypassing the webhook, keep its data anyway
+	if mil.IsDeletionProtected() {
+		r.logger.Info("deletion protected, keep the dependencies", "name", mil.Name, "namespace", mil.Namespace)
+		return true, nil
+	}
+
+	if !GetExternalDataInfo(mil).IsEmpty() {
+		stopped, err := r.StopWorkloads(ctx, mil)
+		if err != nil {
+			return false, errors.Wrap(err, "stop workloads")
+		}
+		if !stopped {
+			r.logger.Info("waiting for the workloads to stop before deleting the data", "name", mil.Name, "namespace", mil.Namespace)
+			return false, nil
+		}
+	}
+
+	if err := r.DeleteExternalData(ctx, mil); err != nil {
+		return false, err
+	}
+
+	// the dependencies served by a pool are uninstalled with the pool
+	if mil.Spec.Dep.Pool != nil {
+		return true, r.DeletePoolStorageUser(ctx, mil)
+	}
+
 	deletingReleases := map[string]bool{}
 
 	if mil.Spec.Dep.Etcd.InCluster.DeletionPolicy == v1alpha1.DeletionPolicyDelete {
 		deletingReleases[mil.Name+"-etcd"] = mil.Spec.Dep.Etcd.InCluster.PVCDeletion
 	}
+	if mil.Spec.IsCluster() && mil.Spec.Dep.Pulsar.InCluster.DeletionPolicy == v1alpha1.DeletionPolicyDelete {
+		deletingReleases[mil.Name+"-pulsar"] = mil.Spec.Dep.Pulsar.InCluster.PVCDeletion
+	}
 	if mil.Spec.Dep.Storage.InCluster.DeletionPolicy == v1alpha1.DeletionPolicyDelete {
 		deletingReleases[mil.Name+"-minio"] = mil.Spec.Dep.Storage.InCluster.PVCDeletion
 	}
 
-	if len(deletingReleases) > 0 {
-		cfg := r.helmReconciler.NewHelmCfg(mil.Namespace)
+	return true, UninstallReleases(ctx, r.Client, r.logger, r.helmReconciler, mil.Namespace, deletingReleases)
+}
 
-		errs := []error{}
-		for releaseName, deletePVC := range deletingReleases {
-			if err := helm.Uninstall(cfg, releaseName); err != nil {
-				errs = append(errs, err)
+// StopWorkloads deletes the deployments and the statefulsets of the instance before its data is deleted,
+// otherwise its components keep writing the data back. They're deleted in the foreground, so that they're gone
+// only after their pods. It returns true once no workload of the instance is left
+func (r *MilvusReconciler) StopWorkloads(ctx context.Context, mil v1alpha1.Milvus) (bool, error) {
+	stopped := true
+	for _, list := range []client.ObjectList{&appsv1.DeploymentList{}, &appsv1.StatefulSetList{}} {
+		if err := r.List(ctx, list, client.InNamespace(mil.Namespace)); err != nil {
+			return false, err
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return false, err
+		}
+		for _, item := range items {
+			obj := item.(client.Object)
+			if !metav1.IsControlledBy(obj, &mil) {
 				continue
 			}
-
-			if deletePVC {
-				pvcList := &corev1.PersistentVolumeClaimList{}
-				if err := r.List(ctx, pvcList, &client.ListOptions{
-					Namespace: mil.Namespace,
-					LabelSelector: labels.SelectorFromSet(map[string]string{
-						AppLabelInstance: releaseName,
-					}),
-				}); err != nil {
-					errs = append(errs, err)
-					continue
-				}
-
-				for _, pvc := range pvcList.Items {
-					if err := r.Delete(ctx, &pvc); err != nil {
-						errs = append(errs, err)
-					} else {
-						r.logger.Info("pvc deleted", "name", pvc.Name, "namespace", pvc.Namespace)
-					}
-				}
+			stopped = false
+			if !obj.GetDeletionTimestamp().IsZero() {
+				continue
 			}
-		}
-
-		if len(errs) > 0 {
-			return errors.Errorf(util.JoinErrors(errs))
+			err := r.Delete(ctx, obj, client.PropagationPolicy(metav1.DeletePropagationForeground))
+			if err != nil && !k8sErrors.IsNotFound(err) {
+				return false, err
+			}
+			r.logger.Info("workload deleted before the data", "kind", reflect.TypeOf(obj).Elem().Name(),
+				"name", obj.GetName(), "namespace", obj.GetNamespace())
 		}
 	}
-
-	return nil
+	return stopped, nil
 }
 
 func (r *MilvusReconciler) SetDefault(ctx context.Context, mc *v1alpha1.Milvus) error {
-	if !mc.Spec.Dep.Etcd.External && len(mc.Spec.Dep.Etcd.Endpoints) == 0 {
-		mc.Spec.Dep.Etcd.Endpoints = []string{fmt.Sprintf("%s-etcd.%s:2379", mc.Name, mc.Namespace)}
+	defaults, err := v1alpha1.GetOperatorDefaults(ctx, r.Client, mc.Namespace)
+	if err != nil {
+		return errors.Wrap(err, "get operator defaults")
 	}
-	if !mc.Spec.Dep.Storage.External && len(mc.Spec.Dep.Storage.Endpoint) == 0 {
-		mc.Spec.Dep.Storage.Endpoint = fmt.Sprintf("%s-minio.%s:9000", mc.Name, mc.Namespace)
+	mc.SetOperatorDefaults(defaults)
+
+	var pulsar *v1alpha1.MilvusPulsar
+	if mc.Spec.IsCluster() {
+		pulsar = &mc.Spec.Dep.Pulsar
 	}
-	return nil
+	if mc.Spec.Dep.Pool != nil {
+		SetDependencyPoolEndpoints(mc.Namespace, *mc.Spec.Dep.Pool, &mc.Spec.Dep.Etcd, &mc.Spec.Dep.Storage, pulsar)
+	} else {
+		if !mc.Spec.Dep.Etcd.External && len(mc.Spec.Dep.Etcd.Endpoints) == 0 {
+			mc.Spec.Dep.Etcd.Endpoints = []string{fmt.Sprintf("%s:2379", ServiceHost(mc.Name+"-etcd", mc.Namespace))}
+		}
+		if mc.Spec.IsCluster() && !mc.Spec.Dep.Pulsar.External && len(mc.Spec.Dep.Pulsar.Endpoint) == 0 {
+			mc.Spec.Dep.Pulsar.Endpoint = fmt.Sprintf("%s:6650", ServiceHost(mc.Name+"-pulsar-proxy", mc.Namespace))
+		}
+		if !mc.Spec.Dep.Storage.External && len(mc.Spec.Dep.Storage.Endpoint) == 0 {
+			mc.Spec.Dep.Storage.Endpoint = fmt.Sprintf("%s:9000", ServiceHost(mc.Name+"-minio", mc.Namespace))
+		}
+	}
+
+	return ResolveDependencyServiceRefs(ctx, r.Client, mc.Namespace, &mc.Spec.Dep.Etcd, &mc.Spec.Dep.Storage, pulsar)
 }
 
 // SetDefaultStatus update status if default not set; return true if updated, return false if not, return err if update failed
@@ -90,9 +139,35 @@ func (r *MilvusReconciler) SetDefaultStatus(ctx context.Context, mc *v1alpha1.Mi
 	return false, nil
 }
 
+// UpdateReconcileStatus records the result of ReconcileAll in the status: the generation is observed if it succeeded,
+// and the Stalled, Ready and Reconciling conditions are updated accordingly
+func (r *MilvusReconciler) UpdateReconcileStatus(ctx context.Context, mil *v1alpha1.Milvus, reconcileErr error) error {
+	old := mil.Status.DeepCopy()
+	if reconcileErr == nil {
+		if mil.Status.ObservedGeneration != mil.Generation {
+			UpdateCondition(&mil.Status, GetRolloutNotCheckedCondition(mil.Generation))
+		}
+		mil.Status.ObservedGeneration = mil.Generation
+	}
+	UpdateCondition(&mil.Status, GetStalledCondition(reconcileErr))
+	for _, cond := range GetKStatusConditions(mil.Status.Conditions, mil.Generation, mil.Status.ObservedGeneration) {
+		UpdateCondition(&mil.Status, cond)
+	}
+	if IsEqual(*old, mil.Status) {
+		return nil
+	}
+	err := r.Client.Status().Update(ctx, mil)
+	return errors.Wrapf(err, "update reconcile status[%s/%s] failed", mil.Namespace, mil.Name)
+}
+
 func (r *MilvusReconciler) ReconcileAll(ctx context.Context, mil v1alpha1.Milvus) error {
+	if err := r.HandOverMilvusClusterChildren(ctx, mil); err != nil {
+		return errors.Wrap(err, "hand over milvuscluster children")
+	}
+
 	milvusReconcilers := []Func{
 		r.ReconcileEtcd,
+		r.ReconcilePulsar,
 		r.ReconcileMinio,
 		r.ReconcileMilvus,
 	}
@@ -100,19 +175,166 @@ func (r *MilvusReconciler) ReconcileAll(ctx context.Context, mil v1alpha1.Milvus
 	return errors.Wrap(err, "reconcile milvus")
 }
 
+// milvusClusterChildren are the kinds of the children the MilvusCluster controlled before it's served by a Milvus
+var milvusClusterChildren = []func() client.ObjectList{
+	func() client.ObjectList { return &appsv1.DeploymentList{} },
+	func() client.ObjectList { return &corev1.ServiceList{} },
+	func() client.ObjectList { return &corev1.ConfigMapList{} },
+	func() client.ObjectList { return &monitoringv1.PodMonitorList{} },
+}
+
+// HandOverMilvusClusterChildren moves the controller reference of the children the MilvusCluster created before
+// it's served by the Milvus to the Milvus, so that the Milvus can apply them, and they're garbage collected with it.
+// It's done until the Milvus is reconciled the first time, the later children are created by the Milvus
+func (r *MilvusReconciler) HandOverMilvusClusterChildren(ctx context.Context, mil v1alpha1.Milvus) error {
+	owner := metav1.GetControllerOf(&mil)
+	if owner == nil || owner.Kind != "MilvusCluster" || mil.Status.ObservedGeneration > 0 {
+		return nil
+	}
+
+	for _, newList := range milvusClusterChildren {
+		list := newList()
+		err := r.List(ctx, list, client.InNamespace(mil.Namespace), client.MatchingLabels{AppLabelInstance: mil.Name})
+		if meta.IsNoMatchError(err) {
+			continue
+		}
+		if err != nil {
+			return errors.Wrap(err, "list children")
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			obj := item.(client.Object)
+			ref := metav1.GetControllerOf(obj)
+			if ref == nil || ref.UID != owner.UID {
+				continue
+			}
+			patch := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+			refs := []metav1.OwnerReference{}
+			for _, ref := range obj.GetOwnerReferences() {
+				if ref.UID != owner.UID {
+					refs = append(refs, ref)
+				}
+			}
+			obj.SetOwnerReferences(refs)
+			if err := ctrl.SetControllerReference(&mil, obj, r.Scheme); err != nil {
+				return err
+			}
+			r.logger.Info("hand over milvuscluster child", "list", fmt.Sprintf("%T", list), "name", obj.GetName(), "namespace", obj.GetNamespace())
+			if err := r.Patch(ctx, obj, patch); err != nil {
+				return errors.Wrapf(err, "patch owner of %s", obj.GetName())
+			}
+		}
+	}
+	return nil
+}
+
 func (r *MilvusReconciler) ReconcileMilvus(ctx context.Context, mil v1alpha1.Milvus) error {
-	if !IsDependencyReady(mil.Status.Conditions, false) {
+	if !IsDependencyReady(mil.Status.Conditions, mil.Spec.IsCluster()) {
 		return nil
 	}
 
+	if mil.Spec.IsCluster() {
+		drained, err := r.DrainStandalone(ctx, mil)
+		if err != nil {
+			return errors.Wrap(err, "drain standalone")
+		}
+		if !drained {
+			r.logger.Info("waiting for the standalone to drain", "name", mil.Name, "namespace", mil.Namespace)
+			return nil
+		}
+		if err := r.DeleteStandalone(ctx, mil); err != nil {
+			return errors.Wrap(err, "delete standalone")
+		}
+	}
+
+	if IsAdopted(mil.Status.Conditions) {
+		if err := r.ReplaceAdoptedDeployments(ctx, mil); err != nil {
+			return errors.Wrap(err, "replace adopted deployments")
+		}
+	}
+
 	if err := r.ReconcileConfigMaps(ctx, mil); err != nil {
 		return errors.Wrap(err, "configmap")
 	}
 	milvusComsReconcilers := []Func{
 		r.ReconcileDeployments,
+		r.DeleteStaleWorkloads,
 		r.ReconcileServices,
 		r.ReconcilePodMonitor,
 	}
 	err := defaultGroupRunner.Run(milvusComsReconcilers, ctx, mil)
 	return errors.Wrap(err, "reconcile components")
 }
+
+// DeleteExternalData deletes the data in the external dependencies if the dataDeletionPolicy is Delete,
+// the progress is reported in the status if it fails
+func (r *MilvusReconciler) DeleteExternalData(ctx context.Context, mil v1alpha1.Milvus) error {
+	info := GetExternalDataInfo(mil)
+	if info.IsEmpty() {
+		return nil
+	}
+
+	cond := DeleteExternalData(ctx, r.logger, r.Client, info)
+	if cond.Status == corev1.ConditionTrue {
+		return nil
+	}
+
+	mil.Status.Status = v1alpha1.StatusDeleting
+	UpdateCondition(&mil.Status, cond)
+	if err := r.Status().Update(ctx, &mil); err != nil {
+		r.logger.Error(err, "update data deletion status failed", "name", mil.Name, "namespace", mil.Namespace)
+	}
+	return errors.Errorf("delete external data: %s", cond.Message)
+}
+
+// DrainStandalone scales the standalone to 0 before it's deleted for cluster mode, so that the components of the cluster
+// don't start until it stopped accepting requests and exited gracefully. It returns true once no pod of it is left
+func (r *MilvusReconciler) DrainStandalone(ctx context.Context, mil v1alpha1.Milvus) (bool, error) {
+	deployment := &appsv1.Deployment{}
+	err := r.Get(ctx, NamespacedName(mil.Namespace, MilvusStandalone.GetDeploymentInstanceName(mil.Name)), deployment)
+	if k8sErrors.IsNotFound(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if !metav1.IsControlledBy(deployment, &mil) || deployment.Labels[AppLabelComponent] != MilvusName {
+		return true, nil
+	}
+
+	if deployment.Spec.Replicas == nil || *deployment.Spec.Replicas > 0 {
+		patch := client.MergeFrom(deployment.DeepCopy())
+		deployment.Spec.Replicas = int32Ptr(0)
+		r.logger.Info("scale down the standalone for cluster mode", "name", deployment.Name, "namespace", deployment.Namespace)
+		return false, r.Patch(ctx, deployment, patch)
+	}
+	return deployment.Status.Replicas == 0, nil
+}
+
+// DeleteStandalone deletes the deployment and the service of the standalone once the instance is changed to cluster mode.
+// They're named after the instance, so only the ones of its standalone component are deleted.
+// The coordinators of the cluster wait for the sessions of the standalone to expire before serving
+func (r *MilvusReconciler) DeleteStandalone(ctx context.Context, mil v1alpha1.Milvus) error {
+	objs := []client.Object{&appsv1.Deployment{}, &corev1.Service{}}
+	for _, obj := range objs {
+		err := r.Get(ctx, NamespacedName(mil.Namespace, MilvusStandalone.GetDeploymentInstanceName(mil.Name)), obj)
+		if k8sErrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if !metav1.IsControlledBy(obj, &mil) || obj.GetLabels()[AppLabelComponent] != MilvusName {
+			continue
+		}
+		if err := r.Delete(ctx, obj); err != nil && !k8sErrors.IsNotFound(err) {
+			return err
+		}
+		r.logger.Info("standalone deleted for cluster mode", "kind", reflect.TypeOf(obj).Elem().Name(),
+			"name", obj.GetName(), "namespace", obj.GetNamespace())
+	}
+	return nil
+}
@@ -21,11 +21,16 @@ import (
 	"fmt"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"github.com/go-logr/logr"
 	milvusv1alpha1 "github.com/milvus-io/milvus-operator/apis/milvus.io/v1alpha1"
@@ -34,6 +39,8 @@ import (
 
 const (
 	MilvusFinalizerName = "milvus.milvus.io/finalizer"
+	// workloadStopInterval is the interval to check the workloads stopped before the data is deleted
+	workloadStopInterval = 5 * time.Second
 )
 
 // MilvusReconciler reconciles a Milvus object
@@ -89,17 +96,25 @@ func (r *MilvusReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 
 	} else {
 		if controllerutil.ContainsFinalizer(milvus, MilvusFinalizerName) {
-			if err := r.Finalize(ctx, *milvus); err != nil {
+			done, err := r.Finalize(ctx, *milvus)
+			if err != nil {
 				return ctrl.Result{}, err
 			}
+			if !done {
+				return ctrl.Result{RequeueAfter: workloadStopInterval}, nil
+			}
 			controllerutil.RemoveFinalizer(milvus, MilvusFinalizerName)
-			err := r.Update(ctx, milvus)
-			return ctrl.Result{}, err
+			return ctrl.Result{}, r.Update(ctx, milvus)
 		}
 		// Stop reconciliation as the item is being deleted
 		return ctrl.Result{}, nil
 	}
 
+	// the adopted release is mapped into the spec before any default is set
+	if milvus.IsAdopting() {
+		return ctrl.Result{}, r.Adopt(ctx, milvus)
+	}
+
 	// Start reconcile
 	r.logger.Info("start reconcile")
 	old := milvus.DeepCopy()
@@ -119,7 +134,12 @@ func (r *MilvusReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		return ctrl.Result{}, err
 	}
 
-	if err := r.ReconcileAll(ctx, *milvus); err != nil {
+	reconcileErr := r.ReconcileAll(ctx, *milvus)
+	err = r.UpdateReconcileStatus(ctx, milvus, reconcileErr)
+	if reconcileErr != nil {
+		return ctrl.Result{}, reconcileErr
+	}
+	if err != nil {
 		return ctrl.Result{}, err
 	}
 
@@ -140,8 +160,54 @@ func (r *MilvusReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 }
 
 // SetupWithManager sets up the controller with the Manager.
-func (r *MilvusReconciler) SetupWithManager(mgr ctrl.Manager) error {
+func (r *MilvusReconciler) SetupWithManager(mgr ctrl.Manager, options controller.Options) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&milvusv1alpha1.Milvus{}).
+		Watches(
+			&source.Kind{Type: &corev1.Secret{}},
+			handler.EnqueueRequestsFromMapFunc(r.secretToMilvus),
+		).
+		Watches(
+			&source.Kind{Type: &corev1.Service{}},
+			handler.EnqueueRequestsFromMapFunc(r.serviceToMilvus),
+		).
+		WithOptions(options).
 		Complete(r)
 }
+
+// secretToMilvus enqueues the Milvus referencing the storage secret,
+// so that the credential rotation rolls out to the pods
+func (r *MilvusReconciler) secretToMilvus(obj client.Object) []reconcile.Request {
+	list := &milvusv1alpha1.MilvusList{}
+	if err := r.List(context.Background(), list, client.InNamespace(obj.GetNamespace())); err != nil {
+		r.logger.Error(err, "list milvus for secret error", "secret", obj.GetName())
+		return nil
+	}
+
+	ret := []reconcile.Request{}
+	for _, mil := range list.Items {
+		if mil.Spec.Dep.Storage.SecretRef == obj.GetName() {
+			ret = append(ret, reconcile.Request{NamespacedName: NamespacedName(mil.Namespace, mil.Name)})
+		}
+	}
+	return ret
+}
+
+// serviceToMilvus enqueues the Milvus referring to the service by the serviceRefs of the dependencies,
+// so that their endpoints are re-resolved
+func (r *MilvusReconciler) serviceToMilvus(obj client.Object) []reconcile.Request {
+	list := &milvusv1alpha1.MilvusList{}
+	if err := r.List(context.Background(), list); err != nil {
+		r.logger.Error(err, "list milvus for service error", "service", obj.GetName())
+		return nil
+	}
+
+	ret := []reconcile.Request{}
+	for _, mil := range list.Items {
+		dep := mil.Spec.Dep
+		if IsServiceReferred(obj, mil.Namespace, dep.Etcd.ServiceRef, dep.Storage.ServiceRef, dep.Pulsar.ServiceRef) {
+			ret = append(ret, reconcile.Request{NamespacedName: NamespacedName(mil.Namespace, mil.Name)})
+		}
+	}
+	return ret
+}
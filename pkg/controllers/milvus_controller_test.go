@@ -11,6 +11,7 @@ import (
 	"github.com/milvus-io/milvus-operator/pkg/util"
 	"github.com/stretchr/testify/assert"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -42,6 +43,9 @@ func TestMilvusReconciler_ReconcileFinalizer(t *testing.T) {
 			*o = m
 		}).
 		Return(nil)
+	// operator defaults
+	mockClient.EXPECT().Get(gomock.Any(), client.ObjectKey{Name: v1alpha1.MilvusOperatorConfigName}, gomock.Any()).
+		Return(nil)
 
 	mockClient.EXPECT().Update(gomock.Any(), gomock.Any()).Do(
 		func(ctx, obj interface{}, opts ...interface{}) {
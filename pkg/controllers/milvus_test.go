@@ -9,9 +9,12 @@ import (
 	"github.com/milvus-io/milvus-operator/apis/milvus.io/v1alpha1"
 	"github.com/milvus-io/milvus-operator/pkg/helm"
 	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -27,8 +30,9 @@ func TestMilvus_Finalize(t *testing.T) {
 	errTest := errors.New("test")
 
 	// no delete
-	err := r.Finalize(ctx, m)
+	done, err := r.Finalize(ctx, m)
 	assert.NoError(t, err)
+	assert.True(t, done)
 
 	// etcd, delete pvc
 	m.Spec.Dep.Etcd.InCluster.DeletionPolicy = v1alpha1.DeletionPolicyDelete
@@ -42,7 +46,7 @@ func TestMilvus_Finalize(t *testing.T) {
 			}
 		})
 	mockClient.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(nil)
-	err = r.Finalize(ctx, m)
+	_, err = r.Finalize(ctx, m)
 	assert.NoError(t, err)
 
 	// storage, delete, uninstall failed
@@ -50,7 +54,7 @@ func TestMilvus_Finalize(t *testing.T) {
 	m.Spec.Dep.Storage.InCluster.DeletionPolicy = v1alpha1.DeletionPolicyDelete
 	m.Spec.Dep.Storage.InCluster.PVCDeletion = true
 	mockHelm.EXPECT().Uninstall(gomock.Any(), gomock.Any()).Return(errTest)
-	err = r.Finalize(ctx, m)
+	_, err = r.Finalize(ctx, m)
 	assert.Error(t, err)
 
 	// storage, delete, list failed
@@ -62,7 +66,7 @@ func TestMilvus_Finalize(t *testing.T) {
 				{},
 			}
 		}).Return(errTest)
-	err = r.Finalize(ctx, m)
+	_, err = r.Finalize(ctx, m)
 	assert.Error(t, err)
 
 	// storage, delete, delete failed
@@ -75,8 +79,18 @@ func TestMilvus_Finalize(t *testing.T) {
 			}
 		})
 	mockClient.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(errTest)
-	err = r.Finalize(ctx, m)
+	_, err = r.Finalize(ctx, m)
 	assert.Error(t, err)
+
+	// deletion protected, nothing uninstalled
+	m.Spec.DeletionProtection = true
+	_, err = r.Finalize(ctx, m)
+	assert.NoError(t, err)
+
+	m.Spec.DeletionProtection = false
+	m.Annotations = map[string]string{v1alpha1.AnnotationDeletionProtection: "true"}
+	_, err = r.Finalize(ctx, m)
+	assert.NoError(t, err)
 }
 
 func TestMilvus_SetDefaultStatus(t *testing.T) {
@@ -110,6 +124,57 @@ func TestMilvus_SetDefaultStatus(t *testing.T) {
 	assert.False(t, updated)
 }
 
+func TestMilvus_UpdateReconcileStatus(t *testing.T) {
+	env := newMilvusTestEnv(t)
+	defer env.tearDown()
+	r := env.Reconciler
+	mockClient := env.MockClient
+	ctx := env.ctx
+
+	// reconcile failed
+	m := env.Inst
+	m.Generation = 1
+	mockClient.EXPECT().Status().Return(mockClient)
+	mockClient.EXPECT().Update(gomock.Any(), gomock.Any())
+	err := r.UpdateReconcileStatus(ctx, &m, errors.New("test"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), m.Status.ObservedGeneration)
+	assert.Equal(t, corev1.ConditionTrue, GetCondition(m.Status.Conditions, v1alpha1.Stalled).Status)
+
+	// reconcile succeeded
+	mockClient.EXPECT().Status().Return(mockClient)
+	mockClient.EXPECT().Update(gomock.Any(), gomock.Any())
+	err = r.UpdateReconcileStatus(ctx, &m, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), m.Status.ObservedGeneration)
+	assert.Equal(t, corev1.ConditionFalse, GetCondition(m.Status.Conditions, v1alpha1.Ready).Status)
+
+	// not changed, not updated
+	err = r.UpdateReconcileStatus(ctx, &m, nil)
+	assert.NoError(t, err)
+
+	// ready for the generation once the status syncer checked the workloads
+	UpdateCondition(&m.Status, v1alpha1.MilvusCondition{Type: v1alpha1.MilvusReady, Status: corev1.ConditionTrue})
+	mockClient.EXPECT().Status().Return(mockClient)
+	mockClient.EXPECT().Update(gomock.Any(), gomock.Any())
+	err = r.UpdateReconcileStatus(ctx, &m, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, corev1.ConditionTrue, GetCondition(m.Status.Conditions, v1alpha1.Ready).Status)
+
+	// a new generation is not ready by the MilvusReady of the previous one
+	m.Generation = 2
+	mockClient.EXPECT().Status().Return(mockClient)
+	mockClient.EXPECT().Update(gomock.Any(), gomock.Any())
+	err = r.UpdateReconcileStatus(ctx, &m, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), m.Status.ObservedGeneration)
+	assert.Equal(t, v1alpha1.ReasonRolloutNotChecked, GetCondition(m.Status.Conditions, v1alpha1.MilvusReady).Reason)
+	ready := GetCondition(m.Status.Conditions, v1alpha1.Ready)
+	assert.Equal(t, corev1.ConditionFalse, ready.Status)
+	assert.Equal(t, v1alpha1.ReasonRolloutNotChecked, ready.Reason)
+	assert.Equal(t, corev1.ConditionTrue, GetCondition(m.Status.Conditions, v1alpha1.Reconciling).Status)
+}
+
 func TestMilvus_ReconcileAll(t *testing.T) {
 	env := newMilvusTestEnv(t)
 	defer env.tearDown()
@@ -120,7 +185,7 @@ func TestMilvus_ReconcileAll(t *testing.T) {
 	mockGroup := NewMockGroupRunner(env.Ctrl)
 	defaultGroupRunner = mockGroup
 
-	mockGroup.EXPECT().Run(gomock.Len(3), gomock.Any(), m)
+	mockGroup.EXPECT().Run(gomock.Len(4), gomock.Any(), m)
 
 	err := r.ReconcileAll(ctx, m)
 	assert.NoError(t, err)
@@ -155,17 +220,238 @@ func TestMilvus_ReconcileMilvus(t *testing.T) {
 
 	gomock.InOrder(
 		mockClient.EXPECT().
-			Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&corev1.ConfigMap{})).
+			Patch(gomock.Any(), gomock.AssignableToTypeOf(&corev1.ConfigMap{}), client.Apply, gomock.Any(), gomock.Any()).
+			Return(nil),
+		mockGroup.EXPECT().Run(gomock.Len(4), gomock.Any(), m),
+	)
+
+	err = r.ReconcileMilvus(ctx, m)
+	assert.NoError(t, err)
+}
+
+func TestMilvus_ReconcileMilvus_ClusterMode(t *testing.T) {
+	env := newClusterModeTestEnv(t)
+	defer env.tearDown()
+	r := env.Reconciler
+	mockClient := env.MockClient
+	ctx := env.ctx
+	m := env.Inst
+
+	// pulsar not ready
+	m.Status.Conditions = []v1alpha1.MilvusCondition{
+		{Type: v1alpha1.EtcdReady, Status: corev1.ConditionTrue},
+		{Type: v1alpha1.StorageReady, Status: corev1.ConditionTrue},
+	}
+	err := r.ReconcileMilvus(ctx, m)
+	assert.NoError(t, err)
+
+	// dep ready, standalone deleted before the components reconciled
+	mockGroup := NewMockGroupRunner(env.Ctrl)
+	defaultGroupRunner = mockGroup
+	m.Status.Conditions = append(m.Status.Conditions,
+		v1alpha1.MilvusCondition{Type: v1alpha1.PulsarReady, Status: corev1.ConditionTrue})
+
+	gomock.InOrder(
+		mockClient.EXPECT().Get(gomock.Any(), NamespacedName(m.Namespace, m.Name), gomock.AssignableToTypeOf(&appsv1.Deployment{})).
+			Return(k8sErrors.NewNotFound(schema.GroupResource{}, "")),
+		mockClient.EXPECT().Get(gomock.Any(), NamespacedName(m.Namespace, m.Name), gomock.AssignableToTypeOf(&appsv1.Deployment{})).
+			Return(k8sErrors.NewNotFound(schema.GroupResource{}, "")),
+		mockClient.EXPECT().Get(gomock.Any(), NamespacedName(m.Namespace, m.Name), gomock.AssignableToTypeOf(&corev1.Service{})).
 			Return(k8sErrors.NewNotFound(schema.GroupResource{}, "")),
-		// get secret of minio
-		mockClient.EXPECT().
-			Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&corev1.Secret{})).
-			Return(k8sErrors.NewNotFound(schema.GroupResource{}, "mockErr")),
 		mockClient.EXPECT().
-			Create(gomock.Any(), gomock.Any()).Return(nil),
-		mockGroup.EXPECT().Run(gomock.Len(3), gomock.Any(), m),
+			Patch(gomock.Any(), gomock.AssignableToTypeOf(&corev1.ConfigMap{}), client.Apply, gomock.Any(), gomock.Any()).
+			Return(nil),
+		mockGroup.EXPECT().Run(gomock.Len(4), gomock.Any(), m),
 	)
-
 	err = r.ReconcileMilvus(ctx, m)
 	assert.NoError(t, err)
 }
+
+func TestMilvus_DrainStandalone(t *testing.T) {
+	ctx := context.Background()
+	scheme := newSchemeForTest()
+	mil := v1alpha1.Milvus{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "mc", UID: "uid"}}
+	mil.Spec.Mode = v1alpha1.MilvusModeCluster
+	replicas := int32(1)
+	standalone := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "mc", Labels: NewComponentAppLabels("mc", MilvusName)},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status:     appsv1.DeploymentStatus{Replicas: 1},
+	}
+	assert.NoError(t, ctrl.SetControllerReference(&mil, standalone, scheme))
+	r, cli := newAdoptionTestEnv(t, standalone)
+
+	// scaled down first
+	drained, err := r.DrainStandalone(ctx, mil)
+	assert.NoError(t, err)
+	assert.False(t, drained)
+	deployment := &appsv1.Deployment{}
+	assert.NoError(t, cli.Get(ctx, NamespacedName("ns", "mc"), deployment))
+	assert.Equal(t, int32(0), *deployment.Spec.Replicas)
+
+	// pods terminating
+	drained, err = r.DrainStandalone(ctx, mil)
+	assert.NoError(t, err)
+	assert.False(t, drained)
+
+	// no pod left
+	deployment.Status.Replicas = 0
+	assert.NoError(t, cli.Update(ctx, deployment))
+	drained, err = r.DrainStandalone(ctx, mil)
+	assert.NoError(t, err)
+	assert.True(t, drained)
+
+	// not found
+	assert.NoError(t, cli.Delete(ctx, deployment))
+	drained, err = r.DrainStandalone(ctx, mil)
+	assert.NoError(t, err)
+	assert.True(t, drained)
+}
+
+func TestMilvus_DeleteStandalone(t *testing.T) {
+	env := newClusterModeTestEnv(t)
+	defer env.tearDown()
+	r := env.Reconciler
+	mockClient := env.MockClient
+	ctx := env.ctx
+	m := env.Inst
+	m.UID = "uid"
+	trueVal := true
+	controlled := metav1.ObjectMeta{
+		Labels:          map[string]string{AppLabelComponent: MilvusName},
+		OwnerReferences: []metav1.OwnerReference{{Controller: &trueVal, UID: m.UID}},
+	}
+
+	// the standalone ones deleted
+	mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&appsv1.Deployment{})).
+		Do(func(ctx, key interface{}, obj *appsv1.Deployment) { obj.ObjectMeta = controlled })
+	mockClient.EXPECT().Delete(gomock.Any(), gomock.AssignableToTypeOf(&appsv1.Deployment{}))
+	mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&corev1.Service{})).
+		Do(func(ctx, key interface{}, obj *corev1.Service) { obj.ObjectMeta = controlled })
+	mockClient.EXPECT().Delete(gomock.Any(), gomock.AssignableToTypeOf(&corev1.Service{}))
+	assert.NoError(t, r.DeleteStandalone(ctx, m))
+
+	// not controlled or not the standalone, kept
+	mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&appsv1.Deployment{}))
+	mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&corev1.Service{})).
+		Do(func(ctx, key interface{}, obj *corev1.Service) {
+			obj.ObjectMeta = controlled
+			obj.Labels = map[string]string{AppLabelComponent: ProxyName}
+		})
+	assert.NoError(t, r.DeleteStandalone(ctx, m))
+
+	// get failed
+	mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("test"))
+	assert.Error(t, r.DeleteStandalone(ctx, m))
+}
+
+func TestMilvus_Finalize_ClusterMode(t *testing.T) {
+	env := newClusterModeTestEnv(t)
+	defer env.tearDown()
+	r := env.Reconciler
+	ctx := env.ctx
+	m := env.Inst
+	mockHelm := helm.NewMockClient(env.Ctrl)
+	helm.SetDefaultClient(mockHelm)
+
+	// pulsar uninstalled
+	m.Spec.Dep.Pulsar.InCluster.DeletionPolicy = v1alpha1.DeletionPolicyDelete
+	mockHelm.EXPECT().Uninstall(gomock.Any(), m.Name+"-pulsar")
+	_, err := r.Finalize(ctx, m)
+	assert.NoError(t, err)
+
+	// no pulsar for the standalone
+	m.Spec.Mode = v1alpha1.MilvusModeStandalone
+	_, err = r.Finalize(ctx, m)
+	assert.NoError(t, err)
+}
+
+func TestMilvus_HandOverMilvusClusterChildren(t *testing.T) {
+	ctx := context.Background()
+	scheme := newSchemeForTest()
+	mc := &v1alpha1.MilvusCluster{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "mc", UID: "mc-uid"}}
+	mil := mc.ToMilvus()
+	mil.UID = "mil-uid"
+	assert.NoError(t, ctrl.SetControllerReference(mc, mil, scheme))
+
+	// the children of the milvuscluster created by the former versions
+	deploy := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+		Namespace: "ns", Name: "mc-milvus-proxy", Labels: NewComponentAppLabels("mc", ProxyName),
+	}}
+	configmap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Namespace: "ns", Name: "mc", Labels: NewAppLabels("mc"),
+	}}
+	for _, obj := range []client.Object{deploy, configmap} {
+		assert.NoError(t, ctrl.SetControllerReference(mc, obj, scheme))
+	}
+	other := &corev1.Service{ObjectMeta: metav1.ObjectMeta{
+		Namespace: "ns", Name: "other", Labels: NewAppLabels("mc"),
+		OwnerReferences: []metav1.OwnerReference{{APIVersion: "v1", Kind: "Other", Name: "other", UID: "other-uid"}},
+	}}
+	r, cli := newAdoptionTestEnv(t, deploy, configmap, other)
+
+	assert.NoError(t, r.HandOverMilvusClusterChildren(ctx, *mil))
+	for _, obj := range []client.Object{&appsv1.Deployment{}, &corev1.ConfigMap{}} {
+		name := "mc"
+		if _, ok := obj.(*appsv1.Deployment); ok {
+			name = "mc-milvus-proxy"
+		}
+		assert.NoError(t, cli.Get(ctx, NamespacedName("ns", name), obj))
+		assert.Len(t, obj.GetOwnerReferences(), 1)
+		assert.True(t, metav1.IsControlledBy(obj, mil))
+	}
+	svc := &corev1.Service{}
+	assert.NoError(t, cli.Get(ctx, NamespacedName("ns", "other"), svc))
+	assert.Equal(t, other.OwnerReferences, svc.OwnerReferences)
+
+	// done once the milvus is reconciled
+	deploy = &appsv1.Deployment{}
+	assert.NoError(t, cli.Get(ctx, NamespacedName("ns", "mc-milvus-proxy"), deploy))
+	deploy.OwnerReferences = nil
+	assert.NoError(t, ctrl.SetControllerReference(mc, deploy, scheme))
+	assert.NoError(t, cli.Update(ctx, deploy))
+	mil.Status.ObservedGeneration = 1
+	assert.NoError(t, r.HandOverMilvusClusterChildren(ctx, *mil))
+	assert.NoError(t, cli.Get(ctx, NamespacedName("ns", "mc-milvus-proxy"), deploy))
+	assert.True(t, metav1.IsControlledBy(deploy, mc))
+
+	// not serving a milvuscluster
+	assert.NoError(t, r.HandOverMilvusClusterChildren(ctx, v1alpha1.Milvus{}))
+}
+
+func TestMilvus_Finalize_StopWorkloads(t *testing.T) {
+	ctx := context.Background()
+	m := v1alpha1.Milvus{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "m", UID: "uid"}}
+	m.Spec.Dep.DataDeletionPolicy = v1alpha1.DeletionPolicyDelete
+	m.Spec.Dep.Storage.External = true
+	m.Spec.Dep.Storage.Endpoint = "minio:9000"
+	trueVal := true
+	owner := []metav1.OwnerReference{{Controller: &trueVal, UID: m.UID}}
+	deploy := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "m-milvus-standalone", OwnerReferences: owner}}
+	statefulSet := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "m-milvus-querynode", OwnerReferences: owner}}
+	other := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "other"}}
+	r, cli := newAdoptionTestEnv(t, deploy, statefulSet, other)
+
+	// the data is deleted only after the workloads are gone
+	done, err := r.Finalize(ctx, m)
+	assert.NoError(t, err)
+	assert.False(t, done)
+	err = cli.Get(ctx, client.ObjectKeyFromObject(deploy), &appsv1.Deployment{})
+	assert.True(t, k8sErrors.IsNotFound(err))
+	err = cli.Get(ctx, client.ObjectKeyFromObject(statefulSet), &appsv1.StatefulSet{})
+	assert.True(t, k8sErrors.IsNotFound(err))
+	assert.NoError(t, cli.Get(ctx, client.ObjectKeyFromObject(other), &appsv1.Deployment{}))
+
+	stopped, err := r.StopWorkloads(ctx, m)
+	assert.NoError(t, err)
+	assert.True(t, stopped)
+
+	// the data is kept, no need to wait
+	m.Spec.Dep.DataDeletionPolicy = v1alpha1.DeletionPolicyRetain
+	assert.NoError(t, cli.Create(ctx, &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "m-milvus-standalone", OwnerReferences: owner}}))
+	m.Spec.Dep.Etcd.InCluster = &v1alpha1.InClusterConfig{}
+	m.Spec.Dep.Storage.InCluster = &v1alpha1.InClusterConfig{}
+	done, err = r.Finalize(ctx, m)
+	assert.NoError(t, err)
+	assert.True(t, done)
+}
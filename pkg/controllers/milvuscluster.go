@@ -4,20 +4,15 @@ import (
 	"context"
 	"fmt"
 
-	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/labels"
-	"sigs.k8s.io/controller-runtime/pkg/client"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	"github.com/milvus-io/milvus-operator/apis/milvus.io/v1alpha1"
-	"github.com/milvus-io/milvus-operator/pkg/helm"
-	"github.com/milvus-io/milvus-operator/pkg/util"
 	"github.com/pkg/errors"
 )
 
-func IsClusterSetDefaultDone(mc *v1alpha1.MilvusCluster) bool {
-	return mc.Status.Status != ""
-}
-
 // SetDefaultStatus update status if default not set; return true if updated, return false if not, return err if update failed
 func (r *MilvusClusterReconciler) SetDefaultStatus(ctx context.Context, mc *v1alpha1.MilvusCluster) (bool, error) {
 	if mc.Status.Status == "" {
@@ -32,97 +27,112 @@ func (r *MilvusClusterReconciler) SetDefaultStatus(ctx context.Context, mc *v1al
 }
 
 func (r *MilvusClusterReconciler) SetDefault(ctx context.Context, mc *v1alpha1.MilvusCluster) error {
-	if !mc.Spec.Dep.Etcd.External && len(mc.Spec.Dep.Etcd.Endpoints) == 0 {
-		mc.Spec.Dep.Etcd.Endpoints = []string{fmt.Sprintf("%s-etcd.%s:2379", mc.Name, mc.Namespace)}
-	}
-	if !mc.Spec.Dep.Pulsar.External && len(mc.Spec.Dep.Pulsar.Endpoint) == 0 {
-		mc.Spec.Dep.Pulsar.Endpoint = fmt.Sprintf("%s-pulsar-proxy.%s:6650", mc.Name, mc.Namespace)
-	}
-	if !mc.Spec.Dep.Storage.External && len(mc.Spec.Dep.Storage.Endpoint) == 0 {
-		mc.Spec.Dep.Storage.Endpoint = fmt.Sprintf("%s-minio.%s:9000", mc.Name, mc.Namespace)
+	defaults, err := v1alpha1.GetOperatorDefaults(ctx, r.Client, mc.Namespace)
+	if err != nil {
+		return errors.Wrap(err, "get operator defaults")
 	}
+	mc.SetOperatorDefaults(defaults)
 
-	return nil
-}
+	if mc.Spec.Dep.Pool != nil {
+		SetDependencyPoolEndpoints(mc.Namespace, *mc.Spec.Dep.Pool, &mc.Spec.Dep.Etcd, &mc.Spec.Dep.Storage, &mc.Spec.Dep.Pulsar)
+	} else {
+		if !mc.Spec.Dep.Etcd.External && len(mc.Spec.Dep.Etcd.Endpoints) == 0 {
+			mc.Spec.Dep.Etcd.Endpoints = []string{fmt.Sprintf("%s:2379", ServiceHost(mc.Name+"-etcd", mc.Namespace))}
+		}
+		if !mc.Spec.Dep.Pulsar.External && len(mc.Spec.Dep.Pulsar.Endpoint) == 0 {
+			mc.Spec.Dep.Pulsar.Endpoint = fmt.Sprintf("%s:6650", ServiceHost(mc.Name+"-pulsar-proxy", mc.Namespace))
+		}
+		if !mc.Spec.Dep.Storage.External && len(mc.Spec.Dep.Storage.Endpoint) == 0 {
+			mc.Spec.Dep.Storage.Endpoint = fmt.Sprintf("%s:9000", ServiceHost(mc.Name+"-minio", mc.Namespace))
+		}
+	}
 
-func (r *MilvusClusterReconciler) ReconcileAll(ctx context.Context, mc v1alpha1.MilvusCluster) error {
-	clusterReconcilers := []Func{
-		r.ReconcileEtcd,
-		r.ReconcilePulsar,
-		r.ReconcileMinio,
-		r.ReconcileMilvus,
-	}
-	err := defaultGroupRunner.Run(clusterReconcilers, ctx, mc)
-	return errors.Wrap(err, "reconcile milvuscluster")
+	return ResolveDependencyServiceRefs(ctx, r.Client, mc.Namespace, &mc.Spec.Dep.Etcd, &mc.Spec.Dep.Storage, &mc.Spec.Dep.Pulsar)
 }
 
-func (r *MilvusClusterReconciler) ReconcileMilvus(ctx context.Context, mc v1alpha1.MilvusCluster) error {
-	if !IsClusterDependencyReady(mc.Status) {
-		return nil
+// ReconcileMilvus applies the Milvus in cluster mode serving the MilvusCluster, and returns the applied one.
+// The Milvus of the same name must not exist or be controlled by the MilvusCluster
+func (r *MilvusClusterReconciler) ReconcileMilvus(ctx context.Context, mc v1alpha1.MilvusCluster) (*v1alpha1.Milvus, error) {
+	existing := &v1alpha1.Milvus{}
+	err := r.Get(ctx, NamespacedName(mc.Namespace, mc.Name), existing)
+	if err == nil && !metav1.IsControlledBy(existing, &mc) {
+		return nil, errors.Errorf("milvus[%s/%s] exists and it's not serving the milvuscluster", mc.Namespace, mc.Name)
 	}
-
-	if err := r.ReconcileConfigMaps(ctx, mc); err != nil {
-		return fmt.Errorf("configmap: %w", err)
+	if err != nil && !k8sErrors.IsNotFound(err) {
+		return nil, errors.Wrap(err, "get milvus")
 	}
 
-	comReconcilers := []Func{
-		r.ReconcileDeployments,
-		r.ReconcileServices,
-		r.ReconcilePodMonitor,
+	mil := mc.ToMilvus()
+	if err := ctrl.SetControllerReference(&mc, mil, r.Scheme); err != nil {
+		return nil, err
+	}
+	if err := ApplyObject(ctx, r.Client, r.Scheme, mil); err != nil {
+		return nil, errors.Wrap(err, "apply milvus")
 	}
-	err := defaultGroupRunner.Run(comReconcilers, ctx, mc)
-	return errors.Wrap(err, "reconcile milvuscluster")
+	return mil, nil
 }
 
-func (r *MilvusClusterReconciler) Finalize(ctx context.Context, mc v1alpha1.MilvusCluster) error {
-	deletingReleases := map[string]bool{}
-
-	if mc.Spec.Dep.Etcd.InCluster.DeletionPolicy == v1alpha1.DeletionPolicyDelete {
-		deletingReleases[mc.Name+"-etcd"] = mc.Spec.Dep.Etcd.InCluster.PVCDeletion
+// UpdateStatus copies the status of the Milvus serving the MilvusCluster: the generation is observed once
+// the Milvus observed its own, the Stalled condition reports the error of ReconcileMilvus if any,
+// and the Ready and Reconciling conditions are computed for the MilvusCluster
+func (r *MilvusClusterReconciler) UpdateStatus(ctx context.Context, mc *v1alpha1.MilvusCluster, mil *v1alpha1.Milvus, reconcileErr error) error {
+	old := mc.Status.DeepCopy()
+	if mil != nil {
+		if mil.Status.Status != "" {
+			mc.Status.Status = mil.Status.Status
+		}
+		mc.Status.Endpoint = mil.Status.Endpoint
+		mc.Status.ActiveCoordinators = mil.Status.ActiveCoordinators
+		for _, cond := range mil.Status.Conditions {
+			if cond.Type == v1alpha1.Ready || cond.Type == v1alpha1.Reconciling {
+				continue
+			}
+			UpdateClusterCondition(&mc.Status, cond)
+		}
+		if reconcileErr == nil && mil.Status.ObservedGeneration == mil.Generation {
+			mc.Status.ObservedGeneration = mc.Generation
+		}
 	}
-	if mc.Spec.Dep.Pulsar.InCluster.DeletionPolicy == v1alpha1.DeletionPolicyDelete {
-		deletingReleases[mc.Name+"-pulsar"] = mc.Spec.Dep.Pulsar.InCluster.PVCDeletion
+	if reconcileErr != nil || GetCondition(mc.Status.Conditions, v1alpha1.Stalled) == nil {
+		UpdateClusterCondition(&mc.Status, GetStalledCondition(reconcileErr))
 	}
-	if mc.Spec.Dep.Storage.InCluster.DeletionPolicy == v1alpha1.DeletionPolicyDelete {
-		deletingReleases[mc.Name+"-minio"] = mc.Spec.Dep.Storage.InCluster.PVCDeletion
+	for _, cond := range GetKStatusConditions(mc.Status.Conditions, mc.Generation, mc.Status.ObservedGeneration) {
+		UpdateClusterCondition(&mc.Status, cond)
 	}
+	if IsEqual(*old, mc.Status) {
+		return nil
+	}
+	err := r.Client.Status().Update(ctx, mc)
+	return errors.Wrapf(err, "update status[%s/%s] failed", mc.Namespace, mc.Name)
+}
 
-	if len(deletingReleases) > 0 {
-		cfg := r.helmReconciler.NewHelmCfg(mc.Namespace)
-
-		errs := []error{}
-		for releaseName, deletePVC := range deletingReleases {
-			if err := helm.Uninstall(cfg, releaseName); err != nil {
-				errs = append(errs, err)
-				continue
-			}
-
-			if deletePVC {
-				pvcList := &corev1.PersistentVolumeClaimList{}
-				if err := r.List(ctx, pvcList, &client.ListOptions{
-					Namespace: mc.Namespace,
-					LabelSelector: labels.SelectorFromSet(map[string]string{
-						AppLabelInstance: releaseName,
-					}),
-				}); err != nil {
-					errs = append(errs, err)
-					continue
-				}
+// Finalize deletes the Milvus serving the MilvusCluster, it returns true once the Milvus is gone.
+// The Milvus is kept if the MilvusCluster is deleted with the orphan propagation policy,
+// which is the way to migrate from MilvusCluster to Milvus
+func (r *MilvusClusterReconciler) Finalize(ctx context.Context, mc v1alpha1.MilvusCluster) (bool, error) {
+	if controllerutil.ContainsFinalizer(&mc, metav1.FinalizerOrphanDependents) {
+		r.logger.Info("milvus orphaned", "name", mc.Name, "namespace", mc.Namespace)
+		return true, nil
+	}
 
-				for _, pvc := range pvcList.Items {
-					if err := r.Delete(ctx, &pvc); err != nil {
-						errs = append(errs, err)
-					} else {
-						r.logger.Info("pvc deleted", "name", pvc.Name, "namespace", pvc.Namespace)
-					}
-				}
-			}
-		}
+	mil := &v1alpha1.Milvus{}
+	err := r.Get(ctx, NamespacedName(mc.Namespace, mc.Name), mil)
+	if k8sErrors.IsNotFound(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, errors.Wrap(err, "get milvus")
+	}
+	if !metav1.IsControlledBy(mil, &mc) {
+		return true, nil
+	}
 
-		if len(errs) > 0 {
-			return errors.Errorf(util.JoinErrors(errs))
+	if mil.DeletionTimestamp.IsZero() {
+		if err := r.Delete(ctx, mil); err != nil && !k8sErrors.IsNotFound(err) {
+			return false, errors.Wrap(err, "delete milvus")
 		}
+		r.logger.Info("milvus deleting", "name", mc.Name, "namespace", mc.Namespace)
 	}
-
-	return nil
+	// wait for the milvus finalized, the deletion of it requeues the milvuscluster
+	return false, nil
 }
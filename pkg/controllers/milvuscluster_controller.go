@@ -19,9 +19,9 @@ package controllers
 import (
 	"context"
 	"fmt"
-	"time"
 
 	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -29,8 +29,11 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	milvusv1alpha1 "github.com/milvus-io/milvus-operator/apis/milvus.io/v1alpha1"
 	"github.com/milvus-io/milvus-operator/pkg/config"
@@ -43,19 +46,19 @@ const (
 // MilvusClusterReconciler reconciles a MilvusCluster object
 type MilvusClusterReconciler struct {
 	client.Client
-	Scheme         *runtime.Scheme
-	logger         logr.Logger
-	helmReconciler HelmReconciler
-	statusSyncer   *MilvusClusterStatusSyncer
+	Scheme *runtime.Scheme
+	logger logr.Logger
 }
 
 //+kubebuilder:rbac:groups=milvus.io,resources=milvusclusters,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=milvus.io,resources=milvusclusters/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=milvus.io,resources=milvusclusters/finalizers,verbs=update
+//+kubebuilder:rbac:groups=milvus.io,resources=milvusoperatorconfigs,verbs=get;list;watch
 //+kubebuilder:rbac:groups=extensions,resources=statefulsets;deployments;pods;secrets;services,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=apps,resources=statefulsets;deployments,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=apps,resources=pods;secrets;services,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=autoscaling,resources=horizontalpodautoscalers,verbs=get;list;watch
 //+kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups="",resources=services;configmaps;secrets,verbs=get;list;watch;create;update;patch;delete
@@ -71,13 +74,12 @@ type MilvusClusterReconciler struct {
 //+kubebuilder:rbac:groups="extensions",resources=ingresses,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups="monitoring.coreos.com",resources=servicemonitors;podmonitors,verbs=get;list;watch;create;update;patch;delete
 
-// Reconcile is part of the main kubernetes reconciliation loop which aims to
-// move the current state of the cluster closer to the desired state.
+// Reconcile serves the MilvusCluster by the Milvus in cluster mode of the same name,
+// the spec is converted to the Milvus and its status is copied back.
 //
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.8.3/pkg/reconcile
 func (r *MilvusClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	r.statusSyncer.RunIfNot()
 	if !config.IsDebug() {
 		defer func() {
 			if err := recover(); err != nil {
@@ -107,12 +109,12 @@ func (r *MilvusClusterReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 
 	} else {
 		if controllerutil.ContainsFinalizer(milvuscluster, MCFinalizerName) {
-			if err := r.Finalize(ctx, *milvuscluster); err != nil {
+			done, err := r.Finalize(ctx, *milvuscluster)
+			if err != nil || !done {
 				return ctrl.Result{}, err
 			}
 			controllerutil.RemoveFinalizer(milvuscluster, MCFinalizerName)
-			err := r.Update(ctx, milvuscluster)
-			return ctrl.Result{}, err
+			return ctrl.Result{}, r.Update(ctx, milvuscluster)
 		}
 		// Stop reconciliation as the item is being deleted
 		return ctrl.Result{}, nil
@@ -137,15 +139,16 @@ func (r *MilvusClusterReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		return ctrl.Result{}, err
 	}
 
-	if err := r.ReconcileAll(ctx, *milvuscluster); err != nil {
+	milvus, reconcileErr := r.ReconcileMilvus(ctx, *milvuscluster)
+	err = r.UpdateStatus(ctx, milvuscluster, milvus, reconcileErr)
+	if reconcileErr != nil {
+		return ctrl.Result{}, reconcileErr
+	}
+	if err != nil {
 		return ctrl.Result{}, err
 	}
 
-	// status will be updated by syncer
-
-	if milvuscluster.Status.Status == milvusv1alpha1.StatusUnHealthy {
-		return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
-	}
+	// the status changes of the milvus requeue the milvuscluster
 
 	if config.IsDebug() {
 		diff, err := client.MergeFrom(old).Data(milvuscluster)
@@ -158,14 +161,16 @@ func (r *MilvusClusterReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 }
 
 // SetupWithManager sets up the controller with the Manager.
-func (r *MilvusClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
+func (r *MilvusClusterReconciler) SetupWithManager(mgr ctrl.Manager, options controller.Options) error {
 	builder := ctrl.NewControllerManagedBy(mgr).
 		For(&milvusv1alpha1.MilvusCluster{}).
-		//Owns(&appsv1.Deployment{}).
-		//Owns(&corev1.ConfigMap{}).
-		//Owns(&corev1.Service{}).
+		Owns(&milvusv1alpha1.Milvus{}).
+		Watches(
+			&source.Kind{Type: &corev1.Service{}},
+			handler.EnqueueRequestsFromMapFunc(r.serviceToMilvusCluster),
+		).
 		//WithEventFilter(&MilvusClusterPredicate{}).
-		WithOptions(controller.Options{MaxConcurrentReconciles: 1})
+		WithOptions(options)
 
 	/* if config.IsDebug() {
 		builder.WithEventFilter(DebugPredicate())
@@ -174,6 +179,25 @@ func (r *MilvusClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return builder.Complete(r)
 }
 
+// serviceToMilvusCluster enqueues the MilvusCluster referring to the service by the serviceRefs of the dependencies,
+// so that their endpoints are re-resolved
+func (r *MilvusClusterReconciler) serviceToMilvusCluster(obj client.Object) []reconcile.Request {
+	list := &milvusv1alpha1.MilvusClusterList{}
+	if err := r.List(context.Background(), list); err != nil {
+		r.logger.Error(err, "list milvuscluster for service error", "service", obj.GetName())
+		return nil
+	}
+
+	ret := []reconcile.Request{}
+	for _, mc := range list.Items {
+		dep := mc.Spec.Dep
+		if IsServiceReferred(obj, mc.Namespace, dep.Etcd.ServiceRef, dep.Storage.ServiceRef, dep.Pulsar.ServiceRef) {
+			ret = append(ret, reconcile.Request{NamespacedName: NamespacedName(mc.Namespace, mc.Name)})
+		}
+	}
+	return ret
+}
+
 var predicateLog = logf.Log.WithName("predicates").WithName("MilvusCluster")
 
 type MilvusClusterPredicate struct {
@@ -9,8 +9,11 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/stretchr/testify/assert"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/milvus-io/milvus-operator/apis/milvus.io/v1alpha1"
@@ -73,9 +76,6 @@ func TestClusterReconciler_ReconcileFinalizer(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 	r := newClusterReconcilerForTest(ctrl)
-	r.statusSyncer = &MilvusClusterStatusSyncer{}
-	// syncer need not to run in this test
-	r.statusSyncer.Once.Do(func() {})
 	mockClient := r.Client.(*MockK8sClient)
 
 	// case create
@@ -95,6 +95,9 @@ func TestClusterReconciler_ReconcileFinalizer(t *testing.T) {
 			*o = m
 		}).
 		Return(nil)
+	// operator defaults
+	mockClient.EXPECT().Get(gomock.Any(), client.ObjectKey{Name: v1alpha1.MilvusOperatorConfigName}, gomock.Any()).
+		Return(nil)
 
 	mockClient.EXPECT().Update(gomock.Any(), gomock.Any()).Do(
 		func(ctx, obj interface{}, opts ...interface{}) {
@@ -116,6 +119,9 @@ func TestClusterReconciler_ReconcileFinalizer(t *testing.T) {
 			*o = m
 		}).
 		Return(nil)
+	// milvus deleted
+	mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&v1alpha1.Milvus{})).
+		Return(k8sErrors.NewNotFound(schema.GroupResource{}, ""))
 
 	mockClient.EXPECT().Update(gomock.Any(), gomock.Any()).Do(
 		func(ctx, obj interface{}, opts ...interface{}) {
@@ -4,13 +4,14 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/milvus-io/milvus-operator/apis/milvus.io/v1alpha1"
-	"github.com/milvus-io/milvus-operator/pkg/helm"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -21,62 +22,55 @@ func TestCluster_Finalize(t *testing.T) {
 	r := env.Reconciler
 	mockClient := env.MockClient
 	ctx := env.ctx
-	m := env.Inst
-	mockHelm := helm.NewMockClient(env.Ctrl)
-	helm.SetDefaultClient(mockHelm)
-	errTest := errors.New("test")
+	mc := env.Inst
+	mc.UID = "uid"
+	trueVal := true
 
-	// no delete
-	err := r.Finalize(ctx, m)
+	// orphaned, milvus kept
+	mc.Finalizers = []string{metav1.FinalizerOrphanDependents, MCFinalizerName}
+	done, err := r.Finalize(ctx, mc)
 	assert.NoError(t, err)
+	assert.True(t, done)
+	mc.Finalizers = []string{MCFinalizerName}
 
-	// etcd, delete pvc
-	m.Spec.Dep.Etcd.InCluster.DeletionPolicy = v1alpha1.DeletionPolicyDelete
-	m.Spec.Dep.Etcd.InCluster.PVCDeletion = true
-	mockHelm.EXPECT().Uninstall(gomock.Any(), gomock.Any())
-	mockClient.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any()).
-		Do(func(ctx context.Context, list client.ObjectList, opts ...client.ListOption) {
-			pvcList := list.(*corev1.PersistentVolumeClaimList)
-			pvcList.Items = []corev1.PersistentVolumeClaim{
-				{},
-			}
-		})
-	mockClient.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(nil)
-	err = r.Finalize(ctx, m)
+	// get failed
+	mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&v1alpha1.Milvus{})).
+		Return(errors.New("test"))
+	_, err = r.Finalize(ctx, mc)
+	assert.Error(t, err)
+
+	// not found, done
+	mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&v1alpha1.Milvus{})).
+		Return(k8sErrors.NewNotFound(schema.GroupResource{}, ""))
+	done, err = r.Finalize(ctx, mc)
 	assert.NoError(t, err)
+	assert.True(t, done)
 
-	// storage, delete, uninstall failed
-	m.Spec.Dep.Etcd.InCluster.DeletionPolicy = v1alpha1.DeletionPolicyRetain
-	m.Spec.Dep.Storage.InCluster.DeletionPolicy = v1alpha1.DeletionPolicyDelete
-	m.Spec.Dep.Storage.InCluster.PVCDeletion = true
-	mockHelm.EXPECT().Uninstall(gomock.Any(), gomock.Any()).Return(errTest)
-	err = r.Finalize(ctx, m)
-	assert.Error(t, err)
+	// not controlled, done
+	mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&v1alpha1.Milvus{}))
+	done, err = r.Finalize(ctx, mc)
+	assert.NoError(t, err)
+	assert.True(t, done)
 
-	// storage, delete, list failed
-	mockHelm.EXPECT().Uninstall(gomock.Any(), gomock.Any())
-	mockClient.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any()).
-		Do(func(ctx context.Context, list client.ObjectList, opts ...client.ListOption) {
-			pvcList := list.(*corev1.PersistentVolumeClaimList)
-			pvcList.Items = []corev1.PersistentVolumeClaim{
-				{},
-			}
-		}).Return(errTest)
-	err = r.Finalize(ctx, m)
-	assert.Error(t, err)
+	// controlled, deleted and wait
+	controlled := func(ctx, key interface{}, obj *v1alpha1.Milvus) {
+		obj.OwnerReferences = []metav1.OwnerReference{{Controller: &trueVal, UID: mc.UID}}
+	}
+	mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&v1alpha1.Milvus{})).Do(controlled)
+	mockClient.EXPECT().Delete(gomock.Any(), gomock.AssignableToTypeOf(&v1alpha1.Milvus{}))
+	done, err = r.Finalize(ctx, mc)
+	assert.NoError(t, err)
+	assert.False(t, done)
 
-	// storage, delete, delete failed
-	mockHelm.EXPECT().Uninstall(gomock.Any(), gomock.Any())
-	mockClient.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any()).
-		Do(func(ctx context.Context, list client.ObjectList, opts ...client.ListOption) {
-			pvcList := list.(*corev1.PersistentVolumeClaimList)
-			pvcList.Items = []corev1.PersistentVolumeClaim{
-				{},
-			}
+	// deleting, wait
+	mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&v1alpha1.Milvus{})).
+		Do(func(ctx, key interface{}, obj *v1alpha1.Milvus) {
+			controlled(ctx, key, obj)
+			obj.DeletionTimestamp = &metav1.Time{Time: time.Now()}
 		})
-	mockClient.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(errTest)
-	err = r.Finalize(ctx, m)
-	assert.Error(t, err)
+	done, err = r.Finalize(ctx, mc)
+	assert.NoError(t, err)
+	assert.False(t, done)
 }
 
 func TestCluster_SetDefaultStatus(t *testing.T) {
@@ -110,66 +104,153 @@ func TestCluster_SetDefaultStatus(t *testing.T) {
 	assert.False(t, updated)
 }
 
-func TestCluster_ReconcileAll(t *testing.T) {
+func TestCluster_SetDefault(t *testing.T) {
 	env := newClusterTestEnv(t)
 	defer env.tearDown()
 	r := env.Reconciler
+	mockClient := env.MockClient
 	ctx := env.ctx
-	m := env.Inst
 
-	mockGroup := NewMockGroupRunner(env.Ctrl)
-	defaultGroupRunner = mockGroup
+	// get operator defaults failed
+	m := env.Inst
+	mockClient.EXPECT().Get(gomock.Any(), client.ObjectKey{Name: v1alpha1.MilvusOperatorConfigName}, gomock.Any()).
+		Return(errors.New("test"))
+	err := r.SetDefault(ctx, &m)
+	assert.Error(t, err)
 
-	mockGroup.EXPECT().Run(gomock.Len(4), gomock.Any(), m)
+	// operator defaults set
+	m = env.Inst
+	m.Spec.Com.Image = ""
+	m.Spec.Dep.Etcd.InCluster = nil
+	mockClient.EXPECT().Get(gomock.Any(), client.ObjectKey{Name: v1alpha1.MilvusOperatorConfigName}, gomock.Any()).
+		Do(func(ctx, key, obj interface{}) {
+			o := obj.(*v1alpha1.MilvusOperatorConfig)
+			o.Spec.Defaults.DeletionPolicy = v1alpha1.DeletionPolicyDelete
+			o.Spec.Defaults.Image = "my/milvus"
+		})
+	err = r.SetDefault(ctx, &m)
+	assert.NoError(t, err)
+	assert.Equal(t, v1alpha1.DeletionPolicyDelete, m.Spec.Dep.Etcd.InCluster.DeletionPolicy)
+	assert.Equal(t, "my/milvus", m.Spec.Com.Image)
+	assert.Len(t, m.Spec.Dep.Etcd.Endpoints, 1)
 
-	err := r.ReconcileAll(ctx, m)
+	// not found
+	m = env.Inst
+	mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(k8sErrors.NewNotFound(schema.GroupResource{}, v1alpha1.MilvusOperatorConfigName))
+	err = r.SetDefault(ctx, &m)
 	assert.NoError(t, err)
 }
 
 func TestCluster_ReconcileMilvus(t *testing.T) {
-	env := newMilvusTestEnv(t)
+	env := newClusterTestEnv(t)
 	defer env.tearDown()
 	r := env.Reconciler
 	mockClient := env.MockClient
 	ctx := env.ctx
-	m := env.Inst
+	mc := env.Inst
+	mc.UID = "uid"
+	mc.Spec.Com.Proxy.ServiceType = corev1.ServiceTypeNodePort
+
+	// not served by the milvuscluster
+	mockClient.EXPECT().Get(gomock.Any(), NamespacedName(mc.Namespace, mc.Name), gomock.AssignableToTypeOf(&v1alpha1.Milvus{}))
+	_, err := r.ReconcileMilvus(ctx, mc)
+	assert.Error(t, err)
+
+	// applied in cluster mode
+	mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&v1alpha1.Milvus{})).
+		Return(k8sErrors.NewNotFound(schema.GroupResource{}, ""))
+	mockClient.EXPECT().
+		Patch(gomock.Any(), gomock.AssignableToTypeOf(&v1alpha1.Milvus{}), client.Apply, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+			mil := obj.(*v1alpha1.Milvus)
+			assert.True(t, metav1.IsControlledBy(mil, &mc))
+			assert.True(t, mil.Spec.IsCluster())
+			assert.Equal(t, corev1.ServiceTypeNodePort, mil.Spec.ServiceType)
+			return nil
+		})
+	mil, err := r.ReconcileMilvus(ctx, mc)
+	assert.NoError(t, err)
+	assert.Equal(t, mc.Name, mil.Name)
 
-	// dep not ready
-	err := r.ReconcileMilvus(ctx, m)
+	// apply failed
+	mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&v1alpha1.Milvus{})).
+		Return(k8sErrors.NewNotFound(schema.GroupResource{}, ""))
+	mockClient.EXPECT().
+		Patch(gomock.Any(), gomock.AssignableToTypeOf(&v1alpha1.Milvus{}), client.Apply, gomock.Any(), gomock.Any()).
+		Return(errors.New("test"))
+	_, err = r.ReconcileMilvus(ctx, mc)
+	assert.Error(t, err)
+}
+
+func TestCluster_UpdateStatus(t *testing.T) {
+	env := newClusterTestEnv(t)
+	defer env.tearDown()
+	r := env.Reconciler
+	mockClient := env.MockClient
+	ctx := env.ctx
+
+	// reconcile failed, stalled
+	mc := env.Inst
+	mc.Generation = 2
+	mc.Status.ObservedGeneration = 1
+	mockClient.EXPECT().Status().Return(mockClient)
+	mockClient.EXPECT().Update(gomock.Any(), gomock.Any())
+	err := r.UpdateStatus(ctx, &mc, nil, errors.New("test"))
 	assert.NoError(t, err)
+	assert.Equal(t, int64(1), mc.Status.ObservedGeneration)
+	assert.Equal(t, corev1.ConditionTrue, GetCondition(mc.Status.Conditions, v1alpha1.Stalled).Status)
+	assert.Equal(t, corev1.ConditionFalse, GetCondition(mc.Status.Conditions, v1alpha1.Ready).Status)
 
-	// dep ready
-	mockGroup := NewMockGroupRunner(env.Ctrl)
-	defaultGroupRunner = mockGroup
-
-	m.Status.Conditions = []v1alpha1.MilvusCondition{
-		{
-			Type:   v1alpha1.EtcdReady,
-			Status: corev1.ConditionTrue,
-		},
-		{
-			Type:   v1alpha1.PulsarReady,
-			Status: corev1.ConditionTrue,
-		},
-		{
-			Type:   v1alpha1.StorageReady,
-			Status: corev1.ConditionTrue,
-		},
+	// the status of the milvus copied, generation observed once the milvus observed its own
+	mil := &v1alpha1.Milvus{}
+	mil.Generation = 3
+	mil.Status.ObservedGeneration = 3
+	mil.Status.Status = v1alpha1.StatusHealthy
+	mil.Status.Endpoint = "mc-milvus.ns:19530"
+	mil.Status.Conditions = []v1alpha1.MilvusCondition{
+		{Type: v1alpha1.MilvusReady, Status: corev1.ConditionTrue},
+		{Type: v1alpha1.Stalled, Status: corev1.ConditionFalse},
+		{Type: v1alpha1.Ready, Status: corev1.ConditionTrue},
 	}
+	mockClient.EXPECT().Status().Return(mockClient)
+	mockClient.EXPECT().Update(gomock.Any(), gomock.Any())
+	err = r.UpdateStatus(ctx, &mc, mil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), mc.Status.ObservedGeneration)
+	assert.Equal(t, v1alpha1.StatusHealthy, mc.Status.Status)
+	assert.Equal(t, mil.Status.Endpoint, mc.Status.Endpoint)
+	assert.Equal(t, corev1.ConditionFalse, GetCondition(mc.Status.Conditions, v1alpha1.Stalled).Status)
+	assert.Equal(t, corev1.ConditionTrue, GetCondition(mc.Status.Conditions, v1alpha1.Ready).Status)
+
+	// not changed, not updated
+	err = r.UpdateStatus(ctx, &mc, mil, nil)
+	assert.NoError(t, err)
+
+	// the milvus not observed yet
+	mc.Generation = 3
+	mil.Generation = 4
+	mockClient.EXPECT().Status().Return(mockClient)
+	mockClient.EXPECT().Update(gomock.Any(), gomock.Any())
+	err = r.UpdateStatus(ctx, &mc, mil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), mc.Status.ObservedGeneration)
+	assert.Equal(t, corev1.ConditionFalse, GetCondition(mc.Status.Conditions, v1alpha1.Ready).Status)
 
-	gomock.InOrder(
-		mockClient.EXPECT().
-			Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&corev1.ConfigMap{})).
-			Return(k8sErrors.NewNotFound(schema.GroupResource{}, "")),
-		// get secret of minio
-		mockClient.EXPECT().
-			Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&corev1.Secret{})).
-			Return(k8sErrors.NewNotFound(schema.GroupResource{}, "mockErr")),
-		mockClient.EXPECT().
-			Create(gomock.Any(), gomock.Any()).Return(nil),
-		mockGroup.EXPECT().Run(gomock.Len(3), gomock.Any(), m),
-	)
-
-	err = r.ReconcileMilvus(ctx, m)
+	// the milvus observed its generation, but the workloads are not rolled out yet
+	mil.Status.ObservedGeneration = 4
+	UpdateCondition(&mil.Status, GetRolloutNotCheckedCondition(4))
+	mockClient.EXPECT().Status().Return(mockClient)
+	mockClient.EXPECT().Update(gomock.Any(), gomock.Any())
+	err = r.UpdateStatus(ctx, &mc, mil, nil)
 	assert.NoError(t, err)
+	assert.Equal(t, int64(3), mc.Status.ObservedGeneration)
+	assert.Equal(t, corev1.ConditionFalse, GetCondition(mc.Status.Conditions, v1alpha1.Ready).Status)
+	assert.Equal(t, corev1.ConditionTrue, GetCondition(mc.Status.Conditions, v1alpha1.Reconciling).Status)
+
+	// update failed
+	mockClient.EXPECT().Status().Return(mockClient)
+	mockClient.EXPECT().Update(gomock.Any(), gomock.Any()).Return(errors.New("test"))
+	err = r.UpdateStatus(ctx, &mc, nil, errors.New("test"))
+	assert.Error(t, err)
 }
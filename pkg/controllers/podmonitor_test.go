@@ -10,95 +10,11 @@ import (
 	"github.com/milvus-io/milvus-operator/pkg/util"
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	"github.com/stretchr/testify/assert"
-	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
 	meta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-func TestClusterReconciler_ReconcilePodMonitor_NoPodmonitorProvider(t *testing.T) {
-	config.Init(util.GetGitRepoRootDir())
-
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
-	r := newClusterReconcilerForTest(ctrl)
-	mockClient := r.Client.(*MockK8sClient)
-	ctx := context.Background()
-
-	// case create
-	m := v1alpha1.MilvusCluster{
-		ObjectMeta: metav1.ObjectMeta{
-			Namespace: "ns",
-			Name:      "mc",
-		},
-	}
-	m.Default()
-
-	mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).
-		Return(&meta.NoKindMatchError{}).Times(1)
-
-	err := r.ReconcilePodMonitor(ctx, m)
-	assert.NoError(t, err)
-}
-
-func TestClusterReconciler_ReconcilePodMonitor_CreateIfNotExist(t *testing.T) {
-	config.Init(util.GetGitRepoRootDir())
-
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
-	r := newClusterReconcilerForTest(ctrl)
-	mockClient := r.Client.(*MockK8sClient)
-	ctx := context.Background()
-
-	// case create
-	m := v1alpha1.MilvusCluster{
-		ObjectMeta: metav1.ObjectMeta{
-			Namespace: "ns",
-			Name:      "mc",
-		},
-	}
-	m.Default()
-
-	mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).
-		Return(k8sErrors.NewNotFound(schema.GroupResource{}, "mockErr")).Times(1)
-
-	mockClient.EXPECT().Create(gomock.Any(), gomock.Any()).Times(1)
-
-	err := r.ReconcilePodMonitor(ctx, m)
-	assert.NoError(t, err)
-}
-
-func TestClusterReconciler_ReconcilePodMonitor_UpdateIfExisted(t *testing.T) {
-	config.Init(util.GetGitRepoRootDir())
-
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
-	r := newClusterReconcilerForTest(ctrl)
-	mockClient := r.Client.(*MockK8sClient)
-	ctx := context.Background()
-
-	m := v1alpha1.MilvusCluster{
-		ObjectMeta: metav1.ObjectMeta{
-			Namespace: "ns",
-			Name:      "mc",
-		},
-	}
-	m.Default()
-
-	mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).
-		Do(func(ctx, key, obj interface{}) error {
-			s := obj.(*monitoringv1.PodMonitor)
-			s.Namespace = "ns"
-			s.Name = "cm1"
-			return nil
-		}).Times(1)
-
-	mockClient.EXPECT().Update(gomock.Any(), gomock.Any()).Times(1)
-
-	err := r.ReconcilePodMonitor(ctx, m)
-	assert.NoError(t, err)
-}
-
 func TestMilvusReconciler_ReconcilePodMonitor_NoPodmonitorProvider(t *testing.T) {
 	config.Init(util.GetGitRepoRootDir())
 
@@ -116,14 +32,15 @@ func TestMilvusReconciler_ReconcilePodMonitor_NoPodmonitorProvider(t *testing.T)
 	}
 	m.Default()
 
-	mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).
+	mockClient.EXPECT().
+		Patch(gomock.Any(), gomock.AssignableToTypeOf(&monitoringv1.PodMonitor{}), client.Apply, gomock.Any(), gomock.Any()).
 		Return(&meta.NoKindMatchError{}).Times(1)
 
 	err := r.ReconcilePodMonitor(ctx, m)
 	assert.NoError(t, err)
 }
 
-func TestMilvusReconciler_ReconcilePodMonitor_CreateIfNotExist(t *testing.T) {
+func TestMilvusReconciler_ReconcilePodMonitor(t *testing.T) {
 	config.Init(util.GetGitRepoRootDir())
 
 	ctrl := gomock.NewController(t)
@@ -140,41 +57,9 @@ func TestMilvusReconciler_ReconcilePodMonitor_CreateIfNotExist(t *testing.T) {
 	}
 	m.Default()
 
-	mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).
-		Return(k8sErrors.NewNotFound(schema.GroupResource{}, "mockErr")).Times(1)
-
-	mockClient.EXPECT().Create(gomock.Any(), gomock.Any()).Times(1)
-
-	err := r.ReconcilePodMonitor(ctx, m)
-	assert.NoError(t, err)
-}
-
-func TestMilvusReconciler_ReconcilePodMonitor_UpdateIfExisted(t *testing.T) {
-	config.Init(util.GetGitRepoRootDir())
-
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
-	r := newMilvusReconcilerForTest(ctrl)
-	mockClient := r.Client.(*MockK8sClient)
-	ctx := context.Background()
-
-	m := v1alpha1.Milvus{
-		ObjectMeta: metav1.ObjectMeta{
-			Namespace: "ns",
-			Name:      "mc",
-		},
-	}
-	m.Default()
-
-	mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).
-		Do(func(ctx, key, obj interface{}) error {
-			s := obj.(*monitoringv1.PodMonitor)
-			s.Namespace = "ns"
-			s.Name = "cm1"
-			return nil
-		}).Times(1)
-
-	mockClient.EXPECT().Update(gomock.Any(), gomock.Any()).Times(1)
+	mockClient.EXPECT().
+		Patch(gomock.Any(), gomock.AssignableToTypeOf(&monitoringv1.PodMonitor{}), client.Apply, gomock.Any(), gomock.Any()).
+		Return(nil).Times(1)
 
 	err := r.ReconcilePodMonitor(ctx, m)
 	assert.NoError(t, err)
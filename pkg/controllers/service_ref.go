@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/milvus-io/milvus-operator/apis/milvus.io/v1alpha1"
+	"github.com/milvus-io/milvus-operator/pkg/config"
+)
+
+// ServiceHost returns the DNS name of the service, qualified by the cluster domain if it's configured
+func ServiceHost(name, namespace string) string {
+	if domain := config.GetClusterDomain(); domain != "" {
+		return fmt.Sprintf("%s.%s.svc.%s", name, namespace, domain)
+	}
+	return fmt.Sprintf("%s.%s", name, namespace)
+}
+
+// GetServicePort returns the port of the service by its name, the first port if the name is empty
+func GetServicePort(svc corev1.Service, name string) (int32, error) {
+	if len(svc.Spec.Ports) == 0 {
+		return 0, errors.Errorf("service[%s/%s] has no port", svc.Namespace, svc.Name)
+	}
+	if name == "" {
+		return svc.Spec.Ports[0].Port, nil
+	}
+	for _, port := range svc.Spec.Ports {
+		if port.Name == name {
+			return port.Port, nil
+		}
+	}
+	return 0, errors.Errorf("service[%s/%s] has no port named %s", svc.Namespace, svc.Name, name)
+}
+
+// ResolveServiceRef resolves the referred service to its endpoint <host>:<port>,
+// the service is in the namespace of the instance if the ref doesn't set it
+func ResolveServiceRef(ctx context.Context, cli client.Reader, namespace string, ref v1alpha1.ServiceRef) (string, error) {
+	if ref.Namespace != "" {
+		namespace = ref.Namespace
+	}
+	svc := &corev1.Service{}
+	if err := cli.Get(ctx, NamespacedName(namespace, ref.Name), svc); err != nil {
+		return "", errors.Wrapf(err, "get service[%s/%s]", namespace, ref.Name)
+	}
+	port, err := GetServicePort(*svc, ref.Port)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s:%d", ServiceHost(svc.Name, svc.Namespace), port), nil
+}
+
+// ResolveDependencyServiceRefs sets the endpoints of the external dependencies referring to services,
+// the pulsar is skipped if it's nil
+func ResolveDependencyServiceRefs(ctx context.Context, cli client.Reader, namespace string,
+	etcd *v1alpha1.MilvusEtcd, storage *v1alpha1.MilvusStorage, pulsar *v1alpha1.MilvusPulsar) error {
+	if etcd.External && etcd.ServiceRef != nil {
+		endpoint, err := ResolveServiceRef(ctx, cli, namespace, *etcd.ServiceRef)
+		if err != nil {
+			return errors.Wrap(err, "resolve etcd serviceRef")
+		}
+		etcd.Endpoints = []string{endpoint}
+	}
+	if storage.External && storage.ServiceRef != nil {
+		endpoint, err := ResolveServiceRef(ctx, cli, namespace, *storage.ServiceRef)
+		if err != nil {
+			return errors.Wrap(err, "resolve storage serviceRef")
+		}
+		storage.Endpoint = endpoint
+	}
+	if pulsar != nil && pulsar.External && pulsar.ServiceRef != nil {
+		endpoint, err := ResolveServiceRef(ctx, cli, namespace, *pulsar.ServiceRef)
+		if err != nil {
+			return errors.Wrap(err, "resolve pulsar serviceRef")
+		}
+		pulsar.Endpoint = endpoint
+	}
+	return nil
+}
+
+// IsServiceReferred returns whether the service is referred by any of the refs of the instance in the namespace
+func IsServiceReferred(svc client.Object, namespace string, refs ...*v1alpha1.ServiceRef) bool {
+	for _, ref := range refs {
+		if ref == nil || ref.Name != svc.GetName() {
+			continue
+		}
+		refNamespace := ref.Namespace
+		if refNamespace == "" {
+			refNamespace = namespace
+		}
+		if refNamespace == svc.GetNamespace() {
+			return true
+		}
+	}
+	return false
+}
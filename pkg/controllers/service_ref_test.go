@@ -0,0 +1,130 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/milvus-io/milvus-operator/apis/milvus.io/v1alpha1"
+	"github.com/milvus-io/milvus-operator/pkg/config"
+)
+
+func newServiceForTest(namespace, name string, ports ...corev1.ServicePort) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Spec:       corev1.ServiceSpec{Ports: ports},
+	}
+}
+
+func TestServiceHost(t *testing.T) {
+	defer config.SetClusterDomain("")
+
+	assert.Equal(t, "svc.ns", ServiceHost("svc", "ns"))
+
+	config.SetClusterDomain("cluster.local")
+	assert.Equal(t, "svc.ns.svc.cluster.local", ServiceHost("svc", "ns"))
+}
+
+func TestGetServicePort(t *testing.T) {
+	svc := newServiceForTest("ns", "svc",
+		corev1.ServicePort{Name: "peer", Port: 2380},
+		corev1.ServicePort{Name: "client", Port: 2379})
+
+	port, err := GetServicePort(*svc, "")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2380), port)
+
+	port, err = GetServicePort(*svc, "client")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2379), port)
+
+	_, err = GetServicePort(*svc, "metrics")
+	assert.Error(t, err)
+
+	_, err = GetServicePort(*newServiceForTest("ns", "svc"), "")
+	assert.Error(t, err)
+}
+
+func TestResolveDependencyServiceRefs(t *testing.T) {
+	ctx := context.Background()
+	_, cli := newAdoptionTestEnv(t,
+		newServiceForTest("ns", "etcd", corev1.ServicePort{Name: "client", Port: 2379}),
+		newServiceForTest("storage", "minio", corev1.ServicePort{Name: "http", Port: 9000}),
+		newServiceForTest("ns", "pulsar", corev1.ServicePort{Name: "pulsar", Port: 6650}))
+
+	etcd := v1alpha1.MilvusEtcd{External: true, ServiceRef: &v1alpha1.ServiceRef{Name: "etcd", Port: "client"}}
+	storage := v1alpha1.MilvusStorage{External: true, ServiceRef: &v1alpha1.ServiceRef{Name: "minio", Namespace: "storage"}}
+	pulsar := v1alpha1.MilvusPulsar{External: true, ServiceRef: &v1alpha1.ServiceRef{Name: "pulsar"}}
+	err := ResolveDependencyServiceRefs(ctx, cli, "ns", &etcd, &storage, &pulsar)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"etcd.ns:2379"}, etcd.Endpoints)
+	assert.Equal(t, "minio.storage:9000", storage.Endpoint)
+	assert.Equal(t, "pulsar.ns:6650", pulsar.Endpoint)
+
+	// the in-cluster and the endpoint ones are kept, pulsar skipped
+	etcd = v1alpha1.MilvusEtcd{Endpoints: []string{"mc-etcd.ns:2379"}, ServiceRef: &v1alpha1.ServiceRef{Name: "etcd"}}
+	storage = v1alpha1.MilvusStorage{External: true, Endpoint: "s3.amazonaws.com:443"}
+	err = ResolveDependencyServiceRefs(ctx, cli, "ns", &etcd, &storage, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"mc-etcd.ns:2379"}, etcd.Endpoints)
+	assert.Equal(t, "s3.amazonaws.com:443", storage.Endpoint)
+
+	// service not found
+	storage = v1alpha1.MilvusStorage{External: true, ServiceRef: &v1alpha1.ServiceRef{Name: "minio"}}
+	err = ResolveDependencyServiceRefs(ctx, cli, "ns", &etcd, &storage, nil)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "storage serviceRef")
+
+	// port not found
+	pulsar = v1alpha1.MilvusPulsar{External: true, ServiceRef: &v1alpha1.ServiceRef{Name: "pulsar", Port: "http"}}
+	err = ResolveDependencyServiceRefs(ctx, cli, "ns", &etcd, &v1alpha1.MilvusStorage{}, &pulsar)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "pulsar serviceRef")
+}
+
+func TestMilvusReconciler_SetDefault_ServiceRef(t *testing.T) {
+	defer config.SetClusterDomain("")
+	config.SetClusterDomain("cluster.local")
+
+	ctx := context.Background()
+	r, _ := newAdoptionTestEnv(t, newServiceForTest("deps", "etcd", corev1.ServicePort{Name: "client", Port: 2379}))
+	mil := &v1alpha1.Milvus{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "mil"}}
+	mil.Spec.Dep.Etcd.External = true
+	mil.Spec.Dep.Etcd.ServiceRef = &v1alpha1.ServiceRef{Name: "etcd", Namespace: "deps"}
+	err := r.SetDefault(ctx, mil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"etcd.deps.svc.cluster.local:2379"}, mil.Spec.Dep.Etcd.Endpoints)
+	assert.Equal(t, "mil-minio.ns.svc.cluster.local:9000", mil.Spec.Dep.Storage.Endpoint)
+	assert.Empty(t, mil.Spec.Dep.Pulsar.Endpoint)
+
+	// service not found
+	mil.Spec.Dep.Etcd.ServiceRef.Namespace = ""
+	err = r.SetDefault(ctx, mil)
+	assert.Error(t, err)
+}
+
+func TestIsServiceReferred(t *testing.T) {
+	svc := newServiceForTest("deps", "etcd")
+	assert.False(t, IsServiceReferred(svc, "deps"))
+	assert.False(t, IsServiceReferred(svc, "deps", nil, &v1alpha1.ServiceRef{Name: "minio"}))
+	assert.False(t, IsServiceReferred(svc, "ns", &v1alpha1.ServiceRef{Name: "etcd"}))
+	assert.True(t, IsServiceReferred(svc, "deps", nil, &v1alpha1.ServiceRef{Name: "etcd"}))
+	assert.True(t, IsServiceReferred(svc, "ns", &v1alpha1.ServiceRef{Name: "etcd", Namespace: "deps"}))
+}
+
+func TestMilvusReconciler_ServiceToMilvus(t *testing.T) {
+	referring := &v1alpha1.Milvus{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "referring"}}
+	referring.Spec.Dep.Storage.External = true
+	referring.Spec.Dep.Storage.ServiceRef = &v1alpha1.ServiceRef{Name: "minio", Namespace: "deps"}
+	other := &v1alpha1.Milvus{ObjectMeta: metav1.ObjectMeta{Namespace: "deps", Name: "other"}}
+	r, _ := newAdoptionTestEnv(t, referring, other)
+
+	reqs := r.serviceToMilvus(newServiceForTest("deps", "minio"))
+	assert.Len(t, reqs, 1)
+	assert.Equal(t, NamespacedName("ns", "referring"), reqs[0].NamespacedName)
+
+	assert.Empty(t, r.serviceToMilvus(newServiceForTest("deps", "etcd")))
+}
@@ -10,69 +10,43 @@ import (
 	"github.com/milvus-io/milvus-operator/pkg/util"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
-	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-func TestClusterReconciler_ReconcileServices_CreateIfNotExist(t *testing.T) {
+func TestMilvusReconciler_ClusterMode_ReconcileServices(t *testing.T) {
 	config.Init(util.GetGitRepoRootDir())
 
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
-	r := newClusterReconcilerForTest(ctrl)
-	mockClient := r.Client.(*MockK8sClient)
-	ctx := context.Background()
-
-	m := v1alpha1.MilvusCluster{
-		ObjectMeta: metav1.ObjectMeta{
-			Namespace: "ns",
-			Name:      "mc",
-		},
-	}
-	m.Default()
-
-	mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).
-		Return(k8sErrors.NewNotFound(schema.GroupResource{}, "mockErr")).Times(5)
-
-	mockClient.EXPECT().Create(gomock.Any(), gomock.Any()).Times(5)
-
-	err := r.ReconcileServices(ctx, m)
-	assert.NoError(t, err)
-}
-
-func TestClusterReconciler_ReconcileServices_UpdateIfExisted(t *testing.T) {
-	config.Init(util.GetGitRepoRootDir())
-
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
-	r := newClusterReconcilerForTest(ctrl)
+	r := newMilvusReconcilerForTest(ctrl)
 	mockClient := r.Client.(*MockK8sClient)
 	ctx := context.Background()
 
-	m := v1alpha1.MilvusCluster{
+	m := v1alpha1.Milvus{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: "ns",
 			Name:      "mc",
 		},
 	}
+	m.Spec.Mode = v1alpha1.MilvusModeCluster
 	m.Default()
 
-	mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).
-		Do(func(ctx, key, obj interface{}) error {
+	// only the coords and the proxy have services
+	mockClient.EXPECT().
+		Patch(gomock.Any(), gomock.AssignableToTypeOf(&corev1.Service{}), client.Apply, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
 			s := obj.(*corev1.Service)
-			s.Namespace = "ns"
-			s.Name = "cm1"
+			assert.Equal(t, "Service", s.Kind)
+			assert.Empty(t, s.Spec.ClusterIP)
 			return nil
 		}).Times(5)
 
-	mockClient.EXPECT().Update(gomock.Any(), gomock.Any()).Times(5)
-
 	err := r.ReconcileServices(ctx, m)
 	assert.NoError(t, err)
 }
 
-func TestMilvusReconciler_ReconcileServices_CreateIfNotExist(t *testing.T) {
+func TestMilvusReconciler_ReconcileServices(t *testing.T) {
 	config.Init(util.GetGitRepoRootDir())
 
 	ctrl := gomock.NewController(t)
@@ -89,42 +63,15 @@ func TestMilvusReconciler_ReconcileServices_CreateIfNotExist(t *testing.T) {
 	}
 	m.Default()
 
-	mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).
-		Return(k8sErrors.NewNotFound(schema.GroupResource{}, "mockErr")).Times(1)
-
-	mockClient.EXPECT().Create(gomock.Any(), gomock.Any()).Times(1)
-
-	err := r.ReconcileServices(ctx, m)
-	assert.NoError(t, err)
-}
-
-func TestMilvusReconciler_ReconcileServices_UpdateIfExisted(t *testing.T) {
-	config.Init(util.GetGitRepoRootDir())
-
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
-	r := newMilvusReconcilerForTest(ctrl)
-	mockClient := r.Client.(*MockK8sClient)
-	ctx := context.Background()
-
-	m := v1alpha1.Milvus{
-		ObjectMeta: metav1.ObjectMeta{
-			Namespace: "ns",
-			Name:      "mc",
-		},
-	}
-	m.Default()
-
-	mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).
-		Do(func(ctx, key, obj interface{}) error {
+	mockClient.EXPECT().
+		Patch(gomock.Any(), gomock.AssignableToTypeOf(&corev1.Service{}), client.Apply, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
 			s := obj.(*corev1.Service)
-			s.Namespace = "ns"
-			s.Name = "cm1"
+			assert.Equal(t, m.Name, s.Name)
+			assert.Len(t, s.Spec.Ports, 2)
 			return nil
 		}).Times(1)
 
-	mockClient.EXPECT().Update(gomock.Any(), gomock.Any()).Times(1)
-
 	err := r.ReconcileServices(ctx, m)
 	assert.NoError(t, err)
 }
@@ -2,15 +2,23 @@ package controllers
 
 import (
 	"context"
+	"time"
 
 	milvusv1alpha1 "github.com/milvus-io/milvus-operator/apis/milvus.io/v1alpha1"
+	"github.com/milvus-io/milvus-operator/pkg/helm"
+	"golang.org/x/time/rate"
 	"helm.sh/helm/v3/pkg/cli"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/util/workqueue"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/ratelimiter"
 )
 
-func SetupControllers(ctx context.Context, mgr manager.Manager, enableHook bool) error {
+// SetupControllers sets up the controllers and the webhooks with the manager,
+// the options are used by the controllers of Milvus, MilvusCluster and MilvusDependencyPool
+func SetupControllers(ctx context.Context, mgr manager.Manager, enableHook bool, options controller.Options) error {
 	logger := ctrl.Log.WithName("controller")
 
 	conf := mgr.GetConfig()
@@ -22,39 +30,47 @@ func SetupControllers(ctx context.Context, mgr manager.Manager, enableHook bool)
 	config := getter.(*genericclioptions.ConfigFlags)
 	insecure := true
 	config.Insecure = &insecure
+	helm.SetDefaultChartCache(helm.NewChartCache(settings, helm.DefaultChartCacheDir))
 	helmReconciler := NewLocalHelmReconciler(settings, logger.WithName("helm"))
 
-	// should be run after mgr started to make sure the client is ready
-	clusterStatusSyncer := NewMilvusClusterStatusSyncer(ctx, mgr.GetClient(), logger.WithName("status-syncer"))
-
 	clusterController := &MilvusClusterReconciler{
-		Client:         mgr.GetClient(),
-		Scheme:         mgr.GetScheme(),
-		logger:         logger.WithName("milvus-cluster"),
-		helmReconciler: helmReconciler,
-		statusSyncer:   clusterStatusSyncer,
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+		logger: logger.WithName("milvus-cluster"),
 	}
 
-	if err := clusterController.SetupWithManager(mgr); err != nil {
+	if err := clusterController.SetupWithManager(mgr, options); err != nil {
 		logger.Error(err, "unable to setup milvus cluster controller with manager", "controller", "MilvusCluster")
 		return err
 	}
 
 	// should be run after mgr started to make sure the client is ready
-	statusSyncer := NewMilvusStatusSyncer(ctx, mgr.GetClient(), logger.WithName("status-syncer"))
+	statusSyncer := NewMilvusStatusSyncer(ctx, mgr.GetClient(), logger.WithName("status-syncer"), helmReconciler)
 
-	controller := &MilvusReconciler{
+	milvusController := &MilvusReconciler{
 		Client:         mgr.GetClient(),
 		Scheme:         mgr.GetScheme(),
 		logger:         logger.WithName("milvus"),
 		helmReconciler: helmReconciler,
 		statusSyncer:   statusSyncer,
 	}
-	if err := controller.SetupWithManager(mgr); err != nil {
+	if err := milvusController.SetupWithManager(mgr, options); err != nil {
 		logger.Error(err, "unable to setup milvus controller with manager", "controller", "MilvusCluster")
 		return err
 	}
 
+	poolController := &MilvusDependencyPoolReconciler{
+		Client:         mgr.GetClient(),
+		Scheme:         mgr.GetScheme(),
+		apiReader:      mgr.GetAPIReader(),
+		logger:         logger.WithName("milvus-dependency-pool"),
+		helmReconciler: helmReconciler,
+	}
+	if err := poolController.SetupWithManager(mgr, options); err != nil {
+		logger.Error(err, "unable to setup milvus dependency pool controller with manager", "controller", "MilvusDependencyPool")
+		return err
+	}
+
 	if enableHook {
 		if err := (&milvusv1alpha1.MilvusCluster{}).SetupWebhookWithManager(mgr); err != nil {
 			logger.Error(err, "unable to create webhook", "webhook", "MilvusCluster")
@@ -64,7 +80,25 @@ func SetupControllers(ctx context.Context, mgr manager.Manager, enableHook bool)
 			logger.Error(err, "unable to create webhook", "webhook", "Milvus")
 			return err
 		}
+		if err := (&milvusv1alpha1.MilvusDependencyPool{}).SetupWebhookWithManager(mgr); err != nil {
+			logger.Error(err, "unable to create webhook", "webhook", "MilvusDependencyPool")
+			return err
+		}
+	} else {
+		// the CRDs point their conversion to the /convert of this operator's webhook service
+		logger.Info("webhooks disabled, the v1beta1 of Milvus and MilvusCluster can't be served " +
+			"unless the webhook service is backed by another operator with the webhooks enabled")
 	}
 
 	return nil
 }
+
+// NewRateLimiter creates the rate limiter of the reconcile requests like the default one of controller-runtime,
+// the failed ones are retried with exponential backoff from baseDelay to maxDelay,
+// and all the requests are limited by qps and burst
+func NewRateLimiter(baseDelay, maxDelay time.Duration, qps float64, burst int) ratelimiter.RateLimiter {
+	return workqueue.NewMaxOfRateLimiter(
+		workqueue.NewItemExponentialFailureRateLimiter(baseDelay, maxDelay),
+		&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(qps), burst)},
+	)
+}
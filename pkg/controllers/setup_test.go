@@ -0,0 +1,20 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRateLimiter(t *testing.T) {
+	limiter := NewRateLimiter(time.Second, 3*time.Second, 100, 10)
+	assert.Equal(t, time.Second, limiter.When("a"))
+	assert.Equal(t, 2*time.Second, limiter.When("a"))
+	assert.Equal(t, 3*time.Second, limiter.When("a"))
+	assert.Equal(t, 3*time.Second, limiter.When("a"))
+	assert.Equal(t, 4, limiter.NumRequeues("a"))
+
+	limiter.Forget("a")
+	assert.Equal(t, time.Second, limiter.When("a"))
+}
@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/milvus-io/milvus-operator/apis/milvus.io/v1alpha1"
+)
+
+const (
+	// CacheVolumeName is the name of the cache volume of the pods of a statefulset,
+	// and the prefix of the names of the claims created for them
+	CacheVolumeName = "cache"
+	// CacheVolumeDefaultMountPath is the default localStorage.path of milvus
+	CacheVolumeDefaultMountPath = "/var/lib/milvus/data"
+)
+
+// GetCacheClaimName returns the name of the claim of the cache volume of the pod of @ordinal in the statefulset
+func GetCacheClaimName(statefulSet string, ordinal int) string {
+	return fmt.Sprintf("%s-%s-%d", CacheVolumeName, statefulSet, ordinal)
+}
+
+// parseCacheClaimName returns the statefulset and the ordinal of the pod of the claim of a cache volume
+func parseCacheClaimName(claim string) (string, int, bool) {
+	if !strings.HasPrefix(claim, CacheVolumeName+"-") {
+		return "", 0, false
+	}
+	name := strings.TrimPrefix(claim, CacheVolumeName+"-")
+	idx := strings.LastIndex(name, "-")
+	if idx < 0 {
+		return "", 0, false
+	}
+	ordinal, err := strconv.Atoi(name[idx+1:])
+	if err != nil {
+		return "", 0, false
+	}
+	return name[:idx], ordinal, true
+}
+
+func (r *MilvusReconciler) updateStatefulSet(
+	mil v1alpha1.Milvus, statefulSet *appsv1.StatefulSet, component MilvusComponent, secretCheckSum string,
+) error {
+	appLabels := GetComponentAppLabels(mil.Name, component)
+
+	statefulSet.Labels = MergeLabels(statefulSet.Labels, appLabels)
+	if err := ctrl.SetControllerReference(&mil, statefulSet, r.Scheme); err != nil {
+		return err
+	}
+
+	statefulSet.Spec.Replicas = component.GetReplicas(mil.Spec)
+	statefulSet.Spec.ServiceName = component.GetServiceInstanceName(mil.Name)
+	// the nodes don't depend on each other, no need to start them one by one
+	statefulSet.Spec.PodManagementPolicy = appsv1.ParallelPodManagement
+	statefulSet.Spec.UpdateStrategy = appsv1.StatefulSetUpdateStrategy{
+		Type: appsv1.RollingUpdateStatefulSetStrategyType,
+	}
+	statefulSet.Spec.Selector = &metav1.LabelSelector{
+		MatchLabels: MergeLabels(appLabels),
+	}
+	statefulSet.Spec.Template.Labels = MergeLabels(appLabels)
+	if err := updatePodTemplate(mil, &statefulSet.Spec.Template, component, secretCheckSum); err != nil {
+		return err
+	}
+
+	spec := component.GetStatefulSetSpec(mil.Spec)
+	mountPath := spec.MountPath
+	if mountPath == "" {
+		mountPath = CacheVolumeDefaultMountPath
+	}
+	container := &statefulSet.Spec.Template.Spec.Containers[0]
+	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+		Name:      CacheVolumeName,
+		MountPath: mountPath,
+	})
+
+	if spec.Ephemeral {
+		statefulSet.Spec.VolumeClaimTemplates = nil
+		statefulSet.Spec.Template.Spec.Volumes = append(statefulSet.Spec.Template.Spec.Volumes, corev1.Volume{
+			Name: CacheVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				Ephemeral: &corev1.EphemeralVolumeSource{
+					VolumeClaimTemplate: &corev1.PersistentVolumeClaimTemplate{
+						ObjectMeta: metav1.ObjectMeta{Labels: appLabels},
+						Spec:       spec.VolumeClaim,
+					},
+				},
+			},
+		})
+		return nil
+	}
+
+	statefulSet.Spec.VolumeClaimTemplates = []corev1.PersistentVolumeClaim{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   CacheVolumeName,
+				Labels: appLabels,
+			},
+			Spec: spec.VolumeClaim,
+		},
+	}
+	return nil
+}
+
+// ReconcileComponentStatefulSet applies the statefulset of the component running in statefulset mode
+func (r *MilvusReconciler) ReconcileComponentStatefulSet(
+	ctx context.Context, mil v1alpha1.Milvus, component MilvusComponent,
+) error {
+	secretCheckSum, err := GetStorageSecretCheckSum(ctx, r.Client, mil.Namespace, mil.Spec.Dep.Storage.SecretRef)
+	if err != nil {
+		return err
+	}
+
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      component.GetDeploymentInstanceName(mil.Name),
+			Namespace: mil.Namespace,
+		},
+	}
+	if err := r.updateStatefulSet(mil, statefulSet, component, secretCheckSum); err != nil {
+		return err
+	}
+	existing := &appsv1.StatefulSet{}
+	err = r.Get(ctx, client.ObjectKeyFromObject(statefulSet), existing)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	if err == nil {
+		if err := omitScaledReplicas(ctx, r.Client, "StatefulSet", existing, &statefulSet.Spec.Replicas); err != nil {
+			return fmt.Errorf("omit scaled replicas: %w", err)
+		}
+	}
+
+	return ApplyObject(ctx, r.Client, r.Scheme, statefulSet)
+}
+
+// DeleteStaleCacheClaims deletes the claims of the cache volumes of the pods removed by scaling down the statefulsets,
+// or by switching back to deployments. @statefulSets are the replicas of the statefulsets expected
+func (r *MilvusReconciler) DeleteStaleCacheClaims(ctx context.Context, mil v1alpha1.Milvus, statefulSets map[string]int32) error {
+	claims := &corev1.PersistentVolumeClaimList{}
+	if err := r.List(ctx, claims, client.InNamespace(mil.Namespace),
+		client.MatchingLabels(NewAppLabels(mil.Name))); err != nil {
+		return fmt.Errorf("list cache claims: %w", err)
+	}
+
+	for i := range claims.Items {
+		claim := &claims.Items[i]
+		statefulSet, ordinal, ok := parseCacheClaimName(claim.Name)
+		if !ok || !strings.HasPrefix(statefulSet, mil.Name+"-milvus-") {
+			continue
+		}
+		if replicas, expected := statefulSets[statefulSet]; expected && int32(ordinal) < replicas {
+			continue
+		}
+		r.logger.Info("delete stale cache claim", "name", claim.Name, "namespace", claim.Namespace)
+		if err := r.Delete(ctx, claim); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("delete cache claim %s: %w", claim.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/milvus-io/milvus-operator/apis/milvus.io/v1alpha1"
+)
+
+func TestParseCacheClaimName(t *testing.T) {
+	statefulSet, ordinal, ok := parseCacheClaimName(GetCacheClaimName("mc-milvus-querynode-hot", 12))
+	assert.True(t, ok)
+	assert.Equal(t, "mc-milvus-querynode-hot", statefulSet)
+	assert.Equal(t, 12, ordinal)
+
+	_, _, ok = parseCacheClaimName("data-mc-milvus-querynode-0")
+	assert.False(t, ok)
+	_, _, ok = parseCacheClaimName("cache-mc-milvus-querynode")
+	assert.False(t, ok)
+}
+
+func TestMilvusReconciler_ClusterMode_updateStatefulSet(t *testing.T) {
+	env := newClusterModeTestEnv(t)
+	defer env.tearDown()
+	r := env.Reconciler
+	mc := env.Inst
+	volumeClaim := corev1.PersistentVolumeClaimSpec{
+		AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("100Gi")},
+		},
+	}
+	mc.Spec.Com.QueryNode.StatefulSet = &v1alpha1.StatefulSetSpec{VolumeClaim: volumeClaim}
+	replicas := int32(3)
+	mc.Spec.Com.QueryNode.Replicas = &replicas
+	assert.Nil(t, DataNode.GetStatefulSetSpec(mc.Spec))
+	assert.Nil(t, MilvusStandalone.GetStatefulSetSpec(mc.Spec))
+
+	statefulSet := &appsv1.StatefulSet{}
+	statefulSet.Namespace = "ns"
+	err := r.updateStatefulSet(mc, statefulSet, QueryNode, "sum")
+	assert.NoError(t, err)
+	assert.Equal(t, replicas, *statefulSet.Spec.Replicas)
+	assert.Equal(t, appsv1.ParallelPodManagement, statefulSet.Spec.PodManagementPolicy)
+	assert.Equal(t, QueryNodeName, statefulSet.Spec.Selector.MatchLabels[AppLabelComponent])
+	assert.Equal(t, statefulSet.Spec.Selector.MatchLabels, statefulSet.Spec.Template.Labels)
+	assert.Equal(t, "sum", statefulSet.Spec.Template.Annotations[AnnotationSecretCheckSum])
+	assert.Len(t, statefulSet.Spec.VolumeClaimTemplates, 1)
+	assert.Equal(t, CacheVolumeName, statefulSet.Spec.VolumeClaimTemplates[0].Name)
+	assert.Equal(t, volumeClaim, statefulSet.Spec.VolumeClaimTemplates[0].Spec)
+	container := statefulSet.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, []string{"milvus", "run", QueryNodeName}, container.Args)
+	assert.Contains(t, container.VolumeMounts, corev1.VolumeMount{Name: CacheVolumeName, MountPath: CacheVolumeDefaultMountPath})
+
+	// ephemeral volume of the group
+	mc.Spec.Com.QueryNode.StatefulSet.Ephemeral = true
+	mc.Spec.Com.QueryNode.StatefulSet.MountPath = "/cache"
+	mc.Spec.Com.QueryNode.Groups = []v1alpha1.ComponentGroup{{Name: "hot"}}
+	err = r.updateStatefulSet(mc, statefulSet, QueryNode.GetGroups(mc.Spec)[0], "")
+	assert.NoError(t, err)
+	assert.Equal(t, "hot", statefulSet.Spec.Selector.MatchLabels[AppLabelResourceGroup])
+	assert.Empty(t, statefulSet.Spec.VolumeClaimTemplates)
+	volumes := statefulSet.Spec.Template.Spec.Volumes
+	assert.Equal(t, CacheVolumeName, volumes[len(volumes)-1].Name)
+	assert.Equal(t, volumeClaim, volumes[len(volumes)-1].Ephemeral.VolumeClaimTemplate.Spec)
+	container = statefulSet.Spec.Template.Spec.Containers[0]
+	assert.Contains(t, container.VolumeMounts, corev1.VolumeMount{Name: CacheVolumeName, MountPath: "/cache"})
+}
+
+func TestMilvusReconciler_ClusterMode_ReconcileComponentDeployment_StatefulSet(t *testing.T) {
+	env := newClusterModeTestEnv(t)
+	defer env.tearDown()
+	r := env.Reconciler
+	mockClient := env.MockClient
+	ctx := env.ctx
+	mc := env.Inst
+	mc.Spec.Com.DataNode.StatefulSet = &v1alpha1.StatefulSetSpec{}
+
+	mockClient.EXPECT().
+		Get(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&appsv1.StatefulSet{})).
+		Return(k8sErrors.NewNotFound(schema.GroupResource{}, ""))
+	mockClient.EXPECT().
+		Patch(gomock.Any(), gomock.AssignableToTypeOf(&appsv1.StatefulSet{}), client.Apply, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+			assert.Equal(t, "mc-milvus-datanode", obj.GetName())
+			return nil
+		})
+	err := r.ReconcileComponentDeployment(ctx, mc, DataNode)
+	assert.NoError(t, err)
+}
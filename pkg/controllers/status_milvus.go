@@ -15,19 +15,42 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+const (
+	MessageEtcdReady       = "Etcd endpoints is healthy"
+	MessageEtcdNotReady    = "All etcd endpoints are unhealthy"
+	MessageStorageReady    = "Storage endpoints is healthy"
+	MessageStorageNotReady = "All Storage endpoints are unhealthy"
+	MessagePulsarReady     = "Pulsar is ready"
+	MessagePulsarNotReady  = "Pulsar is not ready"
+	MessageSecretNotExist  = "Secret not exist"
+	MessageKeyNotExist     = "accesskey or secretkey not exist in secret"
+	MessageDecodeErr       = "accesskey or secretkey decode error"
+	MessageMilvusHealthy   = "All Milvus components are healthy"
+	MessageMilvusUnchecked = "Milvus components are not checked yet"
+	MessageReconciled      = "The latest spec is reconciled"
+)
+
+type EtcdEndPointHealth struct {
+	Ep     string `json:"endpoint"`
+	Health bool   `json:"health"`
+	Error  string `json:"error,omitempty"`
+}
+
 type MilvusStatusSyncer struct {
 	ctx context.Context
 	client.Client
-	logger logr.Logger
+	logger         logr.Logger
+	helmReconciler HelmReconciler
 
 	sync.Once
 }
 
-func NewMilvusStatusSyncer(ctx context.Context, client client.Client, logger logr.Logger) *MilvusStatusSyncer {
+func NewMilvusStatusSyncer(ctx context.Context, client client.Client, logger logr.Logger, helmReconciler HelmReconciler) *MilvusStatusSyncer {
 	return &MilvusStatusSyncer{
-		ctx:    ctx,
-		Client: client,
-		logger: logger,
+		ctx:            ctx,
+		Client:         client,
+		logger:         logger,
+		helmReconciler: helmReconciler,
 	}
 }
 
@@ -88,10 +111,18 @@ func (r *MilvusStatusSyncer) UpdateStatus(ctx context.Context, mil *v1alpha1.Mil
 		return nil
 	}
 
+	// the status of a deleting instance is set by the finalizer
+	if !mil.DeletionTimestamp.IsZero() {
+		return nil
+	}
+
 	funcs := []Func{
 		r.GetEtcdCondition,
 		r.GetMinioCondition,
 	}
+	if mil.Spec.IsCluster() {
+		funcs = append(funcs, r.GetPulsarCondition)
+	}
 	ress := defaultGroupRunner.RunWithResult(funcs, ctx, *mil)
 
 	errTexts := []string{}
@@ -107,11 +138,18 @@ func (r *MilvusStatusSyncer) UpdateStatus(ctx context.Context, mil *v1alpha1.Mil
 		return fmt.Errorf("update status error: %s", strings.Join(errTexts, ":"))
 	}
 
+	for _, cond := range r.GetReleaseConditions(*mil) {
+		UpdateCondition(&mil.Status, cond)
+	}
+
 	milvusCond, err := r.GetMilvusCondition(ctx, *mil)
 	if err != nil {
 		return err
 	}
 	UpdateCondition(&mil.Status, milvusCond)
+	for _, cond := range GetKStatusConditions(mil.Status.Conditions, mil.Generation, mil.Status.ObservedGeneration) {
+		UpdateCondition(&mil.Status, cond)
+	}
 
 	if milvusCond.Status != corev1.ConditionTrue {
 		mil.Status.Status = v1alpha1.StatusUnHealthy
@@ -120,15 +158,23 @@ func (r *MilvusStatusSyncer) UpdateStatus(ctx context.Context, mil *v1alpha1.Mil
 	}
 
 	mil.Status.Endpoint = r.GetMilvusEndpoint(ctx, *mil)
+	activeCoords, err := GetActiveCoordinators(ctx, r.Client, *mil)
+	if err != nil {
+		// keep the last known ones, the sessions may be unavailable for a while during the failover
+		r.logger.Error(err, "get active coordinators failed", "name", mil.Name, "namespace", mil.Namespace)
+	} else {
+		mil.Status.ActiveCoordinators = activeCoords
+	}
 	return r.Status().Update(ctx, mil)
 }
 
 func (r *MilvusStatusSyncer) GetMilvusEndpoint(ctx context.Context, mil v1alpha1.Milvus) string {
+	component := GetServingComponent(mil.Spec)
 	info := MilvusEndpointInfo{
 		Namespace:   mil.Namespace,
-		Name:        mil.Name,
+		ServiceName: component.GetServiceInstanceName(mil.Name),
 		ServiceType: mil.Spec.ServiceType,
-		Port:        MilvusPort, // TODO: @shaoyue: port should be configurable
+		Port:        component.GetComponentPort(mil.Spec),
 	}
 	return GetMilvusEndpoint(ctx, r.logger, r.Client, info)
 }
@@ -137,22 +183,67 @@ func (r *MilvusStatusSyncer) GetMilvusCondition(ctx context.Context, mil v1alpha
 	info := MilvusConditionInfo{
 		Object:     mil.GetObjectMeta(),
 		Conditions: mil.Status.Conditions,
-		IsCluster:  false,
+		IsCluster:  mil.Spec.IsCluster(),
 	}
 	return GetMilvusInstanceCondition(ctx, r.Client, info)
 }
 
+func (r *MilvusStatusSyncer) GetPulsarCondition(
+	ctx context.Context, mil v1alpha1.Milvus) (v1alpha1.MilvusCondition, error) {
+	return GetPulsarCondition(ctx, r.logger, mil.Spec.Dep.Pulsar)
+}
+
 func (r *MilvusStatusSyncer) GetMinioCondition(
 	ctx context.Context, mil v1alpha1.Milvus) (v1alpha1.MilvusCondition, error) {
+	endpoint, useSSL, err := GetStorageEndpoint(mil.Spec.Dep.Storage, mil.Spec.Conf.Data)
+	if err != nil {
+		return newErrStorageCondResult(v1alpha1.ReasonClientErr, err.Error()), nil
+	}
 	info := StorageConditionInfo{
 		Namespace: mil.Namespace,
 		Storage:   mil.Spec.Dep.Storage,
-		EndPoint:  mil.Spec.Dep.Storage.Endpoint,
-		UseSSL:    GetMinioSecure(mil.Spec.Conf.Data),
+		EndPoint:  endpoint.HostPort(),
+		UseSSL:    useSSL,
 	}
 	return GetMinioCondition(ctx, r.logger, r.Client, info)
 }
 
 func (r *MilvusStatusSyncer) GetEtcdCondition(ctx context.Context, mil v1alpha1.Milvus) (v1alpha1.MilvusCondition, error) {
-	return GetEtcdCondition(ctx, mil.Spec.Dep.Etcd.Endpoints)
+	endpoints, err := GetEtcdEndpoints(mil.Spec.Dep.Etcd)
+	if err != nil {
+		return v1alpha1.MilvusCondition{
+			Type:    v1alpha1.EtcdReady,
+			Status:  GetConditionStatus(false),
+			Reason:  v1alpha1.ReasonEtcdNotReady,
+			Message: err.Error(),
+		}, nil
+	}
+	return GetEtcdCondition(ctx, endpoints)
+}
+
+// GetReleaseConditions returns the conditions of the helm releases of in-cluster dependencies,
+// the releases of the pool are checked for the dependencies served by it
+func (r *MilvusStatusSyncer) GetReleaseConditions(mil v1alpha1.Milvus) []v1alpha1.MilvusCondition {
+	namespace := mil.Namespace
+	releaseName := func(chart string) string {
+		return mil.Name + "-" + chart
+	}
+	if pool := mil.Spec.Dep.Pool; pool != nil {
+		namespace = pool.GetNamespace(mil.Namespace)
+		releaseName = func(chart string) string {
+			return DependencyPoolReleaseName(pool.Name, chart)
+		}
+	}
+
+	ret := []v1alpha1.MilvusCondition{}
+	if !mil.Spec.Dep.Etcd.External {
+		ret = append(ret, r.helmReconciler.GetReleaseCondition(namespace, releaseName(EtcdChart), v1alpha1.EtcdReleaseReady))
+	}
+	if !mil.Spec.Dep.Storage.External {
+		ret = append(ret, r.helmReconciler.GetReleaseCondition(namespace, releaseName(MinioChart), v1alpha1.StorageReleaseReady))
+	}
+	if mil.Spec.IsCluster() && !mil.Spec.Dep.Pulsar.External {
+		ret = append(ret, r.helmReconciler.GetReleaseCondition(namespace, releaseName(PulsarChart), v1alpha1.PulsarReleaseReady))
+	}
+	return ret
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/milvus-io/milvus-operator/apis/milvus.io/v1alpha1"
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
@@ -19,7 +20,7 @@ func TestStatusSyncer_syncUnhealthy(t *testing.T) {
 	mockCli := NewMockK8sClient(ctrl)
 	ctx := context.Background()
 	logger := logf.Log.WithName("test")
-	s := NewMilvusStatusSyncer(ctx, mockCli, logger)
+	s := NewMilvusStatusSyncer(ctx, mockCli, logger, NewMockHelmReconciler(ctrl))
 
 	mockRunner := NewMockGroupRunner(ctrl)
 	defaultGroupRunner = mockRunner
@@ -63,7 +64,7 @@ func TestStatusSyncer_syncHealthy(t *testing.T) {
 	mockCli := NewMockK8sClient(ctrl)
 	ctx := context.Background()
 	logger := logf.Log.WithName("test")
-	s := NewMilvusStatusSyncer(ctx, mockCli, logger)
+	s := NewMilvusStatusSyncer(ctx, mockCli, logger, NewMockHelmReconciler(ctrl))
 
 	mockRunner := NewMockGroupRunner(ctrl)
 	defaultGroupRunner = mockRunner
@@ -107,8 +108,9 @@ func TestStatusSyncer_UpdateStatus(t *testing.T) {
 	mockCli := NewMockK8sClient(ctrl)
 	ctx := context.Background()
 	logger := logf.Log.WithName("test")
+	mockHelm := NewMockHelmReconciler(ctrl)
 	m := &v1alpha1.Milvus{}
-	s := NewMilvusStatusSyncer(ctx, mockCli, logger)
+	s := NewMilvusStatusSyncer(ctx, mockCli, logger, mockHelm)
 
 	// default status not set
 	err := s.UpdateStatus(ctx, m)
@@ -132,9 +134,19 @@ func TestStatusSyncer_UpdateStatus(t *testing.T) {
 		Return([]Result{
 			{Data: v1alpha1.MilvusCondition{}},
 		})
+	mockHelm.EXPECT().GetReleaseCondition(m.Namespace, m.Name+"-etcd", v1alpha1.EtcdReleaseReady).
+		Return(v1alpha1.MilvusCondition{Type: v1alpha1.EtcdReleaseReady, Status: corev1.ConditionTrue})
+	mockHelm.EXPECT().GetReleaseCondition(m.Namespace, m.Name+"-minio", v1alpha1.StorageReleaseReady).
+		Return(v1alpha1.MilvusCondition{Type: v1alpha1.StorageReleaseReady, Status: corev1.ConditionFalse})
 	mockCli.EXPECT().Status().Return(mockCli)
 	mockCli.EXPECT().Update(gomock.Any(), gomock.Any())
 	m.Status.Status = v1alpha1.StatusCreating
 	err = s.UpdateStatus(ctx, m)
 	assert.NoError(t, err)
+	releaseConds := map[v1alpha1.MiluvsConditionType]corev1.ConditionStatus{}
+	for _, cond := range m.Status.Conditions {
+		releaseConds[cond.Type] = cond.Status
+	}
+	assert.Equal(t, corev1.ConditionTrue, releaseConds[v1alpha1.EtcdReleaseReady])
+	assert.Equal(t, corev1.ConditionFalse, releaseConds[v1alpha1.StorageReleaseReady])
 }
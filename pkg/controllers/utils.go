@@ -26,123 +26,10 @@ const (
 	AppLabelVersion   = AppLabel + "version"
 	AppLabelComponent = AppLabel + "component"
 	AppLabelName      = AppLabel + "name"
-)
-
-func getServicePortIdx(srcPorts []corev1.ServicePort, dst corev1.ServicePort) int {
-	for i, src := range srcPorts {
-		if src.Name == dst.Name {
-			return i
-		}
-
-		// Check for duplicate Ports, considering (protocol,port) pairs
-		srcKey := corev1.ServicePort{Protocol: src.Protocol, Port: src.Port}
-		dstKey := corev1.ServicePort{Protocol: dst.Protocol, Port: dst.Port}
-		if srcKey == dstKey {
-			return i
-		}
-
-		// Check for duplicate NodePorts, considering (protocol,port) pairs
-		if dst.NodePort != 0 {
-			srcKey = corev1.ServicePort{Protocol: src.Protocol, NodePort: src.NodePort}
-			dstKey = corev1.ServicePort{Protocol: dst.Protocol, NodePort: dst.NodePort}
-			if srcKey == dstKey {
-				return i
-			}
-		}
-	}
-
-	return -1
-}
-
-func MergeServicePort(src, dst []corev1.ServicePort) []corev1.ServicePort {
-	if len(src) == 0 {
-		return dst
-	}
-
-	if len(dst) == 0 {
-		return src
-	}
-
-	merged := src
-	for _, dstPort := range dst {
-		idx := getServicePortIdx(src, dstPort)
-		if idx < 0 {
-			merged = append(merged, dstPort)
-			continue
-		}
-
-		merged[idx].Name = dstPort.Name
-		merged[idx].Protocol = dstPort.Protocol
-		merged[idx].Port = dstPort.Port
-		merged[idx].TargetPort = dstPort.TargetPort
-		if dstPort.NodePort != 0 {
-			merged[idx].NodePort = dstPort.NodePort
-		}
-
-		if merged[idx].Name == "" {
-			merged[idx].Name = strings.ToLower(fmt.Sprintf("%s-%d", merged[idx].Protocol, merged[idx].Port))
-		}
-	}
-
-	return merged
-}
-
-func MergeVolumeMount(src, dst []corev1.VolumeMount) []corev1.VolumeMount {
-	if len(src) == 0 {
-		return dst
-	}
-	if len(dst) == 0 {
-		return src
-	}
-
-	srcMap := map[string]int{}
-	for i, VolumeMount := range src {
-		srcMap[VolumeMount.MountPath] = i
-	}
-
-	merged := []corev1.VolumeMount{}
-	merged = append(merged, src...)
-	for _, VolumeMount := range dst {
-		if idx, ok := srcMap[VolumeMount.MountPath]; ok {
-			merged[idx] = VolumeMount
-		} else {
-			merged = append(merged, VolumeMount)
-		}
-	}
-
-	return merged
-}
-
-func MergeContainerPort(src, dst []corev1.ContainerPort) []corev1.ContainerPort {
-	if len(src) == 0 {
-		return dst
-	}
-	if len(dst) == 0 {
-		return src
-	}
-
-	srcMap := map[string]int{}
-	for i, port := range src {
-		if port.Name != "" {
-			srcMap[port.Name] = i
-		}
-	}
-
-	merged := []corev1.ContainerPort{}
-	merged = append(merged, src...)
-	for _, port := range dst {
-		if port.Name != "" {
-			if idx, ok := srcMap[port.Name]; ok {
-				merged[idx] = port
-				continue
-			}
-		}
-
-		merged = append(merged, port)
-	}
 
-	return merged
-}
+	// AppLabelResourceGroup labels the pods of a component group with the milvus resource group they serve
+	AppLabelResourceGroup = "milvus.io/resource-group"
+)
 
 // Merge dst env into src
 func MergeEnvVar(src, dst []corev1.EnvVar) []corev1.EnvVar {
@@ -171,36 +58,6 @@ func MergeEnvVar(src, dst []corev1.EnvVar) []corev1.EnvVar {
 	return merged
 }
 
-// GetContainerIndex returns index of container @name in @containers, -1 if not found
-func GetContainerIndex(containers []corev1.Container, name string) int {
-	for i, c := range containers {
-		if c.Name == name {
-			return i
-		}
-	}
-	return -1
-}
-
-// GetVolumeIndex returns index of volume @name in @volumes, -1 if not found
-func GetVolumeIndex(volumes []corev1.Volume, name string) int {
-	for i, v := range volumes {
-		if v.Name == name {
-			return i
-		}
-	}
-	return -1
-}
-
-// GetVolumeMountIndex returns index of volumeMount with @mountPath in @volumeMounts, -1 if not found
-func GetVolumeMountIndex(volumeMounts []corev1.VolumeMount, mountPath string) int {
-	for i, vm := range volumeMounts {
-		if vm.MountPath == mountPath {
-			return i
-		}
-	}
-	return -1
-}
-
 func NewComponentAppLabels(instance, component string) map[string]string {
 	return map[string]string{
 		AppLabelInstance:  instance,
@@ -236,6 +93,20 @@ func IsEqual(obj1, obj2 interface{}) bool {
 
 // DeploymentReady returns if deployment is available &
 func DeploymentReady(deployment appsv1.Deployment) bool {
+	// the status is of the previous spec until the deployment controller observes the latest one
+	if deployment.Status.ObservedGeneration < deployment.Generation {
+		return false
+	}
+	// all the pods are of the latest pod template and available, none of the old ones is left
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	status := deployment.Status
+	if status.UpdatedReplicas < replicas || status.AvailableReplicas < replicas || status.Replicas > status.UpdatedReplicas {
+		return false
+	}
+
 	ready := true
 	errored := false
 	inProgress := false
@@ -260,6 +131,19 @@ func DeploymentReady(deployment appsv1.Deployment) bool {
 	return ready && !inProgress && !errored
 }
 
+// StatefulSetReady returns if all the pods of the statefulset are updated and ready
+func StatefulSetReady(statefulSet appsv1.StatefulSet) bool {
+	if statefulSet.Status.ObservedGeneration < statefulSet.Generation {
+		return false
+	}
+	replicas := int32(1)
+	if statefulSet.Spec.Replicas != nil {
+		replicas = *statefulSet.Spec.Replicas
+	}
+	status := statefulSet.Status
+	return status.Replicas == replicas && status.ReadyReplicas == replicas && status.UpdatedReplicas == replicas
+}
+
 // PodRunningAndReady returns whether a pod is running and each container has
 // passed it's ready state.
 func PodRunningAndReady(pod corev1.Pod) (bool, error) {
@@ -285,10 +169,6 @@ func GetConditionStatus(b bool) corev1.ConditionStatus {
 	return corev1.ConditionFalse
 }
 
-func IsClusterDependencyReady(status v1alpha1.MilvusClusterStatus) bool {
-	return IsDependencyReady(status.Conditions, true)
-}
-
 func IsDependencyReady(conditions []v1alpha1.MilvusCondition, isCluster bool) bool {
 	ready := 0
 	for _, c := range conditions {
@@ -310,34 +190,32 @@ func IsDependencyReady(conditions []v1alpha1.MilvusCondition, isCluster bool) bo
 	return ready == 2
 }
 
-func UpdateClusterCondition(status *v1alpha1.MilvusClusterStatus, c v1alpha1.MilvusCondition) {
-	for i := range status.Conditions {
-		cp := &status.Conditions[i]
-		if cp.Type == c.Type {
-			if cp.Status != c.Status ||
-				cp.Reason != c.Reason ||
-				cp.Message != c.Message {
-				// Override
-				cp.Status = c.Status
-				cp.Message = c.Message
-				cp.Reason = c.Reason
-				// Update timestamp
-				now := metav1.Now()
-				cp.LastTransitionTime = &now
-			}
-			return
+// GetCondition returns the condition of the type, nil if not found
+func GetCondition(conditions []v1alpha1.MilvusCondition, conditionType v1alpha1.MiluvsConditionType) *v1alpha1.MilvusCondition {
+	for i := range conditions {
+		if conditions[i].Type == conditionType {
+			return &conditions[i]
 		}
 	}
+	return nil
+}
 
-	// Append if not existing yet
-	now := metav1.Now()
-	c.LastTransitionTime = &now
-	status.Conditions = append(status.Conditions, c)
+func UpdateClusterCondition(status *v1alpha1.MilvusClusterStatus, c v1alpha1.MilvusCondition) {
+	updateCondition(&status.Conditions, c)
 }
 
 func UpdateCondition(status *v1alpha1.MilvusStatus, c v1alpha1.MilvusCondition) {
-	for i := range status.Conditions {
-		cp := &status.Conditions[i]
+	updateCondition(&status.Conditions, c)
+}
+
+func UpdateDependencyPoolCondition(status *v1alpha1.MilvusDependencyPoolStatus, c v1alpha1.MilvusCondition) {
+	updateCondition(&status.Conditions, c)
+}
+
+// updateCondition overrides the condition of the same type, the transition time is updated only if it changes
+func updateCondition(conditions *[]v1alpha1.MilvusCondition, c v1alpha1.MilvusCondition) {
+	for i := range *conditions {
+		cp := &(*conditions)[i]
 		if cp.Type == c.Type {
 			if cp.Status != c.Status ||
 				cp.Reason != c.Reason ||
@@ -357,7 +235,7 @@ func UpdateCondition(status *v1alpha1.MilvusStatus, c v1alpha1.MilvusCondition)
 	// Append if not existing yet
 	now := metav1.Now()
 	c.LastTransitionTime = &now
-	status.Conditions = append(status.Conditions, c)
+	*conditions = append(*conditions, c)
 }
 
 func NamespacedName(namespace, name string) types.NamespacedName {
@@ -377,6 +255,32 @@ func GetMinioSecure(conf map[string]interface{}) bool {
 	return false
 }
 
+// GetEtcdEndpoints returns the etcd endpoints for the clients, with the default port and the scheme if it has one
+func GetEtcdEndpoints(etcd v1alpha1.MilvusEtcd) ([]string, error) {
+	endpoints, err := etcd.GetEndpoints()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]string, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		ret = append(ret, endpoint.String())
+	}
+	return ret, nil
+}
+
+// GetStorageEndpoint returns the endpoint of the storage and whether it's connected with TLS,
+// which is decided by the scheme of the endpoint if it has one, otherwise by minio.useSSL of the config
+func GetStorageEndpoint(storage v1alpha1.MilvusStorage, conf map[string]interface{}) (util.Endpoint, bool, error) {
+	endpoint, err := storage.GetEndpoint()
+	if err != nil {
+		return endpoint, false, err
+	}
+	if endpoint.Scheme != "" {
+		return endpoint, endpoint.IsSecure(), nil
+	}
+	return endpoint, GetMinioSecure(conf), nil
+}
+
 func diffObject(old, new client.Object) ([]byte, error) {
 	return client.MergeFrom(old).Data(new)
 }
@@ -20,108 +20,6 @@ func TestNamespacedName(t *testing.T) {
 	assert.Equal(t, "test", key.Name)
 }
 
-func TestMergeServicePort(t *testing.T) {
-	// empty src
-	src := []corev1.ServicePort{}
-	dst := []corev1.ServicePort{{}}
-	ret := MergeServicePort(src, dst)
-	assert.Equal(t, dst, ret)
-
-	// empty dst
-	src = []corev1.ServicePort{{}}
-	dst = []corev1.ServicePort{}
-	ret = MergeServicePort(src, dst)
-	assert.Equal(t, src, ret)
-
-	// merge same
-	src = []corev1.ServicePort{{}}
-	dst = []corev1.ServicePort{{}}
-	ret = MergeServicePort(src, dst)
-	assert.Len(t, ret, 1)
-
-	// merge same
-	src = []corev1.ServicePort{{}}
-	dst = []corev1.ServicePort{{}}
-	ret = MergeServicePort(src, dst)
-	assert.Len(t, ret, 1)
-
-	// merge same port
-	src = []corev1.ServicePort{{Name: "a", Port: 8080}}
-	dst = []corev1.ServicePort{{Name: "b", Port: 8080}}
-	ret = MergeServicePort(src, dst)
-	assert.Len(t, ret, 1)
-
-	// merge different
-	src = []corev1.ServicePort{{Name: "a", Port: 8081}}
-	dst = []corev1.ServicePort{{Name: "b", Port: 8080}}
-	ret = MergeServicePort(src, dst)
-	assert.Len(t, ret, 2)
-
-	// merge same nodePort
-	src = []corev1.ServicePort{{Name: "a", NodePort: 3000, Port: 8081}}
-	dst = []corev1.ServicePort{{Name: "b", NodePort: 3000, Port: 8080}}
-	ret = MergeServicePort(src, dst)
-	assert.Len(t, ret, 1)
-
-	// merge different nodePort
-	src = []corev1.ServicePort{{Name: "a", NodePort: 3000, Port: 8081}}
-	dst = []corev1.ServicePort{{Name: "b", NodePort: 3001, Port: 8080}}
-	ret = MergeServicePort(src, dst)
-	assert.Len(t, ret, 2)
-}
-
-func TestMergeVolumeMount(t *testing.T) {
-	// empty src
-	src := []corev1.VolumeMount{}
-	dst := []corev1.VolumeMount{{}}
-	ret := MergeVolumeMount(src, dst)
-	assert.Equal(t, dst, ret)
-
-	// empty dst
-	src = []corev1.VolumeMount{{}}
-	dst = []corev1.VolumeMount{}
-	ret = MergeVolumeMount(src, dst)
-	assert.Equal(t, src, ret)
-
-	// merge same
-	dst = []corev1.VolumeMount{{}}
-	src = []corev1.VolumeMount{{}}
-	ret = MergeVolumeMount(src, dst)
-	assert.Len(t, ret, 1)
-
-	// merge different
-	dst = []corev1.VolumeMount{{MountPath: "/p1"}}
-	src = []corev1.VolumeMount{{MountPath: "/p2"}}
-	ret = MergeVolumeMount(src, dst)
-	assert.Len(t, ret, 2)
-}
-
-func TestMergeContainerPort(t *testing.T) {
-	// empty src
-	src := []corev1.ContainerPort{}
-	dst := []corev1.ContainerPort{{}}
-	ret := MergeContainerPort(src, dst)
-	assert.Equal(t, dst, ret)
-
-	// empty dst
-	src = []corev1.ContainerPort{{}}
-	dst = []corev1.ContainerPort{}
-	ret = MergeContainerPort(src, dst)
-	assert.Equal(t, src, ret)
-
-	// merge same
-	dst = []corev1.ContainerPort{{Name: "a"}}
-	src = []corev1.ContainerPort{{Name: "a"}}
-	ret = MergeContainerPort(src, dst)
-	assert.Len(t, ret, 1)
-
-	// merge different
-	dst = []corev1.ContainerPort{{Name: "a"}}
-	src = []corev1.ContainerPort{{Name: "b"}}
-	ret = MergeContainerPort(src, dst)
-	assert.Len(t, ret, 2)
-}
-
 func TestMergeEnvVar(t *testing.T) {
 	// empty src
 	src := []corev1.EnvVar{}
@@ -148,39 +46,6 @@ func TestMergeEnvVar(t *testing.T) {
 	assert.Len(t, ret, 2)
 }
 
-func TestGetContainerIndex(t *testing.T) {
-	containers := []corev1.Container{
-		{Name: "a"},
-		{Name: "b"},
-	}
-	// found
-	assert.Equal(t, 0, GetContainerIndex(containers, "a"))
-	// not found
-	assert.Equal(t, -1, GetContainerIndex(containers, "z"))
-}
-
-func TestGetVolumeIndex(t *testing.T) {
-	volumes := []corev1.Volume{
-		{Name: "a"},
-		{Name: "b"},
-	}
-	// found
-	assert.Equal(t, 0, GetVolumeIndex(volumes, "a"))
-	// not found
-	assert.Equal(t, -1, GetVolumeIndex(volumes, "z"))
-}
-
-func TestGetVolumeMountIndex(t *testing.T) {
-	volumeMounts := []corev1.VolumeMount{
-		{MountPath: "a"},
-		{MountPath: "b"},
-	}
-	// found
-	assert.Equal(t, 0, GetVolumeMountIndex(volumeMounts, "a"))
-	// not found
-	assert.Equal(t, -1, GetVolumeMountIndex(volumeMounts, "z"))
-}
-
 func TestNewComponentAppLabels(t *testing.T) {
 	labels := NewComponentAppLabels("a", "b")
 	assert.Equal(t, "a", labels[AppLabelInstance])
@@ -208,6 +73,9 @@ func TestDeploymentReady(t *testing.T) {
 	// Ready
 	deployment := appsv1.Deployment{
 		Status: appsv1.DeploymentStatus{
+			Replicas:          1,
+			UpdatedReplicas:   1,
+			AvailableReplicas: 1,
 			Conditions: []appsv1.DeploymentCondition{
 				{
 					Type:   appsv1.DeploymentAvailable,
@@ -247,6 +115,9 @@ func TestDeploymentReady(t *testing.T) {
 	// Has Progressing With NewReplicaSetAvailable Ignored
 	deployment = appsv1.Deployment{
 		Status: appsv1.DeploymentStatus{
+			Replicas:          1,
+			UpdatedReplicas:   1,
+			AvailableReplicas: 1,
 			Conditions: []appsv1.DeploymentCondition{
 				{
 					Type:   appsv1.DeploymentProgressing,
@@ -270,6 +141,61 @@ func TestDeploymentReady(t *testing.T) {
 		},
 	}
 	assert.False(t, DeploymentReady(deployment))
+
+	// latest spec not observed yet
+	deployment = appsv1.Deployment{
+		Status: appsv1.DeploymentStatus{
+			ObservedGeneration: 1,
+			Conditions: []appsv1.DeploymentCondition{
+				{
+					Type:   appsv1.DeploymentAvailable,
+					Status: corev1.ConditionTrue,
+				},
+			},
+		},
+	}
+	deployment.Generation = 2
+	assert.False(t, DeploymentReady(deployment))
+
+	// the pods of the old pod template left
+	deployment.Spec.Replicas = int32Ptr(2)
+	deployment.Status = appsv1.DeploymentStatus{
+		ObservedGeneration: 2,
+		Replicas:           3,
+		UpdatedReplicas:    2,
+		AvailableReplicas:  3,
+		Conditions: []appsv1.DeploymentCondition{
+			{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue},
+		},
+	}
+	assert.False(t, DeploymentReady(deployment))
+
+	// updated but not available
+	deployment.Status.Replicas = 2
+	deployment.Status.AvailableReplicas = 1
+	assert.False(t, DeploymentReady(deployment))
+
+	deployment.Status.AvailableReplicas = 2
+	assert.True(t, DeploymentReady(deployment))
+}
+
+func TestStatefulSetReady(t *testing.T) {
+	statefulSet := appsv1.StatefulSet{}
+	statefulSet.Spec.Replicas = int32Ptr(2)
+	statefulSet.Status.Replicas = 2
+	statefulSet.Status.UpdatedReplicas = 2
+	statefulSet.Status.ReadyReplicas = 2
+	assert.True(t, StatefulSetReady(statefulSet))
+
+	// rolling
+	statefulSet.Status.UpdatedReplicas = 1
+	assert.False(t, StatefulSetReady(statefulSet))
+
+	// not observed
+	statefulSet.Status.UpdatedReplicas = 2
+	statefulSet.Generation = 2
+	statefulSet.Status.ObservedGeneration = 1
+	assert.False(t, StatefulSetReady(statefulSet))
 }
 
 func TestPodRunningAndReady(t *testing.T) {
@@ -330,9 +256,9 @@ func TestGetConditionStatus(t *testing.T) {
 	assert.Equal(t, corev1.ConditionTrue, GetConditionStatus(true))
 }
 
-func TestIsClusterDependencyReady(t *testing.T) {
+func TestIsDependencyReady_Cluster(t *testing.T) {
 	// 1 not ready -> not ready
-	status := v1alpha1.MilvusClusterStatus{
+	status := v1alpha1.MilvusStatus{
 		Conditions: []v1alpha1.MilvusCondition{
 			{
 				Type:   v1alpha1.EtcdReady,
@@ -348,10 +274,10 @@ func TestIsClusterDependencyReady(t *testing.T) {
 			},
 		},
 	}
-	assert.False(t, IsClusterDependencyReady(status))
+	assert.False(t, IsDependencyReady(status.Conditions, true))
 	// all ready -> ready
 	status.Conditions[2].Status = corev1.ConditionTrue
-	assert.True(t, IsClusterDependencyReady(status))
+	assert.True(t, IsDependencyReady(status.Conditions, true))
 }
 
 func TestIsDependencyReady(t *testing.T) {
@@ -374,6 +300,15 @@ func TestIsDependencyReady(t *testing.T) {
 	assert.True(t, IsDependencyReady(status.Conditions, false))
 }
 
+func TestGetCondition(t *testing.T) {
+	conditions := []v1alpha1.MilvusCondition{
+		{Type: v1alpha1.EtcdReady, Status: corev1.ConditionTrue},
+		{Type: v1alpha1.MilvusReady, Status: corev1.ConditionFalse},
+	}
+	assert.Equal(t, &conditions[1], GetCondition(conditions, v1alpha1.MilvusReady))
+	assert.Nil(t, GetCondition(conditions, v1alpha1.Ready))
+}
+
 func TestUpdateClusterCondition(t *testing.T) {
 	// append if not existed
 	status := v1alpha1.MilvusClusterStatus{
@@ -461,6 +396,43 @@ func TestGetMinioSecure(t *testing.T) {
 	assert.False(t, GetMinioSecure(conf))
 }
 
+func TestGetEtcdEndpoints(t *testing.T) {
+	endpoints, err := GetEtcdEndpoints(v1alpha1.MilvusEtcd{Endpoints: []string{"etcd", "fd00::1", "https://etcd-0:2379"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"etcd:2379", "[fd00::1]:2379", "https://etcd-0:2379"}, endpoints)
+
+	_, err = GetEtcdEndpoints(v1alpha1.MilvusEtcd{Endpoints: []string{"etcd:badPort"}})
+	assert.Error(t, err)
+}
+
+func TestGetStorageEndpoint(t *testing.T) {
+	sslConf := map[string]interface{}{
+		"minio": map[string]interface{}{
+			"useSSL": true,
+		},
+	}
+
+	// by the config without scheme
+	endpoint, useSSL, err := GetStorageEndpoint(v1alpha1.MilvusStorage{Endpoint: "[fd00::1]"}, sslConf)
+	assert.NoError(t, err)
+	assert.Equal(t, "[fd00::1]:9000", endpoint.HostPort())
+	assert.True(t, useSSL)
+
+	// by the scheme
+	endpoint, useSSL, err = GetStorageEndpoint(v1alpha1.MilvusStorage{Endpoint: "http://minio"}, sslConf)
+	assert.NoError(t, err)
+	assert.Equal(t, "minio:80", endpoint.HostPort())
+	assert.False(t, useSSL)
+
+	endpoint, useSSL, err = GetStorageEndpoint(v1alpha1.MilvusStorage{Endpoint: "https://s3.amazonaws.com"}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "s3.amazonaws.com:443", endpoint.HostPort())
+	assert.True(t, useSSL)
+
+	_, _, err = GetStorageEndpoint(v1alpha1.MilvusStorage{Endpoint: "s3://bucket"}, nil)
+	assert.Error(t, err)
+}
+
 func TestDiffObject(t *testing.T) {
 	obj1 := &v1alpha1.MilvusCluster{
 		ObjectMeta: metav1.ObjectMeta{
@@ -0,0 +1,255 @@
+package helm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Masterminds/semver"
+	auth "github.com/deislabs/oras/pkg/auth/docker"
+	"github.com/deislabs/oras/pkg/content"
+	"github.com/deislabs/oras/pkg/oras"
+	"github.com/pkg/errors"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/repo"
+
+	"github.com/milvus-io/milvus-operator/apis/milvus.io/v1alpha1"
+	"github.com/milvus-io/milvus-operator/pkg/config"
+)
+
+const (
+	// ociChartConfigMediaType is the media type of the config of a chart in an OCI registry
+	ociChartConfigMediaType = "application/vnd.cncf.helm.config.v1+json"
+	// ociChartMediaType is the media type of the chart archive layer pushed by helm 3.7 or later
+	ociChartMediaType = "application/vnd.cncf.helm.chart.content.v1.tar+gzip"
+	// ociLegacyChartMediaType is the media type of the chart archive layer pushed by helm before 3.7
+	ociLegacyChartMediaType = "application/tar+gzip"
+
+	// ociPullTimeout is the timeout of pulling a chart from an OCI registry
+	ociPullTimeout = time.Minute
+)
+
+// DefaultChartCacheDir is where the charts downloaded are kept by default
+var DefaultChartCacheDir = filepath.Join(os.TempDir(), "milvus-operator", "charts")
+
+// ChartCache loads the charts of the requests. The remote charts are downloaded into
+// the cache directory, so that each version is downloaded only once. The archives are loaded
+// for every request because helm may modify the chart loaded, like config.LoadChart does
+type ChartCache struct {
+	settings *cli.EnvSettings
+	dir      string
+
+	mu sync.Mutex
+}
+
+func NewChartCache(settings *cli.EnvSettings, dir string) *ChartCache {
+	return &ChartCache{
+		settings: settings,
+		dir:      dir,
+	}
+}
+
+// SetDefaultChartCache sets the default chart cache
+func SetDefaultChartCache(cache *ChartCache) {
+	defaultChartCache = cache
+}
+
+// defaultChartCache for focade LoadChart function
+var defaultChartCache = NewChartCache(cli.New(), DefaultChartCacheDir)
+
+// LoadChart loads the chart of the request
+func LoadChart(request ChartRequest) (*chart.Chart, error) {
+	return defaultChartCache.Load(request)
+}
+
+// Load loads the chart from the source of the request, or the bundled one if no source set
+func (c *ChartCache) Load(request ChartRequest) (*chart.Chart, error) {
+	if request.Source == nil {
+		return config.LoadChart(request.Chart)
+	}
+
+	source := *request.Source
+	if source.Name == "" {
+		source.Name = request.Chart
+	}
+
+	if source.IsOCI() {
+		return c.loadOCI(source, request.RegistryConfigs)
+	}
+	return c.loadRepo(source)
+}
+
+// loadRepo loads the chart from a chart repository, the index is downloaded every time to resolve the version
+func (c *ChartCache) loadRepo(source v1alpha1.ChartSource) (*chart.Chart, error) {
+	getters := getter.All(c.settings)
+	chartRepo, err := repo.NewChartRepository(&repo.Entry{
+		Name: "milvus-operator-" + checksum([]byte(source.Repository))[:8],
+		URL:  source.Repository,
+	}, getters)
+	if err != nil {
+		return nil, err
+	}
+	chartRepo.CachePath = c.dir
+	indexPath, err := chartRepo.DownloadIndexFile()
+	if err != nil {
+		return nil, errors.Wrapf(err, "download index of %s", source.Repository)
+	}
+	index, err := repo.LoadIndexFile(indexPath)
+	if err != nil {
+		return nil, err
+	}
+	chartVersion, err := index.Get(source.Name, source.Version)
+	if err != nil {
+		return nil, errors.Wrapf(err, "find chart %s %s in %s", source.Name, source.Version, source.Repository)
+	}
+	if len(chartVersion.URLs) == 0 {
+		return nil, errors.Errorf("chart %s-%s has no downloadable URLs", source.Name, chartVersion.Version)
+	}
+
+	return c.loadCached(source.Repository, source.Name, chartVersion.Version, func() ([]byte, error) {
+		chartURL, err := repo.ResolveReferenceURL(source.Repository, chartVersion.URLs[0])
+		if err != nil {
+			return nil, err
+		}
+		u, err := url.Parse(chartURL)
+		if err != nil {
+			return nil, err
+		}
+		g, err := getters.ByScheme(u.Scheme)
+		if err != nil {
+			return nil, err
+		}
+		data, err := g.Get(chartURL)
+		if err != nil {
+			return nil, err
+		}
+		return data.Bytes(), nil
+	})
+}
+
+// loadOCI loads the chart tagged with the version from an OCI registry,
+// with the credentials in the docker configs of the registry
+func (c *ChartCache) loadOCI(source v1alpha1.ChartSource, registryConfigs [][]byte) (*chart.Chart, error) {
+	ref := strings.TrimPrefix(source.Repository, v1alpha1.ChartOCIScheme)
+	name := ref[strings.LastIndex(ref, "/")+1:]
+
+	return c.loadCached(source.Repository, name, source.Version, func() ([]byte, error) {
+		return pullOCIChart(ref+":"+source.Version, registryConfigs)
+	})
+}
+
+// loadCached loads the chart from the archive in the cache, it's downloaded if not cached yet
+func (c *ChartCache) loadCached(repository, name, version string, download func() ([]byte, error)) (*chart.Chart, error) {
+	path := filepath.Join(c.dir, fmt.Sprintf("%s-%s-%s.tgz", name, version, checksum([]byte(repository))[:8]))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := os.Stat(path); err != nil {
+		data, err := download()
+		if err != nil {
+			return nil, errors.Wrapf(err, "download chart %s-%s from %s", name, version, repository)
+		}
+		if err := os.MkdirAll(c.dir, 0755); err != nil {
+			return nil, err
+		}
+		if err := ioutil.WriteFile(path, data, 0644); err != nil {
+			return nil, err
+		}
+	}
+
+	ch, err := loader.Load(path)
+	if err != nil {
+		// remove the broken one to download again next time
+		os.Remove(path)
+		return nil, err
+	}
+	return ch, nil
+}
+
+// NeedChartUpdate returns whether the release isn't deployed with the version pinned in the source
+func NeedChartUpdate(rel *release.Release, source *v1alpha1.ChartSource) bool {
+	if source == nil || rel.Chart == nil || rel.Chart.Metadata == nil {
+		return false
+	}
+	pinned, err := semver.NewVersion(source.Version)
+	if err != nil {
+		// a constraint, upgraded with the values
+		return false
+	}
+	deployed, err := semver.NewVersion(rel.Chart.Metadata.Version)
+	if err != nil {
+		return true
+	}
+	return !pinned.Equal(deployed)
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// pullOCIChart pulls the chart archive with oras like helm's registry client, which is internal to helm
+// and doesn't know the media type of the charts pushed by helm 3.7 or later.
+// The credentials are read from the docker configs only, not the ones of the operator's host
+func pullOCIChart(ref string, registryConfigs [][]byte) ([]byte, error) {
+	dir, err := ioutil.TempDir("", "milvus-operator-registry-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	configPaths := []string{}
+	for i, registryConfig := range registryConfigs {
+		path := filepath.Join(dir, fmt.Sprintf("config-%d.json", i))
+		if err := ioutil.WriteFile(path, registryConfig, 0600); err != nil {
+			return nil, err
+		}
+		configPaths = append(configPaths, path)
+	}
+	if len(configPaths) == 0 {
+		// an absent config for anonymous access, instead of the default one
+		configPaths = append(configPaths, filepath.Join(dir, "config.json"))
+	}
+
+	authClient, err := auth.NewClient(configPaths...)
+	if err != nil {
+		return nil, errors.Wrap(err, "load registry configs")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), ociPullTimeout)
+	defer cancel()
+	resolver, err := authClient.Resolver(ctx, http.DefaultClient, false)
+	if err != nil {
+		return nil, err
+	}
+
+	store := content.NewMemoryStore()
+	_, layers, err := oras.Pull(ctx, resolver, ref, store,
+		oras.WithPullEmptyNameAllowed(),
+		oras.WithAllowedMediaTypes([]string{ociChartConfigMediaType, ociChartMediaType, ociLegacyChartMediaType}))
+	if err != nil {
+		return nil, err
+	}
+	for _, layer := range layers {
+		if layer.MediaType != ociChartMediaType && layer.MediaType != ociLegacyChartMediaType {
+			continue
+		}
+		_, data, ok := store.Get(layer)
+		if !ok {
+			return nil, errors.Errorf("chart layer %s not pulled", layer.Digest)
+		}
+		return data, nil
+	}
+	return nil, errors.Errorf("no chart layer in %s", ref)
+}
@@ -0,0 +1,219 @@
+package helm
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/release"
+
+	"github.com/milvus-io/milvus-operator/apis/milvus.io/v1alpha1"
+)
+
+func newTestChartArchive(t *testing.T, name, version string) []byte {
+	dir, err := ioutil.TempDir("", "chart")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	ch := &chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: name, Version: version},
+	}
+	path, err := chartutil.Save(ch, dir)
+	assert.NoError(t, err)
+	data, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	return data
+}
+
+func TestChartCache_Load_Local(t *testing.T) {
+	cache := NewChartCache(cli.New(), t.TempDir())
+	_, err := cache.Load(ChartRequest{Chart: "not-exist"})
+	assert.Error(t, err)
+}
+
+func TestChartCache_Load_Repo(t *testing.T) {
+	archive := newTestChartArchive(t, "etcd", "6.3.4")
+	downloads := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/index.yaml":
+			fmt.Fprint(w, `apiVersion: v1
+entries:
+  etcd:
+  - apiVersion: v2
+    name: etcd
+    version: 6.3.4
+    urls:
+    - charts/etcd-6.3.4.tgz
+  - apiVersion: v2
+    name: etcd
+    version: 6.3.3
+    urls:
+    - charts/etcd-6.3.3.tgz
+`)
+		case "/charts/etcd-6.3.4.tgz":
+			downloads++
+			w.Write(archive)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	cache := NewChartCache(cli.New(), t.TempDir())
+	request := ChartRequest{
+		Chart:  "etcd",
+		Source: &v1alpha1.ChartSource{Repository: server.URL, Version: "~6.3.0"},
+	}
+	ch, err := cache.Load(request)
+	assert.NoError(t, err)
+	assert.Equal(t, "6.3.4", ch.Metadata.Version)
+
+	// cached, loaded again for each request
+	ch2, err := cache.Load(request)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, downloads)
+	assert.Equal(t, ch.Metadata, ch2.Metadata)
+	assert.NotSame(t, ch, ch2)
+
+	// version not found
+	request.Source.Version = "7.0.0"
+	_, err = cache.Load(request)
+	assert.Error(t, err)
+
+	// download failed
+	request.Source.Version = "6.3.3"
+	_, err = cache.Load(request)
+	assert.Error(t, err)
+}
+
+// newTestRegistry serves the chart archive as repository charts/etcd tagged 6.3.3 by plain http like a local registry,
+// with a bearer token for anonymous access.
+// The basic auth of user:pass is required instead if withAuth
+func newTestRegistry(t *testing.T, archive []byte, withAuth bool) *httptest.Server {
+	config := []byte("{}")
+	configDigest := "sha256:" + checksum(config)
+	digest := "sha256:" + checksum(archive)
+	manifest := []byte(fmt.Sprintf(`{"schemaVersion": 2,
+		"config": {"mediaType": "%s", "digest": "%s", "size": %d},
+		"layers": [{"mediaType": "%s", "digest": "%s", "size": %d}]}`,
+		ociChartConfigMediaType, configDigest, len(config), ociChartMediaType, digest, len(archive)))
+	manifestDigest := "sha256:" + checksum(manifest)
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if withAuth {
+			if user, pass, ok := r.BasicAuth(); !ok || user != "user" || pass != "pass" {
+				w.Header().Set("WWW-Authenticate", `Basic realm="test"`)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		} else {
+			if r.URL.Path == "/token" {
+				assert.Equal(t, "repository:charts/etcd:pull", r.URL.Query().Get("scope"))
+				fmt.Fprint(w, `{"token": "abc"}`)
+				return
+			}
+			if r.Header.Get("Authorization") != "Bearer abc" {
+				w.Header().Set("WWW-Authenticate",
+					fmt.Sprintf(`Bearer realm="%s/token",service="test",scope="repository:charts/etcd:pull"`, server.URL))
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+
+		var data []byte
+		switch r.URL.Path {
+		case "/v2/charts/etcd/manifests/6.3.3", "/v2/charts/etcd/manifests/" + manifestDigest:
+			w.Header().Set("Content-Type", "application/vnd.oci.image.manifest.v1+json")
+			w.Header().Set("Docker-Content-Digest", manifestDigest)
+			data = manifest
+		case "/v2/charts/etcd/blobs/" + configDigest:
+			data = config
+		case "/v2/charts/etcd/blobs/" + digest:
+			data = archive
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		if r.Method != http.MethodHead {
+			w.Write(data)
+		}
+	}))
+	return server
+}
+
+func TestChartCache_Load_OCI(t *testing.T) {
+	archive := newTestChartArchive(t, "etcd", "6.3.3")
+	server := newTestRegistry(t, archive, false)
+	defer server.Close()
+
+	dir := t.TempDir()
+	cache := NewChartCache(cli.New(), dir)
+	host := strings.TrimPrefix(server.URL, "http://")
+	request := ChartRequest{
+		Source: &v1alpha1.ChartSource{Repository: v1alpha1.ChartOCIScheme + host + "/charts/etcd", Version: "6.3.3"},
+	}
+	ch, err := cache.Load(request)
+	assert.NoError(t, err)
+	assert.Equal(t, "6.3.3", ch.Metadata.Version)
+	files, _ := filepath.Glob(filepath.Join(dir, "etcd-6.3.3-*.tgz"))
+	assert.Len(t, files, 1)
+
+	// loaded from the cache dir
+	_, err = NewChartCache(cli.New(), dir).Load(request)
+	assert.NoError(t, err)
+
+	// tag not found
+	request.Source.Version = "6.3.4"
+	_, err = cache.Load(request)
+	assert.Error(t, err)
+}
+
+func TestChartCache_Load_OCI_RegistryConfigs(t *testing.T) {
+	archive := newTestChartArchive(t, "etcd", "6.3.3")
+	server := newTestRegistry(t, archive, true)
+	defer server.Close()
+
+	cache := NewChartCache(cli.New(), t.TempDir())
+	host := strings.TrimPrefix(server.URL, "http://")
+	request := ChartRequest{
+		Source: &v1alpha1.ChartSource{Repository: v1alpha1.ChartOCIScheme + host + "/charts/etcd", Version: "6.3.3"},
+	}
+
+	// anonymous
+	_, err := cache.Load(request)
+	assert.Error(t, err)
+
+	// the credentials of the host in one of the configs
+	auth := base64.StdEncoding.EncodeToString([]byte("user:pass"))
+	request.RegistryConfigs = [][]byte{
+		[]byte(`{"auths": {"other.io": {"auth": "` + auth + `"}}}`),
+		[]byte(`{"auths": {"` + host + `": {"auth": "` + auth + `"}}}`),
+	}
+	ch, err := cache.Load(request)
+	assert.NoError(t, err)
+	assert.Equal(t, "6.3.3", ch.Metadata.Version)
+}
+
+func TestNeedChartUpdate(t *testing.T) {
+	rel := &release.Release{
+		Chart: &chart.Chart{Metadata: &chart.Metadata{Name: "etcd", Version: "6.3.3"}},
+	}
+	assert.False(t, NeedChartUpdate(rel, nil))
+	assert.False(t, NeedChartUpdate(rel, &v1alpha1.ChartSource{Version: "6.3.3"}))
+	assert.False(t, NeedChartUpdate(rel, &v1alpha1.ChartSource{Version: "~6.3.0"}))
+	assert.True(t, NeedChartUpdate(rel, &v1alpha1.ChartSource{Version: "6.3.4"}))
+}
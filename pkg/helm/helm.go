@@ -2,18 +2,40 @@ package helm
 
 import (
 	"errors"
+	"fmt"
+	"time"
 
 	"helm.sh/helm/v3/pkg/action"
-	"helm.sh/helm/v3/pkg/chart/loader"
 	"helm.sh/helm/v3/pkg/release"
 	"helm.sh/helm/v3/pkg/storage/driver"
+
+	"github.com/milvus-io/milvus-operator/apis/milvus.io/v1alpha1"
 )
 
+// DefaultTimeout is the timeout of installing, upgrading and rolling back a release by default
+const DefaultTimeout = 10 * time.Minute
+
+// ErrNoDeployedRevision means the release has never been deployed successfully
+var ErrNoDeployedRevision = errors.New("no deployed revision to roll back to")
+
 type ChartRequest struct {
 	ReleaseName string
 	Namespace   string
 	Chart       string
 	Values      map[string]interface{}
+	// Timeout of waiting the release ready, it's rolled back if not ready in time. Defaults to DefaultTimeout
+	Timeout time.Duration
+	// Source is where to get the chart, the bundled Chart is used if not set
+	Source *v1alpha1.ChartSource
+	// RegistryConfigs are the docker configs with the credentials to pull the chart of the OCI Source
+	RegistryConfigs [][]byte
+}
+
+func (r ChartRequest) getTimeout() time.Duration {
+	if r.Timeout > 0 {
+		return r.Timeout
+	}
+	return DefaultTimeout
 }
 
 func NeedUpdate(status release.Status) bool {
@@ -35,6 +57,11 @@ func (d *LocalClient) GetStatus(cfg *action.Configuration, releaseName string) (
 	return rel.Info.Status, nil
 }
 
+func (d *LocalClient) GetRelease(cfg *action.Configuration, releaseName string) (*release.Release, error) {
+	client := action.NewStatus(cfg)
+	return client.Run(releaseName)
+}
+
 func (d *LocalClient) GetValues(cfg *action.Configuration, releaseName string) (map[string]interface{}, error) {
 	client := action.NewGetValues(cfg)
 	vals, err := client.Run(releaseName)
@@ -75,13 +102,15 @@ func (d *LocalClient) Upgrade(cfg *action.Configuration, request ChartRequest) e
 func (d *LocalClient) Update(cfg *action.Configuration, request ChartRequest) error {
 	client := action.NewUpgrade(cfg)
 	client.Namespace = request.Namespace
-	chartRequested, err := loader.Load(request.Chart)
+	chartRequested, err := LoadChart(request)
 	if err != nil {
 		return err
 	}
 	if len(request.Values) == 0 {
 		client.ResetValues = true
 	}
+	client.Atomic = true
+	client.Timeout = request.getTimeout()
 
 	_, err = client.Run(request.ReleaseName, chartRequested, request.Values)
 	return err
@@ -91,11 +120,13 @@ func (d *LocalClient) Install(cfg *action.Configuration, request ChartRequest) e
 	client := action.NewInstall(cfg)
 	client.ReleaseName = request.ReleaseName
 	client.Namespace = request.Namespace
+	client.Atomic = true
+	client.Timeout = request.getTimeout()
 	if client.Version == "" && client.Devel {
 		client.Version = ">0.0.0-0"
 	}
 
-	chartRequested, err := loader.Load(request.Chart)
+	chartRequested, err := LoadChart(request)
 	if err != nil {
 		return err
 	}
@@ -104,6 +135,37 @@ func (d *LocalClient) Install(cfg *action.Configuration, request ChartRequest) e
 	return err
 }
 
+// Rollback rolls back the release to the last deployed revision, and waits for it ready within the request's timeout
+func (d *LocalClient) Rollback(cfg *action.Configuration, request ChartRequest) error {
+	histClient := action.NewHistory(cfg)
+	history, err := histClient.Run(request.ReleaseName)
+	if err != nil {
+		return err
+	}
+
+	version := 0
+	for _, rel := range history {
+		if rel.Info == nil || rel.Version <= version {
+			continue
+		}
+		if rel.Info.Status == release.StatusDeployed || rel.Info.Status == release.StatusSuperseded {
+			version = rel.Version
+		}
+	}
+	if version == 0 {
+		return ErrNoDeployedRevision
+	}
+
+	client := action.NewRollback(cfg)
+	client.Version = version
+	client.Wait = true
+	client.Timeout = request.getTimeout()
+	if err := client.Run(request.ReleaseName); err != nil {
+		return fmt.Errorf("rollback to revision %d: %w", version, err)
+	}
+	return nil
+}
+
 func (d *LocalClient) Uninstall(cfg *action.Configuration, releaseName string) error {
 	_, err := cfg.Releases.History(releaseName)
 	if errors.Is(err, driver.ErrReleaseNotFound) {
@@ -10,12 +10,14 @@ import (
 // Client interface of helm
 type Client interface {
 	GetStatus(cfg *action.Configuration, releaseName string) (release.Status, error)
+	GetRelease(cfg *action.Configuration, releaseName string) (*release.Release, error)
 	GetValues(cfg *action.Configuration, releaseName string) (map[string]interface{}, error)
 	ReleaseExist(cfg *action.Configuration, releaseName string) (bool, error)
 	Upgrade(cfg *action.Configuration, request ChartRequest) error
 	Update(cfg *action.Configuration, request ChartRequest) error
 	Install(cfg *action.Configuration, request ChartRequest) error
 	Uninstall(cfg *action.Configuration, releaseName string) error
+	Rollback(cfg *action.Configuration, request ChartRequest) error
 }
 
 // SetDefaultClient sets the default client
@@ -30,6 +32,10 @@ func GetStatus(cfg *action.Configuration, releaseName string) (release.Status, e
 	return defaultClient.GetStatus(cfg, releaseName)
 }
 
+func GetRelease(cfg *action.Configuration, releaseName string) (*release.Release, error) {
+	return defaultClient.GetRelease(cfg, releaseName)
+}
+
 func GetValues(cfg *action.Configuration, releaseName string) (map[string]interface{}, error) {
 	return defaultClient.GetValues(cfg, releaseName)
 }
@@ -53,3 +59,7 @@ func Install(cfg *action.Configuration, request ChartRequest) error {
 func Uninstall(cfg *action.Configuration, releaseName string) error {
 	return defaultClient.Uninstall(cfg, releaseName)
 }
+
+func Rollback(cfg *action.Configuration, request ChartRequest) error {
+	return defaultClient.Rollback(cfg, request)
+}
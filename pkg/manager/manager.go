@@ -6,13 +6,18 @@ import (
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 
 	milvusiov1alpha1 "github.com/milvus-io/milvus-operator/apis/milvus.io/v1alpha1"
+	milvusiov1beta1 "github.com/milvus-io/milvus-operator/apis/milvus.io/v1beta1"
 )
 
 var (
@@ -24,23 +29,48 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
 	utilruntime.Must(milvusiov1alpha1.AddToScheme(scheme))
+	utilruntime.Must(milvusiov1beta1.AddToScheme(scheme))
 	utilruntime.Must(monitoringv1.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
-func NewManager(metricsAddr, probeAddr string, enableLeaderElection bool) (ctrl.Manager, error) {
+// DefaultLeaderElectionID is the leader election ID of the manager if not set
+const DefaultLeaderElectionID = "71808ec5.milvus.io"
+
+// Options scope the instances handled by the manager, so that several managers can share the instances
+type Options struct {
+	// LeaderElectionID defaults to DefaultLeaderElectionID,
+	// it should be different for each of the managers sharing the instances in the same namespace
+	LeaderElectionID string
+	// Namespaces are the namespaces watched, all the namespaces are watched if empty
+	Namespaces []string
+	// InstanceSelector selects the Milvus and MilvusCluster instances handled, all of them are handled if nil
+	InstanceSelector labels.Selector
+}
+
+func NewManager(metricsAddr, probeAddr string, enableLeaderElection bool, opts Options) (ctrl.Manager, error) {
 	syncPeriod := 1 * time.Minute
+	leaderElectionID := opts.LeaderElectionID
+	if leaderElectionID == "" {
+		leaderElectionID = DefaultLeaderElectionID
+	}
 	ctrlOptions := ctrl.Options{
 		Scheme:                 scheme,
 		MetricsBindAddress:     metricsAddr,
 		Port:                   9443,
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
-		LeaderElectionID:       "71808ec5.milvus.io",
+		LeaderElectionID:       leaderElectionID,
 		SyncPeriod:             &syncPeriod,
+		NewCache:               newCache(opts.Namespaces, opts.InstanceSelector),
+		// read directly, so that it doesn't require to watch the cluster scoped resource
+		ClientDisableCacheFor: []client.Object{&milvusiov1alpha1.MilvusOperatorConfig{}},
 	}
 
 	conf := ctrl.GetConfigOrDie()
+	// the fields updated by the operator are managed by milvus-operator instead of the executable name manager,
+	// which is left to the fields updated before the operator applied the child resources
+	conf.UserAgent = "milvus-operator"
 	mgr, err := ctrl.NewManager(conf, ctrlOptions)
 	if err != nil {
 		mgrLog.Error(err, "unable to start manager")
@@ -60,3 +90,24 @@ func NewManager(metricsAddr, probeAddr string, enableLeaderElection bool) (ctrl.
 
 	return mgr, nil
 }
+
+// newCache creates the cache of the objects in the namespaces, with the instances not selected filtered out
+func newCache(namespaces []string, instanceSelector labels.Selector) cache.NewCacheFunc {
+	return func(config *rest.Config, opts cache.Options) (cache.Cache, error) {
+		if instanceSelector != nil {
+			opts.SelectorsByObject = cache.SelectorsByObject{
+				&milvusiov1alpha1.Milvus{}:        {Label: instanceSelector},
+				&milvusiov1alpha1.MilvusCluster{}: {Label: instanceSelector},
+			}
+		}
+		switch len(namespaces) {
+		case 0:
+			return cache.New(config, opts)
+		case 1:
+			opts.Namespace = namespaces[0]
+			return cache.New(config, opts)
+		default:
+			return cache.MultiNamespacedCacheBuilder(namespaces)(config, opts)
+		}
+	}
+}
@@ -1,7 +1,7 @@
 package milvus
 
 import (
-	"github.com/milvus-io/milvus-operator/pkg/util"
+	"github.com/milvus-io/milvus-operator/apis/milvus.io/v1alpha1"
 )
 
 const (
@@ -112,22 +112,36 @@ type MilvusConfigLog struct {
 	Format string `json:"format"`
 }
 
-func NewMinioConfig(endpoint, bucket string, useSSL bool) MilvusConfigMinio {
+// NewMinioConfig returns the minio config of the storage endpoint, useSSL is decided by its scheme if it has one
+func NewMinioConfig(endpoint, bucket string, useSSL bool) (MilvusConfigMinio, error) {
 	minio := MilvusConfigMinio{
 		BucketName: bucket,
 		UseSSL:     useSSL,
 	}
-	address, port := util.GetHostPort(endpoint)
-	minio.Address = address
-	minio.Port = port
-	return minio
+	parsed, err := v1alpha1.MilvusStorage{Endpoint: endpoint}.GetEndpoint()
+	if err != nil {
+		return minio, err
+	}
+	minio.Address = parsed.Address()
+	minio.Port = parsed.Port
+	if parsed.Scheme != "" {
+		minio.UseSSL = parsed.IsSecure()
+	}
+	return minio, nil
 }
 
-func NewPulsarConfig(endpoint string, maxMessageSize int64) MilvusConfigPulsar {
+// NewPulsarConfig returns the pulsar config of the pulsar endpoint, the address is the URL if it's pulsar+ssl
+func NewPulsarConfig(endpoint string, maxMessageSize int64) (MilvusConfigPulsar, error) {
 	pulsar := MilvusConfigPulsar{}
-	address, port := util.GetHostPort(endpoint)
-	pulsar.Address = address
-	pulsar.Port = port
+	parsed, err := v1alpha1.MilvusPulsar{Endpoint: endpoint}.GetEndpoint()
+	if err != nil {
+		return pulsar, err
+	}
+	pulsar.Address = parsed.Address()
+	if parsed.IsSecure() {
+		pulsar.Address = parsed.String()
+	}
+	pulsar.Port = parsed.Port
 	pulsar.MaxMessageSize = maxMessageSize
-	return pulsar
+	return pulsar, nil
 }
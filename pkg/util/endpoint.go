@@ -0,0 +1,145 @@
+package util
+
+import (
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// schemeDefaultPorts are the default ports of the schemes, used if the endpoint has no port
+var schemeDefaultPorts = map[string]int32{
+	"http":       80,
+	"https":      443,
+	"pulsar":     6650,
+	"pulsar+ssl": 6651,
+}
+
+// secureSchemes are the schemes connected with TLS
+var secureSchemes = map[string]bool{
+	"https":      true,
+	"pulsar+ssl": true,
+}
+
+// Endpoint is the endpoint of a dependency, parsed from host, host:port, [IPv6]:port or scheme://host:port
+type Endpoint struct {
+	// Scheme is empty if the endpoint has no scheme
+	Scheme string
+	// Host is the host name or the IP, without the brackets of IPv6
+	Host string
+	Port int32
+}
+
+// ParseEndpoint parses the endpoint, the port defaults to the one of the scheme, or defaultPort if it has no scheme.
+// The scheme must be one of the schemes if they're given
+func ParseEndpoint(endpoint string, defaultPort int32, schemes ...string) (Endpoint, error) {
+	ret := Endpoint{}
+	endpoint = strings.TrimSpace(endpoint)
+	if endpoint == "" {
+		return ret, errors.New("empty endpoint")
+	}
+
+	var host, port string
+	if strings.Contains(endpoint, "://") {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return ret, errors.Wrapf(err, "invalid endpoint %s", endpoint)
+		}
+		if (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.User != nil {
+			return ret, errors.Errorf("invalid endpoint %s: only scheme, host and port are allowed", endpoint)
+		}
+		ret.Scheme = strings.ToLower(u.Scheme)
+		host, port = u.Hostname(), u.Port()
+	} else {
+		var err error
+		host, port, err = net.SplitHostPort(endpoint)
+		if err != nil {
+			// no port: a host name, a bare IPv6 or a bracketed one
+			host, port = endpoint, ""
+			if strings.HasPrefix(host, "[") || strings.HasSuffix(host, "]") {
+				if !strings.HasPrefix(host, "[") || !strings.HasSuffix(host, "]") {
+					return ret, errors.Wrapf(err, "invalid endpoint %s", endpoint)
+				}
+				host = host[1 : len(host)-1]
+			}
+			if strings.Contains(host, ":") && net.ParseIP(host) == nil {
+				return ret, errors.Wrapf(err, "invalid endpoint %s", endpoint)
+			}
+		}
+	}
+
+	if len(schemes) > 0 && !containsString(schemes, ret.Scheme) {
+		return ret, errors.Errorf("invalid endpoint %s: scheme should be one of %v", endpoint, schemes)
+	}
+	if host == "" || strings.ContainsAny(host, "/ \t") {
+		return ret, errors.Errorf("invalid endpoint %s: invalid host", endpoint)
+	}
+	if strings.Contains(host, ":") && net.ParseIP(host) == nil {
+		return ret, errors.Errorf("invalid endpoint %s: invalid IPv6 address", endpoint)
+	}
+	ret.Host = host
+
+	ret.Port = defaultPort
+	if schemePort, ok := schemeDefaultPorts[ret.Scheme]; ok {
+		ret.Port = schemePort
+	}
+	if port != "" {
+		portInt, err := strconv.Atoi(port)
+		if err != nil || portInt <= 0 || portInt > 65535 {
+			return ret, errors.Errorf("invalid endpoint %s: invalid port %s", endpoint, port)
+		}
+		ret.Port = int32(portInt)
+	}
+	return ret, nil
+}
+
+// IsIPv6 returns whether the host is an IPv6 address
+func (e Endpoint) IsIPv6() bool {
+	return strings.Contains(e.Host, ":")
+}
+
+// IsSecure returns whether the scheme is connected with TLS
+func (e Endpoint) IsSecure() bool {
+	return secureSchemes[e.Scheme]
+}
+
+// Address returns the host with the brackets of IPv6, to be joined with the port
+func (e Endpoint) Address() string {
+	if e.IsIPv6() {
+		return "[" + e.Host + "]"
+	}
+	return e.Host
+}
+
+// HostPort returns host:port, or [IPv6]:port
+func (e Endpoint) HostPort() string {
+	return net.JoinHostPort(e.Host, strconv.Itoa(int(e.Port)))
+}
+
+// URL returns scheme://host:port, the defaultScheme is used if the endpoint has no scheme
+func (e Endpoint) URL(defaultScheme string) string {
+	scheme := e.Scheme
+	if scheme == "" {
+		scheme = defaultScheme
+	}
+	return scheme + "://" + e.HostPort()
+}
+
+// String returns the endpoint with the port, and the scheme if it has
+func (e Endpoint) String() string {
+	if e.Scheme == "" {
+		return e.HostPort()
+	}
+	return e.URL("")
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -4,8 +4,6 @@ import (
 	"bytes"
 	"crypto/sha256"
 	"fmt"
-	"net"
-	"strconv"
 	"strings"
 	"text/template"
 
@@ -22,6 +20,26 @@ func GetBoolValue(values map[string]interface{}, fields ...string) (bool, bool)
 	return val, true
 }
 
+// GetNumberValue returns the number in values, the numbers unmarshaled from json are float64
+func GetNumberValue(values map[string]interface{}, fields ...string) (float64, bool) {
+	val, found, err := unstructured.NestedFieldNoCopy(values, fields...)
+	if err != nil || !found {
+		return 0, false
+	}
+
+	switch v := val.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	}
+	return 0, false
+}
+
 func DeleteValue(values map[string]interface{}, fields ...string) {
 	unstructured.RemoveNestedField(values, fields...)
 }
@@ -58,18 +76,45 @@ func MergeValues(origin, patch map[string]interface{}) {
 	}
 }
 
-func GetHostPort(endpoint string) (string, int32) {
-	host, port, err := net.SplitHostPort(endpoint)
-	if err != nil {
-		return endpoint, 80
-	}
+// MergeDefaultValues merges the defaults into the origin, the values already set in the origin are kept
+func MergeDefaultValues(origin, defaults map[string]interface{}) {
+	for k, defaultV := range defaults {
+		originV, exist := origin[k]
+		if !exist {
+			origin[k] = copyValue(defaultV)
+			continue
+		}
 
-	portInt, err := strconv.Atoi(port)
-	if err != nil {
-		return host, 80
+		originValues, ok := originV.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		defaultValues, ok := defaultV.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		MergeDefaultValues(originValues, defaultValues)
 	}
+}
 
-	return host, int32(portInt)
+// copyValue deep copies the maps and slices in the value so that the merged values don't share them
+func copyValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		ret := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			ret[k] = copyValue(item)
+		}
+		return ret
+	case []interface{}:
+		ret := make([]interface{}, len(v))
+		for i, item := range v {
+			ret[i] = copyValue(item)
+		}
+		return ret
+	default:
+		return v
+	}
 }
 
 func GetTemplatedValues(templateConfig string, values interface{}) ([]byte, error) {
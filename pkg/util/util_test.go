@@ -28,6 +28,26 @@ func Test_GetBoolValue_SetValue_DeleteValue(t *testing.T) {
 
 }
 
+func TestGetNumberValue(t *testing.T) {
+	values := map[string]interface{}{}
+	_, found := GetNumberValue(values, "l1", "l2")
+	assert.False(t, found)
+
+	SetValue(values, int64(2), "l1", "l2")
+	val, found := GetNumberValue(values, "l1", "l2")
+	assert.True(t, found)
+	assert.Equal(t, float64(2), val)
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"l1": {"l2": 1.5}}`), &values))
+	val, found = GetNumberValue(values, "l1", "l2")
+	assert.True(t, found)
+	assert.Equal(t, 1.5, val)
+
+	SetValue(values, "2", "l1", "l2")
+	_, found = GetNumberValue(values, "l1", "l2")
+	assert.False(t, found)
+}
+
 func TestSetStringSlice(t *testing.T) {
 	origin := map[string]interface{}{}
 	slice := []string{"v1", "v2"}
@@ -75,21 +95,29 @@ func TestMergeValues(t *testing.T) {
 
 }
 
-func TestGetHostPort(t *testing.T) {
-	endPoint := "host:8080"
-	host, port := GetHostPort(endPoint)
-	assert.Equal(t, "host", host)
-	assert.Equal(t, int32(8080), port)
-
-	endPoint = "hostOnly"
-	host, port = GetHostPort(endPoint)
-	assert.Equal(t, "hostOnly", host)
-	assert.Equal(t, int32(80), port)
-
-	endPoint = "host:badPort"
-	host, port = GetHostPort(endPoint)
-	assert.Equal(t, "host", host)
-	assert.Equal(t, int32(80), port)
+func TestMergeDefaultValues(t *testing.T) {
+	origin := map[string]interface{}{
+		"a": 1,
+		"b": map[string]interface{}{"b1": 1},
+		"c": "c",
+	}
+	defaults := map[string]interface{}{
+		"a": 2,
+		"b": map[string]interface{}{"b1": 2, "b2": 2},
+		"c": map[string]interface{}{"c1": 2},
+		"d": map[string]interface{}{"d1": 2},
+	}
+	MergeDefaultValues(origin, defaults)
+	assert.Equal(t, map[string]interface{}{
+		"a": 1,
+		"b": map[string]interface{}{"b1": 1, "b2": 2},
+		"c": "c",
+		"d": map[string]interface{}{"d1": 2},
+	}, origin)
+
+	// copied
+	origin["d"].(map[string]interface{})["d1"] = 1
+	assert.Equal(t, 2, defaults["d"].(map[string]interface{})["d1"])
 }
 
 func TestGetTemplatedValues(t *testing.T) {